@@ -0,0 +1,351 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultAlertStateFile은 알림 상태를 저장하는 기본 파일 경로입니다
+const DefaultAlertStateFile = "data/alert_state.json"
+
+// LoadAlertState는 파일에 저장된 계정별 알림 상태를 불러오고, 이후 상태가 바뀔 때마다 같은 파일에 저장하도록 설정합니다
+// 파일이 없으면 빈 상태로 시작합니다
+func LoadAlertState(path string) error {
+	return globalAlertState.load(path)
+}
+
+// alertStateFile은 알림 상태 파일의 형식입니다
+// 이전 형식(계정별 AlertState 맵)도 계속 읽을 수 있으며, 다음 저장 시 이 형식으로 바뀝니다
+type alertStateFile struct {
+	Accounts     map[string]AlertState `json:"accounts"`
+	Reports      map[string]string     `json:"reports,omitempty"` // "계정/종류"별 마지막 전송 기간
+	SnoozedUntil *time.Time            `json:"snoozedUntil,omitempty"`
+	Ignored      []int                 `json:"ignoredInstances,omitempty"`
+}
+
+func (m *AlertStateManager) load(path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.path = path
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error reading alert state file: %w", err)
+	}
+
+	var file alertStateFile
+	if err := json.Unmarshal(data, &file); err == nil && file.Accounts != nil {
+		m.states = file.Accounts
+		m.reports = file.Reports
+		if file.SnoozedUntil != nil {
+			m.snoozedUntil = *file.SnoozedUntil
+		}
+		for _, id := range file.Ignored {
+			if m.ignored == nil {
+				m.ignored = make(map[int]bool)
+			}
+			m.ignored[id] = true
+		}
+		return nil
+	}
+
+	var states map[string]AlertState
+	if err := json.Unmarshal(data, &states); err != nil {
+		return fmt.Errorf("error parsing alert state file: %w", err)
+	}
+	m.states = states
+	return nil
+}
+
+// save는 알림 상태를 임시 파일에 쓴 뒤 교체하여 저장합니다 (m.mu를 잡은 상태에서 호출)
+func (m *AlertStateManager) save() error {
+	file := alertStateFile{Accounts: m.states, Reports: m.reports}
+	if file.Accounts == nil {
+		file.Accounts = make(map[string]AlertState)
+	}
+	if !m.snoozedUntil.IsZero() {
+		file.SnoozedUntil = &m.snoozedUntil
+	}
+	file.Ignored = m.ignoredIDs()
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling alert state: %w", err)
+	}
+
+	if dir := filepath.Dir(m.path); dir != "." {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return fmt.Errorf("error creating alert state directory: %w", err)
+		}
+	}
+
+	tmpPath := m.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("error writing alert state file: %w", err)
+	}
+	if err := os.Rename(tmpPath, m.path); err != nil {
+		return fmt.Errorf("error replacing alert state file: %w", err)
+	}
+	return nil
+}
+
+// persist는 상태가 바뀐 경우에만 파일에 저장합니다 (m.mu를 잡은 상태에서 호출)
+func (m *AlertStateManager) persist(previous, current AlertState) {
+	if m.path == "" || previous == current {
+		return
+	}
+	if err := m.save(); err != nil {
+		log.Printf("Failed to persist alert state: %v", err)
+	}
+}
+
+// 정기 보고서 종류 (ReportSent/MarkReportSent의 kind)
+const (
+	ReportDaily  = "daily"
+	ReportHourly = "hourly"
+	ReportWeekly = "weekly"
+	ReportWorker = "worker" // POST /api/report 전용 (전송 기록 없음)
+)
+
+// ReportSent는 account의 kind 보고서가 period(예: 날짜, 시간)에 이미 전송되었는지 반환합니다
+// 재시작 직후 타이머가 바로 실행되어 같은 보고서를 다시 보내지 않도록 사용합니다
+func ReportSent(account, kind, period string) bool {
+	return globalAlertState.reportSent(account, kind, period)
+}
+
+// MarkReportSent는 account의 kind 보고서를 period에 전송했다고 알림 상태 파일에 기록합니다
+func MarkReportSent(account, kind, period string) {
+	globalAlertState.markReportSent(account, kind, period)
+}
+
+// reportKey는 계정마다 보고서 전송 기록이 따로 남도록 계정 이름과 종류를 합친 키입니다
+func reportKey(account, kind string) string {
+	return account + "/" + kind
+}
+
+func (m *AlertStateManager) reportSent(account, kind, period string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.reports[reportKey(account, kind)] == period
+}
+
+func (m *AlertStateManager) markReportSent(account, kind, period string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := reportKey(account, kind)
+	if m.reports[key] == period {
+		return
+	}
+	if m.reports == nil {
+		m.reports = make(map[string]string)
+	}
+	m.reports[key] = period
+	if m.path == "" {
+		return
+	}
+	if err := m.save(); err != nil {
+		log.Printf("Failed to persist report state: %v", err)
+	}
+}
+
+// SnoozeAlerts는 until까지 모든 알림을 보내지 않도록 일시 중지합니다
+func SnoozeAlerts(until time.Time) {
+	globalAlertState.setSnooze(until)
+}
+
+// ResumeAlerts는 알림 일시 중지를 해제하며, 일시 중지 중이었으면 true를 반환합니다
+func ResumeAlerts() bool {
+	return globalAlertState.setSnooze(time.Time{})
+}
+
+// AlertsSnoozed는 now에 알림이 일시 중지되어 있는지와 해제 예정 시각을 반환합니다
+func AlertsSnoozed(now time.Time) (time.Time, bool) {
+	return globalAlertState.snoozed(now)
+}
+
+// ExpireSnooze는 일시 중지 시각이 지났으면 해제하고 그 시각과 true를 반환합니다
+// 해제는 한 번만 일어나므로 "알림 재개" 안내를 한 번만 보낼 수 있습니다
+func ExpireSnooze(now time.Time) (time.Time, bool) {
+	return globalAlertState.expireSnooze(now)
+}
+
+func (m *AlertStateManager) snoozed(now time.Time) (time.Time, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.snoozedUntil, now.Before(m.snoozedUntil)
+}
+
+func (m *AlertStateManager) expireSnooze(now time.Time) (time.Time, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	until := m.snoozedUntil
+	if until.IsZero() || now.Before(until) {
+		return time.Time{}, false
+	}
+	m.snoozedUntil = time.Time{}
+	m.saveSnooze()
+	return until, true
+}
+
+// setSnooze는 일시 중지 해제 시각을 바꾸며, 이전에 일시 중지 중이었으면 true를 반환합니다
+func (m *AlertStateManager) setSnooze(until time.Time) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	wasSnoozed := time.Now().Before(m.snoozedUntil)
+	m.snoozedUntil = until
+	m.saveSnooze()
+	return wasSnoozed
+}
+
+// saveSnooze는 일시 중지 상태를 파일에 저장합니다 (m.mu를 잡은 상태에서 호출)
+func (m *AlertStateManager) saveSnooze() {
+	if m.path == "" {
+		return
+	}
+	if err := m.save(); err != nil {
+		log.Printf("Failed to persist alert snooze: %v", err)
+	}
+}
+
+// /alerts 명령어와 확인 처리에 사용하는 알림 종류
+const (
+	AlertVersionOlder  = "version"
+	AlertVersionNewer  = "version_newer"
+	AlertInstanceCount = "instance_count"
+	AlertCredit        = "credit"
+	AlertTokenDrop     = "token_drop"
+	AlertGPUHealth     = "gpu_health"
+	AlertRPMRatio      = "rpm_ratio"
+)
+
+// AlertTypes는 /alerts에 표시하는 순서대로 나열한 알림 종류입니다
+var AlertTypes = []string{AlertVersionOlder, AlertVersionNewer, AlertInstanceCount, AlertCredit, AlertTokenDrop, AlertGPUHealth, AlertRPMRatio}
+
+// AlertAcks는 알림별 확인 처리 여부입니다
+// 확인 처리된 알림은 해소되어도 복구 알림을 보내지 않으며, 해소되면 확인 처리도 해제됩니다
+type AlertAcks struct {
+	Version       bool `json:"version,omitempty"`
+	VersionNewer  bool `json:"versionNewer,omitempty"`
+	InstanceCount bool `json:"instanceCount,omitempty"`
+	Credit        bool `json:"credit,omitempty"`
+	TokenDrop     bool `json:"tokenDrop,omitempty"`
+	GPUHealth     bool `json:"gpuHealth,omitempty"`
+	RPMRatio      bool `json:"rpmRatio,omitempty"`
+}
+
+// ActiveAlert는 현재 활성화된 알림입니다
+type ActiveAlert struct {
+	Type  string    `json:"type"`
+	Since time.Time `json:"since"` // 알 수 없으면 zero
+	Acked bool      `json:"acked"`
+}
+
+// fields는 알림 종류에 해당하는 활성 여부, 시작 시각, 확인 처리 필드를 반환합니다
+func (s *AlertState) fields(alertType string) (active bool, since *time.Time, acked *bool, ok bool) {
+	switch alertType {
+	case AlertVersionOlder:
+		return s.VersionMismatchAlerted, &s.VersionMismatchSince, &s.Acked.Version, true
+	case AlertVersionNewer:
+		return s.VersionNewerAlerted, &s.VersionNewerSince, &s.Acked.VersionNewer, true
+	case AlertInstanceCount:
+		return s.InstanceCountAlerted, &s.InstanceMismatchStart, &s.Acked.InstanceCount, true
+	case AlertCredit:
+		return s.CreditAlerted, &s.CreditSince, &s.Acked.Credit, true
+	case AlertTokenDrop:
+		return s.TokenDropAlerted, &s.TokenDropSince, &s.Acked.TokenDrop, true
+	case AlertGPUHealth:
+		return s.GPUHealthAlerted, &s.GPUHealthSince, &s.Acked.GPUHealth, true
+	case AlertRPMRatio:
+		return s.RPMRatioAlerted, &s.RPMRatioSince, &s.Acked.RPMRatio, true
+	}
+	return false, nil, nil, false
+}
+
+// trackActive는 새로 활성화된 알림의 시작 시각을 기록하고, 해소된 알림의 시작 시각과 확인 처리를 지웁니다
+func (s *AlertState) trackActive(now time.Time) {
+	for _, alertType := range AlertTypes {
+		active, since, acked, _ := s.fields(alertType)
+		if !active {
+			*acked = false
+			if alertType != AlertInstanceCount { // 불일치 시작 시각은 checkInstanceCount가 관리
+				*since = time.Time{}
+			}
+			continue
+		}
+		if since.IsZero() {
+			*since = now
+		}
+	}
+}
+
+// mergeAcks는 저장된 확인 처리 중 아직 활성화된 알림의 것을 유지합니다
+func (s *AlertState) mergeAcks(stored AlertAcks) {
+	previous := AlertState{Acked: stored}
+	for _, alertType := range AlertTypes {
+		active, _, acked, _ := s.fields(alertType)
+		_, _, storedAcked, _ := previous.fields(alertType)
+		if active && *storedAcked {
+			*acked = true
+		}
+	}
+}
+
+// sendUnlessAcked는 확인 처리되지 않은 알림의 메시지만 전송합니다
+func (s *AlertState) sendUnlessAcked(alertType string, sendAlert func(string, string) error, message, channel string) error {
+	if _, _, acked, ok := s.fields(alertType); ok && *acked {
+		log.Printf("Suppressing %s notification (acknowledged)", alertType)
+		return nil
+	}
+	return sendAlert(message, channel)
+}
+
+// ActiveAlerts는 현재 활성화된 알림을 AlertTypes 순서로 반환합니다
+func (s AlertState) ActiveAlerts() []ActiveAlert {
+	var alerts []ActiveAlert
+	for _, alertType := range AlertTypes {
+		active, since, acked, _ := s.fields(alertType)
+		if active {
+			alerts = append(alerts, ActiveAlert{Type: alertType, Since: *since, Acked: *acked})
+		}
+	}
+	return alerts
+}
+
+// CurrentAlertState는 계정의 현재 알림 상태를 반환합니다
+func CurrentAlertState(accountName string) AlertState {
+	return globalAlertState.getState(accountName)
+}
+
+// AcknowledgeAlert는 계정의 활성화된 알림을 해소될 때까지 확인 처리합니다
+func AcknowledgeAlert(accountName, alertType string) error {
+	return globalAlertState.acknowledge(accountName, alertType)
+}
+
+func (m *AlertStateManager) acknowledge(key, alertType string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state := m.states[key]
+	active, _, acked, ok := state.fields(alertType)
+	if !ok {
+		return fmt.Errorf("unknown alert type %q", alertType)
+	}
+	if !active {
+		return fmt.Errorf("alert %q is not active", alertType)
+	}
+
+	previous := state
+	*acked = true
+	m.states[key] = state
+	m.persist(previous, state)
+	return nil
+}
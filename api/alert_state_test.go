@@ -0,0 +1,203 @@
+package api
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAlertStatePersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "alert_state.json")
+
+	m := &AlertStateManager{}
+	if err := m.load(path); err != nil {
+		t.Fatalf("expected missing file to be ignored, got %v", err)
+	}
+
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	m.setState("account1", AlertState{InstanceCountAlerted: true, InstanceMismatchStart: start})
+
+	restored := &AlertStateManager{}
+	if err := restored.load(path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	state := restored.getState("account1")
+	if !state.InstanceCountAlerted {
+		t.Error("expected InstanceCountAlerted to be restored")
+	}
+	if !state.InstanceMismatchStart.Equal(start) {
+		t.Errorf("expected mismatch start %v, got %v", start, state.InstanceMismatchStart)
+	}
+}
+
+func TestAlertStateLoadsLegacyFormat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "alert_state.json")
+	if err := os.WriteFile(path, []byte(`{"account1": {"creditAlerted": true}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	m := &AlertStateManager{}
+	if err := m.load(path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !m.getState("account1").CreditAlerted {
+		t.Error("expected legacy per-account state to be loaded")
+	}
+}
+
+func TestReportSentPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "alert_state.json")
+
+	m := &AlertStateManager{}
+	if err := m.load(path); err != nil {
+		t.Fatal(err)
+	}
+	m.setState("account1", AlertState{CreditAlerted: true})
+	if m.reportSent("account1", ReportDaily, "2025-01-02") {
+		t.Error("expected no report to be marked before sending")
+	}
+	m.markReportSent("account1", ReportDaily, "2025-01-02")
+	m.markReportSent("account1", ReportHourly, "2025-01-02T09")
+	if m.reportSent("account2", ReportDaily, "2025-01-02") {
+		t.Error("expected another account's report to be unsent")
+	}
+
+	restored := &AlertStateManager{}
+	if err := restored.load(path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !restored.reportSent("account1", ReportDaily, "2025-01-02") || !restored.reportSent("account1", ReportHourly, "2025-01-02T09") {
+		t.Error("expected sent reports to be restored after a restart")
+	}
+	if restored.reportSent("account1", ReportDaily, "2025-01-03") || restored.reportSent("account1", ReportHourly, "2025-01-02T10") {
+		t.Error("expected later periods to be unsent")
+	}
+	if !restored.getState("account1").CreditAlerted {
+		t.Error("expected alert state to be kept alongside report state")
+	}
+}
+
+func TestAlertStateIsolatedPerAccount(t *testing.T) {
+	m := &AlertStateManager{}
+	m.setState("account1", AlertState{VersionMismatchAlerted: true})
+	m.setState("account2", AlertState{})
+
+	if !m.getState("account1").VersionMismatchAlerted {
+		t.Error("expected account1 state to be unaffected by account2 update")
+	}
+	if m.getState("account2").VersionMismatchAlerted {
+		t.Error("expected account2 state to be independent")
+	}
+}
+
+func TestAlertAcknowledgeUntilCleared(t *testing.T) {
+	m := &AlertStateManager{}
+	if err := m.acknowledge("account1", AlertCredit); err == nil {
+		t.Error("expected acknowledging an inactive alert to fail")
+	}
+	if err := m.acknowledge("account1", "nope"); err == nil {
+		t.Error("expected unknown alert type to fail")
+	}
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	state := AlertState{CreditAlerted: true}
+	state.trackActive(now)
+	m.setState("account1", state)
+
+	if err := m.acknowledge("account1", AlertCredit); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// 수집 루프가 확인 처리 전 상태로 덮어써도 확인 처리는 유지됨
+	m.setState("account1", state)
+	alerts := m.getState("account1").ActiveAlerts()
+	if len(alerts) != 1 || alerts[0].Type != AlertCredit || !alerts[0].Acked || !alerts[0].Since.Equal(now) {
+		t.Fatalf("unexpected active alerts: %+v", alerts)
+	}
+
+	// 확인 처리된 알림의 복구 메시지는 전송하지 않음
+	state = m.getState("account1")
+	sent := false
+	if err := state.sendUnlessAcked(AlertCredit, func(string, string) error { sent = true; return nil }, "recovered", "status"); err != nil || sent {
+		t.Errorf("expected recovery notice to be suppressed (sent=%v, err=%v)", sent, err)
+	}
+
+	// 해소되면 시작 시각과 확인 처리가 해제됨
+	state.CreditAlerted = false
+	state.trackActive(now.Add(time.Hour))
+	m.setState("account1", state)
+	cleared := m.getState("account1")
+	if cleared.Acked.Credit || !cleared.CreditSince.IsZero() || len(cleared.ActiveAlerts()) != 0 {
+		t.Errorf("expected alert and acknowledgement to be cleared, got %+v", cleared)
+	}
+}
+
+func TestAlertSnoozePersistenceAndExpiry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "alert_state.json")
+
+	m := &AlertStateManager{}
+	if err := m.load(path); err != nil {
+		t.Fatal(err)
+	}
+	until := time.Now().Add(2 * time.Hour).Truncate(time.Second)
+	if m.setSnooze(until) {
+		t.Error("expected alerts not to be snoozed before /snooze")
+	}
+
+	restored := &AlertStateManager{}
+	if err := restored.load(path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, snoozed := restored.snoozed(time.Now()); !snoozed || !got.Equal(until) {
+		t.Fatalf("expected snooze until %v to be restored, got %v (%v)", until, got, snoozed)
+	}
+
+	// 해제 시각 전에는 만료되지 않고, 지나면 한 번만 만료
+	if _, expired := restored.expireSnooze(time.Now()); expired {
+		t.Error("expected snooze not to expire early")
+	}
+	if _, expired := restored.expireSnooze(until); !expired {
+		t.Error("expected snooze to expire at its end time")
+	}
+	if _, expired := restored.expireSnooze(until.Add(time.Minute)); expired {
+		t.Error("expected snooze to expire only once")
+	}
+	if _, snoozed := restored.snoozed(until.Add(-time.Minute)); snoozed {
+		t.Error("expected alerts to be resumed after expiry")
+	}
+}
+
+func TestIgnoredInstancesPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "alert_state.json")
+
+	m := &AlertStateManager{}
+	if err := m.load(path); err != nil {
+		t.Fatal(err)
+	}
+	if !m.setIgnored(202, true) || !m.setIgnored(101, true) {
+		t.Fatal("expected instances to be newly ignored")
+	}
+	if m.setIgnored(101, true) {
+		t.Error("expected ignoring an ignored instance to report no change")
+	}
+
+	restored := &AlertStateManager{}
+	if err := restored.load(path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ids := restored.ignoredIDs(); len(ids) != 2 || ids[0] != 101 || ids[1] != 202 {
+		t.Fatalf("expected ignored instances [101 202] to be restored, got %v", ids)
+	}
+
+	if !restored.setIgnored(101, false) || restored.setIgnored(101, false) {
+		t.Error("expected unignore to report a change only once")
+	}
+	reloaded := &AlertStateManager{}
+	if err := reloaded.load(path); err != nil {
+		t.Fatal(err)
+	}
+	if ids := reloaded.ignoredIDs(); len(ids) != 1 || ids[0] != 202 {
+		t.Errorf("expected only 202 to remain ignored, got %v", ids)
+	}
+}
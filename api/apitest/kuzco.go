@@ -0,0 +1,184 @@
+// Package apitest는 api 패키지 테스트용 Kuzco API 목 서버를 제공합니다
+package apitest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"test/api"
+	"testing"
+)
+
+// Instance는 worker.list 응답에 포함될 인스턴스입니다
+type Instance struct {
+	ID      string
+	Status  string
+	Lane    string
+	Runtime string
+	Version string
+	IP      string
+	GPU     string // nvidia-smi product_name (예: "NVIDIA GeForce RTX 3090")
+	Country string
+	Region  string
+	City    string
+}
+
+// Worker는 worker.list 응답에 포함될 워커입니다
+type Worker struct {
+	ID        string
+	Name      string
+	TeamID    string
+	Archived  bool
+	Instances []Instance
+}
+
+// KuzcoFixture는 목 서버가 반환할 값입니다
+type KuzcoFixture struct {
+	Token      string
+	UserID     string
+	CLIVersion string
+
+	// MetricValue는 숫자형 메트릭 엔드포인트의 기본 응답 값입니다
+	MetricValue int
+	// Metrics는 엔드포인트별 응답 값으로 MetricValue보다 우선합니다
+	Metrics map[string]int
+	// GenerationsLastHour는 generationsHistory 엔드포인트가 반환하는 최근 1시간 생성량입니다
+	GenerationsLastHour int
+	// TokenEarningsHistory는 tokenEarningsHistory 엔드포인트가 반환하는 시간별 토큰 적립량입니다
+	TokenEarningsHistory []api.TokenEarningsHistory
+
+	Workers []Worker
+
+	// FailingEndpoints에 포함된 엔드포인트는 500을 반환합니다
+	FailingEndpoints []string
+}
+
+// DefaultKuzcoFixture는 대부분의 테스트에서 사용할 기본 응답 값을 반환합니다
+func DefaultKuzcoFixture() KuzcoFixture {
+	return KuzcoFixture{
+		Token:               "test-token",
+		UserID:              "user-1",
+		CLIVersion:          "0.2.3",
+		MetricValue:         123,
+		GenerationsLastHour: 5,
+	}
+}
+
+// NewKuzcoServer는 tRPC 형식의 응답을 반환하는 Kuzco API 목 서버를 시작합니다
+// 서버는 테스트 종료 시 자동으로 닫힙니다
+func NewKuzcoServer(t *testing.T, fixture KuzcoFixture) *httptest.Server {
+	t.Helper()
+
+	failing := make(map[string]bool, len(fixture.FailingEndpoints))
+	for _, endpoint := range fixture.FailingEndpoints {
+		failing[endpoint] = true
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		endpoint := strings.TrimPrefix(r.URL.Path, "/")
+		if failing[endpoint] {
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+
+		var data interface{}
+		switch endpoint {
+		case api.EndpointUserLogin:
+			data = map[string]interface{}{
+				"token": fixture.Token,
+				"user":  map[string]string{"_id": fixture.UserID},
+			}
+		case api.EndpointSystemBucketVersions:
+			data = map[string]string{"cliVersion": fixture.CLIVersion}
+		case api.EndpointMetricsGenerationsHistory:
+			data = []map[string]interface{}{
+				{"date": "2025-01-01T00:00:00Z", "value": fixture.GenerationsLastHour},
+			}
+		case api.EndpointMetricsTokensHistory:
+			data = fixture.TokenEarningsHistory
+		case "worker.list":
+			data = map[string]interface{}{"workers": workerList(fixture.Workers)}
+		default:
+			value, ok := fixture.Metrics[endpoint]
+			if !ok {
+				value = fixture.MetricValue
+			}
+			data = value
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode([]interface{}{
+			map[string]interface{}{"result": map[string]interface{}{"data": map[string]interface{}{"json": data}}},
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// NewClient는 목 서버를 바라보는 api.Client를 생성합니다
+func NewClient(server *httptest.Server) *api.Client {
+	client := api.NewClient()
+	client.SetBaseURL(server.URL + "/")
+	return client
+}
+
+// ChdirWithGPUPrices는 GetWorkers가 읽는 instance.json을 임시 디렉터리에 쓰고 그 디렉터리로 이동합니다
+// 테스트 종료 시 원래 디렉터리로 돌아갑니다
+func ChdirWithGPUPrices(t *testing.T, instanceJSON string) {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "instance.json"), []byte(instanceJSON), 0600); err != nil {
+		t.Fatal(err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+// workerList는 워커 목록을 worker.list 응답 형식으로 변환합니다
+func workerList(workers []Worker) []interface{} {
+	list := make([]interface{}, 0, len(workers))
+	for _, w := range workers {
+		instances := make([]interface{}, 0, len(w.Instances))
+		for _, inst := range w.Instances {
+			var pools []interface{}
+			if inst.Lane != "" {
+				pools = append(pools, map[string]string{"lane": inst.Lane})
+			}
+			var gpus []interface{}
+			if inst.GPU != "" {
+				gpus = append(gpus, map[string][]string{"product_name": {inst.GPU}})
+			}
+			instances = append(instances, map[string]interface{}{
+				"_id":             inst.ID,
+				"status":          inst.Status,
+				"poolAssignments": pools,
+				"info": map[string]interface{}{
+					"runtime":   inst.Runtime,
+					"version":   inst.Version,
+					"ipAddress": inst.IP,
+					"country":   inst.Country,
+					"region":    inst.Region,
+					"city":      inst.City,
+					"nvidiaSmi": map[string]interface{}{"gpu": gpus},
+				},
+			})
+		}
+		list = append(list, map[string]interface{}{
+			"_id":        w.ID,
+			"name":       w.Name,
+			"isArchived": w.Archived,
+			"teamId":     w.TeamID,
+			"instances":  instances,
+		})
+	}
+	return list
+}
@@ -1,7 +1,6 @@
 package api
 
 import (
-	"encoding/json"
 	"fmt"
 	"time"
 )
@@ -78,16 +77,19 @@ func (c *Client) Login(email, password string) (string, string, error) {
 		} `json:"result"`
 	}
 
-	if err := json.Unmarshal(respBody, &loginResp); err != nil {
-		return "", "", fmt.Errorf("error parsing response: %w", err)
+	if err := parseJSONOrError(EndpointUserLogin, respBody, &loginResp); err != nil {
+		return "", "", err
 	}
 
 	if len(loginResp) == 0 {
-		return "", "", fmt.Errorf("empty response received")
+		return "", "", fmt.Errorf("empty response received: %w", ErrParse)
 	}
 	if loginResp[0].Result.Data.JSON.Token == "" {
 		return "", "", fmt.Errorf("failed to login account : %s", email)
 	}
+	if loginResp[0].Result.Data.JSON.User.ID == "" {
+		return "", "", fmt.Errorf("login succeeded but no user/team ID was returned for account : %s", email)
+	}
 
 	return loginResp[0].Result.Data.JSON.Token, loginResp[0].Result.Data.JSON.User.ID, nil
 }
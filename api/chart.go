@@ -0,0 +1,84 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+)
+
+// 막대 차트 색상
+var (
+	chartBackground = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	chartGrid       = color.RGBA{R: 0xe0, G: 0xe0, B: 0xe0, A: 0xff}
+	chartAxis       = color.RGBA{R: 0x60, G: 0x60, B: 0x60, A: 0xff}
+	chartBar        = color.RGBA{R: 0x3b, G: 0x82, B: 0xf6, A: 0xff}
+)
+
+// chartPadding은 차트 영역 바깥 여백(px)입니다
+const chartPadding = 16
+
+// RenderBarChart는 values를 왼쪽부터 순서대로 막대로 그린 PNG를 반환합니다
+// 표준 라이브러리에는 글꼴 렌더링이 없으므로 축 레이블 없이 25% 간격의 눈금선만 그리며,
+// 범위와 최댓값은 호출하는 쪽에서 캡션으로 전달합니다
+func RenderBarChart(values []int, width, height int) ([]byte, error) {
+	if len(values) == 0 {
+		return nil, fmt.Errorf("no values to chart")
+	}
+	plotWidth := width - 2*chartPadding
+	plotHeight := height - 2*chartPadding
+	if plotWidth < len(values) || plotHeight <= 0 {
+		return nil, fmt.Errorf("chart size %dx%d is too small for %d bars", width, height, len(values))
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	fillRect(img, 0, 0, width, height, chartBackground)
+
+	left, top := chartPadding, chartPadding
+	bottom := top + plotHeight
+
+	for i := 1; i <= 4; i++ {
+		y := bottom - plotHeight*i/4
+		fillRect(img, left, y, left+plotWidth, y+1, chartGrid)
+	}
+
+	maxValue := 0
+	for _, v := range values {
+		if v > maxValue {
+			maxValue = v
+		}
+	}
+
+	slot := plotWidth / len(values)
+	gap := slot / 5
+	for i, v := range values {
+		if v <= 0 || maxValue == 0 {
+			continue
+		}
+		barHeight := plotHeight * v / maxValue
+		if barHeight == 0 {
+			barHeight = 1
+		}
+		x := left + i*slot
+		fillRect(img, x+gap, bottom-barHeight, x+slot-gap, bottom, chartBar)
+	}
+
+	fillRect(img, left, bottom, left+plotWidth, bottom+1, chartAxis)
+	fillRect(img, left, top, left+1, bottom, chartAxis)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode chart: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// fillRect는 [x0, x1) x [y0, y1) 영역을 c로 채웁니다
+func fillRect(img *image.RGBA, x0, y0, x1, y1 int, c color.RGBA) {
+	for y := y0; y < y1; y++ {
+		for x := x0; x < x1; x++ {
+			img.SetRGBA(x, y, c)
+		}
+	}
+}
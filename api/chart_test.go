@@ -0,0 +1,46 @@
+package api
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+)
+
+func TestRenderBarChart(t *testing.T) {
+	data, err := RenderBarChart([]int{0, 5, 10}, 130, 100)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Expected a valid PNG: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 130 || b.Dy() != 100 {
+		t.Fatalf("Unexpected size %v", b)
+	}
+
+	// 막대 영역: 16px 여백, 막대당 32px 슬롯, 그래프 높이 68px
+	isBar := func(x, y int) bool {
+		r, g, b, _ := img.At(x, y).RGBA()
+		return r>>8 == uint32(chartBar.R) && g>>8 == uint32(chartBar.G) && b>>8 == uint32(chartBar.B)
+	}
+	if isBar(16+16, 80) {
+		t.Error("Expected no bar for a zero value")
+	}
+	if !isBar(16+32+16, 80) || isBar(16+32+16, 30) {
+		t.Error("Expected a half-height bar for the middle value")
+	}
+	if !isBar(16+64+16, 20) {
+		t.Error("Expected a full-height bar for the maximum value")
+	}
+}
+
+func TestRenderBarChartErrors(t *testing.T) {
+	if _, err := RenderBarChart(nil, 100, 100); err == nil {
+		t.Error("Expected error without values")
+	}
+	if _, err := RenderBarChart(make([]int, 10), 30, 100); err == nil {
+		t.Error("Expected error when the chart is narrower than the bars")
+	}
+}
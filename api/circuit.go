@@ -0,0 +1,84 @@
+package api
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultCircuitFailureThreshold는 회로를 여는 연속 수집 실패 횟수의 기본값입니다
+const DefaultCircuitFailureThreshold = 5
+
+// DefaultCircuitOpenMinutes는 회로가 열린 동안 수집을 다시 시도하는 간격(분)의 기본값입니다
+const DefaultCircuitOpenMinutes = 5
+
+// CircuitBreaker는 Kuzco 장애가 길어질 때 매분 실패할 요청을 보내지 않도록 수집 간격을 늘립니다
+// 연속 실패가 threshold에 도달하면 열리고(degraded), 열린 동안에는 openInterval마다 한 번만 시도하며
+// 시도가 성공하면 닫힙니다
+type CircuitBreaker struct {
+	threshold    int
+	openInterval time.Duration
+	failures     int
+	open         bool
+	nextProbe    time.Time
+	mu           sync.Mutex
+}
+
+// NewCircuitBreaker는 0 이하의 값에 기본값을 적용하여 CircuitBreaker를 생성합니다
+func NewCircuitBreaker(threshold int, openInterval time.Duration) *CircuitBreaker {
+	if threshold <= 0 {
+		threshold = DefaultCircuitFailureThreshold
+	}
+	if openInterval <= 0 {
+		openInterval = DefaultCircuitOpenMinutes * time.Minute
+	}
+	return &CircuitBreaker{threshold: threshold, openInterval: openInterval}
+}
+
+// Allow는 now에 수집을 시도해야 하는지 반환합니다
+func (b *CircuitBreaker) Allow(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return !b.open || !now.Before(b.nextProbe)
+}
+
+// RecordFailure는 실패를 기록하며, 이번 실패로 회로가 열렸으면 true를 반환합니다
+func (b *CircuitBreaker) RecordFailure(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	if b.open {
+		b.nextProbe = now.Add(b.openInterval)
+		return false
+	}
+	if b.failures < b.threshold {
+		return false
+	}
+	b.open = true
+	b.nextProbe = now.Add(b.openInterval)
+	return true
+}
+
+// RecordSuccess는 성공을 기록하며, 열려 있던 회로가 닫혔으면 true를 반환합니다
+func (b *CircuitBreaker) RecordSuccess() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	recovered := b.open
+	b.failures = 0
+	b.open = false
+	b.nextProbe = time.Time{}
+	return recovered
+}
+
+// Failures는 현재 연속 실패 횟수를 반환합니다
+func (b *CircuitBreaker) Failures() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.failures
+}
+
+// OpenInterval은 회로가 열린 동안의 재시도 간격을 반환합니다
+func (b *CircuitBreaker) OpenInterval() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.openInterval
+}
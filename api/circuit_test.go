@@ -0,0 +1,65 @@
+package api
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerOpensAndRecovers(t *testing.T) {
+	b := NewCircuitBreaker(3, 5*time.Minute)
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 2; i++ {
+		if !b.Allow(now) {
+			t.Fatalf("Expected closed circuit to allow attempt %d", i+1)
+		}
+		if b.RecordFailure(now) {
+			t.Fatalf("Circuit opened too early after %d failures", i+1)
+		}
+		now = now.Add(time.Minute)
+	}
+	if !b.RecordFailure(now) {
+		t.Fatal("Expected circuit to open after 3 consecutive failures")
+	}
+
+	// 열린 동안에는 5분마다 한 번만 시도
+	if b.Allow(now.Add(4 * time.Minute)) {
+		t.Error("Expected open circuit to skip attempts before the probe interval")
+	}
+	now = now.Add(5 * time.Minute)
+	if !b.Allow(now) {
+		t.Fatal("Expected open circuit to allow a probe after the interval")
+	}
+	if b.RecordFailure(now) {
+		t.Error("Failed probe must not report the circuit opening again")
+	}
+	if b.Allow(now.Add(time.Minute)) {
+		t.Error("Expected failed probe to push back the next attempt")
+	}
+
+	now = now.Add(5 * time.Minute)
+	if !b.RecordSuccess() {
+		t.Error("Expected successful probe to close the circuit")
+	}
+	if b.RecordSuccess() || !b.Allow(now) || b.Failures() != 0 {
+		t.Error("Expected closed circuit after recovery")
+	}
+}
+
+func TestCircuitBreakerSuccessResetsFailures(t *testing.T) {
+	b := NewCircuitBreaker(2, time.Minute)
+	now := time.Now()
+
+	b.RecordFailure(now)
+	b.RecordSuccess()
+	if b.RecordFailure(now) {
+		t.Error("Expected failure count to reset after a success")
+	}
+}
+
+func TestNewCircuitBreakerDefaults(t *testing.T) {
+	b := NewCircuitBreaker(0, 0)
+	if b.threshold != DefaultCircuitFailureThreshold || b.OpenInterval() != DefaultCircuitOpenMinutes*time.Minute {
+		t.Errorf("Unexpected defaults: threshold %d, interval %v", b.threshold, b.OpenInterval())
+	}
+}
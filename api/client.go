@@ -3,24 +3,96 @@ package api
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"strings"
+	"sync"
+	"time"
 )
 
 type Client struct {
-	baseURL    string
-	httpClient *http.Client
-	token      string
-	alertState AlertState
+	baseURL            string
+	httpClient         *http.Client
+	token              string
+	email              string // 토큰 만료 시 다시 로그인하기 위한 계정 정보 (SetCredentials)
+	password           string
+	accountName        string // 알림 상태 등 계정별 데이터를 구분하는 키
+	vastaiCostSource   string
+	userAgent          string
+	tokenSamples       []tokenSample                    // 토큰 급감 감지용 최근 샘플
+	workerTokenSamples map[string][]tokenSample         // 워커 ID별 최근 1시간 누적 토큰 샘플 (TokensLastHour 계산용)
+	previousWorkers    []WorkerMinuteMetrics            // 워커 변경 이벤트 감지용 직전 수집 결과
+	circuit            *CircuitBreaker                  // Kuzco 장애 시 분 단위 수집 간격을 늘리는 회로 차단기
+	historyHours       int                              // GetAllMetrics가 조회하는 생성량 기록 기간(시간), 0이면 기본값
+	initializing       map[string]*initializingInstance // Initializing 상태로 관측된 인스턴스 (멈춤 감지용)
+	teams              []Team                           // GetAllMetrics가 수집하는 워커 팀, 비어 있으면 로그인 사용자 ID
+	workerChanges      map[string]*changeHistory        // 워커/인스턴스별 최근 변경 기록 (변경 알림 중복 억제, flapping 감지)
+	duplicateIPs       map[string]bool                  // 중복 알림을 보낸 인스턴스 IP
+	dailyTrigger       chan struct{}                    // CollectMetrics 루프에 일일 보고서 수집을 요청 (RequestDailyReport)
+	alertOnly          bool                             // true면 alerts.autoRebootStuck이어도 멈춘 인스턴스를 재부팅하지 않음 (SetAutoReboot)
+
+	// 설정 다시 불러오기(SIGHUP)로 변경된 알림 설정, 설정되지 않으면 CollectMetrics 인자를 사용
+	alertConfig    *AlertConfig
+	alertConfigMux sync.Mutex
+}
+
+// tokenSample은 특정 시점의 24시간 토큰 수입니다
+type tokenSample struct {
+	Tokens    int64
+	Timestamp time.Time
 }
 
 func NewClient() *Client {
 	return &Client{
-		baseURL:    KuzcoAPI, // Kuzco API URL 수정
-		httpClient: &http.Client{},
+		baseURL:      KuzcoAPI, // Kuzco API URL 수정
+		httpClient:   &http.Client{Transport: sharedHTTPTransport()},
+		userAgent:    DefaultUserAgent,
+		circuit:      NewCircuitBreaker(0, 0),
+		dailyTrigger: make(chan struct{}, 1),
+	}
+}
+
+// RequestDailyReport asks the running CollectMetrics loop to collect and send the daily report now.
+// It returns false if a request is already pending.
+func (c *Client) RequestDailyReport() bool {
+	select {
+	case c.dailyTrigger <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// SetMonitoringConfig applies the circuit breaker thresholds used by CollectMetrics
+func (c *Client) SetMonitoringConfig(cfg MonitoringConfig) {
+	c.circuit = NewCircuitBreaker(cfg.CircuitFailureThreshold, time.Duration(cfg.CircuitOpenMinutes)*time.Minute)
+	c.historyHours = cfg.GenerationsHistoryHours
+}
+
+// SetAutoReboot controls whether instances stuck in Initializing are rebooted when alerts.autoRebootStuck
+// is set (default) or only reported
+func (c *Client) SetAutoReboot(enabled bool) {
+	c.alertOnly = !enabled
+}
+
+// SetAlertConfig replaces the alert thresholds used by a running CollectMetrics loop
+func (c *Client) SetAlertConfig(config AlertConfig) {
+	c.alertConfigMux.Lock()
+	defer c.alertConfigMux.Unlock()
+	c.alertConfig = &config
+}
+
+// currentAlertConfig는 SetAlertConfig로 변경된 설정이 있으면 그 값을, 없으면 fallback을 반환합니다
+func (c *Client) currentAlertConfig(fallback AlertConfig) AlertConfig {
+	c.alertConfigMux.Lock()
+	defer c.alertConfigMux.Unlock()
+	if c.alertConfig != nil {
+		return *c.alertConfig
 	}
+	return fallback
 }
 
 // SetBaseURL allows changing the base URL (useful for testing)
@@ -32,6 +104,64 @@ func (c *Client) SetToken(token string) {
 	c.token = token
 }
 
+// SetCredentials stores the Kuzco login used to refresh the token when a request fails with ErrAuth
+func (c *Client) SetCredentials(email, password string) {
+	c.email = email
+	c.password = password
+}
+
+// withReauth는 fn이 인증 에러(ErrAuth)로 실패하면 저장된 계정 정보로 다시 로그인한 뒤 한 번 더 실행합니다
+// 계정 정보가 없으면 처음 에러를 그대로 반환합니다
+func (c *Client) withReauth(fn func() error) error {
+	err := fn()
+	if !errors.Is(err, ErrAuth) || c.email == "" {
+		return err
+	}
+
+	log.Printf("Kuzco token for %s was rejected, logging in again", c.accountName)
+	c.token = "" // 만료된 토큰을 로그인 요청에 보내지 않음
+	token, _, loginErr := c.Login(c.email, c.password)
+	if loginErr != nil {
+		return fmt.Errorf("%w (re-login failed: %v)", err, loginErr)
+	}
+	c.token = token
+	log.Printf("Re-login succeeded for %s, retrying", c.accountName)
+	return fn()
+}
+
+// SetUserAgent sets the User-Agent header sent to the Kuzco API. An empty value keeps the default
+func (c *Client) SetUserAgent(userAgent string) {
+	if userAgent == "" {
+		return
+	}
+	c.userAgent = userAgent
+}
+
+// SetAccountName sets the account name used to keep per-account state such as alert flags apart
+func (c *Client) SetAccountName(name string) {
+	c.accountName = name
+}
+
+// alertStateKey returns the key of this account's alert state, falling back to the user ID
+func (c *Client) alertStateKey(userID string) string {
+	if c.accountName != "" {
+		return c.accountName
+	}
+	return userID
+}
+
+// SetVastaiCostSource sets the cost source used by Vast.ai clients created for this account
+func (c *Client) SetVastaiCostSource(source string) {
+	c.vastaiCostSource = source
+}
+
+// newVastaiClient creates a Vast.ai client with this account's settings applied
+func (c *Client) newVastaiClient(token string) *VastaiClient {
+	vastaiClient := NewVastaiClient(token)
+	vastaiClient.SetCostSource(c.vastaiCostSource)
+	return vastaiClient
+}
+
 // APIError는 API 호출 시 발생하는 에러를 나타내는 구조체입니다
 type APIError struct {
 	StatusCode int
@@ -47,8 +177,35 @@ func (e *APIError) Error() string {
 	return fmt.Sprintf("request failed with status %d: %s", e.StatusCode, string(e.RawBody))
 }
 
-// DoRequest sends an HTTP request and returns the response
+// errorBodyPreviewLen은 파싱 실패 에러에 포함할 응답 본문의 최대 길이입니다
+const errorBodyPreviewLen = 200
+
+// parseJSONOrError는 응답 본문을 v로 파싱하며, 실패하면 엔드포인트 이름과 본문 앞부분을 에러에 포함합니다
+// 장애 중 HTML 점검 페이지가 반환된 경우를 로그에서 바로 구분하기 위함입니다
+func parseJSONOrError(endpoint string, body []byte, v interface{}) error {
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("error parsing %s response (%w): %w (body: %q)", endpoint, ErrParse, err, bodyPreview(body))
+	}
+	return nil
+}
+
+// bodyPreview는 로그와 에러에 포함할 응답 본문 앞부분입니다
+func bodyPreview(body []byte) []byte {
+	if len(body) > errorBodyPreviewLen {
+		return body[:errorBodyPreviewLen]
+	}
+	return body
+}
+
+// DoRequest sends an HTTP request and returns the response, recording its latency in GlobalRequestMetrics
 func (c *Client) DoRequest(method, path string, body interface{}, headers map[string]string) ([]byte, error) {
+	start := time.Now()
+	respBody, err := c.doRequest(method, path, body, headers)
+	GlobalRequestMetrics.Record(requestEndpoint("kuzco", method, path), time.Since(start), err != nil)
+	return respBody, err
+}
+
+func (c *Client) doRequest(method, path string, body interface{}, headers map[string]string) ([]byte, error) {
 	var reqBody io.Reader
 	if body != nil {
 		jsonData, err := json.Marshal(body)
@@ -72,7 +229,7 @@ func (c *Client) DoRequest(method, path string, body interface{}, headers map[st
 	}
 
 	// Add default headers
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/133.0.0.0 Safari/537.36")
+	req.Header.Set("User-Agent", c.userAgent)
 
 	if body != nil {
 		req.Header.Set("Content-Type", "application/json")
@@ -88,7 +245,7 @@ func (c *Client) DoRequest(method, path string, body interface{}, headers map[st
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+		return nil, fmt.Errorf("request failed (%w): %w", ErrUpstream, err)
 	}
 	defer resp.Body.Close()
 
@@ -117,7 +274,7 @@ func (c *Client) DoRequest(method, path string, body interface{}, headers map[st
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(respBody))
+		return nil, &APIError{StatusCode: resp.StatusCode, RawBody: respBody}
 	}
 
 	return respBody, nil
@@ -0,0 +1,144 @@
+package api
+
+import (
+	"sync"
+	"time"
+)
+
+// dailyStatsBuckets는 DailyStatsManager가 보관하는 시간 버킷 수입니다 (24시간)
+const dailyStatsBuckets = 24
+
+// HourSummary는 한 시간 동안의 최소/최대/평균 값입니다
+type HourSummary struct {
+	Min int     `json:"min"`
+	Max int     `json:"max"`
+	Avg float64 `json:"avg"`
+}
+
+// DailyHourStats는 1시간 버킷의 집계 통계입니다
+type DailyHourStats struct {
+	Hour           time.Time   `json:"hour"` // 버킷 시작 시각 (정시)
+	RPM            HourSummary `json:"rpm"`
+	TotalInstances HourSummary `json:"totalInstances"`
+	Samples        int         `json:"samples"` // 집계된 분 단위 샘플 수
+}
+
+// hourBucket은 한 시간 동안의 MinuteStats 누적값입니다
+type hourBucket struct {
+	hour         time.Time
+	rpmMin       int
+	rpmMax       int
+	rpmSum       int
+	instancesMin int
+	instancesMax int
+	instancesSum int
+	count        int
+}
+
+// add는 분 단위 통계를 버킷에 누적합니다
+func (b *hourBucket) add(stat MinuteStats) {
+	if b.count == 0 || stat.RPM < b.rpmMin {
+		b.rpmMin = stat.RPM
+	}
+	if b.count == 0 || stat.RPM > b.rpmMax {
+		b.rpmMax = stat.RPM
+	}
+	if b.count == 0 || stat.TotalInstances < b.instancesMin {
+		b.instancesMin = stat.TotalInstances
+	}
+	if b.count == 0 || stat.TotalInstances > b.instancesMax {
+		b.instancesMax = stat.TotalInstances
+	}
+	b.rpmSum += stat.RPM
+	b.instancesSum += stat.TotalInstances
+	b.count++
+}
+
+// summary는 누적값을 DailyHourStats로 변환합니다
+func (b *hourBucket) summary() DailyHourStats {
+	result := DailyHourStats{
+		Hour:           b.hour,
+		RPM:            HourSummary{Min: b.rpmMin, Max: b.rpmMax},
+		TotalInstances: HourSummary{Min: b.instancesMin, Max: b.instancesMax},
+		Samples:        b.count,
+	}
+	if b.count > 0 {
+		result.RPM.Avg = float64(b.rpmSum) / float64(b.count)
+		result.TotalInstances.Avg = float64(b.instancesSum) / float64(b.count)
+	}
+	return result
+}
+
+// DailyStatsManager는 최근 24시간의 통계를 1시간 단위로 집계하여 관리합니다
+type DailyStatsManager struct {
+	buckets []*hourBucket // 시간순 정렬, 최대 24개
+	mutex   sync.Mutex
+}
+
+// 계정마다 사용자 생성량이 다르므로 일별 통계는 계정 이름별로 보관합니다
+var (
+	dailyStats     = make(map[string]*DailyStatsManager)
+	dailyStatsLock sync.Mutex
+)
+
+// DailyStatsFor returns the daily stats of an account, creating them on first use
+func DailyStatsFor(account string) *DailyStatsManager {
+	dailyStatsLock.Lock()
+	defer dailyStatsLock.Unlock()
+	m, ok := dailyStats[account]
+	if !ok {
+		m = &DailyStatsManager{buckets: make([]*hourBucket, 0, dailyStatsBuckets)}
+		dailyStats[account] = m
+	}
+	return m
+}
+
+// UpdateStats는 새로운 분 단위 메트릭스를 해당 시간 버킷에 누적합니다
+func (m *DailyStatsManager) UpdateStats(metrics MinuteMetrics) {
+	m.add(MinuteStats{
+		RPM:            metrics.General.RPM,
+		TotalInstances: metrics.General.TotalInstances,
+		GeneralGen:     metrics.General.GenerationLastHour,
+		UserGen:        metrics.User.GenerationLastHour,
+		Timestamp:      time.Now(),
+	})
+}
+
+// add는 분 단위 통계를 시간 버킷에 누적하고 24시간이 지난 버킷은 제거합니다
+func (m *DailyStatsManager) add(stat MinuteStats) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	hour := stat.Timestamp.Truncate(time.Hour)
+
+	var bucket *hourBucket
+	if n := len(m.buckets); n > 0 && m.buckets[n-1].hour.Equal(hour) {
+		bucket = m.buckets[n-1]
+	} else {
+		bucket = &hourBucket{hour: hour}
+		m.buckets = append(m.buckets, bucket)
+	}
+	bucket.add(stat)
+
+	// 24시간이 지난 버킷 제거
+	cutoff := hour.Add(-(dailyStatsBuckets - 1) * time.Hour)
+	valid := m.buckets[:0]
+	for _, b := range m.buckets {
+		if !b.hour.Before(cutoff) {
+			valid = append(valid, b)
+		}
+	}
+	m.buckets = valid
+}
+
+// GetStats는 최근 24시간의 시간별 통계를 시간순으로 반환합니다
+func (m *DailyStatsManager) GetStats() []DailyHourStats {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	result := make([]DailyHourStats, 0, len(m.buckets))
+	for _, b := range m.buckets {
+		result = append(result, b.summary())
+	}
+	return result
+}
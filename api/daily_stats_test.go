@@ -0,0 +1,52 @@
+package api
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDailyStatsManagerRollup(t *testing.T) {
+	m := &DailyStatsManager{}
+	base := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	m.add(MinuteStats{RPM: 10, TotalInstances: 4, Timestamp: base.Add(1 * time.Minute)})
+	m.add(MinuteStats{RPM: 30, TotalInstances: 2, Timestamp: base.Add(30 * time.Minute)})
+	m.add(MinuteStats{RPM: 20, TotalInstances: 6, Timestamp: base.Add(61 * time.Minute)})
+
+	stats := m.GetStats()
+	if len(stats) != 2 {
+		t.Fatalf("expected 2 hourly buckets, got %d", len(stats))
+	}
+
+	first := stats[0]
+	if !first.Hour.Equal(base) || first.Samples != 2 {
+		t.Errorf("unexpected first bucket: %+v", first)
+	}
+	if first.RPM.Min != 10 || first.RPM.Max != 30 || first.RPM.Avg != 20 {
+		t.Errorf("unexpected RPM summary: %+v", first.RPM)
+	}
+	if first.TotalInstances.Min != 2 || first.TotalInstances.Max != 4 || first.TotalInstances.Avg != 3 {
+		t.Errorf("unexpected instance summary: %+v", first.TotalInstances)
+	}
+
+	if !stats[1].Hour.Equal(base.Add(time.Hour)) || stats[1].RPM.Avg != 20 {
+		t.Errorf("unexpected second bucket: %+v", stats[1])
+	}
+}
+
+func TestDailyStatsManagerKeeps24Buckets(t *testing.T) {
+	m := &DailyStatsManager{}
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 30; i++ {
+		m.add(MinuteStats{RPM: i, Timestamp: base.Add(time.Duration(i) * time.Hour)})
+	}
+
+	stats := m.GetStats()
+	if len(stats) != dailyStatsBuckets {
+		t.Fatalf("expected %d buckets, got %d", dailyStatsBuckets, len(stats))
+	}
+	if stats[0].RPM.Min != 6 || stats[len(stats)-1].RPM.Min != 29 {
+		t.Errorf("unexpected bucket range: first %d, last %d", stats[0].RPM.Min, stats[len(stats)-1].RPM.Min)
+	}
+}
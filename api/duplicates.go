@@ -0,0 +1,80 @@
+package api
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DuplicateIP는 여러 인스턴스가 같은 IP를 보고한 경우입니다
+// 같은 머신에서 워커가 두 번 실행되는 등 설정 오류로 비용이 중복될 수 있습니다
+type DuplicateIP struct {
+	IP        string
+	Instances []string // "워커 #순번 (상태)" 형식
+}
+
+// FindDuplicateIPs는 두 개 이상의 인스턴스가 보고한 IP를 IP 순서로 반환합니다 (IP가 없는 인스턴스는 제외)
+func FindDuplicateIPs(workers []WorkerMinuteMetrics) []DuplicateIP {
+	byIP := make(map[string][]string)
+	for _, worker := range workers {
+		for i, inst := range worker.Instances {
+			if inst.IP == "" {
+				continue
+			}
+			byIP[inst.IP] = append(byIP[inst.IP], fmt.Sprintf("%s #%d (%s)", worker.Name, i+1, inst.Status))
+		}
+	}
+
+	var duplicates []DuplicateIP
+	for ip, instances := range byIP {
+		if len(instances) > 1 {
+			duplicates = append(duplicates, DuplicateIP{IP: ip, Instances: instances})
+		}
+	}
+	sort.Slice(duplicates, func(i, j int) bool { return duplicates[i].IP < duplicates[j].IP })
+	return duplicates
+}
+
+// checkDuplicateIPs는 새로 중복된 IP를 알리고, 알린 중복이 해소되면 복구 알림을 보냅니다
+func (m *Client) checkDuplicateIPs(mm *MinuteMetrics, config AlertConfig, sendAlert func(string, string) error) error {
+	if !config.Enabled {
+		return nil
+	}
+
+	duplicates := FindDuplicateIPs(mm.User.Workers)
+	current := make(map[string]bool, len(duplicates))
+	var lines []string
+	for _, dup := range duplicates {
+		current[dup.IP] = true
+		if m.duplicateIPs[dup.IP] {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s", dup.IP, strings.Join(dup.Instances, ", ")))
+	}
+
+	var resolved []string
+	for ip := range m.duplicateIPs {
+		if !current[ip] {
+			resolved = append(resolved, ip)
+		}
+	}
+	sort.Strings(resolved)
+	m.duplicateIPs = current
+
+	if len(lines) > 0 {
+		title := "⚠️ Duplicate Instance IP Alert"
+		msg := "The following IPs are reported by more than one instance (possibly paid twice):\n" + strings.Join(lines, "\n")
+		message := fmt.Sprintf("%s\n%s", title, CodeBlock(msg))
+		if err := sendAlert(message, "error"); err != nil {
+			return fmt.Errorf("failed to send duplicate IP alert: %w", err)
+		}
+	}
+	if len(resolved) > 0 {
+		title := "✅ Duplicate Instance IPs Resolved"
+		message := fmt.Sprintf("%s\n%s", title, CodeBlock(strings.Join(resolved, "\n")))
+		if err := sendAlert(message, "status"); err != nil {
+			return fmt.Errorf("failed to send duplicate IP recovery alert: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFindDuplicateIPs(t *testing.T) {
+	workers := []WorkerMinuteMetrics{
+		{Name: "worker1", Instances: []InstanceMetrics{
+			{IP: "10.0.0.2", Status: "Running"},
+			{IP: "10.0.0.1", Status: "Running"},
+			{IP: "", Status: "Initializing"},
+		}},
+		{Name: "worker2", Instances: []InstanceMetrics{
+			{IP: "10.0.0.1", Status: "Initializing"},
+			{IP: "", Status: "Initializing"},
+		}},
+	}
+
+	duplicates := FindDuplicateIPs(workers)
+	if len(duplicates) != 1 {
+		t.Fatalf("expected one duplicate IP (empty IPs skipped), got %+v", duplicates)
+	}
+	got := duplicates[0]
+	if got.IP != "10.0.0.1" || len(got.Instances) != 2 ||
+		got.Instances[0] != "worker1 #2 (Running)" || got.Instances[1] != "worker2 #1 (Initializing)" {
+		t.Errorf("unexpected duplicate %+v", got)
+	}
+}
+
+func TestCheckDuplicateIPsAlertsOnce(t *testing.T) {
+	client := NewClient()
+	var alerts []string
+	sendAlert := func(message, alertType string) error {
+		alerts = append(alerts, alertType+" "+message)
+		return nil
+	}
+
+	var mm MinuteMetrics
+	mm.User.Workers = []WorkerMinuteMetrics{
+		{Name: "worker1", Instances: []InstanceMetrics{{IP: "10.0.0.1", Status: "Running"}}},
+		{Name: "worker2", Instances: []InstanceMetrics{{IP: "10.0.0.1", Status: "Running"}}},
+	}
+	config := AlertConfig{Enabled: true}
+
+	for i := 0; i < 2; i++ {
+		if err := client.checkDuplicateIPs(&mm, config, sendAlert); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(alerts) != 1 || !strings.HasPrefix(alerts[0], "error") || !strings.Contains(alerts[0], "10.0.0.1: worker1 #1 (Running), worker2 #1 (Running)") {
+		t.Fatalf("expected a single duplicate alert, got %v", alerts)
+	}
+
+	mm.User.Workers = mm.User.Workers[:1]
+	if err := client.checkDuplicateIPs(&mm, config, sendAlert); err != nil {
+		t.Fatal(err)
+	}
+	if len(alerts) != 2 || !strings.Contains(alerts[1], "Duplicate Instance IPs Resolved") {
+		t.Errorf("expected a recovery alert, got %v", alerts)
+	}
+}
@@ -0,0 +1,30 @@
+package api
+
+import "errors"
+
+// Kuzco API 호출 에러 분류
+// DoRequest와 GetMetrics가 반환하는 에러는 errors.Is로 아래 중 하나와 비교할 수 있습니다
+var (
+	ErrAuth        = errors.New("authentication failed") // 401/403: 토큰 만료 등, 다시 로그인해야 함
+	ErrRateLimited = errors.New("rate limited")          // 429: 잠시 후 재시도
+	ErrUpstream    = errors.New("upstream unavailable")  // 5xx 또는 네트워크 오류: 잠시 후 재시도
+	ErrParse       = errors.New("unexpected response")   // 응답 형식이 예상과 다름
+)
+
+// Is는 HTTP 상태 코드에 따라 APIError를 ErrAuth, ErrRateLimited, ErrUpstream으로 분류합니다
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrAuth:
+		return e.StatusCode == 401 || e.StatusCode == 403
+	case ErrRateLimited:
+		return e.StatusCode == 429
+	case ErrUpstream:
+		return e.StatusCode >= 500
+	}
+	return false
+}
+
+// IsTransient는 잠시 후 재시도하면 성공할 수 있는 에러인지 반환합니다
+func IsTransient(err error) bool {
+	return errors.Is(err, ErrRateLimited) || errors.Is(err, ErrUpstream)
+}
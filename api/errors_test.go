@@ -0,0 +1,142 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDoRequestErrorClassification(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   error
+	}{
+		{"unauthorized", http.StatusUnauthorized, `{"error":"unauthorized"}`, ErrAuth},
+		{"forbidden", http.StatusForbidden, `forbidden`, ErrAuth},
+		{"rate limited", http.StatusTooManyRequests, `slow down`, ErrRateLimited},
+		{"server error", http.StatusInternalServerError, `oops`, ErrUpstream},
+		{"bad gateway", http.StatusBadGateway, `<html>502</html>`, ErrUpstream},
+	}
+	classes := []error{ErrAuth, ErrRateLimited, ErrUpstream, ErrParse}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := NewClient()
+			client.SetBaseURL(server.URL + "/")
+			_, err := client.DoRequest("GET", "/metrics", nil, nil)
+			for _, class := range classes {
+				if got := errors.Is(err, class); got != (class == tt.want) {
+					t.Errorf("errors.Is(%v, %v) = %v", err, class, got)
+				}
+			}
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != tt.status {
+				t.Errorf("Expected *APIError with status %d, got %v", tt.status, err)
+			}
+		})
+	}
+}
+
+func TestDoRequestNetworkErrorIsUpstream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := server.URL
+	server.Close()
+
+	client := NewClient()
+	client.SetBaseURL(url + "/")
+	_, err := client.DoRequest("GET", "/metrics", nil, nil)
+	if !errors.Is(err, ErrUpstream) || !IsTransient(err) {
+		t.Errorf("Expected network error to be classified as ErrUpstream, got %v", err)
+	}
+	if errors.Is(err, ErrAuth) {
+		t.Errorf("Network error must not be an auth error: %v", err)
+	}
+}
+
+func TestGetMetricsParseError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html>maintenance</html>"))
+	}))
+	defer server.Close()
+
+	client := NewClient()
+	client.SetBaseURL(server.URL + "/")
+	_, err := NewKuzcoClient(client).GetMetrics(MetricsQuery{Endpoint: EndpointMetricsRPM})
+	if !errors.Is(err, ErrParse) {
+		t.Errorf("Expected ErrParse, got %v", err)
+	}
+	if IsTransient(err) || errors.Is(err, ErrAuth) {
+		t.Errorf("Parse error classified as another kind: %v", err)
+	}
+}
+
+func TestGetAllMetricsAuthErrorSurvivesJoin(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	client := NewClient()
+	client.SetBaseURL(server.URL + "/")
+	metrics, err := NewKuzcoClient(client).GetAllMetrics("user")
+	if metrics == nil || !errors.Is(err, ErrAuth) {
+		t.Errorf("Expected partial metrics with an auth error, got %v / %v", metrics, err)
+	}
+}
+
+func TestWithReauthRefreshesExpiredToken(t *testing.T) {
+	logins := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/"+EndpointUserLogin {
+			if r.Header.Get("Authorization") != "" {
+				t.Error("Expected login without the expired token")
+			}
+			logins++
+			w.Write([]byte(`[{"result":{"data":{"json":{"token":"fresh","user":{"_id":"user-1"}}}}}]`))
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer fresh" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`[{"result":{"data":{"json":42}}}]`))
+	}))
+	defer server.Close()
+
+	client := NewClient()
+	client.SetBaseURL(server.URL + "/")
+	client.SetToken("expired")
+
+	var rpm int
+	fetch := func() (err error) {
+		rpm, err = NewKuzcoClient(client).GetRPM()
+		return
+	}
+
+	// 계정 정보가 없으면 다시 로그인하지 않음
+	if err := client.withReauth(fetch); !errors.Is(err, ErrAuth) || logins != 0 {
+		t.Fatalf("Expected auth error without credentials, got %v (%d logins)", err, logins)
+	}
+
+	client.SetCredentials("user@example.com", "secret")
+	if err := client.withReauth(fetch); err != nil {
+		t.Fatalf("Expected retry after re-login to succeed, got %v", err)
+	}
+	if rpm != 42 || logins != 1 {
+		t.Errorf("Expected RPM 42 after one login, got %d (%d logins)", rpm, logins)
+	}
+
+	// 새 토큰으로는 다시 로그인하지 않음
+	if err := client.withReauth(fetch); err != nil || logins != 1 {
+		t.Errorf("Expected no extra login with a valid token, got %v (%d logins)", err, logins)
+	}
+}
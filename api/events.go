@@ -0,0 +1,195 @@
+package api
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultEventBufferSize는 보관하는 워커 변경 이벤트의 기본 최대 개수입니다
+const DefaultEventBufferSize = 200
+
+// 워커 변경 이벤트 종류
+const (
+	EventWorkerAdded     = "worker_added"
+	EventWorkerRemoved   = "worker_removed"
+	EventInstanceAdded   = "instance_added"
+	EventInstanceRemoved = "instance_removed"
+	EventStatusChanged   = "status_changed"
+	EventIPChanged       = "ip_changed"
+)
+
+// WorkerEvent는 분 단위 수집 사이에 감지된 워커 변경 이벤트입니다
+type WorkerEvent struct {
+	Timestamp time.Time `json:"timestamp"`
+	Type      string    `json:"type"`
+	Worker    string    `json:"worker"`
+	Detail    string    `json:"detail"`
+	Account   string    `json:"account,omitempty"`
+}
+
+// EventLog는 최근 워커 변경 이벤트를 보관하는 링 버퍼입니다
+type EventLog struct {
+	events   []WorkerEvent
+	next     int // 다음에 덮어쓸 위치 (버퍼가 가득 찬 경우)
+	capacity int
+	mu       sync.Mutex
+}
+
+var GlobalWorkerEvents = NewEventLog(DefaultEventBufferSize)
+
+// NewEventLog는 최대 capacity개의 이벤트를 보관하는 EventLog를 생성합니다
+func NewEventLog(capacity int) *EventLog {
+	if capacity <= 0 {
+		capacity = DefaultEventBufferSize
+	}
+	return &EventLog{capacity: capacity}
+}
+
+// SetCapacity는 버퍼 크기를 변경하며, 초과하는 오래된 이벤트는 버립니다
+// 0 이하이면 기본값을 사용합니다
+func (l *EventLog) SetCapacity(capacity int) {
+	if capacity <= 0 {
+		capacity = DefaultEventBufferSize
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	events := l.ordered()
+	if len(events) > capacity {
+		events = events[len(events)-capacity:]
+	}
+	l.events = events
+	l.next = 0
+	l.capacity = capacity
+}
+
+// Add는 이벤트를 추가하고, 버퍼가 가득 차면 가장 오래된 이벤트를 덮어씁니다
+func (l *EventLog) Add(events ...WorkerEvent) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, event := range events {
+		if len(l.events) < l.capacity {
+			l.events = append(l.events, event)
+			continue
+		}
+		l.events[l.next] = event
+		l.next = (l.next + 1) % l.capacity
+	}
+}
+
+// Events는 보관 중인 이벤트를 오래된 순서로 반환합니다
+func (l *EventLog) Events() []WorkerEvent {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.ordered()
+}
+
+// ordered는 링 버퍼를 시간순 슬라이스로 복사합니다 (잠금 상태에서 호출)
+func (l *EventLog) ordered() []WorkerEvent {
+	result := make([]WorkerEvent, 0, len(l.events))
+	result = append(result, l.events[l.next:]...)
+	return append(result, l.events[:l.next]...)
+}
+
+// DetectWorkerChanges는 이전/현재 워커 목록을 비교하여 변경 이벤트를 생성합니다
+// 인스턴스는 IP로 구분하며, 한 워커에서 같은 수의 인스턴스가 사라지고 새로 생기면 IP 변경으로 간주합니다
+func DetectWorkerChanges(previous, current []WorkerMinuteMetrics, now time.Time) []WorkerEvent {
+	prevByID := make(map[string]WorkerMinuteMetrics, len(previous))
+	for _, w := range previous {
+		prevByID[w.ID] = w
+	}
+	curIDs := make(map[string]bool, len(current))
+
+	var events []WorkerEvent
+	for _, cur := range current {
+		curIDs[cur.ID] = true
+		prev, ok := prevByID[cur.ID]
+		if !ok {
+			events = append(events, WorkerEvent{
+				Timestamp: now,
+				Type:      EventWorkerAdded,
+				Worker:    cur.Name,
+				Detail:    fmt.Sprintf("%d instances", len(cur.Instances)),
+			})
+			continue
+		}
+		events = append(events, instanceChanges(prev, cur, now)...)
+	}
+
+	for _, prev := range previous {
+		if !curIDs[prev.ID] {
+			events = append(events, WorkerEvent{
+				Timestamp: now,
+				Type:      EventWorkerRemoved,
+				Worker:    prev.Name,
+				Detail:    fmt.Sprintf("%d instances", len(prev.Instances)),
+			})
+		}
+	}
+	return events
+}
+
+// instanceChanges는 같은 워커의 인스턴스 변경(추가/제거/상태/IP)을 이벤트로 변환합니다
+func instanceChanges(prev, cur WorkerMinuteMetrics, now time.Time) []WorkerEvent {
+	prevByIP := make(map[string]InstanceMetrics, len(prev.Instances))
+	for _, inst := range prev.Instances {
+		prevByIP[inst.IP] = inst
+	}
+	curByIP := make(map[string]InstanceMetrics, len(cur.Instances))
+	for _, inst := range cur.Instances {
+		curByIP[inst.IP] = inst
+	}
+
+	var events []WorkerEvent
+	var added, removed []string
+	for _, inst := range cur.Instances {
+		before, ok := prevByIP[inst.IP]
+		if !ok {
+			added = append(added, inst.IP)
+			continue
+		}
+		if before.Status != inst.Status {
+			events = append(events, WorkerEvent{
+				Timestamp: now,
+				Type:      EventStatusChanged,
+				Worker:    cur.Name,
+				Detail:    fmt.Sprintf("%s: %s → %s", inst.IP, before.Status, inst.Status),
+			})
+		}
+	}
+	for _, inst := range prev.Instances {
+		if _, ok := curByIP[inst.IP]; !ok {
+			removed = append(removed, inst.IP)
+		}
+	}
+
+	if len(added) > 0 && len(added) == len(removed) {
+		for i := range added {
+			events = append(events, WorkerEvent{
+				Timestamp: now,
+				Type:      EventIPChanged,
+				Worker:    cur.Name,
+				Detail:    fmt.Sprintf("%s → %s", removed[i], added[i]),
+			})
+		}
+	} else {
+		for _, ip := range added {
+			events = append(events, WorkerEvent{
+				Timestamp: now,
+				Type:      EventInstanceAdded,
+				Worker:    cur.Name,
+				Detail:    fmt.Sprintf("%s (%s)", ip, curByIP[ip].Status),
+			})
+		}
+		for _, ip := range removed {
+			events = append(events, WorkerEvent{
+				Timestamp: now,
+				Type:      EventInstanceRemoved,
+				Worker:    cur.Name,
+				Detail:    fmt.Sprintf("%s (%s)", ip, prevByIP[ip].Status),
+			})
+		}
+	}
+
+	return events
+}
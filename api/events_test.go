@@ -0,0 +1,78 @@
+package api
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEventLogRingBuffer(t *testing.T) {
+	log := NewEventLog(3)
+	for i := 0; i < 5; i++ {
+		log.Add(WorkerEvent{Worker: string(rune('a' + i))})
+	}
+
+	events := log.Events()
+	if len(events) != 3 {
+		t.Fatalf("expected 3 events, got %d", len(events))
+	}
+	if events[0].Worker != "c" || events[2].Worker != "e" {
+		t.Errorf("expected oldest-first c..e, got %+v", events)
+	}
+
+	log.SetCapacity(2)
+	events = log.Events()
+	if len(events) != 2 || events[0].Worker != "d" || events[1].Worker != "e" {
+		t.Errorf("expected d, e after shrinking, got %+v", events)
+	}
+}
+
+func TestDetectWorkerChanges(t *testing.T) {
+	now := time.Now()
+	previous := []WorkerMinuteMetrics{
+		{ID: "1", Name: "worker-a", Instances: []InstanceMetrics{
+			{IP: "10.0.0.1", Status: "Initializing"},
+			{IP: "10.0.0.2", Status: "Running"},
+		}},
+		{ID: "2", Name: "worker-b", Instances: []InstanceMetrics{{IP: "10.0.1.1", Status: "Running"}}},
+		{ID: "3", Name: "worker-gone"},
+	}
+	current := []WorkerMinuteMetrics{
+		{ID: "1", Name: "worker-a", Instances: []InstanceMetrics{
+			{IP: "10.0.0.1", Status: "Running"},
+			{IP: "10.0.0.3", Status: "Running"},
+			{IP: "10.0.0.4", Status: "Initializing"},
+		}},
+		{ID: "2", Name: "worker-b", Instances: []InstanceMetrics{{IP: "10.0.1.2", Status: "Running"}}},
+		{ID: "4", Name: "worker-new"},
+	}
+
+	events := DetectWorkerChanges(previous, current, now)
+
+	got := make(map[string][]string)
+	for _, e := range events {
+		got[e.Type] = append(got[e.Type], e.Worker+" "+e.Detail)
+	}
+
+	expect := map[string][]string{
+		EventStatusChanged:   {"worker-a 10.0.0.1: Initializing → Running"},
+		EventInstanceAdded:   {"worker-a 10.0.0.3 (Running)", "worker-a 10.0.0.4 (Initializing)"},
+		EventInstanceRemoved: {"worker-a 10.0.0.2 (Running)"},
+		EventIPChanged:       {"worker-b 10.0.1.1 → 10.0.1.2"},
+		EventWorkerAdded:     {"worker-new 0 instances"},
+		EventWorkerRemoved:   {"worker-gone 0 instances"},
+	}
+	if len(events) != 7 {
+		t.Errorf("expected 7 events, got %d: %v", len(events), got)
+	}
+	for eventType, want := range expect {
+		if len(got[eventType]) != len(want) {
+			t.Errorf("%s: expected %v, got %v", eventType, want, got[eventType])
+			continue
+		}
+		for i := range want {
+			if got[eventType][i] != want[i] {
+				t.Errorf("%s: expected %q, got %q", eventType, want[i], got[eventType][i])
+			}
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// 숫자 표시 방식 (reporting.numberStyle)
+const (
+	NumberStyleSuffix  = "suffix"  // 1.23K, 4.56M, 7.89B
+	NumberStyleGrouped = "grouped" // 1,234,567
+)
+
+// DefaultPointDivisor는 1포인트에 해당하는 토큰 수의 기본값입니다 (reporting.pointDivisor)
+const DefaultPointDivisor = 10000
+
+// pointDivisor는 Points/FormatPoints가 사용하는 토큰 → 포인트 환산 기준입니다
+var pointDivisor int64 = DefaultPointDivisor
+
+// SetPointDivisor sets how many tokens make one point. Zero or less selects the default
+func SetPointDivisor(divisor int64) {
+	if divisor <= 0 {
+		divisor = DefaultPointDivisor
+	}
+	pointDivisor = divisor
+}
+
+// Points는 토큰 수를 포인트로 환산합니다
+func Points(tokens int64) float64 {
+	return float64(tokens) / float64(pointDivisor)
+}
+
+// FormatPoints는 토큰 수를 포인트로 환산한 뒤 설정된 표시 방식으로 변환합니다
+// 보고서와 명령어의 모든 토큰 표시는 이 함수를 사용합니다
+func FormatPoints(tokens int64) string {
+	return FormatNumber(Points(tokens))
+}
+
+// PointsNote는 보고서에 함께 표시하는 환산 기준입니다 (예: "1 pt = 10,000 tokens")
+func PointsNote() string {
+	return fmt.Sprintf("1 pt = %s tokens", FormatInt(pointDivisor))
+}
+
+// DefaultEfficiencyDecimals는 1% 효율(비중 1%당 비용)의 기본 소수점 자릿수입니다 (reporting.efficiencyDecimals)
+const DefaultEfficiencyDecimals = 2
+
+// efficiencyDecimals는 FormatEfficiency가 사용하는 소수점 자릿수입니다
+var efficiencyDecimals = DefaultEfficiencyDecimals
+
+// SetEfficiencyDecimals sets how many decimals FormatEfficiency shows. A negative value selects the default
+func SetEfficiencyDecimals(decimals int) {
+	if decimals < 0 {
+		decimals = DefaultEfficiencyDecimals
+	}
+	efficiencyDecimals = decimals
+}
+
+// FormatEfficiency는 1% 효율을 설정된 소수점 자릿수로 표시합니다 (달러 기호 제외)
+// $1 미만의 효율도 구분되도록 정수로 버리지 않습니다
+func FormatEfficiency(efficiency float64) string {
+	return strconv.FormatFloat(efficiency, 'f', efficiencyDecimals, 64)
+}
+
+// numberStyle은 FormatNumber가 사용하는 표시 방식입니다
+var numberStyle = NumberStyleSuffix
+
+// SetNumberStyle sets the style used by FormatNumber. An empty style selects the suffix style
+func SetNumberStyle(style string) {
+	if style == "" {
+		style = NumberStyleSuffix
+	}
+	numberStyle = style
+}
+
+// FormatNumber는 설정된 표시 방식에 따라 숫자를 문자열로 변환합니다
+func FormatNumber(num float64) string {
+	if numberStyle == NumberStyleGrouped {
+		return formatGrouped(num)
+	}
+	return formatSuffix(num)
+}
+
+// formatSuffix는 숫자를 K, M, B 단위로 자동 변환합니다 (음수는 절댓값 기준)
+// 반올림하면 1000이 되는 값(예: 999,999)은 다음 단위로 올려 "1000.00K" 대신 "1.00M"으로 표시합니다
+func formatSuffix(num float64) string {
+	scaled, suffix := math.Abs(num), ""
+	for _, unit := range []string{"K", "M", "B"} {
+		if math.Round(scaled*100)/100 < 1000 {
+			break
+		}
+		scaled /= 1000
+		suffix = unit
+	}
+
+	text := strconv.FormatFloat(scaled, 'f', 2, 64) + suffix
+	if num < 0 && !strings.HasPrefix(text, "0.00") {
+		return "-" + text
+	}
+	return text
+}
+
+// formatGrouped는 정수 부분을 세 자리마다 쉼표로 구분하며, 소수 부분은 0이 아닐 때만 둘째 자리까지 표시합니다
+func formatGrouped(num float64) string {
+	digits := strconv.FormatFloat(math.Abs(num), 'f', 2, 64)
+	intPart, fracPart, _ := strings.Cut(digits, ".")
+
+	text := groupDigits(intPart)
+	if fracPart != "00" {
+		text += "." + fracPart
+	}
+	if num < 0 && digits != "0.00" {
+		return "-" + text
+	}
+	return text
+}
+
+// FormatInt는 정수를 세 자리마다 쉼표로 구분합니다
+// float64를 거치지 않으므로 2^53을 넘는 큰 값과 math.MinInt64도 정확하게 표시합니다
+func FormatInt(n int64) string {
+	if n < 0 {
+		// -n은 math.MinInt64에서 오버플로되므로 uint64로 절댓값을 구합니다
+		return "-" + groupDigits(strconv.FormatUint(uint64(-(n+1))+1, 10))
+	}
+	return groupDigits(strconv.FormatInt(n, 10))
+}
+
+// groupDigits는 부호 없는 숫자 문자열에 세 자리마다 쉼표를 넣습니다
+func groupDigits(digits string) string {
+	var b strings.Builder
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(digit)
+	}
+	return b.String()
+}
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFormatNumber(t *testing.T) {
+	defer SetNumberStyle("")
+
+	tests := []struct {
+		style    string
+		input    float64
+		expected string
+	}{
+		{NumberStyleSuffix, 0, "0.00"},
+		{NumberStyleSuffix, 12.345, "12.35"},
+		{NumberStyleSuffix, 1500, "1.50K"},
+		{NumberStyleSuffix, 2500000, "2.50M"},
+		{NumberStyleSuffix, 3000000000, "3.00B"},
+		{NumberStyleSuffix, -1500, "-1.50K"},
+		{NumberStyleSuffix, -2500000, "-2.50M"},
+		{NumberStyleSuffix, -12.5, "-12.50"},
+		{NumberStyleSuffix, 999, "999.00"},
+		{NumberStyleSuffix, 1000, "1.00K"},
+		{NumberStyleSuffix, 999.999, "1.00K"},
+		{NumberStyleSuffix, 999999, "1.00M"},
+		{NumberStyleSuffix, 1000000, "1.00M"},
+		{NumberStyleSuffix, -999999, "-1.00M"},
+		{NumberStyleSuffix, 999999999, "1.00B"},
+		{NumberStyleSuffix, 9223372036854775807, "9223372036.85B"},
+		{NumberStyleSuffix, -0.001, "0.00"},
+		{NumberStyleGrouped, 0, "0"},
+		{NumberStyleGrouped, 999, "999"},
+		{NumberStyleGrouped, 1000, "1,000"},
+		{NumberStyleGrouped, 1234567, "1,234,567"},
+		{NumberStyleGrouped, 1234.5, "1,234.50"},
+		{NumberStyleGrouped, -1234567, "-1,234,567"},
+		{NumberStyleGrouped, -999, "-999"},
+		{NumberStyleGrouped, -0.001, "0"},
+		{NumberStyleGrouped, 1000000, "1,000,000"},
+		{NumberStyleGrouped, 999.999, "1,000"},
+		{NumberStyleGrouped, 100000, "100,000"},
+	}
+
+	for _, tt := range tests {
+		SetNumberStyle(tt.style)
+		if got := FormatNumber(tt.input); got != tt.expected {
+			t.Errorf("FormatNumber(%v) with %s style = %q, expected %q", tt.input, tt.style, got, tt.expected)
+		}
+	}
+}
+
+func TestFormatPoints(t *testing.T) {
+	defer SetPointDivisor(0)
+
+	if got := FormatPoints(25000000); got != "2.50K" {
+		t.Errorf("expected 2.50K points, got %q", got)
+	}
+	if got := PointsNote(); got != "1 pt = 10,000 tokens" {
+		t.Errorf("unexpected note: %q", got)
+	}
+
+	SetPointDivisor(1000)
+	if got := FormatPoints(25000000); got != "25.00K" {
+		t.Errorf("expected 25.00K points with divisor 1000, got %q", got)
+	}
+	if got := Points(1500); got != 1.5 {
+		t.Errorf("expected 1.5 points, got %v", got)
+	}
+}
+
+func TestFormatEfficiency(t *testing.T) {
+	defer SetEfficiencyDecimals(-1)
+
+	if got := FormatEfficiency(0.456); got != "0.46" {
+		t.Errorf("expected 0.46 by default, got %q", got)
+	}
+	SetEfficiencyDecimals(0)
+	if got := FormatEfficiency(12.6); got != "13" {
+		t.Errorf("expected 13 with no decimals, got %q", got)
+	}
+	SetEfficiencyDecimals(3)
+	if got := FormatEfficiency(0.0456); got != "0.046" {
+		t.Errorf("expected 0.046 with 3 decimals, got %q", got)
+	}
+}
+
+func TestFormatInt(t *testing.T) {
+	tests := []struct {
+		input    int64
+		expected string
+	}{
+		{0, "0"},
+		{7, "7"},
+		{-7, "-7"},
+		{999, "999"},
+		{-999, "-999"},
+		{1000, "1,000"},
+		{-1000, "-1,000"},
+		{100000, "100,000"},
+		{1000000, "1,000,000"},
+		{-1234567, "-1,234,567"},
+		{math.MaxInt64, "9,223,372,036,854,775,807"},
+		{math.MinInt64, "-9,223,372,036,854,775,808"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatInt(tt.input); got != tt.expected {
+			t.Errorf("FormatInt(%d) = %q, expected %q", tt.input, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"sort"
+	"strings"
+)
+
+// UnknownLocation은 Kuzco가 위치를 알려주지 않은 인스턴스의 국가/지역 이름입니다
+const UnknownLocation = "Unknown"
+
+// GeoCount는 국가/지역별 인스턴스 수입니다
+type GeoCount struct {
+	Country   string
+	Region    string
+	Instances int
+	Running   int
+}
+
+// GeoDistribution은 인스턴스를 국가/지역별로 집계하여 인스턴스 수가 많은 순서로 반환합니다
+// 지역별 장애를 알아보기 쉽도록 Running 인스턴스 수도 함께 셉니다
+func GeoDistribution(workers []WorkerMinuteMetrics) []GeoCount {
+	type key struct{ country, region string }
+	counts := make(map[key]*GeoCount)
+	for _, worker := range workers {
+		for _, inst := range worker.Instances {
+			k := key{inst.Country, inst.Region}
+			if k.country == "" {
+				k.country = UnknownLocation
+			}
+			count, ok := counts[k]
+			if !ok {
+				count = &GeoCount{Country: k.country, Region: k.region}
+				counts[k] = count
+			}
+			count.Instances++
+			if strings.EqualFold(inst.Status, "running") {
+				count.Running++
+			}
+		}
+	}
+
+	result := make([]GeoCount, 0, len(counts))
+	for _, count := range counts {
+		result = append(result, *count)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Instances != result[j].Instances {
+			return result[i].Instances > result[j].Instances
+		}
+		if result[i].Country != result[j].Country {
+			return result[i].Country < result[j].Country
+		}
+		return result[i].Region < result[j].Region
+	})
+	return result
+}
@@ -0,0 +1,32 @@
+package api
+
+import "testing"
+
+func TestGeoDistribution(t *testing.T) {
+	workers := []WorkerMinuteMetrics{
+		{Name: "gpu-1", Instances: []InstanceMetrics{
+			{Status: "Running", Country: "US", Region: "California"},
+			{Status: "Initializing", Country: "US", Region: "California"},
+			{Status: "Running", Country: "KR", Region: "Seoul"},
+		}},
+		{Name: "gpu-2", Instances: []InstanceMetrics{
+			{Status: "Running", Country: "US", Region: "California"},
+			{Status: "Running"},
+		}},
+	}
+
+	got := GeoDistribution(workers)
+	want := []GeoCount{
+		{Country: "US", Region: "California", Instances: 3, Running: 2},
+		{Country: "KR", Region: "Seoul", Instances: 1, Running: 1},
+		{Country: UnknownLocation, Instances: 1, Running: 1},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d locations, got %+v", len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("location %d: expected %+v, got %+v", i, want[i], got[i])
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// GPU 상태 알림 기본값
+const (
+	DefaultGPUTempThreshold   = 85 // 과열로 판단하는 GPU 온도(°C)
+	DefaultGPUIdleUtilPercent = 1  // Running 인스턴스가 유휴(멈춤)로 판단되는 GPU 사용률(%) 상한
+)
+
+// GPUReading은 인스턴스의 nvidia-smi 측정값입니다
+// 값을 읽을 수 없는 항목은 -1입니다
+type GPUReading struct {
+	TempC       int     `json:"tempC"`
+	UtilPercent int     `json:"utilPercent"`
+	PowerW      float64 `json:"powerW"`
+}
+
+// nvidiaSmiGPU는 worker.list 응답의 nvidiaSmi.gpu 항목입니다 (nvidia-smi XML을 변환한 형식이라 값이 배열로 옵니다)
+type nvidiaSmiGPU struct {
+	ProductName []string `json:"product_name"`
+	Temperature []struct {
+		GPUTemp []string `json:"gpu_temp"`
+	} `json:"temperature"`
+	Utilization []struct {
+		GPUUtil []string `json:"gpu_util"`
+	} `json:"utilization"`
+	PowerReadings []struct {
+		PowerDraw []string `json:"power_draw"`
+	} `json:"power_readings"`
+	GPUPowerReadings []struct { // 최신 드라이버는 gpu_power_readings를 사용
+		PowerDraw []string `json:"power_draw"`
+	} `json:"gpu_power_readings"`
+}
+
+// reading은 온도, 사용률, 전력을 읽으며, 하나도 읽을 수 없으면 nil을 반환합니다
+func (g nvidiaSmiGPU) reading() *GPUReading {
+	reading := GPUReading{TempC: -1, UtilPercent: -1, PowerW: -1}
+	found := false
+
+	if len(g.Temperature) > 0 {
+		if v, ok := parseGPUValue(g.Temperature[0].GPUTemp); ok {
+			reading.TempC = int(v)
+			found = true
+		}
+	}
+	if len(g.Utilization) > 0 {
+		if v, ok := parseGPUValue(g.Utilization[0].GPUUtil); ok {
+			reading.UtilPercent = int(v)
+			found = true
+		}
+	}
+	power := g.PowerReadings
+	if len(power) == 0 || len(power[0].PowerDraw) == 0 {
+		power = g.GPUPowerReadings
+	}
+	if len(power) > 0 {
+		if v, ok := parseGPUValue(power[0].PowerDraw); ok {
+			reading.PowerW = v
+			found = true
+		}
+	}
+
+	if !found {
+		return nil
+	}
+	return &reading
+}
+
+// parseGPUValue는 "65 C", "98 %", "250.00 W" 같은 nvidia-smi 값에서 숫자를 읽습니다
+// "N/A"나 숫자가 아닌 값은 ok가 false입니다
+func parseGPUValue(values []string) (float64, bool) {
+	if len(values) == 0 {
+		return 0, false
+	}
+	fields := strings.Fields(values[0])
+	if len(fields) == 0 {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0, false
+	}
+	return v, true
+}
+
+// GPUThresholds는 설정된 과열 온도와 유휴 사용률 기준을 반환하며, 설정되지 않은 값은 기본값을 사용합니다
+func (c AlertConfig) GPUThresholds() (tempC, idleUtil int) {
+	tempC = c.GPUTempThreshold
+	if tempC <= 0 {
+		tempC = DefaultGPUTempThreshold
+	}
+	idleUtil = c.GPUIdleUtilPercent
+	if idleUtil <= 0 {
+		idleUtil = DefaultGPUIdleUtilPercent
+	}
+	return tempC, idleUtil
+}
+
+// GPUIssue는 과열되었거나 유휴 상태인 인스턴스입니다
+type GPUIssue struct {
+	Worker   string
+	IP       string
+	Instance int // 워커 안에서의 인스턴스 순서 (IP가 비어 있거나 겹쳐도 인스턴스를 구분)
+	Reading  GPUReading
+	Hot      bool // 온도가 기준 이상
+	Idle     bool // Running인데 GPU 사용률이 기준 이하
+}
+
+// FindGPUIssues는 워커 인스턴스 중 GPU 온도가 tempC 이상이거나, Running인데 사용률이 idleUtil% 이하인 것을 찾습니다
+func FindGPUIssues(workers []WorkerMinuteMetrics, tempC, idleUtil int) []GPUIssue {
+	var issues []GPUIssue
+	for _, worker := range workers {
+		for i, inst := range worker.Instances {
+			if inst.GPU == nil {
+				continue
+			}
+			hot := inst.GPU.TempC >= tempC
+			idle := strings.EqualFold(inst.Status, "running") && inst.GPU.UtilPercent >= 0 && inst.GPU.UtilPercent <= idleUtil
+			if hot || idle {
+				issues = append(issues, GPUIssue{Worker: worker.Name, IP: inst.IP, Instance: i, Reading: *inst.GPU, Hot: hot, Idle: idle})
+			}
+		}
+	}
+	return issues
+}
+
+// FormatGPUReading은 측정값을 "72°C | 98% | 250W" 형식으로 포맷하며, 읽을 수 없는 값은 "-"로 표시합니다
+func FormatGPUReading(r GPUReading) string {
+	temp, util, power := "-", "-", "-"
+	if r.TempC >= 0 {
+		temp = fmt.Sprintf("%d°C", r.TempC)
+	}
+	if r.UtilPercent >= 0 {
+		util = fmt.Sprintf("%d%%", r.UtilPercent)
+	}
+	if r.PowerW >= 0 {
+		power = fmt.Sprintf("%.0fW", r.PowerW)
+	}
+	return fmt.Sprintf("%5s | %4s | %5s", temp, util, power)
+}
+
+// checkGPUHealth는 GPU 과열 또는 Running 상태에서의 유휴(사용률 ~0%) 인스턴스가 있으면 알림을 보냅니다
+func (m *Client) checkGPUHealth(mm *MinuteMetrics, config AlertConfig, sendAlert func(string, string) error) error {
+	if !config.Enabled {
+		return nil
+	}
+
+	tempC, idleUtil := config.GPUThresholds()
+	issues := FindGPUIssues(mm.User.Workers, tempC, idleUtil)
+
+	if len(issues) > 0 && !mm.AlertState.GPUHealthAlerted {
+		lines := make([]string, 0, len(issues))
+		for _, issue := range issues {
+			var reasons []string
+			if issue.Hot {
+				reasons = append(reasons, "hot")
+			}
+			if issue.Idle {
+				reasons = append(reasons, "idle")
+			}
+			lines = append(lines, fmt.Sprintf("%s %s: %s (%s)", issue.Worker, issue.IP, FormatGPUReading(issue.Reading), strings.Join(reasons, ", ")))
+		}
+		title := "🌡️ GPU Health Alert"
+		msg := fmt.Sprintf("Threshold: >= %d°C or <= %d%% utilization while Running\n%s", tempC, idleUtil, strings.Join(lines, "\n"))
+		message := fmt.Sprintf("%s\n%s", title, CodeBlock(msg))
+
+		if err := sendAlert(message, "status"); err != nil {
+			return fmt.Errorf("failed to send GPU health alert: %w", err)
+		}
+		mm.AlertState.GPUHealthAlerted = true
+	} else if len(issues) == 0 && mm.AlertState.GPUHealthAlerted {
+		title := "✅ GPU Health Recovered"
+		msg := fmt.Sprintf("No instance is at or above %d°C or idle while Running", tempC)
+		message := fmt.Sprintf("%s\n%s", title, CodeBlock(msg))
+
+		if err := mm.AlertState.sendUnlessAcked(AlertGPUHealth, sendAlert, message, "status"); err != nil {
+			return fmt.Errorf("failed to send GPU health recovery alert: %w", err)
+		}
+		mm.AlertState.GPUHealthAlerted = false
+	}
+
+	return nil
+}
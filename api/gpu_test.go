@@ -0,0 +1,110 @@
+package api
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestNvidiaSmiGPUReading(t *testing.T) {
+	var gpu nvidiaSmiGPU
+	data := `{
+		"product_name": ["NVIDIA GeForce RTX 4090"],
+		"temperature": [{"gpu_temp": ["72 C"]}],
+		"utilization": [{"gpu_util": ["98 %"]}],
+		"gpu_power_readings": [{"power_draw": ["310.55 W"]}]
+	}`
+	if err := json.Unmarshal([]byte(data), &gpu); err != nil {
+		t.Fatal(err)
+	}
+
+	reading := gpu.reading()
+	if reading == nil || reading.TempC != 72 || reading.UtilPercent != 98 || reading.PowerW != 310.55 {
+		t.Fatalf("unexpected reading: %+v", reading)
+	}
+
+	// 읽을 수 없는 값은 -1, 전부 없으면 nil
+	gpu = nvidiaSmiGPU{}
+	if err := json.Unmarshal([]byte(`{"temperature": [{"gpu_temp": ["N/A"]}], "utilization": [{"gpu_util": ["0 %"]}]}`), &gpu); err != nil {
+		t.Fatal(err)
+	}
+	reading = gpu.reading()
+	if reading == nil || reading.TempC != -1 || reading.UtilPercent != 0 || reading.PowerW != -1 {
+		t.Fatalf("unexpected reading: %+v", reading)
+	}
+	if reading := (nvidiaSmiGPU{}).reading(); reading != nil {
+		t.Errorf("expected nil reading, got %+v", reading)
+	}
+}
+
+func TestCheckGPUHealth(t *testing.T) {
+	client := NewClient()
+	config := AlertConfig{Enabled: true, GPUTempThreshold: 85}
+
+	var alerts []string
+	sendAlert := func(message, alertType string) error {
+		alerts = append(alerts, message)
+		return nil
+	}
+
+	var mm MinuteMetrics
+	mm.User.Workers = []WorkerMinuteMetrics{{
+		Name: "worker1",
+		Instances: []InstanceMetrics{
+			{IP: "10.0.0.1", Status: "Running", GPU: &GPUReading{TempC: 70, UtilPercent: 95, PowerW: 300}},
+			{IP: "10.0.0.2", Status: "Running", GPU: &GPUReading{TempC: 90, UtilPercent: 95, PowerW: 300}},
+			{IP: "10.0.0.3", Status: "Running", GPU: &GPUReading{TempC: 40, UtilPercent: 0, PowerW: 30}},
+			{IP: "10.0.0.4", Status: "Initializing", GPU: &GPUReading{TempC: 40, UtilPercent: 0, PowerW: 30}},
+			{IP: "10.0.0.5", Status: "Running"},
+		},
+	}}
+
+	if err := client.checkGPUHealth(&mm, config, sendAlert); err != nil {
+		t.Fatal(err)
+	}
+	if len(alerts) != 1 || !strings.Contains(alerts[0], "GPU Health Alert") {
+		t.Fatalf("expected a GPU health alert, got %v", alerts)
+	}
+	if !strings.Contains(alerts[0], "10.0.0.2") || !strings.Contains(alerts[0], "10.0.0.3") ||
+		strings.Contains(alerts[0], "10.0.0.1") || strings.Contains(alerts[0], "10.0.0.4") {
+		t.Errorf("unexpected instances in alert: %s", alerts[0])
+	}
+
+	// 계속 이상이 있으면 추가 알림 없음
+	if err := client.checkGPUHealth(&mm, config, sendAlert); err != nil {
+		t.Fatal(err)
+	}
+	if len(alerts) != 1 {
+		t.Fatalf("expected no additional alert, got %v", alerts)
+	}
+
+	// 모두 정상으로 돌아오면 복구 알림
+	mm.User.Workers[0].Instances = mm.User.Workers[0].Instances[:1]
+	if err := client.checkGPUHealth(&mm, config, sendAlert); err != nil {
+		t.Fatal(err)
+	}
+	if len(alerts) != 2 || !strings.Contains(alerts[1], "GPU Health Recovered") || mm.AlertState.GPUHealthAlerted {
+		t.Errorf("expected a recovery alert, got %v (state %+v)", alerts, mm.AlertState)
+	}
+}
+
+func TestFindGPUIssuesInstanceIndex(t *testing.T) {
+	workers := []WorkerMinuteMetrics{{
+		Name: "worker1",
+		Instances: []InstanceMetrics{
+			{Status: "Running", GPU: &GPUReading{TempC: 70, UtilPercent: 95, PowerW: 300}},
+			{Status: "Running"},
+			{Status: "Running", GPU: &GPUReading{TempC: 90, UtilPercent: 95, PowerW: 300}},
+		},
+	}}
+
+	// IP가 없어도 인스턴스 순서로 과열 인스턴스를 구분
+	tempC, idleUtil := AlertConfig{}.GPUThresholds()
+	issues := FindGPUIssues(workers, tempC, idleUtil)
+	if len(issues) != 1 || issues[0].Instance != 2 || !issues[0].Hot {
+		t.Errorf("expected only the third instance to be hot, got %+v", issues)
+	}
+	if tempC != DefaultGPUTempThreshold || idleUtil != DefaultGPUIdleUtilPercent {
+		t.Errorf("expected default thresholds, got %d°C / %d%%", tempC, idleUtil)
+	}
+}
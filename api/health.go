@@ -0,0 +1,148 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// readyMaxAge는 마지막 수집 이후 준비 상태로 간주하는 최대 시간입니다
+	readyMaxAge = 2 * time.Minute
+	// errorWindow는 최근 에러 수를 집계하는 기간입니다
+	errorWindow = 10 * time.Minute
+)
+
+// CollectionHealth는 메트릭스 수집 상태를 추적합니다
+type CollectionHealth struct {
+	lastCollectionTime time.Time
+	recentErrors       []time.Time
+	totalCollections   int
+	totalErrors        int
+	lastError          string
+	mu                 sync.Mutex
+}
+
+// HealthStatus는 한 계정의 수집 상태입니다
+type HealthStatus struct {
+	Ready              bool      `json:"ready"`
+	LastCollectionTime time.Time `json:"lastCollectionTime"`
+	AgeSeconds         float64   `json:"ageSeconds"`
+	RecentErrors       int       `json:"recentErrors"` // 최근 10분간 에러 수
+	TotalCollections   int       `json:"totalCollections"`
+	TotalErrors        int       `json:"totalErrors"`
+	LastError          string    `json:"lastError,omitempty"`
+}
+
+// ReadyStatus는 /readyz 응답 본문입니다
+type ReadyStatus struct {
+	Ready    bool                    `json:"ready"` // 수집 중인 모든 계정이 준비된 경우 true
+	Accounts map[string]HealthStatus `json:"accounts"`
+}
+
+// 계정별 수집 상태입니다 (한 계정의 실패가 다른 계정의 상태로 보고되지 않도록 계정마다 따로 기록)
+var (
+	collectionHealth     = make(map[string]*CollectionHealth)
+	collectionHealthLock sync.Mutex
+)
+
+// CollectionHealthFor는 계정의 수집 상태를 반환하며, 없으면 새로 만듭니다
+func CollectionHealthFor(account string) *CollectionHealth {
+	collectionHealthLock.Lock()
+	defer collectionHealthLock.Unlock()
+	h, ok := collectionHealth[account]
+	if !ok {
+		h = &CollectionHealth{}
+		collectionHealth[account] = h
+	}
+	return h
+}
+
+// CollectionReadiness는 모든 계정의 수집 상태를 반환합니다
+// 수집을 기록한 계정이 없거나 한 계정이라도 준비되지 않았으면 Ready는 false입니다
+func CollectionReadiness() ReadyStatus {
+	collectionHealthLock.Lock()
+	healths := make(map[string]*CollectionHealth, len(collectionHealth))
+	for account, h := range collectionHealth {
+		healths[account] = h
+	}
+	collectionHealthLock.Unlock()
+
+	result := ReadyStatus{Ready: len(healths) > 0, Accounts: make(map[string]HealthStatus, len(healths))}
+	for account, h := range healths {
+		status := h.Status()
+		result.Accounts[account] = status
+		result.Ready = result.Ready && status.Ready
+	}
+	return result
+}
+
+// RecordSuccess는 성공한 수집을 기록합니다
+func (h *CollectionHealth) RecordSuccess() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.lastCollectionTime = time.Now()
+	h.totalCollections++
+}
+
+// RecordError는 실패한 수집을 기록합니다
+func (h *CollectionHealth) RecordError(err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	now := time.Now()
+	h.recentErrors = append(h.pruneErrors(now), now)
+	h.totalErrors++
+	h.lastError = err.Error()
+}
+
+// pruneErrors는 집계 기간이 지난 에러를 제거합니다 (잠금 상태에서 호출)
+func (h *CollectionHealth) pruneErrors(now time.Time) []time.Time {
+	cutoff := now.Add(-errorWindow)
+	valid := h.recentErrors[:0]
+	for _, t := range h.recentErrors {
+		if t.After(cutoff) {
+			valid = append(valid, t)
+		}
+	}
+	return valid
+}
+
+// Status는 현재 수집 상태를 반환합니다
+func (h *CollectionHealth) Status() HealthStatus {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	now := time.Now()
+	h.recentErrors = h.pruneErrors(now)
+
+	status := HealthStatus{
+		LastCollectionTime: h.lastCollectionTime,
+		RecentErrors:       len(h.recentErrors),
+		TotalCollections:   h.totalCollections,
+		TotalErrors:        h.totalErrors,
+		LastError:          h.lastError,
+	}
+	if !h.lastCollectionTime.IsZero() {
+		age := now.Sub(h.lastCollectionTime)
+		status.AgeSeconds = age.Seconds()
+		status.Ready = age <= readyMaxAge
+	}
+	return status
+}
+
+// handleHealthz는 프로세스가 살아 있으면 항상 200을 반환합니다
+func (s *MetricsServer) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+// handleReadyz는 모든 계정의 메트릭스가 최근 2분 내에 수집된 경우에만 200을 반환하며, 계정별 상태를 함께 보여줍니다
+func (s *MetricsServer) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	status := CollectionReadiness()
+
+	w.Header().Set("Content-Type", "application/json")
+	if !status.Ready {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(status)
+}
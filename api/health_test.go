@@ -0,0 +1,37 @@
+package api
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCollectionHealthPerAccount(t *testing.T) {
+	saved := collectionHealth
+	collectionHealth = make(map[string]*CollectionHealth)
+	defer func() { collectionHealth = saved }()
+
+	if CollectionReadiness().Ready {
+		t.Error("expected not ready before any collection")
+	}
+
+	CollectionHealthFor("a").RecordError(errors.New("login failed"))
+	CollectionHealthFor("b").RecordSuccess()
+
+	// b의 성공이 a의 실패를 지우지 않음
+	status := CollectionReadiness()
+	if status.Ready {
+		t.Error("expected not ready while account a has not collected")
+	}
+	a, b := status.Accounts["a"], status.Accounts["b"]
+	if a.Ready || a.LastError != "login failed" || a.TotalErrors != 1 {
+		t.Errorf("unexpected status for a: %+v", a)
+	}
+	if !b.Ready || b.LastError != "" || b.TotalErrors != 0 {
+		t.Errorf("unexpected status for b: %+v", b)
+	}
+
+	CollectionHealthFor("a").RecordSuccess()
+	if !CollectionReadiness().Ready {
+		t.Error("expected ready once every account has collected")
+	}
+}
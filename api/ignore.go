@@ -0,0 +1,64 @@
+package api
+
+import (
+	"log"
+	"sort"
+)
+
+// IgnoreInstance는 Vast.ai 인스턴스를 자동 재부팅 대상에서 제외하며, 새로 제외했으면 true를 반환합니다
+// 제외 목록은 알림 상태 파일에 저장되어 재시작 후에도 유지됩니다
+func IgnoreInstance(instanceID int) bool {
+	return globalAlertState.setIgnored(instanceID, true)
+}
+
+// UnignoreInstance는 인스턴스를 다시 자동 재부팅 대상에 포함하며, 제외되어 있었으면 true를 반환합니다
+func UnignoreInstance(instanceID int) bool {
+	return globalAlertState.setIgnored(instanceID, false)
+}
+
+// InstanceIgnored는 인스턴스가 자동 재부팅 대상에서 제외되어 있는지 반환합니다
+func InstanceIgnored(instanceID int) bool {
+	globalAlertState.mu.Lock()
+	defer globalAlertState.mu.Unlock()
+	return globalAlertState.ignored[instanceID]
+}
+
+// IgnoredInstances는 자동 재부팅 대상에서 제외된 인스턴스 ID를 오름차순으로 반환합니다
+func IgnoredInstances() []int {
+	globalAlertState.mu.Lock()
+	defer globalAlertState.mu.Unlock()
+	return globalAlertState.ignoredIDs()
+}
+
+// ignoredIDs는 제외된 인스턴스 ID를 정렬하여 반환합니다 (m.mu를 잡은 상태에서 호출)
+func (m *AlertStateManager) ignoredIDs() []int {
+	ids := make([]int, 0, len(m.ignored))
+	for id := range m.ignored {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+func (m *AlertStateManager) setIgnored(instanceID int, ignored bool) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.ignored[instanceID] == ignored {
+		return false
+	}
+	if ignored {
+		if m.ignored == nil {
+			m.ignored = make(map[int]bool)
+		}
+		m.ignored[instanceID] = true
+	} else {
+		delete(m.ignored, instanceID)
+	}
+	if m.path != "" {
+		if err := m.save(); err != nil {
+			log.Printf("Failed to persist ignored instances: %v", err)
+		}
+	}
+	return true
+}
@@ -2,20 +2,57 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"strconv"
+	"strings"
+	"time"
 )
 
 // KuzcoClient handles Kuzco API interactions
 type KuzcoClient struct {
 	httpClient *Client
+
+	// StrictMode makes GetAllMetrics fail on the first error instead of returning partial metrics
+	StrictMode bool
+	// HistoryHours is how many hours of generations history GetAllMetrics fetches (0: DefaultGenerationsHistoryHours)
+	HistoryHours int
+	// Teams are the worker teams GetAllMetrics collects and sums (empty: the userID passed to GetAllMetrics)
+	Teams []Team
+	// StepHook, if set, is called after each GetAllMetrics step with its name, duration and error
+	StepHook func(name string, elapsed time.Duration, err error)
+}
+
+// Generations history lookback limits
+const (
+	DefaultGenerationsHistoryHours = 2   // hours fetched by GetAllMetrics when not configured
+	MaxGenerationsHistoryHours     = 168 // longest lookback accepted from config or /genhours (7 days)
+)
+
+// ValidateHistoryHours checks that a generations history lookback is within 1..MaxGenerationsHistoryHours
+func ValidateHistoryHours(hours int) error {
+	if hours < 1 || hours > MaxGenerationsHistoryHours {
+		return fmt.Errorf("history hours must be between 1 and %d, got %d", MaxGenerationsHistoryHours, hours)
+	}
+	return nil
 }
 
 // NewKuzcoClient creates a new Kuzco client
 func NewKuzcoClient(client *Client) *KuzcoClient {
 	return &KuzcoClient{
-		httpClient: client,
+		httpClient:   client,
+		HistoryHours: client.historyHours,
+		Teams:        client.teams,
+	}
+}
+
+// historyHours returns the configured lookback, falling back to the default
+func (c *KuzcoClient) historyHours() int {
+	if c.HistoryHours <= 0 {
+		return DefaultGenerationsHistoryHours
 	}
+	return c.HistoryHours
 }
 
 // GetMetrics retrieves metrics from Kuzco API
@@ -41,42 +78,51 @@ func (c *KuzcoClient) GetMetrics(query MetricsQuery) (float64, error) {
 	}
 
 	var resp []MetricsResponse
-	if err := json.Unmarshal(respBody, &resp); err != nil {
-		return 0, fmt.Errorf("error parsing response: %w", err)
+	if err := parseJSONOrError(query.Endpoint, respBody, &resp); err != nil {
+		return 0, err
 	}
 
 	if len(resp) == 0 {
-		return 0, fmt.Errorf("empty response received")
+		return 0, fmt.Errorf("empty response received: %w", ErrParse)
+	}
+
+	value, err := metricValue(resp[0].Result.Data.JSON)
+	if err != nil {
+		log.Printf("Unexpected metrics response from %s: %v (body: %q)", query.Endpoint, err, bodyPreview(respBody))
+		return 0, fmt.Errorf("%s: %w", query.Endpoint, err)
 	}
+	return value, nil
+}
 
-	// Try to handle different response formats
-	switch v := resp[0].Result.Data.JSON.(type) {
+// metricValue는 tRPC 응답의 data.json 값을 숫자로 바꿉니다
+// 숫자, 숫자 문자열, null과 이들을 {"json": ...}로 한 번 더 감싼 형식을 지원합니다
+func metricValue(raw interface{}) (float64, error) {
+	switch v := raw.(type) {
 	case float64:
 		return v, nil
-	case map[string]interface{}:
-		if val, ok := v["json"].(float64); ok {
-			return val, nil
-		}
-		// Handle null value in json field
-		if v["json"] == nil {
-			return 0, nil
-		}
 	case int:
 		return float64(v), nil
 	case int64:
 		return float64(v), nil
 	case nil:
 		return 0, nil
-	}
-
-	// If we can't parse it directly, try to parse it as a number
-	if numStr, ok := resp[0].Result.Data.JSON.(string); ok {
-		if num, err := strconv.ParseFloat(numStr, 64); err == nil {
-			return num, nil
+	case string:
+		num, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid response format (%w): non-numeric string %q", ErrParse, v)
+		}
+		return num, nil
+	case map[string]interface{}:
+		nested, ok := v["json"]
+		if !ok {
+			return 0, fmt.Errorf("invalid response format (%w): object without \"json\" field: %v", ErrParse, v)
 		}
+		if _, isMap := nested.(map[string]interface{}); isMap {
+			return 0, fmt.Errorf("invalid response format (%w): nested too deeply: %v", ErrParse, v)
+		}
+		return metricValue(nested)
 	}
-
-	return 0, fmt.Errorf("invalid response format: %v", resp[0].Result.Data.JSON)
+	return 0, fmt.Errorf("invalid response format (%w): unexpected %T: %v", ErrParse, raw, raw)
 }
 
 // GetRunningInstanceCount retrieves the count of running instances
@@ -186,12 +232,12 @@ func (c *KuzcoClient) GetGenerationsHistory(hoursBack int) ([]GenerationHistory,
 	}
 
 	var resp []GenerationHistoryResponse
-	if err := json.Unmarshal(respBody, &resp); err != nil {
-		return nil, fmt.Errorf("error parsing response: %w", err)
+	if err := parseJSONOrError(EndpointMetricsGenerationsHistory, respBody, &resp); err != nil {
+		return nil, err
 	}
 
 	if len(resp) == 0 {
-		return nil, fmt.Errorf("empty response received")
+		return nil, fmt.Errorf("empty response received: %w", ErrParse)
 	}
 
 	return resp[0].Result.Data.JSON, nil
@@ -206,12 +252,12 @@ func (c *KuzcoClient) GetUserGenerationsHistory(userID string, hoursBack int) ([
 	}
 
 	var resp []GenerationHistoryResponse
-	if err := json.Unmarshal(respBody, &resp); err != nil {
-		return nil, fmt.Errorf("error parsing response: %w", err)
+	if err := parseJSONOrError(EndpointMetricsGenerationsHistory, respBody, &resp); err != nil {
+		return nil, err
 	}
 
 	if len(resp) == 0 {
-		return nil, fmt.Errorf("empty response received")
+		return nil, fmt.Errorf("empty response received: %w", ErrParse)
 	}
 
 	return resp[0].Result.Data.JSON, nil
@@ -226,12 +272,32 @@ func (c *KuzcoClient) GetWorkerGenerationsHistory(workerID string, teamID string
 	}
 
 	var resp []GenerationHistoryResponse
-	if err := json.Unmarshal(respBody, &resp); err != nil {
-		return nil, fmt.Errorf("error parsing response: %w", err)
+	if err := parseJSONOrError(EndpointMetricsGenerationsHistory, respBody, &resp); err != nil {
+		return nil, err
 	}
 
 	if len(resp) == 0 {
-		return nil, fmt.Errorf("empty response received")
+		return nil, fmt.Errorf("empty response received: %w", ErrParse)
+	}
+
+	return resp[0].Result.Data.JSON, nil
+}
+
+// GetUserTokenEarningsHistory retrieves the user's hourly token earnings history
+func (c *KuzcoClient) GetUserTokenEarningsHistory(userID string, hoursBack int) ([]TokenEarningsHistory, error) {
+	respBody, err := c.httpClient.DoRequest("GET", fmt.Sprintf("%s?batch=1&input={\"0\":{\"json\":{\"hoursBack\":%d,\"workerTeamId\":\"%s\"}}}",
+		EndpointMetricsTokensHistory, hoursBack, userID), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp []TokenEarningsHistoryResponse
+	if err := parseJSONOrError(EndpointMetricsTokensHistory, respBody, &resp); err != nil {
+		return nil, err
+	}
+
+	if len(resp) == 0 {
+		return nil, fmt.Errorf("empty response received: %w", ErrParse)
 	}
 
 	return resp[0].Result.Data.JSON, nil
@@ -245,71 +311,138 @@ func (c *KuzcoClient) GetVersions() (string, error) {
 	}
 
 	var resp []VersionResponse
-	if err := json.Unmarshal(respBody, &resp); err != nil {
-		return "", fmt.Errorf("error parsing response: %w", err)
+	if err := parseJSONOrError(EndpointSystemBucketVersions, respBody, &resp); err != nil {
+		return "", err
 	}
 
 	if len(resp) == 0 {
-		return "", fmt.Errorf("empty response received")
+		return "", fmt.Errorf("empty response received: %w", ErrParse)
 	}
 
 	return resp[0].Result.Data.JSON.CLIVersion, nil
 }
 
 // GetAllMetrics retrieves all metrics for a user
+// With Teams set, the user-scoped metrics are collected per team, summed into User and kept in User.Teams,
+// and workers of other teams are skipped.
+// Unless StrictMode is set, fields that fail to load are left zero, flagged in Metrics.Missing, and the
+// returned error joins every failure, so callers can still use the partial *Metrics
 func (c *KuzcoClient) GetAllMetrics(userID string) (*Metrics, error) {
 	metrics := &Metrics{}
-	var err error
-
-	// Get version info
-	if metrics.General.CLIVersion, err = c.GetVersions(); err != nil {
-		return nil, fmt.Errorf("failed to get CLI version: %w", err)
-	}
-
-	// Get General metrics
-	if metrics.General.RunningInstanceCount, err = c.GetRunningInstanceCount(); err != nil {
-		return nil, fmt.Errorf("failed to get running instance count: %w", err)
-	}
-	if metrics.General.RPM, err = c.GetRPM(); err != nil {
-		return nil, fmt.Errorf("failed to get RPM: %w", err)
-	}
-
-	if metrics.General.TokensLast24Hours, err = c.GetTokensLast24Hours(); err != nil {
-		return nil, fmt.Errorf("failed to get tokens last 24h: %w", err)
-	}
-
-	if metrics.General.TokensAllTime, err = c.GetTokensAllTime(); err != nil {
-		return nil, fmt.Errorf("failed to get total tokens: %w", err)
-	}
-
-	if metrics.General.GenerationsLast24Hours, err = c.GetGenerationsLast24Hours(); err != nil {
-		return nil, fmt.Errorf("failed to get generations last 24h: %w", err)
-	}
-
-	if metrics.General.GenerationsHistory, err = c.GetGenerationsHistory(2); err != nil {
-		return nil, fmt.Errorf("failed to get generations history: %w", err)
-	}
-
-	// Get User metrics
-	if metrics.User.TokensLast24Hours, err = c.GetUserTokensLast24Hours(userID); err != nil {
-		return nil, fmt.Errorf("failed to get user tokens last 24h: %w", err)
-	}
-
-	if metrics.User.TokensAllTime, err = c.GetUserTokensAllTime(userID); err != nil {
-		return nil, fmt.Errorf("failed to get user total tokens: %w", err)
+	var errs []error
+
+	// missing은 단계가 실패했을 때 표시할 Metrics.Missing 필드입니다
+	type step struct {
+		name    string
+		missing *bool
+		fetch   func() error
+	}
+	missing := &metrics.Missing
+	steps := []step{
+		// Get version info
+		{"CLI version", &missing.CLIVersion, func() (err error) {
+			metrics.General.CLIVersion, err = c.GetVersions()
+			return
+		}},
+
+		// Get General metrics
+		{"running instance count", &missing.RunningInstances, func() (err error) {
+			metrics.General.RunningInstanceCount, err = c.GetRunningInstanceCount()
+			return
+		}},
+		{"RPM", &missing.RPM, func() (err error) {
+			metrics.General.RPM, err = c.GetRPM()
+			return
+		}},
+		{"tokens last 24h", &missing.GeneralTokens, func() (err error) {
+			metrics.General.TokensLast24Hours, err = c.GetTokensLast24Hours()
+			return
+		}},
+		{"total tokens", &missing.GeneralTokens, func() (err error) {
+			metrics.General.TokensAllTime, err = c.GetTokensAllTime()
+			return
+		}},
+		{"generations last 24h", &missing.GeneralGenerations, func() (err error) {
+			metrics.General.GenerationsLast24Hours, err = c.GetGenerationsLast24Hours()
+			return
+		}},
+		{"generations history", &missing.GeneralGenerations, func() (err error) {
+			metrics.General.GenerationsHistory, err = c.GetGenerationsHistory(c.historyHours())
+			return
+		}},
+	}
+
+	// Get User metrics (팀별)
+	teams := c.teamsOrUser(userID)
+	teamMetrics := make([]TeamMetrics, len(teams))
+	teamHistories := make([][]GenerationHistory, len(teams))
+	for i, team := range teams {
+		teamMetrics[i].ID = team.ID
+		teamMetrics[i].Name = team.Label()
+		prefix := "user"
+		if len(c.Teams) > 0 {
+			prefix = "team " + team.Label()
+		}
+		steps = append(steps,
+			step{prefix + " tokens last 24h", &missing.UserTokens, func() (err error) {
+				teamMetrics[i].TokensLast24Hours, err = c.GetUserTokensLast24Hours(team.ID)
+				return
+			}},
+			step{prefix + " total tokens", &missing.UserTokens, func() (err error) {
+				teamMetrics[i].TokensAllTime, err = c.GetUserTokensAllTime(team.ID)
+				return
+			}},
+			step{prefix + " generations last 24h", &missing.UserGenerations, func() (err error) {
+				teamMetrics[i].GenerationsLast24Hours, err = c.GetUserGenerationsLast24Hours(team.ID)
+				return
+			}},
+			step{prefix + " generations history", &missing.UserGenerations, func() (err error) {
+				teamHistories[i], err = c.GetUserGenerationsHistory(team.ID, c.historyHours())
+				return
+			}},
+		)
 	}
 
-	if metrics.User.GenerationsLast24Hours, err = c.GetUserGenerationsLast24Hours(userID); err != nil {
-		return nil, fmt.Errorf("failed to get user generations last 24h: %w", err)
+	// Get Worker information
+	steps = append(steps, step{"workers", &missing.Workers, func() (err error) {
+		metrics.User.Workers, err = c.httpClient.GetWorkers(false)
+		return
+	}})
+
+	for _, step := range steps {
+		start := time.Now()
+		err := step.fetch()
+		if c.StepHook != nil {
+			c.StepHook(step.name, time.Since(start), err)
+		}
+		if err != nil {
+			err = fmt.Errorf("failed to get %s: %w", step.name, err)
+			if c.StrictMode {
+				return nil, err
+			}
+			*step.missing = true
+			errs = append(errs, err)
+		}
 	}
 
-	if metrics.User.GenerationsHistory, err = c.GetUserGenerationsHistory(userID, 2); err != nil {
-		return nil, fmt.Errorf("failed to get user generations history: %w", err)
+	// 팀별 값을 합산
+	for i := range teamMetrics {
+		metrics.User.TokensLast24Hours += teamMetrics[i].TokensLast24Hours
+		metrics.User.TokensAllTime += teamMetrics[i].TokensAllTime
+		metrics.User.GenerationsLast24Hours += teamMetrics[i].GenerationsLast24Hours
+		metrics.User.GenerationsHistory = mergeGenerationsHistory(metrics.User.GenerationsHistory, teamHistories[i])
+		if len(teamHistories[i]) > 0 {
+			teamMetrics[i].GenerationLastHour = teamHistories[i][0].Value
+		}
 	}
-
-	// Get Worker information
-	if metrics.User.Workers, err = c.httpClient.GetWorkers(); err != nil {
-		return nil, fmt.Errorf("failed to get workers: %w", err)
+	if len(c.Teams) > 0 {
+		metrics.User.Workers = teamWorkers(metrics.User.Workers, teamMetrics)
+		for i := range teamMetrics {
+			if metrics.General.TokensLast24Hours > 0 {
+				teamMetrics[i].Share = float64(teamMetrics[i].TokensLast24Hours) / float64(metrics.General.TokensLast24Hours)
+			}
+		}
+		metrics.User.Teams = teamMetrics
 	}
 
 	// Calculate totals
@@ -333,5 +466,5 @@ func (c *KuzcoClient) GetAllMetrics(userID string) (*Metrics, error) {
 		}
 	}
 
-	return metrics, nil
+	return metrics, errors.Join(errs...)
 }
@@ -0,0 +1,300 @@
+package api_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"test/api"
+	"test/api/apitest"
+	"testing"
+	"time"
+)
+
+func TestGetAllMetricsPartialSuccess(t *testing.T) {
+	apitest.ChdirWithGPUPrices(t, `[{"Gpu": "RTX 3090", "Price": 0.21}]`)
+	fixture := apitest.DefaultKuzcoFixture()
+	fixture.FailingEndpoints = []string{api.EndpointMetricsRPM}
+	server := apitest.NewKuzcoServer(t, fixture)
+
+	metrics, err := api.NewKuzcoClient(apitest.NewClient(server)).GetAllMetrics("user")
+	if err == nil {
+		t.Fatal("Expected error for failing RPM endpoint")
+	}
+	if !strings.Contains(err.Error(), "RPM") {
+		t.Errorf("Expected error to mention RPM, got %v", err)
+	}
+	if metrics == nil {
+		t.Fatal("Expected partial metrics, got nil")
+	}
+	if metrics.General.RPM != 0 {
+		t.Errorf("Expected RPM 0, got %d", metrics.General.RPM)
+	}
+	if metrics.General.RunningInstanceCount != 123 {
+		t.Errorf("Expected running instance count 123, got %d", metrics.General.RunningInstanceCount)
+	}
+	if metrics.User.TokensLast24Hours != 123 {
+		t.Errorf("Expected user tokens 123, got %d", metrics.User.TokensLast24Hours)
+	}
+	if metrics.General.CLIVersion != "0.2.3" {
+		t.Errorf("Expected CLI version 0.2.3, got %s", metrics.General.CLIVersion)
+	}
+	if want := (api.MissingFields{RPM: true}); metrics.Missing != want {
+		t.Errorf("Expected missing fields %+v, got %+v", want, metrics.Missing)
+	}
+}
+
+func TestGetAllMetricsStrictMode(t *testing.T) {
+	apitest.ChdirWithGPUPrices(t, `[{"Gpu": "RTX 3090", "Price": 0.21}]`)
+	fixture := apitest.DefaultKuzcoFixture()
+	fixture.FailingEndpoints = []string{api.EndpointMetricsRPM}
+	server := apitest.NewKuzcoServer(t, fixture)
+
+	kuzcoClient := api.NewKuzcoClient(apitest.NewClient(server))
+	kuzcoClient.StrictMode = true
+	metrics, err := kuzcoClient.GetAllMetrics("user")
+	if err == nil {
+		t.Fatal("Expected error for failing RPM endpoint")
+	}
+	if metrics != nil {
+		t.Errorf("Expected nil metrics in strict mode, got %+v", metrics)
+	}
+}
+
+func TestGetAllMetricsSuccess(t *testing.T) {
+	apitest.ChdirWithGPUPrices(t, `[{"Gpu": "RTX 3090", "Price": 0.21}]`)
+	fixture := apitest.DefaultKuzcoFixture()
+	fixture.Metrics = map[string]int{
+		api.EndpointMetricsRPM:               40,
+		api.EndpointMetricsTokensLast24Hours: 1000,
+	}
+	fixture.Workers = []apitest.Worker{
+		{ID: "w1", Name: "worker-1", TeamID: "team", Instances: []apitest.Instance{
+			{Status: "Running", IP: "10.0.0.1", GPU: "NVIDIA GeForce RTX 3090"},
+			{Status: "Running", IP: "10.0.0.2", GPU: "NVIDIA GeForce RTX 3090"},
+		}},
+	}
+	server := apitest.NewKuzcoServer(t, fixture)
+
+	metrics, err := api.NewKuzcoClient(apitest.NewClient(server)).GetAllMetrics("user")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if metrics.General.RPM != 40 {
+		t.Errorf("Expected RPM 40, got %d", metrics.General.RPM)
+	}
+	if metrics.General.RunningInstanceCount != 123 {
+		t.Errorf("Expected running instance count 123, got %d", metrics.General.RunningInstanceCount)
+	}
+	if len(metrics.General.GenerationsHistory) != 1 || metrics.General.GenerationsHistory[0].Value != 5 {
+		t.Errorf("Unexpected generations history: %+v", metrics.General.GenerationsHistory)
+	}
+	if metrics.User.TotalInstances != 2 {
+		t.Errorf("Expected 2 user instances, got %d", metrics.User.TotalInstances)
+	}
+	if metrics.User.TokensPerInstance != 500 {
+		t.Errorf("Expected 500 tokens per instance, got %d", metrics.User.TokensPerInstance)
+	}
+	if metrics.User.Share != 1 {
+		t.Errorf("Expected share 1, got %v", metrics.User.Share)
+	}
+
+	wantCost := 2 * (0.21*24 + api.DiskCostPerDay)
+	if diff := metrics.User.TotalDailyCost - wantCost; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("Expected total daily cost %.4f, got %.4f", wantCost, metrics.User.TotalDailyCost)
+	}
+}
+
+func TestLogin(t *testing.T) {
+	server := apitest.NewKuzcoServer(t, apitest.DefaultKuzcoFixture())
+
+	token, userID, err := apitest.NewClient(server).Login("user@example.com", "password")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if token != "test-token" || userID != "user-1" {
+		t.Errorf("Unexpected login result: token %q, user %q", token, userID)
+	}
+}
+
+func TestLoginWithoutUserID(t *testing.T) {
+	fixture := apitest.DefaultKuzcoFixture()
+	fixture.UserID = ""
+	server := apitest.NewKuzcoServer(t, fixture)
+
+	if _, _, err := apitest.NewClient(server).Login("user@example.com", "password"); err == nil {
+		t.Error("Expected error when no user ID is returned")
+	}
+}
+
+func TestLoginHTMLResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<html><body>Down for maintenance</body></html>"))
+	}))
+	defer server.Close()
+
+	_, _, err := apitest.NewClient(server).Login("user@example.com", "password")
+	if err == nil {
+		t.Fatal("Expected error for an HTML response")
+	}
+	if !strings.Contains(err.Error(), api.EndpointUserLogin) || !strings.Contains(err.Error(), "Down for maintenance") {
+		t.Errorf("Expected endpoint and body preview in error, got: %v", err)
+	}
+}
+
+func TestGetMetricsResponseVariants(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    float64
+		wantErr bool
+	}{
+		{"number", `42.5`, 42.5, false},
+		{"null", `null`, 0, false},
+		{"numeric string", `"1234"`, 1234, false},
+		{"nested number", `{"json": 7}`, 7, false},
+		{"nested numeric string", `{"json": "99.5"}`, 99.5, false},
+		{"nested null", `{"json": null}`, 0, false},
+		{"non-numeric string", `"abc"`, 0, true},
+		{"object without json", `{"value": 3}`, 0, true},
+		{"nested too deeply", `{"json": {"json": 1}}`, 0, true},
+		{"array", `[1, 2]`, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`[{"result": {"data": {"json": ` + tt.data + `}}}]`))
+			}))
+			defer server.Close()
+
+			got, err := api.NewKuzcoClient(apitest.NewClient(server)).GetMetrics(api.MetricsQuery{Endpoint: "metrics.test"})
+			if tt.wantErr {
+				if !errors.Is(err, api.ErrParse) || !strings.Contains(err.Error(), "metrics.test") {
+					t.Errorf("expected a parse error naming the endpoint, got %v", err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("expected %v, got %v (err %v)", tt.want, got, err)
+			}
+		})
+	}
+}
+
+func TestGetUserTokenEarningsHistory(t *testing.T) {
+	fixture := apitest.DefaultKuzcoFixture()
+	fixture.TokenEarningsHistory = []api.TokenEarningsHistory{
+		{Date: "2025-01-01T00:00:00Z", Value: 1500000},
+		{Date: "2025-01-01T01:00:00Z", Value: 2500000},
+	}
+	server := apitest.NewKuzcoServer(t, fixture)
+
+	history, err := api.NewKuzcoClient(apitest.NewClient(server)).GetUserTokenEarningsHistory("user", 24)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 2 || history[1].Value != 2500000 || history[1].Date != "2025-01-01T01:00:00Z" {
+		t.Errorf("unexpected history: %+v", history)
+	}
+}
+
+func TestGetTeamsTokenEarningsHistory(t *testing.T) {
+	fixture := apitest.DefaultKuzcoFixture()
+	fixture.TokenEarningsHistory = []api.TokenEarningsHistory{
+		{Date: "2025-01-01T00:00:00Z", Value: 1500000},
+		{Date: "2025-01-01T01:00:00Z", Value: 2500000},
+	}
+	server := apitest.NewKuzcoServer(t, fixture)
+
+	// 목 서버는 팀과 무관하게 같은 값을 반환하므로 팀 수만큼 더해지고, 최신 시각부터 정렬됩니다
+	client := apitest.NewClient(server)
+	client.SetTeams([]api.Team{{ID: "team-a", Name: "A"}, {ID: "team-b"}})
+	history, err := api.NewKuzcoClient(client).GetTeamsTokenEarningsHistory("user", 24)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 2 || history[0].Date != "2025-01-01T01:00:00Z" || history[0].Value != 5000000 || history[1].Value != 3000000 {
+		t.Errorf("unexpected merged history: %+v", history)
+	}
+
+	fixture.FailingEndpoints = []string{api.EndpointMetricsTokensHistory}
+	client = apitest.NewClient(apitest.NewKuzcoServer(t, fixture))
+	client.SetTeams([]api.Team{{ID: "team-a", Name: "A"}, {ID: "team-b"}})
+	history, err = api.NewKuzcoClient(client).GetTeamsTokenEarningsHistory("user", 24)
+	if err == nil || !strings.Contains(err.Error(), "team A") || !strings.Contains(err.Error(), "team team-b") {
+		t.Errorf("expected per-team errors, got %v", err)
+	}
+	if len(history) != 0 {
+		t.Errorf("expected no history when every team fails, got %+v", history)
+	}
+}
+
+func TestGetAllMetricsTeams(t *testing.T) {
+	apitest.ChdirWithGPUPrices(t, `[{"Gpu": "RTX 3090", "Price": 0.21}]`)
+	fixture := apitest.DefaultKuzcoFixture()
+	fixture.Metrics = map[string]int{api.EndpointMetricsTokensLast24Hours: 1000}
+	fixture.Workers = []apitest.Worker{
+		{ID: "w1", Name: "worker-1", TeamID: "team-a", Instances: []apitest.Instance{{Status: "Running"}, {Status: "Running"}}},
+		{ID: "w2", Name: "worker-2", TeamID: "team-b", Instances: []apitest.Instance{{Status: "Running"}}},
+		{ID: "w3", Name: "worker-3", TeamID: "other", Instances: []apitest.Instance{{Status: "Running"}}},
+	}
+	server := apitest.NewKuzcoServer(t, fixture)
+
+	client := apitest.NewClient(server)
+	client.SetTeams([]api.Team{{ID: "team-a", Name: "A"}, {ID: "team-b"}})
+	metrics, err := api.NewKuzcoClient(client).GetAllMetrics("user")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// 목 서버는 팀과 무관하게 같은 값을 반환하므로 합계는 팀 수만큼 늘어납니다
+	if metrics.User.TokensLast24Hours != 2000 {
+		t.Errorf("Expected tokens summed over 2 teams, got %d", metrics.User.TokensLast24Hours)
+	}
+	if len(metrics.User.GenerationsHistory) != 1 || metrics.User.GenerationsHistory[0].Value != 10 {
+		t.Errorf("Expected generations history merged by date, got %+v", metrics.User.GenerationsHistory)
+	}
+	if len(metrics.User.Workers) != 2 || metrics.User.TotalInstances != 3 {
+		t.Errorf("Expected workers of other teams to be skipped, got %d workers, %d instances", len(metrics.User.Workers), metrics.User.TotalInstances)
+	}
+	if len(metrics.User.Teams) != 2 {
+		t.Fatalf("Expected 2 team breakdowns, got %+v", metrics.User.Teams)
+	}
+	a, b := metrics.User.Teams[0], metrics.User.Teams[1]
+	if a.Name != "A" || a.Instances != 2 || a.TokensLast24Hours != 1000 || a.Share != 1 {
+		t.Errorf("Unexpected team A metrics: %+v", a)
+	}
+	if b.Name != "team-b" || b.Instances != 1 || b.GenerationLastHour != 5 {
+		t.Errorf("Unexpected team-b metrics: %+v", b)
+	}
+}
+
+func TestGetAllMetricsStepHook(t *testing.T) {
+	apitest.ChdirWithGPUPrices(t, `[{"Gpu": "RTX 3090", "Price": 0.21}]`)
+	fixture := apitest.DefaultKuzcoFixture()
+	fixture.FailingEndpoints = []string{api.EndpointMetricsRPM}
+	server := apitest.NewKuzcoServer(t, fixture)
+
+	steps := make(map[string]error)
+	var order []string
+	kuzcoClient := api.NewKuzcoClient(apitest.NewClient(server))
+	kuzcoClient.StepHook = func(name string, elapsed time.Duration, err error) {
+		if elapsed < 0 {
+			t.Errorf("negative duration for %s", name)
+		}
+		steps[name] = err
+		order = append(order, name)
+	}
+	kuzcoClient.GetAllMetrics("user")
+
+	if err, ok := steps["RPM"]; !ok || err == nil {
+		t.Errorf("expected the RPM step to report its error, got %v (called %v)", err, ok)
+	}
+	if err, ok := steps["workers"]; !ok || err != nil {
+		t.Errorf("expected the workers step to succeed, got %v (called %v)", err, ok)
+	}
+	if len(order) == 0 || order[len(order)-1] != "workers" {
+		t.Errorf("expected workers to be the last step, got %v", order)
+	}
+}
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// DefaultLogBufferLines는 /api/logs를 위해 메모리에 보관하는 로그 줄 수의 기본값입니다
+const DefaultLogBufferLines = 500
+
+// logSecretPatterns는 /api/logs로 노출되지 않도록 보관 전에 가리는 비밀 값 패턴입니다
+// 텔레그램 클라이언트 오류에는 봇 토큰이 들어간 요청 URL이 포함되고, 웹훅 URL은 그 자체가 비밀 값입니다
+var logSecretPatterns = []struct {
+	re          *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile(`bot[0-9]+:[A-Za-z0-9_-]+`), "bot***"},
+	{regexp.MustCompile(`(https://(?:discord|discordapp)\.com/api/webhooks/)[^\s"']+`), "${1}***"},
+	{regexp.MustCompile(`(https://hooks\.slack\.com/)[^\s"']+`), "${1}***"},
+}
+
+// LogBuffer는 최근 로그 줄을 보관하는 링 버퍼이며, log.SetOutput에 io.Writer로 연결합니다
+type LogBuffer struct {
+	lines    []string
+	next     int // 다음에 덮어쓸 위치 (버퍼가 가득 찬 경우)
+	capacity int
+	partial  string            // 줄바꿈으로 끝나지 않은 마지막 쓰기
+	secrets  *strings.Replacer // 설정의 비밀 값을 가림 (SetSecrets)
+	mu       sync.Mutex
+}
+
+// GlobalLogBuffer는 프로세스 로그를 보관하는 버퍼입니다 (/api/logs)
+var GlobalLogBuffer = NewLogBuffer(DefaultLogBufferLines)
+
+// NewLogBuffer는 최대 capacity줄을 보관하는 LogBuffer를 생성합니다
+func NewLogBuffer(capacity int) *LogBuffer {
+	if capacity <= 0 {
+		capacity = DefaultLogBufferLines
+	}
+	return &LogBuffer{capacity: capacity}
+}
+
+// SetCapacity는 버퍼 크기를 변경하며, 초과하는 오래된 줄은 버립니다
+// 0 이하이면 기본값을 사용합니다
+func (b *LogBuffer) SetCapacity(capacity int) {
+	if capacity <= 0 {
+		capacity = DefaultLogBufferLines
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	lines := b.ordered()
+	if len(lines) > capacity {
+		lines = lines[len(lines)-capacity:]
+	}
+	b.lines = lines
+	b.next = 0
+	b.capacity = capacity
+}
+
+// SetSecrets는 보관하는 로그 줄에서 가릴 비밀 값(토큰, 비밀번호, 웹훅 URL 등)을 설정합니다
+func (b *LogBuffer) SetSecrets(secrets []string) {
+	var pairs []string
+	for _, secret := range secrets {
+		if secret != "" {
+			pairs = append(pairs, secret, "***")
+		}
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.secrets = strings.NewReplacer(pairs...)
+}
+
+// redact는 로그 줄의 비밀 값을 가립니다 (잠금 상태에서 호출)
+func (b *LogBuffer) redact(line string) string {
+	if b.secrets != nil {
+		line = b.secrets.Replace(line)
+	}
+	for _, p := range logSecretPatterns {
+		line = p.re.ReplaceAllString(line, p.replacement)
+	}
+	return line
+}
+
+// Write는 p를 줄 단위로 나누어 비밀 값을 가린 뒤 보관하며, 항상 len(p)를 반환합니다
+func (b *LogBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	text := b.partial + string(p)
+	lines := strings.Split(text, "\n")
+	b.partial = lines[len(lines)-1]
+	for _, line := range lines[:len(lines)-1] {
+		b.add(b.redact(line))
+	}
+	return len(p), nil
+}
+
+// add는 한 줄을 추가하고, 버퍼가 가득 차면 가장 오래된 줄을 덮어씁니다 (잠금 상태에서 호출)
+func (b *LogBuffer) add(line string) {
+	if len(b.lines) < b.capacity {
+		b.lines = append(b.lines, line)
+		return
+	}
+	b.lines[b.next] = line
+	b.next = (b.next + 1) % b.capacity
+}
+
+// Lines는 보관 중인 로그 줄을 오래된 순서로 반환합니다
+func (b *LogBuffer) Lines() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.ordered()
+}
+
+// ordered는 링 버퍼를 시간순 슬라이스로 복사합니다 (잠금 상태에서 호출)
+func (b *LogBuffer) ordered() []string {
+	result := make([]string, 0, len(b.lines))
+	result = append(result, b.lines[b.next:]...)
+	return append(result, b.lines[:b.next]...)
+}
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"testing"
+)
+
+func TestLogBuffer(t *testing.T) {
+	buffer := NewLogBuffer(3)
+	logger := log.New(buffer, "", 0)
+	for i := 1; i <= 4; i++ {
+		logger.Printf("line %d", i)
+	}
+
+	if got := strings.Join(buffer.Lines(), ","); got != "line 2,line 3,line 4" {
+		t.Errorf("expected the last 3 lines oldest first, got %q", got)
+	}
+
+	// 줄바꿈 없이 나뉘어 쓰인 줄은 줄이 끝날 때 한 줄로 보관
+	fmt.Fprint(buffer, "multi")
+	fmt.Fprint(buffer, "part\nnext\n")
+	buffer.SetCapacity(2)
+	if got := strings.Join(buffer.Lines(), ","); got != "multipart,next" {
+		t.Errorf("expected joined partial writes after shrinking, got %q", got)
+	}
+}
+
+func TestLogBufferRedactsSecrets(t *testing.T) {
+	buffer := NewLogBuffer(10)
+	buffer.SetSecrets([]string{"vast-api-key", ""})
+	logger := log.New(buffer, "", 0)
+
+	logger.Printf(`Post "https://api.telegram.org/bot123456:AAH-secret_token/sendMessage": timeout`)
+	logger.Printf("discord: https://discord.com/api/webhooks/42/abc-def failed")
+	logger.Printf("slack: https://hooks.slack.com/services/T0/B0/xyz failed")
+	logger.Printf("vastai request with vast-api-key failed")
+
+	got := strings.Join(buffer.Lines(), "\n")
+	for _, secret := range []string{"AAH-secret_token", "123456:", "42/abc-def", "T0/B0/xyz", "vast-api-key"} {
+		if strings.Contains(got, secret) {
+			t.Errorf("expected %q to be redacted, got:\n%s", secret, got)
+		}
+	}
+	if !strings.Contains(got, "https://api.telegram.org/bot***/sendMessage") {
+		t.Errorf("expected the bot token to be replaced, got:\n%s", got)
+	}
+}
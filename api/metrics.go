@@ -1,24 +1,23 @@
 package api
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 )
 
-var (
-	tokenUnit = 10000.0
-)
-
 // HourlyStats는 시간별 통계를 저장하는 구조체입니다
 type HourlyStats struct {
 	RPM struct {
 		Min     int     `json:"min"`
 		Max     int     `json:"max"`
 		Avg     float64 `json:"avg"`
+		EWMA    float64 `json:"ewma"` // 지수가중이동평균
 		Current int     `json:"current"`
 		Count   int     `json:"count"`
 		Sum     int     `json:"sum"`
@@ -28,6 +27,7 @@ type HourlyStats struct {
 		Max     int     `json:"max"`
 		Current int     `json:"current"`
 		Avg     float64 `json:"avg"`
+		EWMA    float64 `json:"ewma"` // 지수가중이동평균
 		Count   int     `json:"count"`
 		Sum     int     `json:"sum"`
 	} `json:"totalInstances"`
@@ -40,24 +40,37 @@ type HourlyStats struct {
 	EndTime   time.Time `json:"endTime"`
 }
 
-// AlertStateManager는 알림 상태를 관리합니다
+// AlertStateManager는 계정별 알림 상태를 관리합니다
+// 계정마다 CollectMetrics가 따로 실행되므로 상태를 계정 키로 분리하여 서로 덮어쓰지 않도록 합니다
 type AlertStateManager struct {
-	state AlertState
-	mu    sync.Mutex
+	states  map[string]AlertState
+	reports map[string]string // 보고서 종류별 마지막 전송 기간 (예: "daily" -> "2025-01-02")
+	path    string            // 비어 있지 않으면 상태 변경 시 이 파일에 저장
+	mu      sync.Mutex
+
+	snoozedUntil time.Time    // 이 시각까지 모든 알림을 보내지 않음 (/snooze)
+	ignored      map[int]bool // 자동 재부팅하지 않는 Vast.ai 인스턴스 ID (/ignore)
 }
 
 var globalAlertState = &AlertStateManager{}
 
-func (m *AlertStateManager) getState() AlertState {
+func (m *AlertStateManager) getState(key string) AlertState {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	return m.state
+	return m.states[key]
 }
 
-func (m *AlertStateManager) setState(state AlertState) {
+func (m *AlertStateManager) setState(key string, state AlertState) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	m.state = state
+	if m.states == nil {
+		m.states = make(map[string]AlertState)
+	}
+	previous := m.states[key]
+	// 수집 중에 /alerts ack으로 추가된 확인 처리를 덮어쓰지 않도록 합칩니다
+	state.mergeAcks(previous.Acked)
+	m.states[key] = state
+	m.persist(previous, state)
 }
 
 type MetricsResponse struct {
@@ -87,6 +100,21 @@ type GenerationHistoryResponse struct {
 	} `json:"result"`
 }
 
+// TokenEarningsHistory는 tokenEarningsHistory 엔드포인트의 시간별 토큰 적립량입니다
+type TokenEarningsHistory struct {
+	Date  string  `json:"date"`
+	Value float64 `json:"value"`
+	Label string  `json:"label"`
+}
+
+type TokenEarningsHistoryResponse struct {
+	Result struct {
+		Data struct {
+			JSON []TokenEarningsHistory `json:"json"`
+		} `json:"data"`
+	} `json:"result"`
+}
+
 type VersionResponse struct {
 	Result struct {
 		Data struct {
@@ -119,6 +147,7 @@ type UserMetrics struct {
 	Efficiency             float64             `json:"efficiency"`
 	GenerationsHistory     []GenerationHistory `json:"generationsHistory"`
 	Workers                []Worker            `json:"workers"`
+	Teams                  []TeamMetrics       `json:"teams,omitempty"` // kuzco.teams를 설정한 경우 팀별 값
 }
 
 type DailyMetrics struct {
@@ -131,6 +160,7 @@ type DailyMetrics struct {
 }
 
 type InstanceMetrics struct {
+	ID              string `json:"id,omitempty"` // Kuzco 인스턴스 ID
 	Status          string `json:"status"`
 	Model           string `json:"model"`
 	Lane            string `json:"lane"`
@@ -138,11 +168,20 @@ type InstanceMetrics struct {
 	GPUModel        string `json:"gpuModel"`
 	Version         string `json:"version"`
 	VersionMismatch bool   `json:"versionMismatch"`
+	VersionStatus   string `json:"versionStatus,omitempty"` // 불일치 방향: VersionNewer 또는 VersionOlder
+
+	GPU *GPUReading `json:"gpu,omitempty"` // nvidia-smi 온도/사용률/전력 (없으면 nil)
+
+	// Kuzco가 IP로 판단한 인스턴스 위치
+	Country string `json:"country,omitempty"`
+	Region  string `json:"region,omitempty"`
+	City    string `json:"city,omitempty"`
 }
 
 type WorkerMinuteMetrics struct {
 	ID                 string            `json:"id"`
 	Name               string            `json:"name"`
+	TeamID             string            `json:"teamId,omitempty"`
 	InstanceCount      int               `json:"instanceCount"`
 	DailyCost          float64           `json:"dailyCost"`
 	TokensPerInstance  int64             `json:"tokensPerInstance"`
@@ -150,7 +189,38 @@ type WorkerMinuteMetrics struct {
 	TotalTokens        int64             `json:"totalTokens"`
 	GenerationsLast24H int               `json:"generationsLast24h"`
 	GenerationLastHour int               `json:"generationLastHour"`
+	Archived           bool              `json:"archived,omitempty"`
 	Instances          []InstanceMetrics `json:"instances"`
+
+	// TokensLastHour는 직전 1시간 동안 늘어난 누적 토큰 수입니다 (1시간치 수집 기록이 쌓이기 전에는 nil)
+	TokensLastHour *int64 `json:"tokensLastHour,omitempty"`
+}
+
+// NewWorkerMinuteMetrics는 Kuzco 워커 정보를 분 단위 메트릭스 형식(토큰 단위 보정 포함)으로 변환합니다
+func NewWorkerMinuteMetrics(w Worker) WorkerMinuteMetrics {
+	worker := WorkerMinuteMetrics{
+		ID:                 w.ID,
+		Name:               w.Name,
+		TeamID:             w.TeamID,
+		InstanceCount:      w.InstanceCount,
+		DailyCost:          w.DailyCost,
+		TokensPerInstance:  w.TokensPerInstance,
+		TokensLast24H:      w.TokensLast24H,
+		TotalTokens:        w.TotalTokens,
+		GenerationsLast24H: w.GenerationsLast24H,
+		Archived:           w.Archived,
+		Instances:          make([]InstanceMetrics, 0, len(w.Instances)),
+	}
+
+	// 인스턴스 정보 추가
+	for _, inst := range w.Instances {
+		worker.Instances = append(worker.Instances, InstanceMetrics(inst))
+	}
+
+	if len(w.GenerationsHistory) > 0 {
+		worker.GenerationLastHour = w.GenerationsHistory[0].Value
+	}
+	return worker
 }
 
 type MinuteMetrics struct {
@@ -165,6 +235,7 @@ type MinuteMetrics struct {
 	User struct {
 		TokensLast24Hours      int64                 `json:"tokensLast24Hours"`
 		TokensAllTime          int64                 `json:"tokensAllTime"`
+		PointsLast24Hours      float64               `json:"pointsLast24Hours"` // TokensLast24Hours / reporting.pointDivisor
 		GenerationsLast24Hours int                   `json:"generationsLast24Hours"`
 		TotalInstances         int                   `json:"totalInstances"`       // Vast.ai API의 instances_found
 		ActualTotalInstances   int                   `json:"actualTotalInstances"` // 기존 Kuzco의 totalInstances
@@ -175,8 +246,12 @@ type MinuteMetrics struct {
 		TokensPerInstance      int64                 `json:"tokensPerInstance"`
 		Share                  float64               `json:"share"`
 		GenerationLastHour     int                   `json:"generationLastHour"`
-		VastaiCredit           *VastaiCredit         `json:"vastaiCredit,omitempty"` // Vast.ai credit 정보
+		GenerationsPerInstance float64               `json:"generationsPerInstance"`    // 인스턴스당 시간당 생성량 (GenerationLastHour / TotalInstances)
+		VastaiCredit           *VastaiCredit         `json:"vastaiCredit,omitempty"`    // Vast.ai credit 정보
+		VastaiInstances        []VastaiInstance      `json:"vastaiInstances,omitempty"` // 인스턴스 수 불일치 시에만 조회
+		VastaiHourlyBurn       float64               `json:"vastaiHourlyBurn"`          // 현재 인스턴스 dph_total 합계 ($/시간)
 		Workers                []WorkerMinuteMetrics `json:"workers"`
+		Teams                  []TeamMetrics         `json:"teams,omitempty"` // kuzco.teams를 설정한 경우 팀별 값
 	} `json:"user"`
 	Timestamp  string     `json:"timestamp"`
 	AlertState AlertState `json:"alertState"` // 알림 상태 추가
@@ -185,6 +260,25 @@ type MinuteMetrics struct {
 type Metrics struct {
 	General GeneralMetrics `json:"general"`
 	User    UserMetrics    `json:"user"`
+	Missing MissingFields  `json:"-"` // 부분 수집에서 가져오지 못한 값
+}
+
+// MissingFields는 GetAllMetrics의 부분 결과에서 조회에 실패하여 0으로 남은 값입니다 (false인 값은 정상 조회됨)
+// 0 값을 실제 값으로 오인하여 알림, 워커 변경, 통계가 잘못 기록되지 않도록 사용하는 쪽에서 확인합니다
+type MissingFields struct {
+	CLIVersion         bool // General.CLIVersion
+	RunningInstances   bool // General.RunningInstanceCount
+	RPM                bool // General.RPM
+	GeneralTokens      bool // General 토큰 (24시간, 전체)
+	GeneralGenerations bool // General 생성량 (24시간, 기록)
+	UserTokens         bool // User 토큰 (팀 중 하나라도 실패하면 합계가 틀리므로 true)
+	UserGenerations    bool // User 생성량 (24시간, 기록)
+	Workers            bool // User.Workers와 워커에서 계산한 인스턴스 수, 비용, 효율
+}
+
+// Any는 하나라도 조회하지 못한 값이 있는지 반환합니다
+func (f MissingFields) Any() bool {
+	return f != MissingFields{}
 }
 
 type TokenHistory struct {
@@ -203,11 +297,26 @@ type TokenHistoryResponse struct {
 
 // AlertState는 각 알림의 상태를 관리하는 구조체입니다
 type AlertState struct {
-	VersionMismatchAlerted bool      `json:"versionMismatchAlerted"` // 버전 불일치 알림 여부
+	VersionMismatchAlerted bool      `json:"versionMismatchAlerted"` // 구버전 인스턴스 알림 여부
+	VersionNewerAlerted    bool      `json:"versionNewerAlerted"`    // 신버전 인스턴스 알림 여부
 	InstanceCountAlerted   bool      `json:"instanceCountAlerted"`   // 인스턴스 수 알림 여부
 	CreditAlerted          bool      `json:"creditAlerted"`          // credit 알림 여부
 	LastAlertTime          time.Time `json:"lastAlertTime"`          // 마지막 알림 시간
 	InstanceMismatchStart  time.Time `json:"instanceMismatchStart"`  // 인스턴스 불일치 시작 시간
+	TokenDropAlerted       bool      `json:"tokenDropAlerted"`       // 토큰 급감 알림 여부
+	TokenDropBaseline      int64     `json:"tokenDropBaseline"`      // 급감 감지 시점의 비교 기준 토큰 수
+	GPUHealthAlerted       bool      `json:"gpuHealthAlerted"`       // GPU 과열/유휴 알림 여부
+	RPMRatioAlerted        bool      `json:"rpmRatioAlerted"`        // 인스턴스당 RPM 이상 알림 여부
+
+	// 알림별 시작 시각 (인스턴스 수 알림은 InstanceMismatchStart 사용)
+	VersionMismatchSince time.Time `json:"versionMismatchSince"`
+	VersionNewerSince    time.Time `json:"versionNewerSince"`
+	CreditSince          time.Time `json:"creditSince"`
+	TokenDropSince       time.Time `json:"tokenDropSince"`
+	GPUHealthSince       time.Time `json:"gpuHealthSince"`
+	RPMRatioSince        time.Time `json:"rpmRatioSince"`
+
+	Acked AlertAcks `json:"acked"` // /alerts ack으로 확인 처리된 알림
 }
 
 // AlertConfig는 알림 설정을 관리하는 구조체입니다
@@ -215,11 +324,73 @@ type AlertConfig struct {
 	MinInstanceCount int     `json:"minInstanceCount" yaml:"minInstanceCount"` // 최소 인스턴스 수
 	MinCredit        float64 `json:"minCredit" yaml:"minCredit"`               // 최소 credit 잔액
 	Enabled          bool    `json:"enabled" yaml:"enabled"`                   // 알림 활성화 여부
+
+	TokenDropPercent       float64 `json:"tokenDropPercent" yaml:"tokenDropPercent"`             // 토큰 급감 알림 기준 하락률(%), 기본값 50
+	TokenDropWindowMinutes int     `json:"tokenDropWindowMinutes" yaml:"tokenDropWindowMinutes"` // 토큰 급감 비교 기간(분), 기본값 15
+
+	GPUTempThreshold   int `json:"gpuTempThreshold" yaml:"gpuTempThreshold"`     // GPU 과열 알림 온도(°C), 기본값 85
+	GPUIdleUtilPercent int `json:"gpuIdleUtilPercent" yaml:"gpuIdleUtilPercent"` // Running 인스턴스를 유휴로 판단하는 GPU 사용률(%) 상한, 기본값 1
+
+	StuckInitializingMinutes int  `json:"stuckInitializingMinutes" yaml:"stuckInitializingMinutes"` // Initializing 상태가 이 시간(분)을 넘으면 알림, 기본값 20
+	AutoRebootStuck          bool `json:"autoRebootStuck" yaml:"autoRebootStuck"`                   // Initializing에 멈춘 인스턴스를 Vast.ai에서 자동 재부팅
+
+	NotifyWorkerChanges      bool `json:"notifyWorkerChanges" yaml:"notifyWorkerChanges"`           // 워커/인스턴스 변경을 Status 스레드로 알림
+	WorkerChangeDedupMinutes int  `json:"workerChangeDedupMinutes" yaml:"workerChangeDedupMinutes"` // 같은 변경을 다시 알리지 않는 시간(분), 기본값 10
+	FlapTransitions          int  `json:"flapTransitions" yaml:"flapTransitions"`                   // flapWindowMinutes 안에 이 횟수 이상 바뀌면 flapping 알림, 기본값 4
+	FlapWindowMinutes        int  `json:"flapWindowMinutes" yaml:"flapWindowMinutes"`               // flapping 판단 기간(분), 기본값 30
+
+	// 버킷보다 새 버전을 일부러 실행하는 경우 신버전 알림 제외 (구버전 알림은 그대로)
+	IgnoreNewerVersions bool     `json:"ignoreNewerVersions" yaml:"ignoreNewerVersions"` // 모든 신버전 인스턴스 제외
+	KnownAheadVersions  []string `json:"knownAheadVersions" yaml:"knownAheadVersions"`   // 제외할 신버전 목록 (예: "0.2.4" 또는 "0.2.4-fe4d73f")
+
+	RPMRatioBandPercent float64 `json:"rpmRatioBandPercent" yaml:"rpmRatioBandPercent"` // 인스턴스당 RPM이 지난 60분 평균에서 이 비율(%) 이상 벗어나면 알림, 0이면 사용 안 함
+}
+
+// newerVersionSuppressed는 버킷보다 새 버전인 instanceVersion을 신버전 알림에서 제외할지 반환합니다
+// instanceVersion은 "0.2.4-fe4d73f (newer (...))"처럼 설명이 붙을 수 있으며, 목록의 버전은 빌드 접미사 없이도 일치합니다
+func (c AlertConfig) newerVersionSuppressed(instanceVersion string) bool {
+	if c.IgnoreNewerVersions {
+		return true
+	}
+	fields := strings.Fields(instanceVersion)
+	if len(fields) == 0 {
+		return false
+	}
+	version := fields[0]
+	for _, known := range c.KnownAheadVersions {
+		if known == version || known == strings.Split(version, "-")[0] {
+			return true
+		}
+	}
+	return false
 }
 
+// 토큰 급감 알림 기본값
+const (
+	DefaultTokenDropPercent       = 50.0
+	DefaultTokenDropWindowMinutes = 15
+)
+
+// TokenDropThreshold는 설정된 하락률과 비교 기간을 반환하며, 설정되지 않은 값은 기본값을 사용합니다
+func (c AlertConfig) TokenDropThreshold() (percent float64, window time.Duration) {
+	percent = c.TokenDropPercent
+	if percent <= 0 || percent > 100 {
+		percent = DefaultTokenDropPercent
+	}
+	minutes := c.TokenDropWindowMinutes
+	if minutes <= 0 {
+		minutes = DefaultTokenDropWindowMinutes
+	}
+	return percent, time.Duration(minutes) * time.Minute
+}
+
+// DefaultEWMAAlpha는 시간별 통계의 지수가중이동평균 기본 가중치입니다
+const DefaultEWMAAlpha = 0.3
+
 // HourlyStatsManager는 시간별 통계를 관리합니다
 type HourlyStatsManager struct {
 	stats []MinuteStats
+	alpha float64 // EWMA 가중치 (0 < alpha <= 1)
 	mutex sync.Mutex
 }
 
@@ -232,8 +403,49 @@ type MinuteStats struct {
 	Timestamp      time.Time `json:"timestamp"`
 }
 
-var GlobalHourlyStats = &HourlyStatsManager{
-	stats: make([]MinuteStats, 0, 60), // 60분 동안의 데이터를 저장
+// 계정마다 사용자 생성량이 다르므로 시간별 통계는 계정 이름별로 보관합니다
+var (
+	hourlyStats     = make(map[string]*HourlyStatsManager)
+	hourlyAlpha     = DefaultEWMAAlpha
+	hourlyStatsLock sync.Mutex
+)
+
+// HourlyStatsFor returns the hourly stats of an account, creating them on first use
+func HourlyStatsFor(account string) *HourlyStatsManager {
+	hourlyStatsLock.Lock()
+	defer hourlyStatsLock.Unlock()
+	m, ok := hourlyStats[account]
+	if !ok {
+		m = &HourlyStatsManager{
+			stats: make([]MinuteStats, 0, 60), // 60분 동안의 데이터를 저장
+			alpha: hourlyAlpha,
+		}
+		hourlyStats[account] = m
+	}
+	return m
+}
+
+// SetHourlyEWMAAlpha sets the EWMA weight of every account's hourly stats. Out-of-range values are ignored
+func SetHourlyEWMAAlpha(alpha float64) {
+	if alpha <= 0 || alpha > 1 {
+		return
+	}
+	hourlyStatsLock.Lock()
+	defer hourlyStatsLock.Unlock()
+	hourlyAlpha = alpha
+	for _, m := range hourlyStats {
+		m.SetEWMAAlpha(alpha)
+	}
+}
+
+// SetEWMAAlpha는 EWMA 가중치를 설정합니다. 범위를 벗어난 값은 무시됩니다
+func (m *HourlyStatsManager) SetEWMAAlpha(alpha float64) {
+	if alpha <= 0 || alpha > 1 {
+		return
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.alpha = alpha
 }
 
 // GetStats는 지난 60분 동안의 통계를 반환합니다
@@ -252,11 +464,17 @@ func (m *HourlyStatsManager) GetStats() HourlyStats {
 	result.StartTime = m.stats[0].Timestamp
 	result.EndTime = m.stats[len(m.stats)-1].Timestamp
 
+	alpha := m.alpha
+	if alpha <= 0 || alpha > 1 {
+		alpha = DefaultEWMAAlpha
+	}
+
 	// RPM 통계 계산
 	for i, stat := range m.stats {
 		if i == 0 {
 			result.RPM.Min = stat.RPM
 			result.RPM.Max = stat.RPM
+			result.RPM.EWMA = float64(stat.RPM)
 
 		} else {
 			if stat.RPM < result.RPM.Min {
@@ -265,6 +483,7 @@ func (m *HourlyStatsManager) GetStats() HourlyStats {
 			if stat.RPM > result.RPM.Max {
 				result.RPM.Max = stat.RPM
 			}
+			result.RPM.EWMA = alpha*float64(stat.RPM) + (1-alpha)*result.RPM.EWMA
 		}
 		result.RPM.Current = stat.RPM
 		result.RPM.Sum += stat.RPM
@@ -280,6 +499,7 @@ func (m *HourlyStatsManager) GetStats() HourlyStats {
 		if i == 0 {
 			result.TotalInstances.Min = stat.TotalInstances
 			result.TotalInstances.Max = stat.TotalInstances
+			result.TotalInstances.EWMA = float64(stat.TotalInstances)
 
 		} else {
 			if stat.TotalInstances < result.TotalInstances.Min {
@@ -288,6 +508,7 @@ func (m *HourlyStatsManager) GetStats() HourlyStats {
 			if stat.TotalInstances > result.TotalInstances.Max {
 				result.TotalInstances.Max = stat.TotalInstances
 			}
+			result.TotalInstances.EWMA = alpha*float64(stat.TotalInstances) + (1-alpha)*result.TotalInstances.EWMA
 		}
 		result.TotalInstances.Sum += stat.TotalInstances
 		result.TotalInstances.Count++
@@ -343,7 +564,9 @@ func (m *HourlyStatsManager) GetAndResetStats() HourlyStats {
 }
 
 func (m *Client) collectDailyMetrics(userID string, vastaiToken string, includeVastaiCost bool, sendAlert func(string, string) error, ch chan<- DailyMetrics) error {
+	// 일일 보고서는 일부 값이 빠지면 비중/효율이 왜곡되므로 전체 수집에 성공한 경우에만 전송
 	kuzcoClient := NewKuzcoClient(m)
+	kuzcoClient.StrictMode = true
 	metrics, err := kuzcoClient.GetAllMetrics(userID)
 	if err != nil {
 		return err
@@ -356,7 +579,7 @@ func (m *Client) collectDailyMetrics(userID string, vastaiToken string, includeV
 	isVastaiEnabled := vastaiToken != ""
 
 	if isVastaiEnabled && includeVastaiCost {
-		vastaiClient := NewVastaiClient(vastaiToken)
+		vastaiClient := m.newVastaiClient(vastaiToken)
 		vastaiCost, err = vastaiClient.GetDailyCost()
 		if err != nil {
 			log.Printf("Failed to get vastai cost: %v", err)
@@ -388,33 +611,34 @@ func (m *Client) collectDailyMetrics(userID string, vastaiToken string, includeV
 		kuzcoEfficiency = metrics.User.TotalDailyCost / (metrics.User.Share * 100)
 	}
 
-	// 현재 KST 시간 가져오기
-	kst := time.Now().In(time.FixedZone("KST", 9*60*60))
-	dateStr := kst.Format("2006-01-02")
-
-	// 포인트 값 먼저 1000으로 나누기 (소수점 조정)
-	myPoints := float64(metrics.User.TokensLast24Hours) / tokenUnit
-	totalPoints := float64(metrics.General.TokensLast24Hours) / tokenUnit
+	// 보고서 타임존 기준 날짜
+	dateStr := ReportTime(time.Now()).Format("2006-01-02")
 
-	// 적절한 단위 결정 (K, M, B)
-	myPointsFormatted := formatNumber(myPoints)
-	totalPointsFormatted := formatNumber(totalPoints)
+	// 포인트 환산 및 단위 결정 (K, M, B)
+	myPointsFormatted := FormatPoints(metrics.User.TokensLast24Hours)
+	totalPointsFormatted := FormatPoints(metrics.General.TokensLast24Hours)
 
 	// 텔레그램 메시지 작성
-	message := fmt.Sprintf("%s\n\n포인트 : %s | %s\n비중 : %.1f%%\n비용(vast,kuzco) : $%.2f | $%.2f\n1%% 효율(vast,kuzco) : $%d | $%d",
+	message := fmt.Sprintf("%s\n\n포인트 : %s | %s\n비중 : %.1f%%\n비용(vast,kuzco) : $%.2f | $%.2f\n1%% 효율(vast,kuzco) : $%s | $%s",
 		dateStr,
 		myPointsFormatted,
 		totalPointsFormatted,
 		metrics.User.Share*100,
 		vastaiCost,
 		metrics.User.TotalDailyCost,
-		int(vastaiEfficiency),
-		int(kuzcoEfficiency))
+		FormatEfficiency(vastaiEfficiency),
+		FormatEfficiency(kuzcoEfficiency))
 
 	// Vastai credit 정보가 있는 경우 추가
 	if vastaiCredit != nil {
 		message += fmt.Sprintf("\n잔액 : $%.2f", vastaiCredit.Credit)
 	}
+	message += "\n(" + PointsNote() + ")"
+
+	// 워커별 1% 효율 추가
+	if workerLines := formatWorkerEfficiency(metrics.User.Workers, metrics.General.TokensLast24Hours); workerLines != "" {
+		message += "\n\n워커별 1% 효율\n" + CodeBlock(workerLines)
+	}
 
 	// 텔레그램으로 메시지 전송
 	if err := sendAlert(message, "daily"); err != nil {
@@ -432,6 +656,48 @@ func (m *Client) collectDailyMetrics(userID string, vastaiToken string, includeV
 	return nil
 }
 
+// formatWorkerEfficiency는 워커별 일일 비용(GPU 가격 기준), 비중, 1% 비중당 비용을 포맷합니다
+// 1% 비중당 비용이 낮은 워커부터 정렬하며, 토큰이 없는 워커는 마지막에 N/A로 표시합니다
+func formatWorkerEfficiency(workers []Worker, generalTokens int64) string {
+	type workerEfficiency struct {
+		Name       string
+		Share      float64
+		DailyCost  float64
+		Efficiency float64 // 1% 비중당 비용, 비중이 0이면 -1
+	}
+
+	if len(workers) == 0 || generalTokens <= 0 {
+		return ""
+	}
+
+	rows := make([]workerEfficiency, 0, len(workers))
+	for _, w := range workers {
+		share := float64(w.TokensLast24H) / float64(generalTokens)
+		efficiency := -1.0
+		if share > 0 {
+			efficiency = w.DailyCost / (share * 100)
+		}
+		rows = append(rows, workerEfficiency{Name: w.Name, Share: share, DailyCost: w.DailyCost, Efficiency: efficiency})
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		if (rows[i].Efficiency < 0) != (rows[j].Efficiency < 0) {
+			return rows[j].Efficiency < 0
+		}
+		return rows[i].Efficiency < rows[j].Efficiency
+	})
+
+	lines := make([]string, 0, len(rows))
+	for _, r := range rows {
+		efficiency := "N/A"
+		if r.Efficiency >= 0 {
+			efficiency = fmt.Sprintf("$%s per 1%%", FormatEfficiency(r.Efficiency))
+		}
+		lines = append(lines, fmt.Sprintf("%s | %.3f%% | $%.2f | %s", r.Name, r.Share*100, r.DailyCost, efficiency))
+	}
+	return strings.Join(lines, "\n")
+}
+
 // CollectMetrics collects metrics periodically
 func (c *Client) CollectMetrics(
 	userID string,
@@ -456,7 +722,7 @@ func (c *Client) CollectMetrics(
 		dailyInterval = time.Minute  // 개발 환경: 1분
 		minuteInterval = time.Minute // 개발 환경: 1분
 	} else {
-		// 프로덕션 환경: 기존 로직
+		// 프로덕션 환경: Vast.ai 일일 비용이 UTC 날짜 기준이므로 UTC 자정에 일일 보고서를 전송
 		now := time.Now().UTC()
 		nextMidnight := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
 		dailyInterval = nextMidnight.Sub(now)
@@ -470,12 +736,12 @@ func (c *Client) CollectMetrics(
 	defer minuteTicker.Stop()
 
 	// 초기 메트릭스 수집
-	if err := c.collectMinuteMetrics(userID, vastaiToken, includeVastaiCost, alertConfig, sendAlert, minuteChan); err != nil {
-		log.Printf("Failed to collect minute metrics: %v", err)
-	}
+	c.collectMinuteMetricsGuarded(userID, vastaiToken, includeVastaiCost, c.currentAlertConfig(alertConfig), sendAlert, minuteChan)
 	if isDev {
 		// 개발 환경에서는 즉시 일일 메트릭스도 수집
-		if err := c.collectDailyMetrics(userID, vastaiToken, includeVastaiCost, sendAlert, dailyChan); err != nil {
+		if err := c.withReauth(func() error {
+			return c.collectDailyMetrics(userID, vastaiToken, includeVastaiCost, sendAlert, dailyChan)
+		}); err != nil {
 			log.Printf("Failed to collect daily metrics: %v", err)
 		}
 	}
@@ -483,7 +749,9 @@ func (c *Client) CollectMetrics(
 	for {
 		select {
 		case <-dailyTimer.C:
-			if err := c.collectDailyMetrics(userID, vastaiToken, includeVastaiCost, sendAlert, dailyChan); err != nil {
+			if err := c.withReauth(func() error {
+				return c.collectDailyMetrics(userID, vastaiToken, includeVastaiCost, sendAlert, dailyChan)
+			}); err != nil {
 				log.Printf("Failed to collect daily metrics: %v", err)
 			}
 			// 타이머 재설정
@@ -493,22 +761,76 @@ func (c *Client) CollectMetrics(
 				dailyTimer.Reset(24 * time.Hour)
 			}
 
-		case <-minuteTicker.C:
-			if err := c.collectMinuteMetrics(userID, vastaiToken, includeVastaiCost, alertConfig, sendAlert, minuteChan); err != nil {
-				log.Printf("Failed to collect minute metrics: %v", err)
+		case <-c.dailyTrigger:
+			// 요청된 일일 보고서는 정기 보고서 타이머에 영향을 주지 않음
+			if err := c.withReauth(func() error {
+				return c.collectDailyMetrics(userID, vastaiToken, includeVastaiCost, sendAlert, dailyChan)
+			}); err != nil {
+				log.Printf("Failed to collect requested daily metrics: %v", err)
 			}
 
+		case <-minuteTicker.C:
+			c.collectMinuteMetricsGuarded(userID, vastaiToken, includeVastaiCost, c.currentAlertConfig(alertConfig), sendAlert, minuteChan)
+
 		case <-stop:
 			return
 		}
 	}
 }
 
+// collectMinuteMetricsGuarded는 회로 차단기를 거쳐 분 단위 메트릭스를 수집합니다
+// 연속 실패로 회로가 열리면 degraded 알림을 한 번 보내고, 이후 시도가 성공하면 복구 알림을 한 번 보냅니다
+func (c *Client) collectMinuteMetricsGuarded(userID string, vastaiToken string, includeVastaiCost bool, alertConfig AlertConfig, sendAlert func(string, string) error, ch chan<- MinuteMetrics) {
+	now := time.Now()
+	if !c.circuit.Allow(now) {
+		return
+	}
+
+	err := c.withReauth(func() error {
+		return c.collectMinuteMetrics(userID, vastaiToken, includeVastaiCost, alertConfig, sendAlert, ch)
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrAuth):
+			log.Printf("[ERROR] Kuzco authentication failed for %s even after re-login: %v", c.accountName, err)
+		case IsTransient(err):
+			log.Printf("Transient error collecting minute metrics for %s, retrying: %v", c.accountName, err)
+		default:
+			log.Printf("Failed to collect minute metrics: %v", err)
+		}
+		if c.circuit.RecordFailure(now) {
+			log.Printf("Metrics collection for %s entering degraded mode after %d consecutive failures", c.accountName, c.circuit.Failures())
+			title := "⚠️ Metrics Collection Degraded"
+			msg := fmt.Sprintf("Account: %s\nConsecutive failures: %d\nRetrying every %s until a request succeeds\nLast error: %v",
+				c.accountName, c.circuit.Failures(), c.circuit.OpenInterval(), err)
+			if err := sendAlert(fmt.Sprintf("%s\n%s", title, CodeBlock(msg)), "error"); err != nil {
+				log.Printf("Failed to send degraded mode alert: %v", err)
+			}
+		}
+		return
+	}
+
+	if failures := c.circuit.Failures(); c.circuit.RecordSuccess() {
+		log.Printf("Metrics collection for %s recovered", c.accountName)
+		title := "✅ Metrics Collection Recovered"
+		msg := fmt.Sprintf("Account: %s\nFailed attempts before recovery: %d", c.accountName, failures)
+		if err := sendAlert(fmt.Sprintf("%s\n%s", title, CodeBlock(msg)), "error"); err != nil {
+			log.Printf("Failed to send recovery alert: %v", err)
+		}
+	}
+}
+
 func (m *Client) collectMinuteMetrics(userID string, vastaiToken string, includeVastaiCost bool, alertConfig AlertConfig, sendAlert func(string, string) error, ch chan<- MinuteMetrics) error {
 	kuzcoClient := NewKuzcoClient(m)
 	metrics, err := kuzcoClient.GetAllMetrics(userID)
 	if err != nil {
-		return err
+		CollectionHealthFor(m.accountName).RecordError(err)
+		// 인증 실패는 모든 값이 0으로 수집되므로 부분 수집으로 진행하지 않습니다
+		if metrics == nil || errors.Is(err, ErrAuth) {
+			return err
+		}
+		// 일부 값만 실패한 경우 수집된 값으로 계속 진행
+		log.Printf("Partial minute metrics collected: %v", err)
 	}
 
 	mm := MinuteMetrics{
@@ -518,7 +840,7 @@ func (m *Client) collectMinuteMetrics(userID string, vastaiToken string, include
 	// General metrics
 	mm.General.TotalInstances = metrics.General.RunningInstanceCount
 	mm.General.RPM = metrics.General.RPM
-	mm.General.TokensLast24Hours = metrics.General.TokensLast24Hours / int64(tokenUnit)
+	mm.General.TokensLast24Hours = metrics.General.TokensLast24Hours
 	mm.General.GenerationsLast24Hours = metrics.General.GenerationsLast24Hours
 	mm.General.CLIVersion = metrics.General.CLIVersion
 	if len(metrics.General.GenerationsHistory) > 0 {
@@ -526,14 +848,16 @@ func (m *Client) collectMinuteMetrics(userID string, vastaiToken string, include
 	}
 
 	// User metrics
-	mm.User.TokensLast24Hours = metrics.User.TokensLast24Hours / int64(tokenUnit)
-	mm.User.TokensAllTime = metrics.User.TokensAllTime / int64(tokenUnit)
+	mm.User.TokensLast24Hours = metrics.User.TokensLast24Hours
+	mm.User.TokensAllTime = metrics.User.TokensAllTime
+	mm.User.PointsLast24Hours = Points(metrics.User.TokensLast24Hours)
 	mm.User.GenerationsLast24Hours = metrics.User.GenerationsLast24Hours
 	mm.User.ActualTotalInstances = metrics.User.TotalInstances // 기존 Kuzco의 totalInstances 저장
+	mm.User.Teams = metrics.User.Teams
 
 	// Vast.ai API에서 인스턴스 수와 credit 정보 가져오기
 	if vastaiToken != "" {
-		vastaiClient := NewVastaiClient(vastaiToken)
+		vastaiClient := m.newVastaiClient(vastaiToken)
 
 		// Get instance count
 		vastaiInstances, err := vastaiClient.GetInstanceCount()
@@ -545,6 +869,19 @@ func (m *Client) collectMinuteMetrics(userID string, vastaiToken string, include
 			mm.User.InstancesMismatch = mm.User.TotalInstances != mm.User.ActualTotalInstances
 		}
 
+		// 현재 시간당 비용 계산과 불일치 원인 파악을 위해 인스턴스 목록(IP, dph_total) 조회
+		if includeVastaiCost || mm.User.InstancesMismatch {
+			instances, err := vastaiClient.GetInstances()
+			if err != nil {
+				log.Printf("Failed to get vastai instances: %v", err)
+			} else {
+				mm.User.VastaiHourlyBurn = HourlyBurnRate(instances)
+				if mm.User.InstancesMismatch {
+					mm.User.VastaiInstances = instances
+				}
+			}
+		}
+
 		// Get credit information
 		if includeVastaiCost {
 			credit, err := vastaiClient.GetCredit()
@@ -563,7 +900,7 @@ func (m *Client) collectMinuteMetrics(userID string, vastaiToken string, include
 
 	// Get Vast.ai cost if enabled
 	if vastaiToken != "" && includeVastaiCost {
-		vastaiClient := NewVastaiClient(vastaiToken)
+		vastaiClient := m.newVastaiClient(vastaiToken)
 		vastaiCost, err := vastaiClient.GetDailyCost()
 		if err != nil {
 			log.Printf("Failed to get vastai cost: %v", err)
@@ -584,105 +921,180 @@ func (m *Client) collectMinuteMetrics(userID string, vastaiToken string, include
 	if len(metrics.User.GenerationsHistory) > 0 {
 		mm.User.GenerationLastHour = metrics.User.GenerationsHistory[0].Value
 	}
+	mm.User.GenerationsPerInstance = generationsPerInstance(mm.User.GenerationLastHour, mm.User.TotalInstances)
 
 	// Worker metrics
+	missing := metrics.Missing
 	mm.User.Workers = make([]WorkerMinuteMetrics, 0, len(metrics.User.Workers))
 	for _, w := range metrics.User.Workers {
-		worker := WorkerMinuteMetrics{
-			ID:                 w.ID,
-			Name:               w.Name,
-			InstanceCount:      w.InstanceCount,
-			DailyCost:          w.DailyCost,
-			TokensPerInstance:  w.TokensPerInstance / int64(tokenUnit),
-			TokensLast24H:      w.TokensLast24H / int64(tokenUnit),
-			TotalTokens:        w.TotalTokens / int64(tokenUnit),
-			GenerationsLast24H: w.GenerationsLast24H,
-			Instances:          make([]InstanceMetrics, 0, len(w.Instances)),
-		}
+		mm.User.Workers = append(mm.User.Workers, NewWorkerMinuteMetrics(w))
+	}
+	// 워커 조회에 실패하면 모든 워커가 사라진 것처럼 보이므로 워커 기록과 비교 기준을 그대로 둠
+	if !missing.Workers {
+		m.recordWorkerTokenDeltas(mm.User.Workers, time.Now())
+	}
 
-		// 인스턴스 정보 추가
-		for _, inst := range w.Instances {
-			worker.Instances = append(worker.Instances, InstanceMetrics(inst))
+	// 워커 변경 이벤트 기록 (첫 수집은 비교 기준으로만 사용)
+	if missing.Workers {
+		log.Printf("Skipping worker change detection for %s: workers failed to load", m.accountName)
+	} else if m.previousWorkers != nil {
+		events := DetectWorkerChanges(m.previousWorkers, mm.User.Workers, time.Now())
+		for i := range events {
+			events[i].Account = m.accountName
 		}
-
-		if len(w.GenerationsHistory) > 0 {
-			worker.GenerationLastHour = w.GenerationsHistory[0].Value
+		GlobalWorkerEvents.Add(events...)
+		if err := m.notifyWorkerChanges(events, missing, alertConfig, sendAlert); err != nil {
+			log.Printf("Failed to notify worker changes: %v", err)
 		}
-		mm.User.Workers = append(mm.User.Workers, worker)
+	}
+	if !missing.Workers {
+		m.previousWorkers = mm.User.Workers
 	}
 
 	// 알림 상태 가져오기
-	mm.AlertState = globalAlertState.getState()
+	alertKey := m.alertStateKey(userID)
+	mm.AlertState = globalAlertState.getState(alertKey)
 
 	// Check alerts with provided configuration
-	if err := m.checkAlerts(&mm, alertConfig, sendAlert); err != nil {
+	if err := m.checkAlerts(&mm, alertConfig, missing, sendAlert); err != nil {
 		log.Printf("Failed to check alerts: %v", err)
 	}
+	// 재부팅에 Vast.ai 토큰이 필요하므로 checkAlerts와 따로 실행
+	if !missing.Workers {
+		if err := m.checkStuckInitializing(&mm, alertConfig, vastaiToken, !m.alertOnly, sendAlert); err != nil {
+			log.Printf("Failed to check stuck instances: %v", err)
+		}
+	}
+	mm.AlertState.trackActive(time.Now())
 
 	// 알림 상태 업데이트
-	globalAlertState.setState(mm.AlertState)
+	globalAlertState.setState(alertKey, mm.AlertState)
+
+	// 시간별 통계 업데이트 (부분 수집의 0 값이 통계에 섞이지 않도록 전체 수집에 성공한 경우만)
+	if missing.Any() {
+		log.Printf("Skipping stats update for %s: partial metrics", m.accountName)
+	} else {
+		HourlyStatsFor(m.accountName).UpdateStats(mm)
+		DailyStatsFor(m.accountName).UpdateStats(mm)
+		GlobalWeeklyStats.UpdateStats(m.accountName, mm)
+	}
 
-	// 시간별 통계 업데이트
-	GlobalHourlyStats.UpdateStats(mm)
+	// 수집 상태 기록
+	CollectionHealthFor(m.accountName).RecordSuccess()
 
 	ch <- mm
 	return nil
 }
 
-// checkAlerts는 모든 알림을 체크하고 관리합니다
-func (m *Client) checkAlerts(mm *MinuteMetrics, config AlertConfig, sendAlert func(string, string) error) error {
-	if err := m.checkVersionMismatch(mm, config, sendAlert); err != nil {
-		return fmt.Errorf("version mismatch check failed: %w", err)
+// generationsPerInstance는 인스턴스당 시간당 생성량을 계산하며, 인스턴스가 없으면 0을 반환합니다
+func generationsPerInstance(generationLastHour, instances int) float64 {
+	if instances <= 0 {
+		return 0
 	}
+	return float64(generationLastHour) / float64(instances)
+}
 
-	if err := m.checkInstanceCount(mm, config, sendAlert); err != nil {
-		return fmt.Errorf("instance count check failed: %w", err)
+// checkAlerts는 모든 알림을 체크하고 관리합니다
+// 부분 수집에서 조회하지 못한 값(missing)에 의존하는 알림은 0 값으로 잘못 발생하거나 해소되지 않도록 건너뜁니다
+// 한 알림의 전송이 실패해도 나머지 체크는 계속 실행하며, 실패한 체크의 오류를 모두 모아 반환합니다
+func (m *Client) checkAlerts(mm *MinuteMetrics, config AlertConfig, missing MissingFields, sendAlert func(string, string) error) error {
+	checks := []struct {
+		name  string
+		skip  bool
+		check func() error
+	}{
+		{"version mismatch", missing.CLIVersion || missing.Workers, func() error { return m.checkVersionMismatch(mm, config, sendAlert) }},
+		{"instance count", missing.Workers, func() error { return m.checkInstanceCount(mm, config, sendAlert) }},
+		{"credit", missing.Workers, func() error { return m.checkCredit(mm, config, sendAlert) }},
+		{"token drop", missing.UserTokens, func() error { return m.checkTokenDrop(mm, config, sendAlert) }},
+		{"GPU health", missing.Workers, func() error { return m.checkGPUHealth(mm, config, sendAlert) }},
+		{"duplicate IP", missing.Workers, func() error { return m.checkDuplicateIPs(mm, config, sendAlert) }},
+		{"RPM ratio", missing.RPM || missing.RunningInstances, func() error { return m.checkRPMRatio(mm, config, HourlyStatsFor(m.accountName), sendAlert) }},
 	}
-
-	if err := m.checkCredit(mm, config, sendAlert); err != nil {
-		return fmt.Errorf("credit check failed: %w", err)
+	var errs []error
+	for _, c := range checks {
+		if c.skip {
+			log.Printf("Skipping %s check for %s: required metrics failed to load", c.name, m.accountName)
+			continue
+		}
+		if err := c.check(); err != nil {
+			log.Printf("[ERROR] %s check failed for %s: %v", c.name, m.accountName, err)
+			errs = append(errs, fmt.Errorf("%s check failed: %w", c.name, err))
+		}
 	}
-
-	return nil
+	return errors.Join(errs...)
 }
 
 // checkVersionMismatch는 버전 불일치를 체크하고 알림을 보냅니다
+// 구버전 인스턴스는 업그레이드가 필요하므로 error, 신버전 인스턴스는 버킷보다 앞선 것일 수 있으므로 status로 알립니다
 func (m *Client) checkVersionMismatch(mm *MinuteMetrics, config AlertConfig, sendAlert func(string, string) error) error {
-	hasVersionMismatch := false
-	var mismatchedWorkers []string
-
-	for _, worker := range mm.User.Workers {
-		for _, instance := range worker.Instances {
-			if instance.VersionMismatch {
-				hasVersionMismatch = true
-				mismatchedWorkers = append(mismatchedWorkers, fmt.Sprintf("%s (IP: %s)", worker.Name, instance.IP))
-			}
-		}
-	}
+	older := versionMismatchWorkers(mm, VersionOlder, config)
+	newer := versionMismatchWorkers(mm, VersionNewer, config)
 
 	// 문제가 발생했고, 아직 알림을 보내지 않은 경우에만 알림 전송
-	if hasVersionMismatch && !mm.AlertState.VersionMismatchAlerted {
-		title := "⚠️ Version Mismatch Alert"
-		msg := fmt.Sprintf("The following workers have version mismatches:\n%s", strings.Join(mismatchedWorkers, "\n"))
+	if len(older) > 0 && !mm.AlertState.VersionMismatchAlerted {
+		title := "⚠️ Version Mismatch Alert (older)"
+		msg := fmt.Sprintf("Expected CLI version: %s\nThe following workers run an OLDER version and need an upgrade:\n%s",
+			mm.General.CLIVersion, strings.Join(older, "\n"))
 		message := fmt.Sprintf("%s\n%s", title, CodeBlock(msg))
 		if err := sendAlert(message, "error"); err != nil {
 			return err
 		}
 		mm.AlertState.VersionMismatchAlerted = true
-	} else if !hasVersionMismatch && mm.AlertState.VersionMismatchAlerted {
+	} else if len(older) == 0 && mm.AlertState.VersionMismatchAlerted {
 		// 문제가 해결되었고, 이전에 알림을 보냈던 경우에만 복구 알림 전송
-		title := "✅ Version Mismatch Resolved"
-		msg := fmt.Sprintf("All workers are now running the correct version.")
+		title := "✅ Version Mismatch Resolved (older)"
+		msg := fmt.Sprintf("No workers are running an older version anymore.")
 		message := fmt.Sprintf("%s\n%s", title, CodeBlock(msg))
-		if err := sendAlert(message, "error"); err != nil {
+		if err := mm.AlertState.sendUnlessAcked(AlertVersionOlder, sendAlert, message, "error"); err != nil {
 			return err
 		}
 		mm.AlertState.VersionMismatchAlerted = false
 	}
 
+	if len(newer) > 0 && !mm.AlertState.VersionNewerAlerted {
+		title := "ℹ️ Version Mismatch Notice (newer)"
+		msg := fmt.Sprintf("Expected CLI version: %s\nThe following workers run a NEWER version (the bucket may not be updated yet):\n%s",
+			mm.General.CLIVersion, strings.Join(newer, "\n"))
+		message := fmt.Sprintf("%s\n%s", title, CodeBlock(msg))
+		if err := sendAlert(message, "status"); err != nil {
+			return err
+		}
+		mm.AlertState.VersionNewerAlerted = true
+	} else if len(newer) == 0 && mm.AlertState.VersionNewerAlerted {
+		title := "✅ Version Mismatch Resolved (newer)"
+		msg := fmt.Sprintf("All workers are back on the expected version %s.", mm.General.CLIVersion)
+		message := fmt.Sprintf("%s\n%s", title, CodeBlock(msg))
+		if err := mm.AlertState.sendUnlessAcked(AlertVersionNewer, sendAlert, message, "status"); err != nil {
+			return err
+		}
+		mm.AlertState.VersionNewerAlerted = false
+	}
+
 	return nil
 }
 
+// versionMismatchWorkers는 지정한 방향(VersionOlder/VersionNewer)으로 버전이 다른 인스턴스를 워커별로 묶어 반환합니다
+// 신버전 중 알림에서 제외하도록 설정된 버전은 건너뜁니다
+func versionMismatchWorkers(mm *MinuteMetrics, status string, config AlertConfig) []string {
+	var workers []string
+	for _, worker := range mm.User.Workers {
+		var lines []string
+		for _, instance := range worker.Instances {
+			if instance.VersionMismatch && instance.VersionStatus == status {
+				if status == VersionNewer && config.newerVersionSuppressed(instance.Version) {
+					continue
+				}
+				lines = append(lines, fmt.Sprintf("  - IP: %s, Version: %s", instance.IP, instance.Version))
+			}
+		}
+		if len(lines) > 0 {
+			workers = append(workers, fmt.Sprintf("%s:\n%s", worker.Name, strings.Join(lines, "\n")))
+		}
+	}
+	return workers
+}
+
 // checkInstanceCount는 인스턴스 수가 최소 기준보다 낮은지 체크합니다
 func (m *Client) checkInstanceCount(mm *MinuteMetrics, config AlertConfig, sendAlert func(string, string) error) error {
 	if !config.Enabled {
@@ -705,6 +1117,9 @@ func (m *Client) checkInstanceCount(mm *MinuteMetrics, config AlertConfig, sendA
 		if time.Since(mm.AlertState.InstanceMismatchStart) >= 5*time.Minute && !mm.AlertState.InstanceCountAlerted {
 			title := "⚠️ Instance Count Mismatch Alert"
 			msg := fmt.Sprintf("Vast.ai instances: %d\nActual instances: %d", mm.User.TotalInstances, mm.User.ActualTotalInstances)
+			if details := instanceMismatchDetails(mm); details != "" {
+				msg += "\n\n" + details
+			}
 			message := fmt.Sprintf("%s\n%s", title, CodeBlock(msg))
 
 			if err := sendAlert(message, "status"); err != nil {
@@ -719,7 +1134,7 @@ func (m *Client) checkInstanceCount(mm *MinuteMetrics, config AlertConfig, sendA
 			msg := fmt.Sprintf("Vast.ai instances: %d\nActual instances: %d", mm.User.TotalInstances, mm.User.ActualTotalInstances)
 			message := fmt.Sprintf("%s\n%s", title, CodeBlock(msg))
 
-			if err := sendAlert(message, "status"); err != nil {
+			if err := mm.AlertState.sendUnlessAcked(AlertInstanceCount, sendAlert, message, "status"); err != nil {
 				return fmt.Errorf("failed to send instance count mismatch recovery alert: %w", err)
 			}
 			mm.AlertState.InstanceCountAlerted = false
@@ -731,6 +1146,158 @@ func (m *Client) checkInstanceCount(mm *MinuteMetrics, config AlertConfig, sendA
 	return nil
 }
 
+// instanceMismatchDetails는 IP 기준으로 Kuzco 인스턴스와 Vast.ai 인스턴스를 비교하여
+// 한쪽에만 존재하는 인스턴스 목록을 반환합니다
+func instanceMismatchDetails(mm *MinuteMetrics) string {
+	if len(mm.User.VastaiInstances) == 0 {
+		return ""
+	}
+
+	vastaiIPs := make(map[string]bool, len(mm.User.VastaiInstances))
+	for _, inst := range mm.User.VastaiInstances {
+		if inst.PublicIP != "" {
+			vastaiIPs[inst.PublicIP] = true
+		}
+	}
+
+	kuzcoIPs := make(map[string]bool)
+	var kuzcoOnly []string
+	for _, worker := range mm.User.Workers {
+		for _, inst := range worker.Instances {
+			if inst.IP == "" {
+				continue
+			}
+			kuzcoIPs[inst.IP] = true
+			if !vastaiIPs[inst.IP] {
+				kuzcoOnly = append(kuzcoOnly, fmt.Sprintf("  %s (%s, %s)", worker.Name, inst.IP, inst.Status))
+			}
+		}
+	}
+
+	var vastaiOnly []string
+	for _, inst := range mm.User.VastaiInstances {
+		if !kuzcoIPs[inst.PublicIP] {
+			vastaiOnly = append(vastaiOnly, fmt.Sprintf("  #%d (%s, %s)", inst.ID, inst.PublicIP, inst.ActualStatus))
+		}
+	}
+
+	var sections []string
+	if len(kuzcoOnly) > 0 {
+		sections = append(sections, "Kuzco only (no Vast.ai instance):\n"+strings.Join(kuzcoOnly, "\n"))
+	}
+	if len(vastaiOnly) > 0 {
+		sections = append(sections, "Vast.ai only (no Kuzco worker):\n"+strings.Join(vastaiOnly, "\n"))
+	}
+	return strings.Join(sections, "\n\n")
+}
+
+// recordTokenSample은 현재 24시간 토큰 수를 기록하고, 비교 기간 이전의 기준 값을 반환합니다
+// 기준 값은 비교 기간보다 오래된 샘플 중 가장 최근 것이며, 아직 없으면 ok는 false입니다
+func (m *Client) recordTokenSample(tokens int64, now time.Time, window time.Duration) (baseline int64, ok bool) {
+	m.tokenSamples, baseline, ok = appendTokenSample(m.tokenSamples, tokens, now, window)
+	return baseline, ok
+}
+
+// appendTokenSample은 샘플을 추가하고 기준 값 하나만 남기고 비교 기간보다 오래된 샘플을 버립니다
+func appendTokenSample(samples []tokenSample, tokens int64, now time.Time, window time.Duration) ([]tokenSample, int64, bool) {
+	samples = append(samples, tokenSample{Tokens: tokens, Timestamp: now})
+
+	cutoff := now.Add(-window)
+	for len(samples) > 1 && !samples[1].Timestamp.After(cutoff) {
+		samples = samples[1:]
+	}
+
+	if oldest := samples[0]; !oldest.Timestamp.After(cutoff) {
+		return samples, oldest.Tokens, true
+	}
+	return samples, 0, false
+}
+
+// recordWorkerTokenDeltas는 워커별 누적 토큰 수를 기록하고 1시간 전 대비 증가량을 TokensLastHour에 채웁니다
+// 24시간 토큰 수는 이동 구간이라 1시간 변화량이 실제 생산량과 다르므로 누적 토큰 수를 사용합니다
+func (m *Client) recordWorkerTokenDeltas(workers []WorkerMinuteMetrics, now time.Time) {
+	if m.workerTokenSamples == nil {
+		m.workerTokenSamples = make(map[string][]tokenSample)
+	}
+
+	seen := make(map[string]bool, len(workers))
+	for i := range workers {
+		worker := &workers[i]
+		seen[worker.ID] = true
+		// 누적 토큰 조회에 실패하면 0이 되므로 기록하지 않음
+		if worker.TotalTokens <= 0 {
+			continue
+		}
+
+		samples, baseline, ok := appendTokenSample(m.workerTokenSamples[worker.ID], worker.TotalTokens, now, time.Hour)
+		m.workerTokenSamples[worker.ID] = samples
+		if ok && worker.TotalTokens >= baseline {
+			delta := worker.TotalTokens - baseline
+			worker.TokensLastHour = &delta
+		}
+	}
+
+	// 사라진 워커의 기록 정리
+	for id := range m.workerTokenSamples {
+		if !seen[id] {
+			delete(m.workerTokenSamples, id)
+		}
+	}
+}
+
+// checkTokenDrop은 24시간 토큰 수가 비교 기간 동안 설정된 비율 이상 감소했는지 체크합니다
+func (m *Client) checkTokenDrop(mm *MinuteMetrics, config AlertConfig, sendAlert func(string, string) error) error {
+	if !config.Enabled {
+		return nil
+	}
+
+	// 토큰 조회에 실패한 경우(부분 수집) 0이 되므로 급감으로 판단하지 않음
+	current := mm.User.TokensLast24Hours
+	if current <= 0 {
+		return nil
+	}
+
+	percent, window := config.TokenDropThreshold()
+	baseline, ok := m.recordTokenSample(current, time.Now(), window)
+
+	if mm.AlertState.TokenDropAlerted {
+		// 급감 전 기준 값 대비 하락률이 기준 미만으로 회복된 경우
+		reference := mm.AlertState.TokenDropBaseline
+		if reference > 0 && float64(reference-current)/float64(reference)*100 < percent {
+			title := "✅ Token Drop Recovered"
+			msg := fmt.Sprintf("Tokens (24h): %s\nBefore drop: %s", FormatPoints(current), FormatPoints(reference))
+			message := fmt.Sprintf("%s\n%s", title, CodeBlock(msg))
+
+			if err := mm.AlertState.sendUnlessAcked(AlertTokenDrop, sendAlert, message, "status"); err != nil {
+				return fmt.Errorf("failed to send token drop recovery alert: %w", err)
+			}
+			mm.AlertState.TokenDropAlerted = false
+			mm.AlertState.TokenDropBaseline = 0
+		}
+		return nil
+	}
+
+	if !ok || baseline <= 0 {
+		return nil
+	}
+
+	drop := float64(baseline-current) / float64(baseline) * 100
+	if drop >= percent {
+		title := "⚠️ Token Drop Alert"
+		msg := fmt.Sprintf("Tokens (24h): %s -> %s\nDrop: %.1f%% in %s (threshold %.0f%%)",
+			FormatPoints(baseline), FormatPoints(current), drop, window, percent)
+		message := fmt.Sprintf("%s\n%s", title, CodeBlock(msg))
+
+		if err := sendAlert(message, "status"); err != nil {
+			return fmt.Errorf("failed to send token drop alert: %w", err)
+		}
+		mm.AlertState.TokenDropAlerted = true
+		mm.AlertState.TokenDropBaseline = baseline
+	}
+
+	return nil
+}
+
 // checkCredit는 credit이 최소 기준보다 낮은지 체크합니다
 func (m *Client) checkCredit(mm *MinuteMetrics, config AlertConfig, sendAlert func(string, string) error) error {
 	if !config.Enabled {
@@ -746,6 +1313,9 @@ func (m *Client) checkCredit(mm *MinuteMetrics, config AlertConfig, sendAlert fu
 	if mm.User.VastaiCredit.Credit <= mm.User.TotalDailyCost && !mm.AlertState.CreditAlerted {
 		title := "⚠️ Credit Alert"
 		msg := fmt.Sprintf("Vast.ai balance: $%.2f\nDaily cost: $%.2f", mm.User.VastaiCredit.Credit, mm.User.TotalDailyCost)
+		if mm.User.VastaiHourlyBurn > 0 {
+			msg += fmt.Sprintf("\nCurrent burn: $%.3f/hr", mm.User.VastaiHourlyBurn)
+		}
 		message := fmt.Sprintf("%s\n%s", title, CodeBlock(msg))
 
 		if err := sendAlert(message, "status"); err != nil {
@@ -758,7 +1328,7 @@ func (m *Client) checkCredit(mm *MinuteMetrics, config AlertConfig, sendAlert fu
 		msg := fmt.Sprintf("Vast.ai balance: $%.2f\nDaily cost: $%.2f", mm.User.VastaiCredit.Credit, mm.User.TotalDailyCost)
 		message := fmt.Sprintf("%s\n%s", title, CodeBlock(msg))
 
-		if err := sendAlert(message, "status"); err != nil {
+		if err := mm.AlertState.sendUnlessAcked(AlertCredit, sendAlert, message, "status"); err != nil {
 			return fmt.Errorf("failed to send credit recovery alert: %w", err)
 		}
 
@@ -767,15 +1337,3 @@ func (m *Client) checkCredit(mm *MinuteMetrics, config AlertConfig, sendAlert fu
 
 	return nil
 }
-
-// formatNumber 숫자를 K, M, B 단위로 자동 변환
-func formatNumber(num float64) string {
-	if num >= 1000000000 {
-		return fmt.Sprintf("%.1fB", num/1000000000)
-	} else if num >= 1000000 {
-		return fmt.Sprintf("%.1fM", num/1000000)
-	} else if num >= 1000 {
-		return fmt.Sprintf("%.1fK", num/1000)
-	}
-	return fmt.Sprintf("%.1f", num)
-}
@@ -0,0 +1,300 @@
+package api
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInstanceMismatchDetails(t *testing.T) {
+	var mm MinuteMetrics
+	mm.User.Workers = []WorkerMinuteMetrics{
+		{Name: "worker-a", Instances: []InstanceMetrics{
+			{IP: "10.0.0.1", Status: "Running"},
+			{IP: "10.0.0.2", Status: "Initializing"},
+		}},
+	}
+	mm.User.VastaiInstances = []VastaiInstance{
+		{ID: 1, PublicIP: "10.0.0.1", ActualStatus: "running"},
+		{ID: 2, PublicIP: "10.0.0.3", ActualStatus: "loading"},
+	}
+
+	details := instanceMismatchDetails(&mm)
+	if !strings.Contains(details, "worker-a (10.0.0.2, Initializing)") {
+		t.Errorf("expected Kuzco-only instance in details, got:\n%s", details)
+	}
+	if !strings.Contains(details, "#2 (10.0.0.3, loading)") {
+		t.Errorf("expected Vast.ai-only instance in details, got:\n%s", details)
+	}
+	if strings.Contains(details, "10.0.0.1") {
+		t.Errorf("matched instance should not be listed, got:\n%s", details)
+	}
+}
+
+func TestInstanceMismatchDetailsWithoutVastaiInstances(t *testing.T) {
+	var mm MinuteMetrics
+	mm.User.Workers = []WorkerMinuteMetrics{
+		{Name: "worker-a", Instances: []InstanceMetrics{{IP: "10.0.0.1"}}},
+	}
+	if details := instanceMismatchDetails(&mm); details != "" {
+		t.Errorf("expected no details without Vast.ai instances, got %q", details)
+	}
+}
+
+func TestFormatWorkerEfficiency(t *testing.T) {
+	workers := []Worker{
+		{Name: "idle", DailyCost: 5, TokensLast24H: 0},
+		{Name: "pricey", DailyCost: 20, TokensLast24H: 100},
+		{Name: "cheap", DailyCost: 10, TokensLast24H: 200},
+	}
+
+	lines := strings.Split(formatWorkerEfficiency(workers, 10000), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines, got %d: %v", len(lines), lines)
+	}
+	if lines[0] != "cheap | 2.000% | $10.00 | $5.00 per 1%" {
+		t.Errorf("unexpected first line: %q", lines[0])
+	}
+	if lines[1] != "pricey | 1.000% | $20.00 | $20.00 per 1%" {
+		t.Errorf("unexpected second line: %q", lines[1])
+	}
+	if lines[2] != "idle | 0.000% | $5.00 | N/A" {
+		t.Errorf("unexpected last line: %q", lines[2])
+	}
+}
+
+func TestCheckTokenDrop(t *testing.T) {
+	client := NewClient()
+	client.tokenSamples = []tokenSample{{Tokens: 1000, Timestamp: time.Now().Add(-20 * time.Minute)}}
+	config := AlertConfig{Enabled: true, TokenDropPercent: 40, TokenDropWindowMinutes: 15}
+
+	var alerts []string
+	sendAlert := func(message, alertType string) error {
+		alerts = append(alerts, message)
+		return nil
+	}
+
+	var mm MinuteMetrics
+	mm.User.TokensLast24Hours = 500
+	if err := client.checkTokenDrop(&mm, config, sendAlert); err != nil {
+		t.Fatal(err)
+	}
+	if len(alerts) != 1 || !strings.Contains(alerts[0], "Token Drop Alert") {
+		t.Fatalf("expected a token drop alert, got %v", alerts)
+	}
+	if !mm.AlertState.TokenDropAlerted || mm.AlertState.TokenDropBaseline != 1000 {
+		t.Errorf("unexpected alert state: %+v", mm.AlertState)
+	}
+
+	// 아직 기준 대비 40% 이상 낮으면 추가 알림 없음
+	mm.User.TokensLast24Hours = 550
+	if err := client.checkTokenDrop(&mm, config, sendAlert); err != nil {
+		t.Fatal(err)
+	}
+	if len(alerts) != 1 {
+		t.Fatalf("expected no additional alert, got %v", alerts)
+	}
+
+	mm.User.TokensLast24Hours = 900
+	if err := client.checkTokenDrop(&mm, config, sendAlert); err != nil {
+		t.Fatal(err)
+	}
+	if len(alerts) != 2 || !strings.Contains(alerts[1], "Token Drop Recovered") {
+		t.Fatalf("expected a recovery alert, got %v", alerts)
+	}
+	if mm.AlertState.TokenDropAlerted {
+		t.Error("expected alert state to be cleared after recovery")
+	}
+}
+
+func TestCheckAlertsSkipsMissingFields(t *testing.T) {
+	config := AlertConfig{Enabled: true, TokenDropPercent: 40, TokenDropWindowMinutes: 15}
+	var alerts []string
+	sendAlert := func(message, alertType string) error {
+		alerts = append(alerts, message)
+		return nil
+	}
+
+	// 토큰 조회에 실패한 0 값으로 토큰 감소 알림을 보내지 않음
+	client := NewClient()
+	client.tokenSamples = []tokenSample{{Tokens: 1000, Timestamp: time.Now().Add(-20 * time.Minute)}}
+	var mm MinuteMetrics
+	mm.User.TokensLast24Hours = 500
+	missing := MissingFields{UserTokens: true, Workers: true, CLIVersion: true, RPM: true}
+	if err := client.checkAlerts(&mm, config, missing, sendAlert); err != nil {
+		t.Fatal(err)
+	}
+	if len(alerts) != 0 || mm.AlertState.TokenDropAlerted {
+		t.Fatalf("expected no alerts for missing fields, got %v", alerts)
+	}
+
+	if err := client.checkAlerts(&mm, config, MissingFields{Workers: true, CLIVersion: true, RPM: true}, sendAlert); err != nil {
+		t.Fatal(err)
+	}
+	if len(alerts) != 1 || !strings.Contains(alerts[0], "Token Drop Alert") {
+		t.Fatalf("expected a token drop alert once tokens load, got %v", alerts)
+	}
+}
+
+func TestCheckAlertsRunsAllChecksAfterSendFailure(t *testing.T) {
+	config := AlertConfig{Enabled: true, TokenDropPercent: 40, TokenDropWindowMinutes: 15}
+	var attempts []string
+	sendAlert := func(message, alertType string) error {
+		attempts = append(attempts, message)
+		return errors.New("429 Too Many Requests")
+	}
+
+	// 인스턴스 수 불일치 알림 전송이 실패해도 토큰 감소 체크는 실행되어야 함
+	client := NewClient()
+	client.tokenSamples = []tokenSample{{Tokens: 1000, Timestamp: time.Now().Add(-20 * time.Minute)}}
+	var mm MinuteMetrics
+	mm.User.VastaiCredit = &VastaiCredit{Credit: 100}
+	mm.User.InstancesMismatch = true
+	mm.AlertState.InstanceMismatchStart = time.Now().Add(-10 * time.Minute)
+	mm.User.TokensLast24Hours = 500
+
+	err := client.checkAlerts(&mm, config, MissingFields{CLIVersion: true, RPM: true}, sendAlert)
+	if err == nil || !strings.Contains(err.Error(), "instance count") || !strings.Contains(err.Error(), "token drop") {
+		t.Fatalf("expected both failures to be returned, got %v", err)
+	}
+	if len(attempts) != 2 {
+		t.Fatalf("expected both alerts to be attempted, got %v", attempts)
+	}
+}
+
+func TestCheckTokenDropDisabledOrWithoutBaseline(t *testing.T) {
+	sendAlert := func(message, alertType string) error {
+		t.Errorf("unexpected alert: %s", message)
+		return nil
+	}
+
+	var mm MinuteMetrics
+	mm.User.TokensLast24Hours = 100
+
+	// 비교 기간 이전 샘플이 없으면 알림 없음
+	client := NewClient()
+	client.tokenSamples = []tokenSample{{Tokens: 1000, Timestamp: time.Now().Add(-time.Minute)}}
+	if err := client.checkTokenDrop(&mm, AlertConfig{Enabled: true}, sendAlert); err != nil {
+		t.Fatal(err)
+	}
+
+	// 알림이 비활성화되어 있으면 알림 없음
+	client.tokenSamples = []tokenSample{{Tokens: 1000, Timestamp: time.Now().Add(-time.Hour)}}
+	if err := client.checkTokenDrop(&mm, AlertConfig{}, sendAlert); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGenerationsPerInstance(t *testing.T) {
+	if got := generationsPerInstance(120, 4); got != 30 {
+		t.Errorf("expected 30 generations per instance, got %v", got)
+	}
+	if got := generationsPerInstance(120, 0); got != 0 {
+		t.Errorf("expected 0 without instances, got %v", got)
+	}
+}
+
+func TestCheckVersionMismatchDirection(t *testing.T) {
+	client := NewClient()
+	var alerts, types []string
+	sendAlert := func(message, alertType string) error {
+		alerts = append(alerts, message)
+		types = append(types, alertType)
+		return nil
+	}
+
+	var mm MinuteMetrics
+	mm.General.CLIVersion = "0.2.3"
+	mm.User.Workers = []WorkerMinuteMetrics{
+		{Name: "worker-a", Instances: []InstanceMetrics{
+			{IP: "10.0.0.1", Version: "0.2.1 (older (0.2.1 < 0.2.3))", VersionMismatch: true, VersionStatus: VersionOlder},
+			{IP: "10.0.0.2", Version: "0.2.4 (newer (0.2.4 > 0.2.3))", VersionMismatch: true, VersionStatus: VersionNewer},
+		}},
+	}
+
+	if err := client.checkVersionMismatch(&mm, AlertConfig{}, sendAlert); err != nil {
+		t.Fatal(err)
+	}
+	if len(alerts) != 2 || types[0] != "error" || types[1] != "status" {
+		t.Fatalf("expected an error alert for older and a status alert for newer, got %v", types)
+	}
+	if !strings.Contains(alerts[0], "OLDER") || strings.Contains(alerts[0], "10.0.0.2") {
+		t.Errorf("unexpected older alert:\n%s", alerts[0])
+	}
+	if !strings.Contains(alerts[1], "NEWER") || strings.Contains(alerts[1], "10.0.0.1") {
+		t.Errorf("unexpected newer alert:\n%s", alerts[1])
+	}
+
+	// 구버전 인스턴스만 업그레이드되면 해당 복구 알림만 전송
+	mm.User.Workers[0].Instances = mm.User.Workers[0].Instances[1:]
+	if err := client.checkVersionMismatch(&mm, AlertConfig{}, sendAlert); err != nil {
+		t.Fatal(err)
+	}
+	if len(alerts) != 3 || types[2] != "error" || !strings.Contains(alerts[2], "Resolved (older)") {
+		t.Fatalf("expected only the older recovery alert, got %v", alerts[2:])
+	}
+	if mm.AlertState.VersionMismatchAlerted || !mm.AlertState.VersionNewerAlerted {
+		t.Errorf("unexpected alert state: %+v", mm.AlertState)
+	}
+}
+
+func TestVersionMismatchKnownAheadVersions(t *testing.T) {
+	var mm MinuteMetrics
+	mm.User.Workers = []WorkerMinuteMetrics{
+		{Name: "worker-a", Instances: []InstanceMetrics{
+			{IP: "10.0.0.1", Version: "0.2.1 (older (0.2.1 < 0.2.3))", VersionMismatch: true, VersionStatus: VersionOlder},
+			{IP: "10.0.0.2", Version: "0.2.4-fe4d73f (newer (0.2.4 > 0.2.3))", VersionMismatch: true, VersionStatus: VersionNewer},
+			{IP: "10.0.0.3", Version: "0.2.5 (newer (0.2.5 > 0.2.3))", VersionMismatch: true, VersionStatus: VersionNewer},
+		}},
+	}
+
+	config := AlertConfig{KnownAheadVersions: []string{"0.2.4", "0.2.1"}}
+	newer := versionMismatchWorkers(&mm, VersionNewer, config)
+	if len(newer) != 1 || strings.Contains(newer[0], "10.0.0.2") || !strings.Contains(newer[0], "10.0.0.3") {
+		t.Errorf("expected only the unlisted newer version, got %v", newer)
+	}
+	// 목록에 있어도 구버전은 계속 알림
+	if older := versionMismatchWorkers(&mm, VersionOlder, config); len(older) != 1 {
+		t.Errorf("expected older instances to still be flagged, got %v", older)
+	}
+
+	if newer := versionMismatchWorkers(&mm, VersionNewer, AlertConfig{IgnoreNewerVersions: true}); len(newer) != 0 {
+		t.Errorf("expected every newer version to be ignored, got %v", newer)
+	}
+}
+
+func TestRecordWorkerTokenDeltas(t *testing.T) {
+	client := NewClient()
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	collect := func(at time.Time, tokens ...int64) []WorkerMinuteMetrics {
+		workers := make([]WorkerMinuteMetrics, len(tokens))
+		for i, v := range tokens {
+			workers[i] = WorkerMinuteMetrics{ID: string(rune('a' + i)), TotalTokens: v}
+		}
+		client.recordWorkerTokenDeltas(workers, at)
+		return workers
+	}
+
+	workers := collect(start, 1000, 5000)
+	if workers[0].TokensLastHour != nil {
+		t.Fatalf("expected no delta before an hour of samples, got %d", *workers[0].TokensLastHour)
+	}
+	collect(start.Add(30*time.Minute), 1300, 5100)
+
+	workers = collect(start.Add(61*time.Minute), 1700, 0)
+	if workers[0].TokensLastHour == nil || *workers[0].TokensLastHour != 700 {
+		t.Errorf("expected delta 700 against the sample from an hour ago, got %v", workers[0].TokensLastHour)
+	}
+	// 누적 토큰 조회 실패(0)는 증가량을 계산하지 않음
+	if workers[1].TokensLastHour != nil {
+		t.Errorf("expected no delta for a failed read, got %d", *workers[1].TokensLastHour)
+	}
+
+	// 사라진 워커의 기록은 정리됨
+	collect(start.Add(62*time.Minute), 1800)
+	if _, ok := client.workerTokenSamples["b"]; ok {
+		t.Error("expected samples of a removed worker to be dropped")
+	}
+}
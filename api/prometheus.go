@@ -0,0 +1,118 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// prometheusLabelEscaper는 Prometheus 텍스트 형식의 라벨 값에서 특별한 의미가 있는 문자를 이스케이프합니다
+var prometheusLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// handlePrometheus는 현재 메트릭스를 Prometheus 텍스트 형식으로 반환합니다
+func (s *MetricsServer) handlePrometheus(w http.ResponseWriter, r *http.Request) {
+	globalMetricsLock.Lock()
+	metrics := globalCurrentMetrics
+	globalMetricsLock.Unlock()
+
+	if metrics == nil {
+		http.Error(w, "메트릭스 데이터가 아직 수집되지 않았습니다", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	writePrometheusMetrics(w, metrics)
+}
+
+// writePrometheusMetrics는 계정 합계와 워커별 라벨이 붙은 게이지를 Prometheus 텍스트 형식으로 씁니다
+func writePrometheusMetrics(w io.Writer, metrics *MinuteMetrics) {
+	gauge := func(name, help string, samples func(add func(labels string, value float64))) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+		samples(func(labels string, value float64) {
+			fmt.Fprintf(w, "%s%s %g\n", name, labels, value)
+		})
+	}
+	single := func(value float64) func(add func(string, float64)) {
+		return func(add func(string, float64)) { add("", value) }
+	}
+
+	gauge("kuzco_user_tokens_24h", "Tokens earned by the account in the last 24 hours.", single(float64(metrics.User.TokensLast24Hours)))
+	gauge("kuzco_user_share", "Account share of all tokens in the last 24 hours.", single(metrics.User.Share))
+	gauge("kuzco_user_instances", "Instances of the account.", single(float64(metrics.User.TotalInstances)))
+	gauge("kuzco_user_daily_cost", "Daily cost of the account in dollars.", single(metrics.User.TotalDailyCost))
+	gauge("kuzco_general_rpm", "Requests per minute across the network.", single(float64(metrics.General.RPM)))
+
+	workers := metrics.User.Workers
+	gauge("kuzco_worker_instances", "Instances of the worker by GPU model.", func(add func(string, float64)) {
+		for _, worker := range workers {
+			counts := make(map[string]int)
+			for _, inst := range worker.Instances {
+				gpu := inst.GPUModel
+				if gpu == "" {
+					gpu = "unknown"
+				}
+				counts[gpu]++
+			}
+			gpus := make([]string, 0, len(counts))
+			for gpu := range counts {
+				gpus = append(gpus, gpu)
+			}
+			sort.Strings(gpus)
+			for _, gpu := range gpus {
+				add(prometheusLabels("worker", worker.Name, "gpu", gpu), float64(counts[gpu]))
+			}
+		}
+	})
+	gauge("kuzco_worker_tokens_24h", "Tokens earned by the worker in the last 24 hours.", func(add func(string, float64)) {
+		for _, worker := range workers {
+			add(prometheusLabels("worker", worker.Name), float64(worker.TokensLast24H))
+		}
+	})
+	gauge("kuzco_worker_daily_cost", "Daily cost of the worker in dollars.", func(add func(string, float64)) {
+		for _, worker := range workers {
+			add(prometheusLabels("worker", worker.Name), worker.DailyCost)
+		}
+	})
+	gauge("kuzco_worker_gen_last_hour", "Generations of the worker in the last hour.", func(add func(string, float64)) {
+		for _, worker := range workers {
+			add(prometheusLabels("worker", worker.Name), float64(worker.GenerationLastHour))
+		}
+	})
+
+	if teams := metrics.User.Teams; len(teams) > 0 {
+		gauge("kuzco_team_tokens_24h", "Tokens earned by the team in the last 24 hours.", func(add func(string, float64)) {
+			for _, team := range teams {
+				add(prometheusLabels("team", team.Name), float64(team.TokensLast24Hours))
+			}
+		})
+		gauge("kuzco_team_instances", "Instances of the team.", func(add func(string, float64)) {
+			for _, team := range teams {
+				add(prometheusLabels("team", team.Name), float64(team.Instances))
+			}
+		})
+	}
+}
+
+// prometheusLabels는 이름/값 쌍을 {name="value",...} 형식으로 만듭니다
+func prometheusLabels(pairs ...string) string {
+	labels := make([]string, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		labels = append(labels, fmt.Sprintf(`%s="%s"`, pairs[i], sanitizeLabelValue(pairs[i+1])))
+	}
+	return "{" + strings.Join(labels, ",") + "}"
+}
+
+// sanitizeLabelValue는 워커 이름 등을 유효한 라벨 값으로 바꿉니다
+// 잘못된 UTF-8과 제어 문자는 "_"로 바꾸고, 역슬래시, 따옴표, 줄바꿈은 이스케이프합니다
+func sanitizeLabelValue(value string) string {
+	value = strings.ToValidUTF8(value, "_")
+	value = strings.Map(func(r rune) rune {
+		if r != '\n' && (r < 0x20 || r == 0x7f) {
+			return '_'
+		}
+		return r
+	}, value)
+	return prometheusLabelEscaper.Replace(value)
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWritePrometheusMetrics(t *testing.T) {
+	var metrics MinuteMetrics
+	metrics.User.TokensLast24Hours = 1500
+	metrics.User.Workers = []WorkerMinuteMetrics{{
+		Name:               `rig "A"\1`,
+		TokensLast24H:      1000,
+		DailyCost:          2.5,
+		GenerationLastHour: 42,
+		Instances: []InstanceMetrics{
+			{GPUModel: "RTX 4090"},
+			{GPUModel: "RTX 4090"},
+			{GPUModel: "RTX 3090"},
+			{},
+		},
+	}}
+
+	var b strings.Builder
+	writePrometheusMetrics(&b, &metrics)
+	out := b.String()
+
+	for _, want := range []string{
+		"# TYPE kuzco_worker_instances gauge\n",
+		"kuzco_user_tokens_24h 1500\n",
+		`kuzco_worker_instances{worker="rig \"A\"\\1",gpu="RTX 3090"} 1` + "\n",
+		`kuzco_worker_instances{worker="rig \"A\"\\1",gpu="RTX 4090"} 2` + "\n",
+		`kuzco_worker_instances{worker="rig \"A\"\\1",gpu="unknown"} 1` + "\n",
+		`kuzco_worker_tokens_24h{worker="rig \"A\"\\1"} 1000` + "\n",
+		`kuzco_worker_daily_cost{worker="rig \"A\"\\1"} 2.5` + "\n",
+		`kuzco_worker_gen_last_hour{worker="rig \"A\"\\1"} 42` + "\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+}
+
+func TestSanitizeLabelValue(t *testing.T) {
+	tests := map[string]string{
+		"worker-1":    "worker-1",
+		"한글 워커":       "한글 워커",
+		"line\nbreak": `line\nbreak`,
+		"tab\there":   "tab_here",
+		"bad\xffutf8": "bad_utf8",
+		`quote"back\`: `quote\"back\\`,
+	}
+	for input, want := range tests {
+		if got := sanitizeLabelValue(input); got != want {
+			t.Errorf("sanitizeLabelValue(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
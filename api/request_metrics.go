@@ -0,0 +1,113 @@
+package api
+
+import (
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// requestLatencySamples는 엔드포인트별로 백분위수 계산에 사용하는 최근 응답 시간 개수입니다
+const requestLatencySamples = 200
+
+// EndpointStats는 외부 API 엔드포인트별 호출 통계입니다
+type EndpointStats struct {
+	Endpoint string  `json:"endpoint"`
+	Count    int64   `json:"count"`
+	Errors   int64   `json:"errors"`
+	P50Ms    float64 `json:"p50Ms"` // 최근 호출 기준 중앙값 (ms)
+	P95Ms    float64 `json:"p95Ms"`
+}
+
+// endpointRecord는 엔드포인트별 누적 카운터와 최근 응답 시간 링 버퍼입니다
+type endpointRecord struct {
+	count     int64
+	errors    int64
+	latencies []time.Duration
+	next      int
+}
+
+// RequestMetrics는 Kuzco/Vast.ai 호출의 횟수, 에러 수, 응답 시간을 메모리에 기록합니다
+type RequestMetrics struct {
+	endpoints map[string]*endpointRecord
+	mu        sync.Mutex
+}
+
+var GlobalRequestMetrics = &RequestMetrics{}
+
+// Record는 한 번의 호출 결과를 기록합니다
+func (m *RequestMetrics) Record(endpoint string, latency time.Duration, failed bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.endpoints == nil {
+		m.endpoints = make(map[string]*endpointRecord)
+	}
+	record, ok := m.endpoints[endpoint]
+	if !ok {
+		record = &endpointRecord{}
+		m.endpoints[endpoint] = record
+	}
+
+	record.count++
+	if failed {
+		record.errors++
+	}
+	if len(record.latencies) < requestLatencySamples {
+		record.latencies = append(record.latencies, latency)
+		return
+	}
+	record.latencies[record.next] = latency
+	record.next = (record.next + 1) % requestLatencySamples
+}
+
+// Snapshot은 엔드포인트 이름순으로 정렬한 통계를 반환합니다
+func (m *RequestMetrics) Snapshot() []EndpointStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := make([]EndpointStats, 0, len(m.endpoints))
+	for endpoint, record := range m.endpoints {
+		sorted := append([]time.Duration(nil), record.latencies...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		stats = append(stats, EndpointStats{
+			Endpoint: endpoint,
+			Count:    record.count,
+			Errors:   record.errors,
+			P50Ms:    percentileMs(sorted, 50),
+			P95Ms:    percentileMs(sorted, 95),
+		})
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Endpoint < stats[j].Endpoint })
+	return stats
+}
+
+// percentileMs는 정렬된 응답 시간에서 nearest-rank 방식으로 백분위수를 구합니다
+func percentileMs(sorted []time.Duration, percentile int) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := (percentile*len(sorted) + 99) / 100 // ceil(p/100 * n)
+	if rank < 1 {
+		rank = 1
+	}
+	return float64(sorted[rank-1].Microseconds()) / 1000
+}
+
+// numericPathSegment는 경로의 인스턴스 ID 같은 숫자 구간입니다
+var numericPathSegment = regexp.MustCompile(`/\d+(/|$)`)
+
+// requestEndpoint는 URL에서 쿼리와 숫자 ID를 제거하여 엔드포인트 이름을 만듭니다 (예: "vastai GET /api/v0/instances/:id/reboot/")
+func requestEndpoint(service, method, rawURL string) string {
+	path := rawURL
+	if u, err := url.Parse(rawURL); err == nil {
+		path = u.Path
+	} else if i := strings.IndexByte(path, '?'); i >= 0 {
+		path = path[:i]
+	}
+	path = numericPathSegment.ReplaceAllString(path, "/:id$1")
+	path = numericPathSegment.ReplaceAllString(path, "/:id$1") // 연속된 숫자 구간 처리
+	return service + " " + method + " " + path
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRequestMetricsSnapshot(t *testing.T) {
+	m := &RequestMetrics{}
+	for i := 1; i <= 20; i++ {
+		m.Record("kuzco GET /api/trpc/metrics", time.Duration(i)*time.Millisecond, i == 20)
+	}
+	m.Record("vastai GET /api/v0/instances/", 5*time.Millisecond, false)
+
+	stats := m.Snapshot()
+	if len(stats) != 2 || stats[0].Endpoint != "kuzco GET /api/trpc/metrics" {
+		t.Fatalf("expected two endpoints sorted by name, got %+v", stats)
+	}
+	kuzco := stats[0]
+	if kuzco.Count != 20 || kuzco.Errors != 1 {
+		t.Errorf("unexpected counters: %+v", kuzco)
+	}
+	if kuzco.P50Ms != 10 || kuzco.P95Ms != 19 {
+		t.Errorf("expected p50 10ms and p95 19ms, got %v / %v", kuzco.P50Ms, kuzco.P95Ms)
+	}
+}
+
+func TestRequestMetricsKeepsRecentLatencies(t *testing.T) {
+	m := &RequestMetrics{}
+	for i := 0; i < requestLatencySamples; i++ {
+		m.Record("e", time.Second, false)
+	}
+	for i := 0; i < requestLatencySamples; i++ {
+		m.Record("e", time.Millisecond, false)
+	}
+
+	stats := m.Snapshot()
+	if stats[0].Count != 2*requestLatencySamples || stats[0].P95Ms != 1 {
+		t.Errorf("expected old latencies to be dropped, got %+v", stats[0])
+	}
+}
+
+func TestRequestEndpoint(t *testing.T) {
+	tests := []struct {
+		service, method, url, expected string
+	}{
+		{"vastai", "PUT", "https://console.vast.ai/api/v0/instances/12345/reboot/", "vastai PUT /api/v0/instances/:id/reboot/"},
+		{"vastai", "GET", "https://console.vast.ai/api/v0/instances/?owner=me", "vastai GET /api/v0/instances/"},
+		{"kuzco", "GET", "https://relay.kuzco.xyz/api/trpc/metrics.hoganEarningsLast24Hours?batch=1&input={}", "kuzco GET /api/trpc/metrics.hoganEarningsLast24Hours"},
+	}
+	for _, tt := range tests {
+		if got := requestEndpoint(tt.service, tt.method, tt.url); got != tt.expected {
+			t.Errorf("requestEndpoint(%q) = %q, expected %q", tt.url, got, tt.expected)
+		}
+	}
+}
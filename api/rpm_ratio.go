@@ -0,0 +1,80 @@
+package api
+
+import (
+	"fmt"
+	"math"
+)
+
+// DefaultRPMRatioMinSamples는 인스턴스당 RPM 기준선을 계산하는 데 필요한 최소 분 단위 기록 수입니다
+// 재시작 직후 기록이 적을 때 몇 분의 값만으로 알림이 울리지 않도록 합니다
+const DefaultRPMRatioMinSamples = 10
+
+// rpmPerInstance는 인스턴스당 RPM을 계산하며, 인스턴스가 없으면 0을 반환합니다
+func rpmPerInstance(rpm, instances int) float64 {
+	if instances <= 0 {
+		return 0
+	}
+	return float64(rpm) / float64(instances)
+}
+
+// RPMPerInstanceBaseline은 지난 60분 동안 기록된 인스턴스당 RPM의 평균과 사용한 기록 수를 반환합니다
+// RPM이나 인스턴스 수가 0인 기록(부분 수집)은 제외합니다
+func (m *HourlyStatsManager) RPMPerInstanceBaseline() (baseline float64, samples int) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	var sum float64
+	for _, stat := range m.stats {
+		if stat.RPM <= 0 || stat.TotalInstances <= 0 {
+			continue
+		}
+		sum += rpmPerInstance(stat.RPM, stat.TotalInstances)
+		samples++
+	}
+	if samples == 0 {
+		return 0, 0
+	}
+	return sum / float64(samples), samples
+}
+
+// checkRPMRatio는 네트워크 인스턴스당 RPM(General.RPM / General.TotalInstances)이 stats의 기준선에서
+// 설정된 비율 이상 벗어났는지 체크합니다. 레인 재배치나 네트워크 문제의 신호일 수 있습니다
+// 기준선은 이번 분을 추가하기 전의 기록으로 계산하며, 기준선이 따라오거나 값이 돌아오면 복구 알림을 보냅니다
+func (m *Client) checkRPMRatio(mm *MinuteMetrics, config AlertConfig, stats *HourlyStatsManager, sendAlert func(string, string) error) error {
+	if !config.Enabled || config.RPMRatioBandPercent <= 0 {
+		return nil
+	}
+
+	// 조회에 실패한 경우(부분 수집) 0이 되므로 이상으로 판단하지 않음
+	if mm.General.RPM <= 0 || mm.General.TotalInstances <= 0 {
+		return nil
+	}
+	baseline, samples := stats.RPMPerInstanceBaseline()
+	if samples < DefaultRPMRatioMinSamples || baseline <= 0 {
+		return nil
+	}
+
+	current := rpmPerInstance(mm.General.RPM, mm.General.TotalInstances)
+	change := (current - baseline) / baseline * 100
+	band := config.RPMRatioBandPercent
+	msg := fmt.Sprintf("RPM per instance: %.2f (RPM %d / %d instances)\nBaseline (avg of last %d min): %.2f\nChange: %+.1f%% (band ±%.0f%%)",
+		current, mm.General.RPM, mm.General.TotalInstances, samples, baseline, change, band)
+
+	outside := math.Abs(change) >= band
+	if outside && !mm.AlertState.RPMRatioAlerted {
+		title := "⚠️ RPM per Instance Alert"
+		message := fmt.Sprintf("%s\n%s", title, CodeBlock(msg))
+		if err := sendAlert(message, "status"); err != nil {
+			return fmt.Errorf("failed to send RPM ratio alert: %w", err)
+		}
+		mm.AlertState.RPMRatioAlerted = true
+	} else if !outside && mm.AlertState.RPMRatioAlerted {
+		title := "✅ RPM per Instance Recovered"
+		message := fmt.Sprintf("%s\n%s", title, CodeBlock(msg))
+		if err := mm.AlertState.sendUnlessAcked(AlertRPMRatio, sendAlert, message, "status"); err != nil {
+			return fmt.Errorf("failed to send RPM ratio recovery alert: %w", err)
+		}
+		mm.AlertState.RPMRatioAlerted = false
+	}
+	return nil
+}
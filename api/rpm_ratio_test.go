@@ -0,0 +1,75 @@
+package api
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckRPMRatio(t *testing.T) {
+	stats := &HourlyStatsManager{}
+	now := time.Now()
+	for i := 0; i < DefaultRPMRatioMinSamples; i++ {
+		stats.stats = append(stats.stats, MinuteStats{RPM: 1000, TotalInstances: 100, Timestamp: now.Add(time.Duration(i-DefaultRPMRatioMinSamples) * time.Minute)})
+	}
+	// 부분 수집 기록은 기준선에서 제외
+	stats.stats = append(stats.stats, MinuteStats{RPM: 0, TotalInstances: 100, Timestamp: now})
+	if baseline, samples := stats.RPMPerInstanceBaseline(); baseline != 10 || samples != DefaultRPMRatioMinSamples {
+		t.Fatalf("expected baseline 10 from %d samples, got %v from %d", DefaultRPMRatioMinSamples, baseline, samples)
+	}
+
+	client := &Client{}
+	var alerts []string
+	sendAlert := func(message, alertType string) error {
+		alerts = append(alerts, message)
+		return nil
+	}
+	config := AlertConfig{Enabled: true, RPMRatioBandPercent: 30}
+	mm := MinuteMetrics{}
+	mm.General.RPM = 1200
+	mm.General.TotalInstances = 100
+
+	// 기준선에서 20% 변화는 범위 안
+	if err := client.checkRPMRatio(&mm, config, stats, sendAlert); err != nil {
+		t.Fatal(err)
+	}
+	if len(alerts) != 0 {
+		t.Fatalf("expected no alert within the band, got %v", alerts)
+	}
+
+	mm.General.RPM = 600
+	if err := client.checkRPMRatio(&mm, config, stats, sendAlert); err != nil {
+		t.Fatal(err)
+	}
+	if len(alerts) != 1 || !strings.Contains(alerts[0], "RPM per Instance Alert") ||
+		!strings.Contains(alerts[0], "RPM per instance: 6.00") || !strings.Contains(alerts[0], "Baseline (avg of last 10 min): 10.00") {
+		t.Fatalf("expected an alert with current and baseline values, got %v", alerts)
+	}
+
+	// 계속 벗어나 있으면 추가 알림 없음
+	if err := client.checkRPMRatio(&mm, config, stats, sendAlert); err != nil {
+		t.Fatal(err)
+	}
+	if len(alerts) != 1 {
+		t.Fatalf("expected no additional alert, got %v", alerts)
+	}
+
+	mm.General.RPM = 1000
+	if err := client.checkRPMRatio(&mm, config, stats, sendAlert); err != nil {
+		t.Fatal(err)
+	}
+	if len(alerts) != 2 || !strings.Contains(alerts[1], "RPM per Instance Recovered") || mm.AlertState.RPMRatioAlerted {
+		t.Errorf("expected a recovery alert, got %v (state %+v)", alerts, mm.AlertState)
+	}
+
+	// 알림이 꺼져 있거나 범위가 설정되지 않으면 체크하지 않음
+	mm.General.RPM = 100
+	for _, disabled := range []AlertConfig{{Enabled: false, RPMRatioBandPercent: 30}, {Enabled: true}} {
+		if err := client.checkRPMRatio(&mm, disabled, stats, sendAlert); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(alerts) != 2 {
+		t.Errorf("expected no alert when disabled, got %v", alerts)
+	}
+}
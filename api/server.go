@@ -1,10 +1,15 @@
 package api
 
 import (
+	"crypto/subtle"
 	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 )
 
@@ -15,16 +20,39 @@ var (
 
 // MetricsServer는 메트릭스 데이터를 제공하는 HTTP 서버입니다
 type MetricsServer struct {
-	port int
+	addr          string
+	authToken     string                  // 비어 있지 않으면 /api/ 엔드포인트와 /metrics에 이 토큰이 필요
+	triggerReport func(kind string) error // POST /api/report가 호출하는 보고서 전송 함수
+	accountName   string                  // /api/hourly가 반환하는 통계의 계정 (SetAccountName)
 }
 
+// ReportTypes는 POST /api/report?type=으로 요청할 수 있는 보고서 종류입니다
+var ReportTypes = []string{ReportDaily, ReportHourly, ReportWorker}
+
 // NewMetricsServer는 새로운 MetricsServer 인스턴스를 생성합니다
-func NewMetricsServer(port int) *MetricsServer {
+// addr은 "host:port" 형식의 바인드 주소입니다
+func NewMetricsServer(addr string) *MetricsServer {
 	return &MetricsServer{
-		port: port,
+		addr: addr,
 	}
 }
 
+// SetAuthToken requires the token on every /api/ endpoint and /metrics, sent as "Authorization: Bearer <token>"
+// or the token query parameter. An empty token leaves the endpoints open
+func (s *MetricsServer) SetAuthToken(token string) {
+	s.authToken = token
+}
+
+// SetAccountName sets the account whose stats /api/hourly returns
+func (s *MetricsServer) SetAccountName(name string) {
+	s.accountName = name
+}
+
+// SetReportTrigger sets the function POST /api/report calls to generate and send a report
+func (s *MetricsServer) SetReportTrigger(trigger func(kind string) error) {
+	s.triggerReport = trigger
+}
+
 // UpdateMetrics는 글로벌 메트릭스 변수를 업데이트합니다
 func UpdateMetrics(metrics MinuteMetrics) {
 	globalMetricsLock.Lock()
@@ -34,20 +62,83 @@ func UpdateMetrics(metrics MinuteMetrics) {
 }
 
 // Start는 메트릭스 서버를 시작합니다
-func (s *MetricsServer) Start() {
+// 서버가 종료되거나 바인드에 실패하면 에러를 반환합니다
+func (s *MetricsServer) Start() error {
 	http.HandleFunc("/", s.handleRoot)
-	http.HandleFunc("/api/metrics", s.handleMetrics)
-	http.HandleFunc("/api/user", s.handleUserMetrics)
-	http.HandleFunc("/api/general", s.handleGeneralMetrics)
-	http.HandleFunc("/api/hourly", s.handleHourlyStats)
-	http.HandleFunc("/api/workers", s.handleWorkers)
-	http.HandleFunc("/api/calculations", s.handleCalculations)
-
-	log.Printf("Starting metrics server on port %d... (개발 모드 전용)", s.port)
-	addr := fmt.Sprintf(":%d", s.port)
-	if err := http.ListenAndServe(addr, nil); err != nil {
-		log.Fatalf("Failed to start metrics server: %v", err)
+	http.HandleFunc("/api/metrics", s.requireAuth(s.handleMetrics))
+	http.HandleFunc("/api/user", s.requireAuth(s.handleUserMetrics))
+	http.HandleFunc("/api/general", s.requireAuth(s.handleGeneralMetrics))
+	http.HandleFunc("/api/hourly", s.requireAuth(s.handleHourlyStats))
+	http.HandleFunc("/api/workers", s.requireAuth(s.handleWorkers))
+	http.HandleFunc("/api/calculations", s.requireAuth(s.handleCalculations))
+	http.HandleFunc("/api/events", s.requireAuth(s.handleEvents))
+	http.HandleFunc("/api/debug/requests", s.requireAuth(s.handleRequestMetrics))
+	http.HandleFunc("/api/report", s.requireAuth(s.handleReport))
+	http.HandleFunc("/api/logs", s.requireAuth(s.handleLogs))
+	http.HandleFunc("/metrics", s.requireAuth(s.handlePrometheus))
+	http.HandleFunc("/healthz", s.handleHealthz)
+	http.HandleFunc("/readyz", s.handleReadyz)
+
+	log.Printf("Starting metrics server on %s...", s.addr)
+	if err := http.ListenAndServe(s.addr, nil); err != nil {
+		return fmt.Errorf("metrics server failed: %w", err)
+	}
+	return nil
+}
+
+// requireAuth는 인증 토큰이 설정된 경우 토큰이 일치하는 요청만 handler로 전달합니다
+func (s *MetricsServer) requireAuth(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.authToken != "" {
+			token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if token == "" {
+				token = r.URL.Query().Get("token")
+			}
+			if subtle.ConstantTimeCompare([]byte(token), []byte(s.authToken)) != 1 {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+		}
+		handler(w, r)
+	}
+}
+
+// handleReport는 POST /api/report?type=daily|hourly|worker 요청을 받아 보고서 전송을 시작하고 202를 반환합니다
+// 보고서 생성과 텔레그램 전송은 백그라운드에서 실행되며 결과는 로그로 남깁니다
+func (s *MetricsServer) handleReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.triggerReport == nil {
+		http.Error(w, "report trigger is not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	kind := r.URL.Query().Get("type")
+	valid := false
+	for _, reportType := range ReportTypes {
+		if kind == reportType {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		http.Error(w, fmt.Sprintf("invalid type %q (expected %s)", kind, strings.Join(ReportTypes, ", ")), http.StatusBadRequest)
+		return
 	}
+
+	log.Printf("Report %q requested via API from %s", kind, r.RemoteAddr)
+	go func() {
+		if err := s.triggerReport(kind); err != nil {
+			log.Printf("[ERROR] Report %q requested via API failed: %v", kind, err)
+		}
+	}()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]string{"status": "accepted", "type": kind})
 }
 
 // 루트 경로에서 HTML 페이지를 제공합니다
@@ -149,8 +240,14 @@ func (s *MetricsServer) handleRoot(w http.ResponseWriter, r *http.Request) {
 			<a href="#" onclick="fetchData('/api/user'); return false;">/api/user - 사용자 메트릭스 데이터</a>
 			<a href="#" onclick="fetchData('/api/general'); return false;">/api/general - 일반 메트릭스 데이터</a>
 			<a href="#" onclick="fetchData('/api/hourly'); return false;">/api/hourly - 시간별 통계 데이터</a>
-			<a href="#" onclick="fetchData('/api/workers'); return false;">/api/workers - 워커 리스트 및 상세 정보</a>
+			<a href="#" onclick="fetchData('/api/workers'); return false;">/api/workers - 워커 리스트 및 상세 정보 (?sort=tokensPerInstance|dailyCost|name&order=asc|desc&limit=&offset=)</a>
 			<a href="#" onclick="fetchData('/api/calculations'); return false;">/api/calculations - 포인트 및 효율성 계산</a>
+			<a href="#" onclick="fetchData('/api/events'); return false;">/api/events - 최근 워커 변경 이벤트</a>
+			<a href="#" onclick="fetchData('/api/debug/requests'); return false;">/api/debug/requests - 외부 API 호출 수, 에러 수, 응답 시간</a>
+			<a href="#" onclick="fetchData('/readyz'); return false;">/readyz - 계정별 수집 상태 및 에러 카운터</a>
+			<a href="/api/logs">/api/logs - 최근 로그 (?lines=)</a>
+			<p>POST /api/report?type=daily|hourly|worker - 보고서를 즉시 생성하여 텔레그램으로 전송</p>
+			<a href="/metrics">/metrics - Prometheus 형식 계정/워커별 게이지</a>
 		</div>
 		
 		<script>
@@ -222,7 +319,7 @@ func (s *MetricsServer) handleGeneralMetrics(w http.ResponseWriter, r *http.Requ
 
 // handleHourlyStats는 시간별 통계 데이터를 JSON으로 반환합니다
 func (s *MetricsServer) handleHourlyStats(w http.ResponseWriter, r *http.Request) {
-	stats := GlobalHourlyStats.GetStats()
+	stats := HourlyStatsFor(s.accountName).GetStats()
 
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("Access-Control-Allow-Origin", "*")
@@ -240,9 +337,121 @@ func (s *MetricsServer) handleWorkers(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	workers, err := queryWorkers(metrics.User.Workers, r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	json.NewEncoder(w).Encode(workers)
+}
+
+// queryWorkers는 /api/workers의 sort, order, limit, offset 쿼리를 워커 목록에 적용합니다
+// 쿼리가 없으면 원래 순서의 전체 목록을 반환하며, 원본 슬라이스는 변경하지 않습니다
+func queryWorkers(workers []WorkerMinuteMetrics, query url.Values) ([]WorkerMinuteMetrics, error) {
+	result := append([]WorkerMinuteMetrics(nil), workers...)
+
+	if key := query.Get("sort"); key != "" {
+		var less func(a, b WorkerMinuteMetrics) bool
+		switch key {
+		case "tokensPerInstance":
+			less = func(a, b WorkerMinuteMetrics) bool { return a.TokensPerInstance < b.TokensPerInstance }
+		case "dailyCost":
+			less = func(a, b WorkerMinuteMetrics) bool { return a.DailyCost < b.DailyCost }
+		case "name":
+			less = func(a, b WorkerMinuteMetrics) bool { return a.Name < b.Name }
+		default:
+			return nil, fmt.Errorf("invalid sort %q (expected tokensPerInstance, dailyCost or name)", key)
+		}
+
+		desc := false
+		switch order := query.Get("order"); order {
+		case "", "asc":
+		case "desc":
+			desc = true
+		default:
+			return nil, fmt.Errorf("invalid order %q (expected asc or desc)", order)
+		}
+
+		sort.SliceStable(result, func(i, j int) bool {
+			if desc {
+				return less(result[j], result[i])
+			}
+			return less(result[i], result[j])
+		})
+	}
+
+	offset, err := nonNegativeQueryInt(query, "offset")
+	if err != nil {
+		return nil, err
+	}
+	if offset > len(result) {
+		offset = len(result)
+	}
+	result = result[offset:]
+
+	if query.Get("limit") != "" {
+		limit, err := nonNegativeQueryInt(query, "limit")
+		if err != nil {
+			return nil, err
+		}
+		if limit < len(result) {
+			result = result[:limit]
+		}
+	}
+	return result, nil
+}
+
+// nonNegativeQueryInt는 쿼리 값을 0 이상의 정수로 해석하며, 값이 없으면 0을 반환합니다
+func nonNegativeQueryInt(query url.Values, name string) (int, error) {
+	raw := query.Get(name)
+	if raw == "" {
+		return 0, nil
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid %s %q (expected a non-negative integer)", name, raw)
+	}
+	return value, nil
+}
+
+// handleEvents는 최근 워커 변경 이벤트를 오래된 순서로 JSON으로 반환합니다
+func (s *MetricsServer) handleEvents(w http.ResponseWriter, r *http.Request) {
+	events := GlobalWorkerEvents.Events()
+
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("Access-Control-Allow-Origin", "*")
-	json.NewEncoder(w).Encode(metrics.User.Workers)
+	json.NewEncoder(w).Encode(events)
+}
+
+// handleRequestMetrics는 Kuzco/Vast.ai 엔드포인트별 호출 수, 에러 수, p50/p95 응답 시간을 JSON으로 반환합니다
+func (s *MetricsServer) handleRequestMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	json.NewEncoder(w).Encode(GlobalRequestMetrics.Snapshot())
+}
+
+// handleLogs는 메모리에 보관된 최근 로그를 오래된 순서로 plain text로 반환합니다
+// lines 쿼리가 있으면 마지막 lines줄만 반환합니다
+func (s *MetricsServer) handleLogs(w http.ResponseWriter, r *http.Request) {
+	lines := GlobalLogBuffer.Lines()
+	if r.URL.Query().Get("lines") != "" {
+		limit, err := nonNegativeQueryInt(r.URL.Query(), "lines")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if limit < len(lines) {
+			lines = lines[len(lines)-limit:]
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	for _, line := range lines {
+		fmt.Fprintln(w, line)
+	}
 }
 
 // handleCalculations는 포인트 계산 및 효율성 계산 데이터를 JSON으로 반환합니다
@@ -257,7 +466,7 @@ func (s *MetricsServer) handleCalculations(w http.ResponseWriter, r *http.Reques
 	}
 
 	// 포인트 계산 (나눗셈 수치별)
-	// 실제 사용되는 값: myPoints := float64(metrics.User.TokensLast24Hours) / 10000
+	// 실제 사용되는 값: FormatPoints(metrics.User.TokensLast24Hours) (reporting.pointDivisor 기준)
 	userTokens := metrics.User.TokensLast24Hours
 	generalTokens := metrics.General.TokensLast24Hours
 
@@ -288,48 +497,55 @@ func (s *MetricsServer) handleCalculations(w http.ResponseWriter, r *http.Reques
 			"share_percentage":        metrics.User.Share * 100,
 		},
 		"points_calculation": map[string]interface{}{
+			"configured": map[string]interface{}{
+				"note":                   PointsNote(),
+				"my_points_formatted":    FormatPoints(userTokens),
+				"total_points_formatted": FormatPoints(generalTokens),
+			},
 			"at_1000_division": map[string]interface{}{
 				"my_points_raw":          myPointsAt1000,
-				"my_points_formatted":    formatNumber(myPointsAt1000),
+				"my_points_formatted":    FormatNumber(myPointsAt1000),
 				"total_points_raw":       totalPointsAt1000,
-				"total_points_formatted": formatNumber(totalPointsAt1000),
+				"total_points_formatted": FormatNumber(totalPointsAt1000),
 			},
 			"at_10000_division": map[string]interface{}{
 				"my_points_raw":          myPointsAt10000,
-				"my_points_formatted":    formatNumber(myPointsAt10000),
+				"my_points_formatted":    FormatNumber(myPointsAt10000),
 				"total_points_raw":       totalPointsAt10000,
-				"total_points_formatted": formatNumber(totalPointsAt10000),
+				"total_points_formatted": FormatNumber(totalPointsAt10000),
 			},
 			"at_100000_division": map[string]interface{}{
 				"my_points_raw":          myPointsAt100000,
-				"my_points_formatted":    formatNumber(myPointsAt100000),
+				"my_points_formatted":    FormatNumber(myPointsAt100000),
 				"total_points_raw":       totalPointsAt100000,
-				"total_points_formatted": formatNumber(totalPointsAt100000),
+				"total_points_formatted": FormatNumber(totalPointsAt100000),
 			},
 		},
 		"efficiency_calculation": map[string]interface{}{
-			"vastai_efficiency":         vastaiEfficiency,
-			"kuzco_efficiency":          kuzcoEfficiency,
-			"vastai_efficiency_integer": int(vastaiEfficiency),
-			"kuzco_efficiency_integer":  int(kuzcoEfficiency),
+			"vastai_efficiency":           vastaiEfficiency,
+			"kuzco_efficiency":            kuzcoEfficiency,
+			"vastai_efficiency_integer":   int(vastaiEfficiency), // 기존 클라이언트 호환용 (소수점 버림)
+			"kuzco_efficiency_integer":    int(kuzcoEfficiency),
+			"vastai_efficiency_formatted": FormatEfficiency(vastaiEfficiency),
+			"kuzco_efficiency_formatted":  FormatEfficiency(kuzcoEfficiency),
 		},
 		"sample_messages": map[string]interface{}{
-			"at_1000_division": fmt.Sprintf("포인트 : %s | %s\n비중 : %.1f%%\n비용(vast,kuzco) : $%.2f | $%.2f\n1%% 효율(vast,kuzco) : $%d | $%d",
-				formatNumber(myPointsAt1000),
-				formatNumber(totalPointsAt1000),
+			"at_1000_division": fmt.Sprintf("포인트 : %s | %s\n비중 : %.1f%%\n비용(vast,kuzco) : $%.2f | $%.2f\n1%% 효율(vast,kuzco) : $%s | $%s",
+				FormatNumber(myPointsAt1000),
+				FormatNumber(totalPointsAt1000),
 				metrics.User.Share*100,
 				metrics.User.VastaiDailyCost,
 				metrics.User.KuzcoDailyCost,
-				int(vastaiEfficiency),
-				int(kuzcoEfficiency)),
-			"at_10000_division": fmt.Sprintf("포인트 : %s | %s\n비중 : %.1f%%\n비용(vast,kuzco) : $%.2f | $%.2f\n1%% 효율(vast,kuzco) : $%d | $%d",
-				formatNumber(myPointsAt10000),
-				formatNumber(totalPointsAt10000),
+				FormatEfficiency(vastaiEfficiency),
+				FormatEfficiency(kuzcoEfficiency)),
+			"at_10000_division": fmt.Sprintf("포인트 : %s | %s\n비중 : %.1f%%\n비용(vast,kuzco) : $%.2f | $%.2f\n1%% 효율(vast,kuzco) : $%s | $%s",
+				FormatNumber(myPointsAt10000),
+				FormatNumber(totalPointsAt10000),
 				metrics.User.Share*100,
 				metrics.User.VastaiDailyCost,
 				metrics.User.KuzcoDailyCost,
-				int(vastaiEfficiency),
-				int(kuzcoEfficiency)),
+				FormatEfficiency(vastaiEfficiency),
+				FormatEfficiency(kuzcoEfficiency)),
 		},
 	}
 
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestQueryWorkers(t *testing.T) {
+	workers := []WorkerMinuteMetrics{
+		{Name: "c", TokensPerInstance: 20, DailyCost: 3},
+		{Name: "a", TokensPerInstance: 30, DailyCost: 1},
+		{Name: "b", TokensPerInstance: 10, DailyCost: 2},
+	}
+	names := func(ws []WorkerMinuteMetrics) string {
+		s := ""
+		for _, w := range ws {
+			s += w.Name
+		}
+		return s
+	}
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"", "cab"},
+		{"sort=name", "abc"},
+		{"sort=tokensPerInstance&order=desc", "acb"},
+		{"sort=dailyCost&order=asc", "abc"},
+		{"sort=name&limit=2", "ab"},
+		{"sort=name&offset=1&limit=1", "b"},
+		{"offset=5", ""},
+		{"limit=0", ""},
+	}
+	for _, tt := range tests {
+		query, _ := url.ParseQuery(tt.query)
+		got, err := queryWorkers(workers, query)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tt.query, err)
+			continue
+		}
+		if names(got) != tt.want {
+			t.Errorf("%q: expected %q, got %q", tt.query, tt.want, names(got))
+		}
+	}
+	if names(workers) != "cab" {
+		t.Errorf("Expected the original slice to stay unsorted, got %q", names(workers))
+	}
+
+	for _, invalid := range []string{"sort=rpm", "sort=name&order=up", "limit=-1", "offset=x"} {
+		query, _ := url.ParseQuery(invalid)
+		if _, err := queryWorkers(workers, query); err == nil {
+			t.Errorf("%q: expected error", invalid)
+		}
+	}
+}
+
+func TestHandleReport(t *testing.T) {
+	triggered := make(chan string, 1)
+	s := NewMetricsServer("")
+	s.SetAuthToken("secret")
+	s.SetReportTrigger(func(kind string) error {
+		triggered <- kind
+		return nil
+	})
+	handler := s.requireAuth(s.handleReport)
+
+	tests := []struct {
+		method string
+		target string
+		token  string
+		want   int
+	}{
+		{http.MethodPost, "/api/report?type=daily", "", http.StatusUnauthorized},
+		{http.MethodPost, "/api/report?type=daily", "wrong", http.StatusUnauthorized},
+		{http.MethodGet, "/api/report?type=daily", "secret", http.StatusMethodNotAllowed},
+		{http.MethodPost, "/api/report?type=weekly", "secret", http.StatusBadRequest},
+		{http.MethodPost, "/api/report?type=hourly&token=secret", "", http.StatusAccepted},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.target, nil)
+		if tt.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+		}
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("%s %s (token %q): expected %d, got %d", tt.method, tt.target, tt.token, tt.want, rec.Code)
+		}
+	}
+	if kind := <-triggered; kind != ReportHourly {
+		t.Errorf("expected the hourly report to be triggered, got %q", kind)
+	}
+	select {
+	case kind := <-triggered:
+		t.Errorf("expected only one report to be triggered, got another %q", kind)
+	default:
+	}
+}
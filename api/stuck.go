@@ -0,0 +1,188 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// DefaultStuckInitializingMinutes는 인스턴스가 Initializing 상태에 머물면 알림을 보내는 기본 시간(분)입니다
+const DefaultStuckInitializingMinutes = 20
+
+// initializingInstance는 Initializing 상태로 관측된 인스턴스입니다
+type initializingInstance struct {
+	key      string // instanceKey
+	worker   string
+	ip       string
+	since    time.Time // Initializing으로 처음 관측된 시각
+	alerted  bool      // 현재 Initializing 구간에 대해 stuck 알림을 보냈는지 (재부팅하면 초기화)
+	notified bool      // stuck 알림을 한 번이라도 보냈는지 (벗어나면 복구 안내)
+}
+
+// stuckInitializingThreshold는 설정된 Initializing 허용 시간을 반환하며, 설정되지 않았으면 기본값을 사용합니다
+func (c AlertConfig) stuckInitializingThreshold() time.Duration {
+	minutes := c.StuckInitializingMinutes
+	if minutes <= 0 {
+		minutes = DefaultStuckInitializingMinutes
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// instanceKey는 인스턴스를 구분하는 키입니다
+// 목록 순서는 다른 인스턴스가 생기거나 사라질 때마다 바뀌므로 Kuzco 인스턴스 ID, IP, lane 순으로 안정적인 값을 사용하며,
+// 셋 다 없으면 빈 문자열을 반환합니다
+func instanceKey(workerID string, inst InstanceMetrics) string {
+	switch {
+	case inst.ID != "":
+		return workerID + "/id:" + inst.ID
+	case inst.IP != "":
+		return workerID + "/" + inst.IP
+	case inst.Lane != "":
+		return workerID + "/lane:" + inst.Lane
+	}
+	return ""
+}
+
+// trackInitializing은 인스턴스별 Initializing 지속 시간을 갱신합니다
+// threshold를 처음 넘긴 인스턴스(stuck), 알림 후 Initializing에서 벗어난 인스턴스(recovered)와
+// 알림 후 목록에서 사라진 인스턴스(vanished)를 반환합니다
+func (m *Client) trackInitializing(workers []WorkerMinuteMetrics, now time.Time, threshold time.Duration) (stuck, recovered, vanished []initializingInstance) {
+	if m.initializing == nil {
+		m.initializing = make(map[string]*initializingInstance)
+	}
+
+	present := make(map[string]bool)
+	seen := make(map[string]bool)
+	for _, worker := range workers {
+		for _, inst := range worker.Instances {
+			key := instanceKey(worker.ID, inst)
+			if key == "" {
+				// 구분할 수 없는 인스턴스는 다른 인스턴스와 시간이 섞이지 않도록 추적하지 않음
+				continue
+			}
+			present[key] = true
+			if !strings.EqualFold(inst.Status, "initializing") {
+				continue
+			}
+			seen[key] = true
+
+			tracked, ok := m.initializing[key]
+			if !ok {
+				tracked = &initializingInstance{key: key, worker: worker.Name, ip: inst.IP, since: now}
+				m.initializing[key] = tracked
+			}
+			if !tracked.alerted && now.Sub(tracked.since) >= threshold {
+				tracked.alerted = true
+				tracked.notified = true
+				stuck = append(stuck, *tracked)
+			}
+		}
+	}
+
+	for key, tracked := range m.initializing {
+		if seen[key] {
+			continue
+		}
+		if tracked.notified {
+			if present[key] {
+				recovered = append(recovered, *tracked)
+			} else {
+				vanished = append(vanished, *tracked)
+			}
+		}
+		delete(m.initializing, key)
+	}
+	return stuck, recovered, vanished
+}
+
+// restartInitializing은 재부팅한 인스턴스의 Initializing 시작 시각을 초기화하여 다시 허용 시간을 줍니다
+// 이미 stuck 알림을 보냈으므로 notified는 유지하여 Initializing에서 벗어나면 복구를 안내합니다
+func (m *Client) restartInitializing(key string, now time.Time) {
+	if tracked, ok := m.initializing[key]; ok {
+		tracked.since = now
+		tracked.alerted = false
+	}
+}
+
+// checkStuckInitializing은 설정된 시간 이상 Initializing 상태인 인스턴스를 알리고,
+// Vast.ai 인스턴스를 찾으면 재부팅 명령어를 안내하거나 autoRebootStuck과 autoReboot(features.autoReboot)가 모두 켜져 있으면 바로 재부팅합니다
+func (m *Client) checkStuckInitializing(mm *MinuteMetrics, config AlertConfig, vastaiToken string, autoReboot bool, sendAlert func(string, string) error) error {
+	if !config.Enabled {
+		return nil
+	}
+
+	now := time.Now()
+	stuck, recovered, vanished := m.trackInitializing(mm.User.Workers, now, config.stuckInitializingThreshold())
+
+	if len(stuck) > 0 {
+		// Kuzco 인스턴스와 Vast.ai 인스턴스는 IP로 연결
+		var vastaiClient *VastaiClient
+		vastaiIDs := make(map[string]int)
+		if vastaiToken != "" {
+			vastaiClient = m.newVastaiClient(vastaiToken)
+			instances, err := vastaiClient.GetInstances()
+			if err != nil {
+				log.Printf("Failed to get vastai instances for stuck instances: %v", err)
+			}
+			for _, inst := range instances {
+				vastaiIDs[inst.PublicIP] = inst.ID
+			}
+		}
+
+		lines := make([]string, 0, len(stuck))
+		for _, inst := range stuck {
+			line := fmt.Sprintf("%s %s: Initializing for %s", inst.worker, inst.ip, now.Sub(inst.since).Round(time.Minute))
+			id, ok := vastaiIDs[inst.ip]
+			switch {
+			case ok && InstanceIgnored(id):
+				line += fmt.Sprintf(" (#%d ignored)", id)
+			case ok && config.AutoRebootStuck && autoReboot:
+				if err := vastaiClient.RebootInstance(id); err != nil {
+					line += fmt.Sprintf(" (reboot #%d failed: %v)", id, err)
+				} else {
+					log.Printf("Rebooted instance %d stuck in Initializing (%s %s)", id, inst.worker, inst.ip)
+					line += fmt.Sprintf(" (rebooted #%d)", id)
+					m.restartInitializing(inst.key, now)
+				}
+			case ok:
+				line += fmt.Sprintf(" (/restart %d)", id)
+			}
+			lines = append(lines, line)
+		}
+
+		title := "⏳ Stuck Initializing Alert"
+		msg := strings.Join(lines, "\n")
+		message := fmt.Sprintf("%s\n%s", title, CodeBlock(msg))
+		if err := sendAlert(message, "status"); err != nil {
+			return fmt.Errorf("failed to send stuck initializing alert: %w", err)
+		}
+	}
+
+	if len(recovered) > 0 {
+		lines := make([]string, 0, len(recovered))
+		for _, inst := range recovered {
+			lines = append(lines, fmt.Sprintf("%s %s: left Initializing after %s", inst.worker, inst.ip, now.Sub(inst.since).Round(time.Minute)))
+		}
+		title := "✅ Stuck Instances Recovered"
+		message := fmt.Sprintf("%s\n%s", title, CodeBlock(strings.Join(lines, "\n")))
+		if err := sendAlert(message, "status"); err != nil {
+			return fmt.Errorf("failed to send stuck initializing recovery alert: %w", err)
+		}
+	}
+
+	// 목록에서 사라진 인스턴스는 복구된 것이 아니므로 따로 알림
+	if len(vanished) > 0 {
+		lines := make([]string, 0, len(vanished))
+		for _, inst := range vanished {
+			lines = append(lines, fmt.Sprintf("%s %s: disappeared after %s in Initializing", inst.worker, inst.ip, now.Sub(inst.since).Round(time.Minute)))
+		}
+		title := "ℹ️ Stuck Instances Removed"
+		message := fmt.Sprintf("%s\n%s", title, CodeBlock(strings.Join(lines, "\n")))
+		if err := sendAlert(message, "status"); err != nil {
+			return fmt.Errorf("failed to send stuck initializing removal alert: %w", err)
+		}
+	}
+
+	return nil
+}
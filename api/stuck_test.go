@@ -0,0 +1,124 @@
+package api
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTrackInitializing(t *testing.T) {
+	client := NewClient()
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	threshold := 20 * time.Minute
+
+	workers := []WorkerMinuteMetrics{{
+		ID:   "w1",
+		Name: "worker1",
+		Instances: []InstanceMetrics{
+			{IP: "10.0.0.1", Status: "Initializing"},
+			{IP: "10.0.0.2", Status: "Running"},
+		},
+	}}
+
+	if stuck, _, _ := client.trackInitializing(workers, start, threshold); len(stuck) != 0 {
+		t.Fatalf("expected no stuck instance at first sight, got %+v", stuck)
+	}
+	stuck, _, _ := client.trackInitializing(workers, start.Add(threshold), threshold)
+	if len(stuck) != 1 || stuck[0].ip != "10.0.0.1" {
+		t.Fatalf("expected 10.0.0.1 to be stuck, got %+v", stuck)
+	}
+	// 이미 알린 인스턴스는 다시 알리지 않음
+	if stuck, _, _ := client.trackInitializing(workers, start.Add(threshold+time.Minute), threshold); len(stuck) != 0 {
+		t.Errorf("expected no repeated alert, got %+v", stuck)
+	}
+
+	// Running이 되면 복구로 보고하고 추적을 끝냄
+	workers[0].Instances[0].Status = "Running"
+	_, recovered, _ := client.trackInitializing(workers, start.Add(30*time.Minute), threshold)
+	if len(recovered) != 1 || recovered[0].ip != "10.0.0.1" {
+		t.Errorf("expected 10.0.0.1 to be recovered, got %+v", recovered)
+	}
+	if len(client.initializing) != 0 {
+		t.Errorf("expected tracking to be cleared, got %+v", client.initializing)
+	}
+}
+
+func TestTrackInitializingStableKeys(t *testing.T) {
+	client := NewClient()
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	threshold := 20 * time.Minute
+
+	workers := []WorkerMinuteMetrics{{
+		ID:        "w1",
+		Name:      "worker1",
+		Instances: []InstanceMetrics{{ID: "i2", Status: "Initializing"}},
+	}}
+	client.trackInitializing(workers, start, threshold)
+
+	// 앞에 새 인스턴스가 생겨 순서가 바뀌어도 기존 인스턴스의 시작 시각을 유지
+	workers[0].Instances = []InstanceMetrics{{ID: "i1", Status: "Initializing"}, {ID: "i2", Status: "Initializing"}}
+	stuck, _, _ := client.trackInitializing(workers, start.Add(threshold), threshold)
+	if len(stuck) != 1 || stuck[0].key != "w1/id:i2" {
+		t.Fatalf("expected only i2 to be stuck, got %+v", stuck)
+	}
+}
+
+func TestTrackInitializingVanishedAndRebooted(t *testing.T) {
+	client := NewClient()
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	threshold := 20 * time.Minute
+
+	workers := []WorkerMinuteMetrics{{
+		ID:   "w1",
+		Name: "worker1",
+		Instances: []InstanceMetrics{
+			{ID: "i1", Status: "Initializing"},
+			{ID: "i2", Status: "Initializing"},
+		},
+	}}
+	client.trackInitializing(workers, start, threshold)
+	if stuck, _, _ := client.trackInitializing(workers, start.Add(threshold), threshold); len(stuck) != 2 {
+		t.Fatalf("expected both instances to be stuck, got %+v", stuck)
+	}
+
+	// 재부팅한 인스턴스도 Initializing에서 벗어나면 복구로 보고
+	client.restartInitializing("w1/id:i1", start.Add(threshold))
+	workers[0].Instances = []InstanceMetrics{{ID: "i1", Status: "Running"}}
+	_, recovered, vanished := client.trackInitializing(workers, start.Add(25*time.Minute), threshold)
+	if len(recovered) != 1 || recovered[0].key != "w1/id:i1" {
+		t.Errorf("expected the rebooted i1 to be recovered, got %+v", recovered)
+	}
+	if len(vanished) != 1 || vanished[0].key != "w1/id:i2" {
+		t.Errorf("expected i2 to be reported as vanished, got %+v", vanished)
+	}
+}
+
+func TestCheckStuckInitializingWithoutVastai(t *testing.T) {
+	client := NewClient()
+	client.initializing = map[string]*initializingInstance{
+		"w1/10.0.0.1": {key: "w1/10.0.0.1", worker: "worker1", ip: "10.0.0.1", since: time.Now().Add(-25 * time.Minute)},
+	}
+
+	var alerts []string
+	sendAlert := func(message, alertType string) error {
+		alerts = append(alerts, message)
+		return nil
+	}
+
+	var mm MinuteMetrics
+	mm.User.Workers = []WorkerMinuteMetrics{{
+		ID:        "w1",
+		Name:      "worker1",
+		Instances: []InstanceMetrics{{IP: "10.0.0.1", Status: "Initializing"}},
+	}}
+
+	if err := client.checkStuckInitializing(&mm, AlertConfig{Enabled: true}, "", true, sendAlert); err != nil {
+		t.Fatal(err)
+	}
+	if len(alerts) != 1 || !strings.Contains(alerts[0], "Stuck Initializing Alert") || !strings.Contains(alerts[0], "worker1 10.0.0.1") {
+		t.Fatalf("expected a stuck initializing alert, got %v", alerts)
+	}
+	if strings.Contains(alerts[0], "/restart") {
+		t.Errorf("expected no reboot hint without a Vast.ai instance, got %s", alerts[0])
+	}
+}
@@ -0,0 +1,136 @@
+package api
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Align은 표 열의 정렬 방향입니다
+type Align int
+
+const (
+	AlignLeft Align = iota
+	AlignRight
+)
+
+// Table은 고정폭 글꼴(코드 블록) 보고서용 표입니다
+// 열 너비는 헤더와 셀 내용 중 가장 넓은 값으로 계산하며, 한글/CJK 같은 전각 문자는 2칸으로 셉니다
+type Table struct {
+	headers []string
+	aligns  []Align
+	rows    [][]string
+}
+
+// NewTable은 주어진 헤더로 모든 열이 왼쪽 정렬된 표를 만듭니다
+func NewTable(headers ...string) *Table {
+	return &Table{
+		headers: headers,
+		aligns:  make([]Align, len(headers)),
+	}
+}
+
+// SetAlign은 col번째 열(0부터)의 정렬 방향을 설정합니다
+func (t *Table) SetAlign(col int, align Align) *Table {
+	if col >= 0 && col < len(t.aligns) {
+		t.aligns[col] = align
+	}
+	return t
+}
+
+// AddRow는 행을 추가하며, 헤더보다 적은 셀은 빈 칸으로 채우고 많은 셀은 버립니다
+func (t *Table) AddRow(cells ...string) {
+	row := make([]string, len(t.headers))
+	copy(row, cells)
+	t.rows = append(t.rows, row)
+}
+
+// Len은 추가된 행 수입니다
+func (t *Table) Len() int {
+	return len(t.rows)
+}
+
+// Lines는 헤더, 구분선, 각 행을 순서대로 렌더링합니다 (코드 블록 구분자는 포함하지 않음)
+func (t *Table) Lines() []string {
+	widths := make([]int, len(t.headers))
+	for i, header := range t.headers {
+		widths[i] = DisplayWidth(header)
+	}
+	for _, row := range t.rows {
+		for i, cell := range row {
+			if w := DisplayWidth(cell); w > widths[i] {
+				widths[i] = w
+			}
+		}
+	}
+
+	separators := make([]string, len(widths))
+	for i, w := range widths {
+		separators[i] = strings.Repeat("-", w)
+	}
+
+	lines := make([]string, 0, len(t.rows)+2)
+	lines = append(lines, t.renderRow(t.headers, widths))
+	lines = append(lines, strings.Join(separators, "-+-"))
+	for _, row := range t.rows {
+		lines = append(lines, t.renderRow(row, widths))
+	}
+	return lines
+}
+
+// String은 표 전체를 코드 블록으로 감싸 반환합니다
+func (t *Table) String() string {
+	return CodeBlock(strings.Join(t.Lines(), "\n"))
+}
+
+// renderRow는 열 너비에 맞춰 셀을 정렬하고 " | "로 잇습니다 (마지막 열의 오른쪽 공백은 제거)
+func (t *Table) renderRow(cells []string, widths []int) string {
+	padded := make([]string, len(cells))
+	for i, cell := range cells {
+		pad := strings.Repeat(" ", widths[i]-DisplayWidth(cell))
+		if t.aligns[i] == AlignRight {
+			padded[i] = pad + cell
+		} else {
+			padded[i] = cell + pad
+		}
+	}
+	return strings.TrimRight(strings.Join(padded, " | "), " ")
+}
+
+// DisplayWidth는 고정폭 글꼴에서 문자열이 차지하는 칸 수입니다
+// 전각 문자(한글, CJK, 전각 기호, 대부분의 이모지)는 2칸, 결합 문자는 0칸으로 셉니다
+func DisplayWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		switch {
+		case unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Me, r) || r == '\u200d' || (r >= '\ufe00' && r <= '\ufe0f'):
+			// 결합 문자, ZWJ, 이형 선택자
+		case isWideRune(r):
+			width += 2
+		default:
+			width++
+		}
+	}
+	return width
+}
+
+// isWideRune은 East Asian Wide/Fullwidth 범위와 이모지 범위의 문자인지 반환합니다
+func isWideRune(r rune) bool {
+	switch {
+	case r >= 0x1100 && r <= 0x115f, // 한글 자모
+		r >= 0x2e80 && r <= 0x303e,   // CJK 부수, 기호
+		r >= 0x3041 && r <= 0x33ff,   // 히라가나, 가타카나, CJK 호환
+		r >= 0x3400 && r <= 0x4dbf,   // CJK 확장 A
+		r >= 0x4e00 && r <= 0x9fff,   // CJK 통합 한자
+		r >= 0xa960 && r <= 0xa97f,   // 한글 자모 확장 A
+		r >= 0xac00 && r <= 0xd7a3,   // 한글 음절
+		r >= 0xf900 && r <= 0xfaff,   // CJK 호환 한자
+		r >= 0xfe30 && r <= 0xfe4f,   // CJK 호환 형태
+		r >= 0xff00 && r <= 0xff60,   // 전각 ASCII
+		r >= 0xffe0 && r <= 0xffe6,   // 전각 기호
+		r >= 0x1f300 && r <= 0x1f6ff, // 기호, 이모티콘, 교통 기호
+		r >= 0x1f900 && r <= 0x1f9ff, // 보충 기호, 이모티콘
+		r >= 0x20000 && r <= 0x3fffd: // CJK 확장 B 이후
+		return true
+	}
+	return false
+}
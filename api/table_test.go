@@ -0,0 +1,84 @@
+package api
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDisplayWidth(t *testing.T) {
+	tests := []struct {
+		input string
+		want  int
+	}{
+		{"worker1", 7},
+		{"워커", 4},
+		{"GPU서버-01", 10},
+		{"ｗｏｒｋ", 8},
+		{"🚀node", 6},
+		{"café", 4},
+		{"", 0},
+	}
+	for _, tt := range tests {
+		if got := DisplayWidth(tt.input); got != tt.want {
+			t.Errorf("DisplayWidth(%q) = %d, want %d", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestTableAlignsColumns(t *testing.T) {
+	table := NewTable("R", "Worker", "Tokens")
+	table.SetAlign(0, AlignRight).SetAlign(2, AlignRight)
+	table.AddRow("1", "a", "1.23M")
+	table.AddRow("10", "very-long-worker-name", "5")
+	table.AddRow("3", "한글워커", "900K")
+	table.AddRow("4", "🚀rocket")
+
+	lines := table.Lines()
+	if len(lines) != 6 {
+		t.Fatalf("expected header, separator and 4 rows, got %d lines:\n%s", len(lines), strings.Join(lines, "\n"))
+	}
+
+	// 모든 셀이 채워진 행은 구분자 " | "가 헤더와 같은 표시 위치에 있어야 함
+	want := separatorColumns(lines[0])
+	for _, line := range lines[2:5] {
+		if got := separatorColumns(line); !equalInts(got, want) {
+			t.Errorf("misaligned row %q: separators at %v, want %v", line, got, want)
+		}
+	}
+	if lines[1] != "---+-----------------------+-------" {
+		t.Errorf("unexpected separator line %q", lines[1])
+	}
+	if lines[2] != " 1 | a                     |  1.23M" {
+		t.Errorf("unexpected right/left alignment %q", lines[2])
+	}
+	if lines[4] != " 3 | 한글워커              |   900K" {
+		t.Errorf("unexpected wide character padding %q", lines[4])
+	}
+	// 빈 마지막 셀은 오른쪽 공백 없이 끝남
+	if strings.HasSuffix(lines[5], " ") {
+		t.Errorf("expected trailing spaces to be trimmed, got %q", lines[5])
+	}
+}
+
+// separatorColumns는 줄에서 " | "가 시작하는 표시 위치 목록입니다
+func separatorColumns(line string) []int {
+	var columns []int
+	for i := range line {
+		if strings.HasPrefix(line[i:], " | ") {
+			columns = append(columns, DisplayWidth(line[:i]))
+		}
+	}
+	return columns
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
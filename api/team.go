@@ -0,0 +1,154 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Team은 하나의 로그인으로 접근하는 Kuzco 워커 팀입니다
+type Team struct {
+	ID   string `yaml:"id" json:"id"`
+	Name string `yaml:"name" json:"name"` // 보고서에 표시할 이름, 비어 있으면 ID 사용
+}
+
+// Label은 보고서에 표시할 팀 이름을 반환합니다
+func (t Team) Label() string {
+	if t.Name != "" {
+		return t.Name
+	}
+	return t.ID
+}
+
+// TeamMetrics는 팀별로 수집한 메트릭스입니다 (kuzco.teams를 설정한 경우에만 채워짐)
+type TeamMetrics struct {
+	ID                     string  `json:"id"`
+	Name                   string  `json:"name"`
+	TokensLast24Hours      int64   `json:"tokensLast24Hours"`
+	TokensAllTime          int64   `json:"tokensAllTime"`
+	GenerationsLast24Hours int     `json:"generationsLast24Hours"`
+	GenerationLastHour     int     `json:"generationLastHour"`
+	Instances              int     `json:"instances"`
+	Share                  float64 `json:"share"`
+}
+
+// SetTeams sets the worker teams whose metrics GetAllMetrics collects and sums.
+// Without teams, the user ID returned by Login is used as the only team
+func (c *Client) SetTeams(teams []Team) {
+	c.teams = append([]Team(nil), teams...)
+}
+
+// mergeHistory는 두 팀의 시간별 기록을 같은 시각(date)끼리 add로 더하고 최신 시각부터 정렬합니다
+// 호출하는 쪽은 API와 같이 [0]을 최근 1시간으로 사용하므로 팀마다 시각이 달라도 순서를 유지해야 합니다
+func mergeHistory[T any](total, history []T, date func(T) string, add func(T, T) T) []T {
+	index := make(map[string]int, len(total))
+	for i, h := range total {
+		index[date(h)] = i
+	}
+	for _, h := range history {
+		if i, ok := index[date(h)]; ok {
+			total[i] = add(total[i], h)
+			continue
+		}
+		index[date(h)] = len(total)
+		total = append(total, h)
+	}
+	sort.SliceStable(total, func(i, j int) bool { return historyNewer(date(total[i]), date(total[j])) })
+	return total
+}
+
+// mergeGenerationsHistory는 두 팀의 생성량 기록을 합칩니다 (mergeHistory)
+func mergeGenerationsHistory(total, history []GenerationHistory) []GenerationHistory {
+	return mergeHistory(total, history,
+		func(h GenerationHistory) string { return h.Date },
+		func(a, b GenerationHistory) GenerationHistory { a.Value += b.Value; return a })
+}
+
+// mergeTokenEarningsHistory는 두 팀의 토큰 적립 기록을 합칩니다 (mergeHistory)
+func mergeTokenEarningsHistory(total, history []TokenEarningsHistory) []TokenEarningsHistory {
+	return mergeHistory(total, history,
+		func(h TokenEarningsHistory) string { return h.Date },
+		func(a, b TokenEarningsHistory) TokenEarningsHistory { a.Value += b.Value; return a })
+}
+
+// historyNewer는 기록 시각 a가 b보다 최신인지 반환합니다 (RFC3339가 아니면 문자열로 비교)
+func historyNewer(a, b string) bool {
+	ta, errA := time.Parse(time.RFC3339, a)
+	tb, errB := time.Parse(time.RFC3339, b)
+	if errA != nil || errB != nil {
+		return a > b
+	}
+	return ta.After(tb)
+}
+
+// teamsOrUser는 설정된 팀을, 없으면 로그인 사용자 ID를 유일한 팀으로 반환합니다
+func (c *KuzcoClient) teamsOrUser(userID string) []Team {
+	if len(c.Teams) == 0 {
+		return []Team{{ID: userID}}
+	}
+	return c.Teams
+}
+
+// teamError는 팀이 설정된 경우 어느 팀에서 실패했는지 오류에 붙입니다
+func (c *KuzcoClient) teamError(team Team, err error) error {
+	if len(c.Teams) == 0 {
+		return err
+	}
+	return fmt.Errorf("team %s: %w", team.Label(), err)
+}
+
+// GetTeamsGenerationsHistory sums the generations history of every configured team, newest first.
+// Teams that fail are skipped and their errors joined, so the history of the other teams is still returned
+func (c *KuzcoClient) GetTeamsGenerationsHistory(userID string, hoursBack int) ([]GenerationHistory, error) {
+	var total []GenerationHistory
+	var errs []error
+	for _, team := range c.teamsOrUser(userID) {
+		history, err := c.GetUserGenerationsHistory(team.ID, hoursBack)
+		if err != nil {
+			errs = append(errs, c.teamError(team, err))
+			continue
+		}
+		total = mergeGenerationsHistory(total, history)
+	}
+	return total, errors.Join(errs...)
+}
+
+// GetTeamsTokenEarningsHistory sums the hourly token earnings of every configured team, newest first.
+// Teams that fail are skipped and their errors joined, so the earnings of the other teams are still returned
+func (c *KuzcoClient) GetTeamsTokenEarningsHistory(userID string, hoursBack int) ([]TokenEarningsHistory, error) {
+	var total []TokenEarningsHistory
+	var errs []error
+	for _, team := range c.teamsOrUser(userID) {
+		history, err := c.GetUserTokenEarningsHistory(team.ID, hoursBack)
+		if err != nil {
+			errs = append(errs, c.teamError(team, err))
+			continue
+		}
+		total = mergeTokenEarningsHistory(total, history)
+	}
+	return total, errors.Join(errs...)
+}
+
+// teamWorkers는 설정된 팀에 속한 워커만 남기고 팀별 인스턴스 수를 채웁니다
+// 팀 ID가 없는 워커는 구분할 수 없으므로 그대로 둡니다
+func teamWorkers(workers []Worker, teams []TeamMetrics) []Worker {
+	index := make(map[string]int, len(teams))
+	for i, team := range teams {
+		index[team.ID] = i
+	}
+	filtered := workers[:0]
+	for _, w := range workers {
+		if w.TeamID == "" {
+			filtered = append(filtered, w)
+			continue
+		}
+		i, ok := index[w.TeamID]
+		if !ok {
+			continue
+		}
+		teams[i].Instances += w.InstanceCount
+		filtered = append(filtered, w)
+	}
+	return filtered
+}
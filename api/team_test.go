@@ -0,0 +1,36 @@
+package api
+
+import "testing"
+
+func TestMergeHistory(t *testing.T) {
+	type sample struct {
+		date  string
+		value int
+	}
+	date := func(s sample) string { return s.date }
+	add := func(a, b sample) sample { a.value += b.value; return a }
+
+	total := mergeHistory(nil, []sample{
+		{"2025-01-01T10:00:00Z", 1},
+		{"2025-01-01T11:00:00Z", 2},
+	}, date, add)
+	total = mergeHistory(total, []sample{
+		{"2025-01-01T12:00:00Z", 5},
+		{"2025-01-01T11:00:00Z", 3},
+	}, date, add)
+
+	// 같은 시각은 더하고, 팀마다 시각이 달라도 최신 시각부터 정렬
+	expected := []sample{
+		{"2025-01-01T12:00:00Z", 5},
+		{"2025-01-01T11:00:00Z", 5},
+		{"2025-01-01T10:00:00Z", 1},
+	}
+	if len(total) != len(expected) {
+		t.Fatalf("expected %d entries, got %+v", len(expected), total)
+	}
+	for i := range expected {
+		if total[i] != expected[i] {
+			t.Errorf("entry %d: expected %+v, got %+v", i, expected[i], total[i])
+		}
+	}
+}
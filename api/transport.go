@@ -0,0 +1,66 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// DefaultUserAgent는 kuzco.userAgent가 설정되지 않았을 때 Kuzco API 요청에 사용하는 User-Agent입니다
+const DefaultUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/133.0.0.0 Safari/537.36"
+
+// 모든 Kuzco/Vast.ai 클라이언트가 공유하는 transport입니다
+// 클라이언트는 수집 주기마다 새로 만들어지므로, 공유하지 않으면 keep-alive 연결을 재사용하지 못하고 유휴 연결이 쌓입니다
+var (
+	transport      = buildHTTPTransport(nil)
+	transportMutex sync.RWMutex
+)
+
+// ParseProxyURL은 프록시 URL을 검증하여 파싱합니다
+func ParseProxyURL(raw string) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL %q: %w", raw, err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL %q: scheme and host are required", raw)
+	}
+	return u, nil
+}
+
+// SetHTTPProxy는 이후 생성되는 Kuzco/Vast.ai 클라이언트가 사용할 프록시를 설정하고 공유 transport를 새로 만듭니다
+// 빈 문자열이면 환경 변수(HTTP_PROXY 등) 기반 기본 프록시 설정으로 되돌립니다
+func SetHTTPProxy(raw string) error {
+	var u *url.URL
+	if raw != "" {
+		parsed, err := ParseProxyURL(raw)
+		if err != nil {
+			return err
+		}
+		u = parsed
+	}
+
+	transportMutex.Lock()
+	old := transport
+	transport = buildHTTPTransport(u)
+	transportMutex.Unlock()
+	old.CloseIdleConnections()
+	return nil
+}
+
+// buildHTTPTransport는 proxy를 적용한 transport를 생성하며, proxy가 nil이면 환경 변수를 따릅니다
+func buildHTTPTransport(proxy *url.URL) *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	if proxy != nil {
+		t.Proxy = http.ProxyURL(proxy)
+	}
+	return t
+}
+
+// sharedHTTPTransport는 클라이언트가 사용할 공유 transport를 반환합니다
+func sharedHTTPTransport() *http.Transport {
+	transportMutex.RLock()
+	defer transportMutex.RUnlock()
+	return transport
+}
@@ -0,0 +1,29 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestClientsShareHTTPTransport(t *testing.T) {
+	defer SetHTTPProxy("")
+
+	client := NewClient()
+	vastai := NewVastaiClient("token")
+	shared := client.httpClient.Transport
+	if vastai.httpClient.Transport != shared || vastai.logHTTPClient.Transport != shared {
+		t.Fatal("expected all clients to share one transport")
+	}
+
+	if err := SetHTTPProxy("http://proxy.example:8080"); err != nil {
+		t.Fatal(err)
+	}
+	rebuilt := NewClient().httpClient.Transport.(*http.Transport)
+	if rebuilt == shared {
+		t.Fatal("expected SetHTTPProxy to rebuild the shared transport")
+	}
+	req, _ := http.NewRequest("GET", "https://relay.kuzco.xyz", nil)
+	if u, err := rebuilt.Proxy(req); err != nil || u == nil || u.Host != "proxy.example:8080" {
+		t.Errorf("expected the configured proxy, got %v (%v)", u, err)
+	}
+}
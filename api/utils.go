@@ -7,12 +7,45 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
 )
 
 const (
-	DiskCostPerDay = 0.006 * 24 // $0.01 per hour * 24 hours
+	// DiskCostPerDay는 instance.json에 디스크 비용이 없을 때 사용하는 기본 일일 디스크 비용입니다
+	DiskCostPerDay = 0.006 * 24 // $0.006 per hour * 24 hours
 )
 
+// reportLocation은 사용자에게 보여지는 시각에 사용할 타임존입니다
+var reportLocation = time.Local
+
+// SetReportLocation sets the timezone used for user-facing timestamps. A nil location is ignored
+func SetReportLocation(loc *time.Location) {
+	if loc != nil {
+		reportLocation = loc
+	}
+}
+
+// ReportTime converts t to the configured reporting timezone
+func ReportTime(t time.Time) time.Time {
+	return t.In(reportLocation)
+}
+
+// compareVersions가 반환하는 불일치 방향
+const (
+	VersionNewer = "newer"
+	VersionOlder = "older"
+)
+
+// versionDirection은 compareVersions의 설명 문자열에서 불일치 방향을 추출합니다
+func versionDirection(diff string) string {
+	if strings.HasPrefix(diff, VersionNewer) {
+		return VersionNewer
+	}
+	return VersionOlder
+}
+
 // Add this helper function to parse and compare versions
 func compareVersions(v1, v2 string) (bool, string) {
 	// Extract version numbers (e.g., "0.2.3" from "0.2.3-fe4d73f")
@@ -33,9 +66,9 @@ func compareVersions(v1, v2 string) (bool, string) {
 
 		if n1 != n2 {
 			if n1 > n2 {
-				return true, fmt.Sprintf("newer (%s > %s)", v1Parts, v2Parts)
+				return true, fmt.Sprintf("%s (%s > %s)", VersionNewer, v1Parts, v2Parts)
 			} else {
-				return true, fmt.Sprintf("older (%s < %s)", v1Parts, v2Parts)
+				return true, fmt.Sprintf("%s (%s < %s)", VersionOlder, v1Parts, v2Parts)
 			}
 		}
 	}
@@ -185,8 +218,16 @@ func PrintPrettierJson(data interface{}) {
 }
 
 type GPUInstance struct {
-	Gpu   string  `json:"Gpu"`
-	Price float64 `json:"Price"`
+	Gpu            string   `json:"Gpu"`
+	Price          float64  `json:"Price"`
+	DiskCostPerDay *float64 `json:"DiskCostPerDay,omitempty"` // GPU별 일일 디스크 비용 (선택)
+}
+
+// GPUPriceFile은 instance.json의 객체 형식입니다
+// 기존 배열 형식([{"Gpu": ..., "Price": ...}])도 계속 지원합니다
+type GPUPriceFile struct {
+	DiskCostPerDay *float64      `json:"DiskCostPerDay,omitempty"` // 전체 기본 일일 디스크 비용 (선택)
+	GPUs           []GPUInstance `json:"GPUs"`
 }
 
 func LoadGPUPrices(path string) (map[string]float64, error) {
@@ -195,21 +236,91 @@ func LoadGPUPrices(path string) (map[string]float64, error) {
 		return nil, fmt.Errorf("error reading GPU prices file: %w", err)
 	}
 
-	var instances []GPUInstance
-	if err := json.Unmarshal(file, &instances); err != nil {
+	var priceFile GPUPriceFile
+	if strings.HasPrefix(strings.TrimSpace(string(file)), "[") {
+		err = json.Unmarshal(file, &priceFile.GPUs)
+	} else {
+		err = json.Unmarshal(file, &priceFile)
+	}
+	if err != nil {
 		return nil, fmt.Errorf("error parsing GPU prices: %w", err)
 	}
+	if err := validateGPUInstances(priceFile.GPUs); err != nil {
+		return nil, fmt.Errorf("invalid GPU prices in %s: %w", path, err)
+	}
+
+	diskCost := DiskCostPerDay
+	if priceFile.DiskCostPerDay != nil {
+		diskCost = *priceFile.DiskCostPerDay
+	}
 
 	// Create a map for easier lookup
 	prices := make(map[string]float64)
-	for _, inst := range instances {
-		// GPU cost per day + disk cost per day
-		prices[inst.Gpu] = (inst.Price * 24) + DiskCostPerDay
+	for _, inst := range priceFile.GPUs {
+		// GPU cost per day + disk cost per day (GPU별 설정이 우선)
+		instDiskCost := diskCost
+		if inst.DiskCostPerDay != nil {
+			instDiskCost = *inst.DiskCostPerDay
+		}
+		prices[inst.Gpu] = (inst.Price * 24) + instDiskCost
 	}
 
 	return prices, nil
 }
 
+// validateGPUInstances는 필드 이름이 틀렸거나 값이 잘못된 항목이 조용히 $0 비용이 되지 않도록 검사합니다
+func validateGPUInstances(gpus []GPUInstance) error {
+	if len(gpus) == 0 {
+		return fmt.Errorf("no GPU entries found (expected a list of {\"Gpu\": ..., \"Price\": ...})")
+	}
+	for i, inst := range gpus {
+		if strings.TrimSpace(inst.Gpu) == "" {
+			return fmt.Errorf("entry %d: missing \"Gpu\" name (price %v)", i, inst.Price)
+		}
+		if inst.Price < 0 {
+			return fmt.Errorf("entry %d (%s): \"Price\" must not be negative, got %v", i, inst.Gpu, inst.Price)
+		}
+		if inst.DiskCostPerDay != nil && *inst.DiskCostPerDay < 0 {
+			return fmt.Errorf("entry %d (%s): \"DiskCostPerDay\" must not be negative, got %v", i, inst.Gpu, *inst.DiskCostPerDay)
+		}
+	}
+	return nil
+}
+
+// warnedUnpricedGPUs는 가격 경고를 이미 로그로 남긴 GPU 모델입니다
+var (
+	warnedUnpricedGPUs     = make(map[string]bool)
+	warnedUnpricedGPUsLock sync.Mutex
+)
+
+// warnUnpricedGPU는 instance.json에 가격이 없는 GPU 모델을 모델별로 한 번만 로그로 남깁니다
+func warnUnpricedGPU(gpuModel string) {
+	warnedUnpricedGPUsLock.Lock()
+	defer warnedUnpricedGPUsLock.Unlock()
+	if warnedUnpricedGPUs[gpuModel] {
+		return
+	}
+	warnedUnpricedGPUs[gpuModel] = true
+	log.Printf("[WARN] GPU model %q has no price in instance.json; its daily cost is counted as $0", gpuModel)
+}
+
+// UnpricedGPUModels는 가격이 없는 GPU 모델별 인스턴스 수를 반환합니다
+// GPU 정보가 없는 인스턴스는 제외합니다
+func UnpricedGPUModels(workers []WorkerMinuteMetrics, prices map[string]float64) map[string]int {
+	unpriced := make(map[string]int)
+	for _, w := range workers {
+		for _, inst := range w.Instances {
+			if inst.GPUModel == "" {
+				continue
+			}
+			if _, ok := prices[inst.GPUModel]; !ok {
+				unpriced[inst.GPUModel]++
+			}
+		}
+	}
+	return unpriced
+}
+
 func normalizeGPUName(gpuName string) string {
 	// Remove "NVIDIA GeForce " or "NVIDIA " prefix
 	gpuName = strings.TrimPrefix(gpuName, "NVIDIA GeForce ")
@@ -241,3 +352,91 @@ func normalizeGPUName(gpuName string) string {
 func CodeBlock(text string) string {
 	return fmt.Sprintf("```\n%s\n```", text)
 }
+
+// Severity is how a chat sink (Slack, Discord) presents an alert, typically as a color
+type Severity int
+
+const (
+	SeverityInfo     Severity = iota // reports and other messages
+	SeverityError                    // "error" alerts
+	SeverityStatus                   // "status" alerts
+	SeverityRecovery                 // recovery notices of any alert type
+)
+
+// AlertSeverity classifies a message sent with alertType
+// Recovery messages (starting with ✅) are SeverityRecovery regardless of alert type
+func AlertSeverity(message, alertType string) Severity {
+	if strings.HasPrefix(message, "✅") {
+		return SeverityRecovery
+	}
+	switch alertType {
+	case "error":
+		return SeverityError
+	case "status":
+		return SeverityStatus
+	default:
+		return SeverityInfo
+	}
+}
+
+// SplitMessage splits text into parts of at most limit bytes for chat services with a message size limit
+// (Telegram, Discord). Parts break between lines; a code block cut by a split is closed at the end of
+// the part and reopened in the next one, and a line that does not fit in a part on its own is cut
+func SplitMessage(text string, limit int) []string {
+	if len(text) <= limit {
+		return []string{text}
+	}
+
+	const fence = "```"
+	// 닫는 구분자("\n```")와 다시 여는 구분자("```\n")를 위한 여유분
+	room := limit - 2*(len(fence)+1)
+	maxLine := room - (len(fence) + 1)
+
+	var parts []string
+	var part strings.Builder
+	lines := 0
+	inCode := false
+	flush := func() {
+		s := strings.TrimRight(part.String(), "\n")
+		if inCode {
+			s += "\n" + fence
+		}
+		parts = append(parts, s)
+		part.Reset()
+		lines = 0
+		if inCode {
+			part.WriteString(fence + "\n")
+		}
+	}
+	write := func(line string) {
+		// 코드 블록을 닫는 줄은 닫는 구분자 여유분에 들어가므로 나누지 않음
+		closing := inCode && strings.HasPrefix(strings.TrimSpace(line), fence)
+		if lines > 0 && !closing && part.Len()+len(line)+1 > room {
+			flush()
+		}
+		part.WriteString(line)
+		part.WriteString("\n")
+		lines++
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		// 한 줄이 너무 길면 UTF-8 문자 경계에서 자름
+		for len(line) > maxLine {
+			cut := maxLine
+			for cut > 0 && !utf8.RuneStart(line[cut]) {
+				cut--
+			}
+			write(line[:cut])
+			line = line[cut:]
+		}
+		write(line)
+		if strings.HasPrefix(strings.TrimSpace(line), fence) {
+			inCode = !inCode
+		}
+	}
+	if lines > 0 {
+		inCode = false
+		flush()
+	}
+	return parts
+}
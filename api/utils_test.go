@@ -0,0 +1,194 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func writeGPUPrices(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "instance.json")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadGPUPrices(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    map[string]float64
+	}{
+		{
+			name:    "legacy array uses default disk cost",
+			content: `[{"Gpu": "RTX 3090", "Price": 0.2}]`,
+			want:    map[string]float64{"RTX 3090": 0.2*24 + DiskCostPerDay},
+		},
+		{
+			name:    "object with top-level disk cost",
+			content: `{"DiskCostPerDay": 0.5, "GPUs": [{"Gpu": "RTX 3090", "Price": 0.2}]}`,
+			want:    map[string]float64{"RTX 3090": 0.2*24 + 0.5},
+		},
+		{
+			name: "per-GPU override wins over top-level",
+			content: `{"DiskCostPerDay": 0.5, "GPUs": [
+				{"Gpu": "RTX 3090", "Price": 0.2},
+				{"Gpu": "RTX 4090", "Price": 0.3, "DiskCostPerDay": 1}
+			]}`,
+			want: map[string]float64{"RTX 3090": 0.2*24 + 0.5, "RTX 4090": 0.3*24 + 1},
+		},
+		{
+			name:    "object without disk cost uses default",
+			content: `{"GPUs": [{"Gpu": "RTX 3090", "Price": 0.2}]}`,
+			want:    map[string]float64{"RTX 3090": 0.2*24 + DiskCostPerDay},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prices, err := LoadGPUPrices(writeGPUPrices(t, tt.content))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(prices) != len(tt.want) {
+				t.Fatalf("Expected %d prices, got %v", len(tt.want), prices)
+			}
+			for gpu, want := range tt.want {
+				if math.Abs(prices[gpu]-want) > 1e-9 {
+					t.Errorf("%s: expected %.4f, got %.4f", gpu, want, prices[gpu])
+				}
+			}
+		})
+	}
+}
+
+func TestLoadGPUPricesInvalid(t *testing.T) {
+	if _, err := LoadGPUPrices(writeGPUPrices(t, `{"GPUs": "not a list"}`)); err == nil {
+		t.Error("Expected error for invalid GPU price file")
+	}
+}
+
+func TestLoadGPUPricesMalformedEntries(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "empty list",
+			content: `[]`,
+			want:    "no GPU entries found",
+		},
+		{
+			name:    "wrong field names",
+			content: `{"GPUs": [{"Gpu": "RTX 3090", "Price": 0.2}, {"model": "RTX 4090", "cost": 0.3}]}`,
+			want:    `entry 1: missing "Gpu" name`,
+		},
+		{
+			name:    "negative price",
+			content: `[{"Gpu": "RTX 3090", "Price": -0.2}]`,
+			want:    `entry 0 (RTX 3090): "Price" must not be negative`,
+		},
+		{
+			name:    "non-numeric price",
+			content: `[{"Gpu": "RTX 3090", "Price": "cheap"}]`,
+			want:    "error parsing GPU prices",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadGPUPrices(writeGPUPrices(t, tt.content))
+			if err == nil {
+				t.Fatal("Expected error for malformed GPU price file")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected error containing %q, got %q", tt.want, err.Error())
+			}
+		})
+	}
+}
+
+func TestUnpricedGPUModels(t *testing.T) {
+	workers := []WorkerMinuteMetrics{
+		{Name: "a", Instances: []InstanceMetrics{{GPUModel: "RTX 4090"}, {GPUModel: "RTX 5090"}}},
+		{Name: "b", Instances: []InstanceMetrics{{GPUModel: "RTX 5090"}, {GPUModel: ""}}},
+	}
+	prices := map[string]float64{"RTX 4090": 10}
+
+	unpriced := UnpricedGPUModels(workers, prices)
+	if len(unpriced) != 1 || unpriced["RTX 5090"] != 2 {
+		t.Errorf("expected only RTX 5090 with 2 instances, got %v", unpriced)
+	}
+}
+
+func TestAlertSeverity(t *testing.T) {
+	tests := []struct {
+		message   string
+		alertType string
+		expected  Severity
+	}{
+		{"⚠️ Version Mismatch Alert", "error", SeverityError},
+		{"⚠️ Credit Alert", "status", SeverityStatus},
+		{"✅ Credit Balance Recovered", "status", SeverityRecovery},
+		{"✅ Version Mismatch Resolved", "error", SeverityRecovery},
+		{"2025-01-01 report", "daily", SeverityInfo},
+	}
+
+	for _, tt := range tests {
+		if got := AlertSeverity(tt.message, tt.alertType); got != tt.expected {
+			t.Errorf("AlertSeverity(%q, %q) = %d, expected %d", tt.message, tt.alertType, got, tt.expected)
+		}
+	}
+}
+
+func TestSplitMessage(t *testing.T) {
+	if parts := SplitMessage("short", 100); len(parts) != 1 || parts[0] != "short" {
+		t.Fatalf("expected a short message unchanged, got %q", parts)
+	}
+
+	var lines []string
+	for i := 0; i < 30; i++ {
+		lines = append(lines, fmt.Sprintf("worker-%02d | 1.23K", i))
+	}
+	text := "⚠️ Title\n" + CodeBlock(strings.Join(lines, "\n")) + "\nfooter"
+	parts := SplitMessage(text, 200)
+	if len(parts) < 2 {
+		t.Fatalf("expected the message to be split, got %q", parts)
+	}
+	var rejoined []string
+	for i, part := range parts {
+		if len(part) > 200 {
+			t.Errorf("part %d is %d bytes, over the limit", i, len(part))
+		}
+		if strings.Count(part, "```")%2 != 0 {
+			t.Errorf("part %d has an unbalanced code block:\n%s", i, part)
+		}
+		for _, line := range strings.Split(part, "\n") {
+			if line != "```" {
+				rejoined = append(rejoined, line)
+			}
+		}
+	}
+	if !strings.HasPrefix(parts[0], "⚠️ Title\n```") || !strings.HasSuffix(parts[len(parts)-1], "```\nfooter") {
+		t.Errorf("unexpected first/last part:\n%s\n---\n%s", parts[0], parts[len(parts)-1])
+	}
+	expected := append(append([]string{"⚠️ Title"}, lines...), "footer")
+	if strings.Join(rejoined, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("lines lost or reordered across parts:\n%s", strings.Join(rejoined, "\n"))
+	}
+
+	// 한 줄이 너무 길면 문자 경계에서 자름
+	long := strings.Repeat("가", 100)
+	for i, part := range SplitMessage(long, 64) {
+		if len(part) > 64 || !utf8.ValidString(part) {
+			t.Errorf("part %d is invalid or over the limit: %q", i, part)
+		}
+	}
+}
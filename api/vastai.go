@@ -1,22 +1,109 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log"
+	"math"
+	"math/rand"
 	"net/http"
 	"net/url"
+	"regexp"
+	"runtime/debug"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
+// DefaultRebootLogPattern is the log line that indicates a stuck heartbeat
+const DefaultRebootLogPattern = `Failed to send heartbeat: TimeoutError: timeout`
+
+// DefaultRebootConsecutiveMinutes is how many consecutive minutes of timeouts trigger a reboot
+const DefaultRebootConsecutiveMinutes = 3
+
+// DefaultLogDownloadTimeoutSeconds is the timeout for downloading instance logs from the temporary URL
+const DefaultLogDownloadTimeoutSeconds = 20
+
+// DefaultLogWaitMaxSeconds is how long to poll the temporary log URL before giving up on an instance's logs
+const DefaultLogWaitMaxSeconds = 30
+
+// logWaitInitialDelay and logWaitMaxDelay bound the backoff between polls of the temporary log URL
+const (
+	logWaitInitialDelay = time.Second
+	logWaitMaxDelay     = 8 * time.Second
+)
+
+// DefaultLogCheckConcurrency is how many instances' logs are checked at the same time
+const DefaultLogCheckConcurrency = 4
+
+// DefaultRebootAlertCooldownMinutes is how long repeated reboot failures of the same instance are not alerted again
+const DefaultRebootAlertCooldownMinutes = 30
+
+// MonitoringConfig configures how instance logs are checked for reboot
+type MonitoringConfig struct {
+	RebootLogPattern           string `json:"rebootLogPattern" yaml:"rebootLogPattern"`                     // 재부팅 대상 로그 정규식
+	RebootConsecutiveMinutes   int    `json:"rebootConsecutiveMinutes" yaml:"rebootConsecutiveMinutes"`     // 연속 감지 시간(분)
+	AlertStateFile             string `json:"alertStateFile" yaml:"alertStateFile"`                         // 알림 상태 저장 파일, 기본값 data/alert_state.json
+	EventBufferSize            int    `json:"eventBufferSize" yaml:"eventBufferSize"`                       // /api/events에 보관할 워커 변경 이벤트 수, 기본값 200
+	LogBufferLines             int    `json:"logBufferLines" yaml:"logBufferLines"`                         // /api/logs에 보관할 최근 로그 줄 수, 기본값 500
+	RebootAlertCooldownMinutes int    `json:"rebootAlertCooldownMinutes" yaml:"rebootAlertCooldownMinutes"` // 같은 인스턴스의 재부팅 실패 알림 재전송 대기 시간(분), 기본값 30
+	LogDownloadTimeoutSeconds  int    `json:"logDownloadTimeoutSeconds" yaml:"logDownloadTimeoutSeconds"`   // 인스턴스 로그 다운로드 타임아웃(초), 기본값 20
+	LogCheckConcurrency        int    `json:"logCheckConcurrency" yaml:"logCheckConcurrency"`               // 동시에 로그를 확인할 인스턴스 수, 기본값 4
+	LogWaitMaxSeconds          int    `json:"logWaitMaxSeconds" yaml:"logWaitMaxSeconds"`                   // 로그 요청 후 로그가 준비될 때까지 기다리는 최대 시간(초), 기본값 30
+	CircuitFailureThreshold    int    `json:"circuitFailureThreshold" yaml:"circuitFailureThreshold"`       // 메트릭스 수집 연속 실패 시 degraded 모드로 전환할 횟수, 기본값 5
+	CircuitOpenMinutes         int    `json:"circuitOpenMinutes" yaml:"circuitOpenMinutes"`                 // degraded 모드에서 수집을 다시 시도하는 간격(분), 기본값 5
+	GenerationsHistoryHours    int    `json:"generationsHistoryHours" yaml:"generationsHistoryHours"`       // 분 단위 수집 시 조회하는 생성량 기록 기간(시간), 기본값 2, 최대 168
+}
+
+// AlertStatePath returns the configured alert state file, falling back to the default
+func (m MonitoringConfig) AlertStatePath() string {
+	if m.AlertStateFile == "" {
+		return DefaultAlertStateFile
+	}
+	return m.AlertStateFile
+}
+
+// CompileRebootLogPattern compiles the configured pattern, falling back to the default
+func (m MonitoringConfig) CompileRebootLogPattern() (*regexp.Regexp, error) {
+	pattern := m.RebootLogPattern
+	if pattern == "" {
+		pattern = regexp.QuoteMeta(DefaultRebootLogPattern)
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rebootLogPattern %q: %w", pattern, err)
+	}
+	return re, nil
+}
+
+// Cost sources for GetDailyCost
+const (
+	CostSourceComputed = "computed" // quantity * rate per charge
+	CostSourceAmount   = "amount"   // pre-computed amount per charge
+)
+
 // VastaiClient handles Vast.ai API interactions
 type VastaiClient struct {
-	baseURL    string
-	httpClient *http.Client
-	token      string
+	baseURL            string
+	httpClient         *http.Client
+	token              string
+	rebootLogPattern   *regexp.Regexp
+	consecutiveMinutes int
+	costSource         string
+
+	logHTTPClient       *http.Client // 로그 다운로드 전용 (API 요청과 별도 타임아웃)
+	logCheckConcurrency int
+	logWaitMax          time.Duration // 로그 요청 후 임시 URL을 다시 확인하는 최대 시간
+	logWaitInitialDelay time.Duration // 첫 확인 전 대기 시간, 이후 확인마다 두 배씩 늘어남
+
+	rebootAlertCooldown     time.Duration
+	lastRebootFailureAlerts map[int]time.Time // 인스턴스별 마지막 재부팅 실패 알림 시각
+	lastRebootCount         int               // 가장 최근 모니터링 사이클에서 재부팅한 인스턴스 수
+	alertOnly               bool              // true면 타임아웃이 감지되어도 재부팅하지 않고 알림만 보냄 (SetAutoReboot)
+	accountName             string            // 실행 중 인스턴스 수를 확인할 계정의 시간별 통계 (SetAccountName)
 }
 
 // VastaiCharge represents a billing charge from Vast.ai
@@ -37,13 +124,28 @@ type VastaiErrorResponse struct {
 	Message string `json:"msg"`
 }
 
+// VastaiInstance represents a single instance returned by the Vast.ai instances API
+type VastaiInstance struct {
+	ActualStatus string  `json:"actual_status"`
+	ID           int     `json:"id"`
+	PublicIP     string  `json:"public_ipaddr"`
+	GPUName      string  `json:"gpu_name"`
+	DPHTotal     float64 `json:"dph_total"`
+}
+
+// HourlyBurnRate returns the current hourly cost of the given instances by summing dph_total
+func HourlyBurnRate(instances []VastaiInstance) float64 {
+	total := 0.0
+	for _, inst := range instances {
+		total += inst.DPHTotal
+	}
+	return total
+}
+
 // VastaiInstancesResponse represents the response from Vast.ai instances API
 type VastaiInstancesResponse struct {
-	InstancesFound int `json:"instances_found"`
-	Instances      []struct {
-		ActualStatus string `json:"actual_status"`
-		ID           int    `json:"id"`
-	} `json:"instances"`
+	InstancesFound int              `json:"instances_found"`
+	Instances      []VastaiInstance `json:"instances"`
 }
 
 // VastaiCreditResponse represents the credit information from Vast.ai
@@ -73,20 +175,115 @@ type LogResponse struct {
 // NewVastaiClient creates a new Vast.ai client
 func NewVastaiClient(token string) *VastaiClient {
 	return &VastaiClient{
-		baseURL:    VastaiAPI,
-		httpClient: &http.Client{Timeout: 10 * time.Second},
-		token:      token,
+		baseURL:            VastaiAPI,
+		httpClient:         &http.Client{Timeout: 10 * time.Second, Transport: sharedHTTPTransport()},
+		token:              token,
+		rebootLogPattern:   regexp.MustCompile(regexp.QuoteMeta(DefaultRebootLogPattern)),
+		consecutiveMinutes: DefaultRebootConsecutiveMinutes,
+		costSource:         CostSourceComputed,
+
+		logHTTPClient:       &http.Client{Timeout: DefaultLogDownloadTimeoutSeconds * time.Second, Transport: sharedHTTPTransport()},
+		logCheckConcurrency: DefaultLogCheckConcurrency,
+		logWaitMax:          DefaultLogWaitMaxSeconds * time.Second,
+		logWaitInitialDelay: logWaitInitialDelay,
+
+		rebootAlertCooldown:     DefaultRebootAlertCooldownMinutes * time.Minute,
+		lastRebootFailureAlerts: make(map[int]time.Time),
 	}
 }
 
+// SetCostSource selects how GetDailyCost sums charges (amount or computed)
+func (c *VastaiClient) SetCostSource(source string) {
+	if source == CostSourceAmount || source == CostSourceComputed {
+		c.costSource = source
+	}
+}
+
+// SetMonitoringConfig applies the log pattern and window used by CheckInstanceLogs
+func (c *VastaiClient) SetMonitoringConfig(cfg MonitoringConfig) error {
+	re, err := cfg.CompileRebootLogPattern()
+	if err != nil {
+		return err
+	}
+	c.rebootLogPattern = re
+	if cfg.RebootConsecutiveMinutes > 0 {
+		c.consecutiveMinutes = cfg.RebootConsecutiveMinutes
+	}
+	if cfg.RebootAlertCooldownMinutes > 0 {
+		c.rebootAlertCooldown = time.Duration(cfg.RebootAlertCooldownMinutes) * time.Minute
+	}
+	if cfg.LogDownloadTimeoutSeconds > 0 {
+		c.logHTTPClient.Timeout = time.Duration(cfg.LogDownloadTimeoutSeconds) * time.Second
+	}
+	if cfg.LogCheckConcurrency > 0 {
+		c.logCheckConcurrency = cfg.LogCheckConcurrency
+	}
+	if cfg.LogWaitMaxSeconds > 0 {
+		c.logWaitMax = time.Duration(cfg.LogWaitMaxSeconds) * time.Second
+	}
+	return nil
+}
+
+// do sends a Vast.ai API request and records its latency in GlobalRequestMetrics
+// Status codes of 400 and above are counted as errors
+func (c *VastaiClient) do(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := c.httpClient.Do(req)
+	failed := err != nil || resp.StatusCode >= http.StatusBadRequest
+	GlobalRequestMetrics.Record(requestEndpoint("vastai", req.Method, req.URL.String()), time.Since(start), failed)
+	return resp, err
+}
+
+// YesterdayUTC returns 00:00 UTC of the previous day, the day GetDailyCost reports
+func YesterdayUTC(now time.Time) time.Time {
+	now = now.UTC()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, -1)
+}
+
 // GetDailyCost retrieves the daily cost from Vast.ai for the previous day (UTC)
 func (c *VastaiClient) GetDailyCost() (float64, error) {
-	// Calculate yesterday's UTC time start and end timestamps
-	now := time.Now().UTC()
-	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
-	yesterday := today.AddDate(0, 0, -1)
-	startOfDay := yesterday // yesterday 00:00:00
-	endOfDay := today       // today 00:00:00
+	charges, err := c.GetCharges(YesterdayUTC(time.Now()))
+	if err != nil {
+		return 0, err
+	}
+
+	// Calculate total cost both ways
+	var computedCost, amountCost float64
+	for _, charge := range charges {
+		computedCost += charge.ComputedCost()
+
+		amount, err := strconv.ParseFloat(charge.Amount, 64)
+		if err != nil {
+			log.Printf("Failed to parse charge amount %q for instance %d: %v", charge.Amount, charge.InstanceID, err)
+			continue
+		}
+		amountCost += amount
+	}
+
+	// Warn if the two methods diverge by more than a cent
+	if math.Abs(computedCost-amountCost) > 0.01 {
+		log.Printf("Warning: Vast.ai daily cost mismatch (computed: $%.4f, amount: $%.4f, using: %s)",
+			computedCost, amountCost, c.costSource)
+	}
+
+	if c.costSource == CostSourceAmount {
+		return amountCost, nil
+	}
+	return computedCost, nil
+}
+
+// ComputedCost returns quantity × rate of the charge, treating unparsable values as zero
+func (c VastaiCharge) ComputedCost() float64 {
+	quantity, _ := strconv.ParseFloat(c.Quantity, 64)
+	rate, _ := strconv.ParseFloat(c.Rate, 64)
+	return quantity * rate
+}
+
+// GetCharges retrieves the individual Vast.ai charges of the UTC day starting at day (00:00 UTC to 00:00 UTC the next day)
+func (c *VastaiClient) GetCharges(day time.Time) ([]VastaiCharge, error) {
+	day = day.UTC()
+	startOfDay := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	endOfDay := startOfDay.AddDate(0, 0, 1)
 
 	// Create filter JSON
 	selectFilters := fmt.Sprintf(`{"when":{"gte":%d,"lte":%d},"service":{"in":["paypal","paypal_manual","crypto.com","coinbase","stripe_connect","stripe_payments","stripe","wise_manual","instance_prepay","transfer"]},"type":{"in":["charge"]},"amount_cents":{}}`,
@@ -98,7 +295,7 @@ func (c *VastaiClient) GetDailyCost() (float64, error) {
 	// Create request
 	req, err := http.NewRequest("GET", fullURL, nil)
 	if err != nil {
-		return 0, fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Set headers
@@ -107,44 +304,35 @@ func (c *VastaiClient) GetDailyCost() (float64, error) {
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.token))
 
 	// Execute request
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
-		return 0, fmt.Errorf("request failed: %w", err)
+		return nil, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	// Read response body
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return 0, fmt.Errorf("failed to read response: %w", err)
+		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
 	// Handle error response
 	if resp.StatusCode != http.StatusOK {
 		var errResp VastaiErrorResponse
 		if jsonErr := json.Unmarshal(body, &errResp); jsonErr == nil {
-			return 0, fmt.Errorf("API error (HTTP %d): %s - %s",
+			return nil, fmt.Errorf("API error (HTTP %d): %s - %s",
 				resp.StatusCode, errResp.Error, errResp.Message)
 		}
-		return 0, fmt.Errorf("request failed with status %d: %s",
+		return nil, fmt.Errorf("request failed with status %d: %s",
 			resp.StatusCode, string(body))
 	}
 
 	// Parse response
 	var charges []VastaiCharge
 	if err := json.Unmarshal(body, &charges); err != nil {
-		return 0, fmt.Errorf("failed to parse response: %w", err)
-	}
-
-	// Calculate total cost
-	var totalCost float64
-	for _, charge := range charges {
-		quantity, _ := strconv.ParseFloat(charge.Quantity, 64)
-		rate, _ := strconv.ParseFloat(charge.Rate, 64)
-		totalCost += quantity * rate
+		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
-
-	return totalCost, nil
+	return charges, nil
 }
 
 // GetInstanceCount retrieves the number of instances from Vast.ai
@@ -160,7 +348,7 @@ func (c *VastaiClient) GetInstanceCount() (int, error) {
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.token))
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return 0, fmt.Errorf("request failed: %w", err)
 	}
@@ -196,7 +384,7 @@ func (c *VastaiClient) GetCredit() (*VastaiCredit, error) {
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.token))
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
@@ -241,7 +429,7 @@ func (c *VastaiClient) RequestInstanceLogs(instanceID int) (*LogResponse, error)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.token))
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
@@ -264,41 +452,40 @@ func (c *VastaiClient) RequestInstanceLogs(instanceID int) (*LogResponse, error)
 	return &logResp, nil
 }
 
-// CheckInstanceLogs checks if the instance logs contain the heartbeat timeout error
-// Returns true if heartbeat timeouts are detected continuously for 3 minutes
+// CheckInstanceLogs checks if the instance logs match the configured reboot pattern
+// Returns true if matches are detected in every minute of the configured window
 func (c *VastaiClient) CheckInstanceLogs(url string) (bool, error) {
-	resp, err := http.Get(url)
-	if err != nil {
-		return false, fmt.Errorf("failed to get logs: %w", err)
-	}
-	defer resp.Body.Close()
+	return c.CheckInstanceLogsContext(context.Background(), url)
+}
 
-	body, err := io.ReadAll(resp.Body)
+// CheckInstanceLogsContext is CheckInstanceLogs with a context that can cancel the log download
+func (c *VastaiClient) CheckInstanceLogsContext(ctx context.Context, url string) (bool, error) {
+	body, err := c.DownloadInstanceLogsContext(ctx, url)
 	if err != nil {
-		return false, fmt.Errorf("failed to read logs: %w", err)
+		return false, err
 	}
+	return c.hasConsecutiveTimeouts(body), nil
+}
 
+// hasConsecutiveTimeouts reports whether the logs match the reboot pattern in every minute of the configured window
+func (c *VastaiClient) hasConsecutiveTimeouts(body string) bool {
 	// Split logs into lines and check for timeout patterns
-	lines := strings.Split(string(body), "\n")
+	lines := strings.Split(body, "\n")
 
-	// Maps to track timeouts in each minute of the last 3 minutes
-	timeoutDetected := make(map[int]bool)
+	// Track timeouts in each minute of the window
+	window := c.consecutiveMinutes
+	timeoutDetected := make([]bool, window)
 	now := time.Now()
 
-	// Initialize the last 3 minutes as not having timeouts
-	for i := 0; i < 3; i++ {
-		timeoutDetected[i] = false
-	}
-
 	for _, line := range lines {
-		if strings.Contains(line, "Failed to send heartbeat: TimeoutError: timeout") {
+		if c.rebootLogPattern.MatchString(line) {
 			// Parse the timestamp from the log line
 			if timestamp, err := parseLogTimestamp(line); err == nil {
 				// Calculate how many minutes ago this timeout occurred
 				minutesAgo := int(now.Sub(timestamp).Minutes())
 
-				// Only consider timeouts from the last 3 minutes
-				if minutesAgo >= 0 && minutesAgo < 3 {
+				// Only consider timeouts inside the window
+				if minutesAgo >= 0 && minutesAgo < window {
 					timeoutDetected[minutesAgo] = true
 					log.Printf("Detected heartbeat timeout from %d minutes ago: %s",
 						minutesAgo, timestamp.Format(time.RFC3339))
@@ -307,15 +494,87 @@ func (c *VastaiClient) CheckInstanceLogs(url string) (bool, error) {
 		}
 	}
 
-	// Check if we have timeouts in all 3 consecutive minutes
-	consecutiveTimeouts := timeoutDetected[0] && timeoutDetected[1] && timeoutDetected[2]
+	// Check if we have timeouts in every consecutive minute
+	for _, detected := range timeoutDetected {
+		if !detected {
+			return false
+		}
+	}
+
+	log.Printf("Detected heartbeat timeouts continuously for the last %d minutes", window)
+	return true
+}
+
+// WaitForInstanceLogs polls the temporary URL returned by RequestInstanceLogs until Vast.ai has uploaded the logs.
+// Polls back off exponentially with jitter, starting at one second, and give up after the configured
+// logWaitMaxSeconds with the last download error so that a slow upload is not mistaken for logs without timeouts
+func (c *VastaiClient) WaitForInstanceLogs(ctx context.Context, url string) (string, error) {
+	deadline := time.Now().Add(c.logWaitMax)
+	delay := c.logWaitInitialDelay
+	for attempt := 1; ; attempt++ {
+		// 여러 인스턴스가 동시에 같은 간격으로 요청하지 않도록 대기 시간에 최대 50%의 jitter를 더함
+		wait := delay + time.Duration(rand.Int63n(int64(delay)/2+1))
+		if remaining := time.Until(deadline); wait > remaining {
+			wait = remaining
+		}
+		if !sleepContext(ctx, wait) {
+			return "", ctx.Err()
+		}
+
+		body, err := c.DownloadInstanceLogsContext(ctx, url)
+		if err == nil && strings.TrimSpace(body) == "" {
+			err = fmt.Errorf("logs are empty")
+		}
+		if err == nil {
+			return body, nil
+		}
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		if !time.Now().Before(deadline) {
+			return "", fmt.Errorf("logs not available after %s (%d attempts): %w", c.logWaitMax, attempt, err)
+		}
+		delay = min(delay*2, logWaitMaxDelay)
+	}
+}
+
+// DownloadInstanceLogs downloads the log file from the temporary URL returned by RequestInstanceLogs
+func (c *VastaiClient) DownloadInstanceLogs(url string) (string, error) {
+	return c.DownloadInstanceLogsContext(context.Background(), url)
+}
 
-	if consecutiveTimeouts {
-		log.Printf("Detected heartbeat timeouts continuously for the last 3 minutes")
-		return true, nil
+// DownloadInstanceLogsContext downloads instance logs using the dedicated log client and its timeout
+func (c *VastaiClient) DownloadInstanceLogsContext(ctx context.Context, url string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
 	}
 
-	return false, nil
+	resp, err := c.logHTTPClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to get logs: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("log download failed with status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read logs: %w", err)
+	}
+
+	return string(body), nil
+}
+
+// TailLines returns the last n lines of text
+func TailLines(text string, n int) []string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines
 }
 
 // parseLogTimestamp parses the timestamp from a log line
@@ -345,7 +604,7 @@ func (c *VastaiClient) RebootInstance(instanceID int) error {
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.token))
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return fmt.Errorf("request failed: %w", err)
 	}
@@ -359,26 +618,97 @@ func (c *VastaiClient) RebootInstance(instanceID int) error {
 	return nil
 }
 
-// MonitorAndRebootInstances monitors all instances and reboots them if they have heartbeat timeout
-func (c *VastaiClient) MonitorAndRebootInstances(sendAlert func(string, string) error) error {
-	return c.StartContinuousMonitoring(sendAlert, false, nil)
+// findTimedOutInstances checks the logs of all instances concurrently (up to logCheckConcurrency at a time)
+// and returns the instances with a heartbeat timeout, in the original order
+func (c *VastaiClient) findTimedOutInstances(ctx context.Context, instances []VastaiInstance) []VastaiInstance {
+	concurrency := c.logCheckConcurrency
+	if concurrency <= 0 {
+		concurrency = DefaultLogCheckConcurrency
+	}
+
+	timedOut := make([]bool, len(instances))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, instance := range instances {
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			// A panic here would crash the whole process since the loop's recover does not cover
+			// this goroutine; treat the instance as not timed out instead
+			defer func() {
+				if r := recover(); r != nil {
+					log.Printf("[ERROR] Log check for instance %d panicked: %v\n%s", instance.ID, r, debug.Stack())
+				}
+			}()
+
+			// Request logs for the instance
+			log.Printf("Requesting logs for instance %d (status: %s)...", instance.ID, instance.ActualStatus)
+			logResp, err := c.RequestInstanceLogs(instance.ID)
+			if err != nil {
+				log.Printf("Failed to request logs for instance %d: %v", instance.ID, err)
+				return
+			}
+
+			// Wait until the logs are available and check if they contain heartbeat timeout
+			body, err := c.WaitForInstanceLogs(ctx, logResp.TempDownloadURL)
+			if err != nil {
+				log.Printf("Failed to check logs for instance %d: %v", instance.ID, err)
+				return
+			}
+			timedOut[i] = c.hasConsecutiveTimeouts(body)
+		}()
+	}
+	wg.Wait()
+
+	var result []VastaiInstance
+	for i, instance := range instances {
+		if timedOut[i] {
+			result = append(result, instance)
+		}
+	}
+	return result
+}
+
+// sleepContext waits for d and returns false if ctx is cancelled first
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// MonitorAndRebootInstances monitors all instances and reboots them if they have heartbeat timeout until ctx is cancelled
+func (c *VastaiClient) MonitorAndRebootInstances(ctx context.Context, sendAlert func(string, string) error) error {
+	return c.StartContinuousMonitoring(ctx, sendAlert, false)
 }
 
-// StartContinuousMonitoring continuously monitors instances for heartbeat timeouts and reboots them if necessary
+// StartContinuousMonitoring continuously monitors instances for heartbeat timeouts and reboots them if necessary.
+// It blocks until ctx is cancelled, which also aborts in-flight log waits without rebooting.
 // Set stopOnFirstExecution to true to run only once (useful for testing)
 func (c *VastaiClient) StartContinuousMonitoring(
+	ctx context.Context,
 	sendAlert func(string, string) error,
 	stopOnFirstExecution bool,
-	stopChan <-chan struct{},
 ) error {
 	log.Printf("Starting continuous instance monitoring...")
-	// Check every minute for timeout issues over a 3-minute period
+	// Check every minute for timeout issues over the configured window
 	monitoringInterval := 1 * time.Minute
 
 	// Function to check and reboot instances
 	checkAndReboot := func() error {
+		c.lastRebootCount = 0
+
 		// Check General.RunningInstanceCount first
-		generalMetrics := GlobalHourlyStats.GetStats()
+		generalMetrics := HourlyStatsFor(c.accountName).GetStats()
 		if generalMetrics.TotalInstances.Current == 0 {
 			log.Printf("General.RunningInstanceCount is 0, skipping monitoring")
 			return nil
@@ -389,59 +719,59 @@ func (c *VastaiClient) StartContinuousMonitoring(
 			return fmt.Errorf("failed to get instances: %w", err)
 		}
 
-		for _, instance := range instances {
-			// Request logs for the instance
-			log.Printf("Requesting logs for instance %d (status: %s)...", instance.ID, instance.ActualStatus)
-			logResp, err := c.RequestInstanceLogs(instance.ID)
-			if err != nil {
-				log.Printf("Failed to request logs for instance %d: %v", instance.ID, err)
+		timedOut := c.findTimedOutInstances(ctx, instances)
+		if ctx.Err() != nil {
+			// 로그 확인이 중단되었으므로 일부 결과로 재부팅하지 않음
+			return nil
+		}
+
+		var outcome rebootOutcome
+		for _, instance := range timedOut {
+			if InstanceIgnored(instance.ID) {
+				log.Printf("Heartbeat timeout detected on instance %d, skipping reboot (ignored via /ignore)", instance.ID)
 				continue
 			}
 
-			// Wait a few seconds for the logs to be available
-			time.Sleep(5 * time.Second)
-			// Check if logs contain heartbeat timeout
-			hasTimeout, err := c.CheckInstanceLogs(logResp.TempDownloadURL)
-			if err != nil {
-				log.Printf("Failed to check logs for instance %d: %v", instance.ID, err)
+			// Double check General.RunningInstanceCount before rebooting
+			currentMetrics := HourlyStatsFor(c.accountName).GetStats()
+			if currentMetrics.TotalInstances.Current == 0 {
+				log.Printf("General.RunningInstanceCount is 0, skipping reboot for instance %d", instance.ID)
+				outcome.Skipped = append(outcome.Skipped, instance.ID)
 				continue
 			}
 
-			if hasTimeout {
-				// Double check General.RunningInstanceCount before rebooting
-				currentMetrics := GlobalHourlyStats.GetStats()
-				if currentMetrics.TotalInstances.Current == 0 {
-					log.Printf("General.RunningInstanceCount is 0, skipping reboot for instance %d", instance.ID)
-					if sendAlert != nil {
-						message := fmt.Sprintf("⚠️ Reboot Skipped\nInstance ID: %d\n사유: General.RunningInstanceCount가 0입니다.", instance.ID)
-						if err := sendAlert(message, "error"); err != nil {
-							log.Printf("Failed to send skip alert: %v", err)
-						}
-					}
-					continue
+			if c.alertOnly {
+				log.Printf("Heartbeat timeout detected continuously for %d minutes on instance %d, auto reboot disabled", c.consecutiveMinutes, instance.ID)
+				if c.shouldAlertRebootFailure(instance.ID, time.Now()) {
+					outcome.Detected = append(outcome.Detected, instance.ID)
 				}
+				continue
+			}
 
-				log.Printf("Heartbeat timeout detected continuously for 3 minutes on instance %d, rebooting... (General.RunningInstanceCount: %d)",
-					instance.ID, currentMetrics.TotalInstances.Current)
-
-				if err := c.RebootInstance(instance.ID); err != nil {
-					log.Printf("Failed to reboot instance %d: %v", instance.ID, err)
-					if sendAlert != nil {
-						message := fmt.Sprintf("⚠️ Instance Reboot Failed\nInstance ID: %d\nError: %v", instance.ID, err)
-						if err := sendAlert(message, "error"); err != nil {
-							log.Printf("Failed to send reboot error alert: %v", err)
-						}
-					}
-					continue
-				}
-				log.Printf("Successfully rebooted instance %d", instance.ID)
-				if sendAlert != nil {
-					message := fmt.Sprintf("✅ Instance Reboot Success\nInstance ID: %d가 성공적으로 재시작되었습니다.\nGeneral.RunningInstanceCount: %d",
-						instance.ID, currentMetrics.TotalInstances.Current)
-					if err := sendAlert(message, "status"); err != nil {
-						log.Printf("Failed to send reboot success alert: %v", err)
-					}
+			log.Printf("Heartbeat timeout detected continuously for %d minutes on instance %d, rebooting... (General.RunningInstanceCount: %d)",
+				c.consecutiveMinutes, instance.ID, currentMetrics.TotalInstances.Current)
+
+			if err := c.RebootInstance(instance.ID); err != nil {
+				log.Printf("Failed to reboot instance %d: %v", instance.ID, err)
+				if c.shouldAlertRebootFailure(instance.ID, time.Now()) {
+					outcome.Failed = append(outcome.Failed, rebootFailure{InstanceID: instance.ID, Err: err})
+				} else {
+					log.Printf("Reboot failure alert for instance %d suppressed (cooldown %s)", instance.ID, c.rebootAlertCooldown)
 				}
+				continue
+			}
+			log.Printf("Successfully rebooted instance %d", instance.ID)
+			delete(c.lastRebootFailureAlerts, instance.ID)
+			outcome.Rebooted = append(outcome.Rebooted, instance.ID)
+			outcome.RunningInstances = currentMetrics.TotalInstances.Current
+		}
+
+		c.lastRebootCount = len(outcome.Rebooted)
+
+		// 한 사이클의 재부팅 결과를 하나의 요약 알림으로 전송
+		if message, alertType, ok := outcome.summary(); ok && sendAlert != nil {
+			if err := sendAlert(message, alertType); err != nil {
+				log.Printf("Failed to send reboot summary alert: %v", err)
 			}
 		}
 
@@ -453,37 +783,107 @@ func (c *VastaiClient) StartContinuousMonitoring(
 		return checkAndReboot()
 	}
 
-	// Start continuous monitoring in a goroutine
-	go func() {
-		for {
-			if err := checkAndReboot(); err != nil {
-				log.Printf("Error during instance monitoring: %v", err)
-			}
+	for {
+		if err := checkAndReboot(); err != nil {
+			log.Printf("Error during instance monitoring: %v", err)
+		}
 
-			// Check if we should stop monitoring
-			if stopChan != nil {
-				select {
-				case <-stopChan:
-					log.Printf("Stopping instance monitoring...")
-					return
-				case <-time.After(monitoringInterval):
-					// Continue to next iteration
-				}
-			} else {
-				// No stop channel provided, just sleep
-				time.Sleep(monitoringInterval)
-			}
+		if !sleepContext(ctx, monitoringInterval) {
+			log.Printf("Stopping instance monitoring...")
+			return nil
 		}
-	}()
+	}
+}
 
-	return nil
+// SetAccountName sets the account whose hourly stats StartContinuousMonitoring checks before rebooting
+func (c *VastaiClient) SetAccountName(name string) {
+	c.accountName = name
+}
+
+// SetAutoReboot controls whether StartContinuousMonitoring reboots timed-out instances (default)
+// or only reports them
+func (c *VastaiClient) SetAutoReboot(enabled bool) {
+	c.alertOnly = !enabled
+}
+
+// LastRebootCount returns how many instances were rebooted in the most recent monitoring cycle
+func (c *VastaiClient) LastRebootCount() int {
+	return c.lastRebootCount
+}
+
+// rebootFailure는 재부팅에 실패한 인스턴스와 원인입니다
+type rebootFailure struct {
+	InstanceID int
+	Err        error
+}
+
+// rebootOutcome은 모니터링 한 사이클의 재부팅 결과입니다
+type rebootOutcome struct {
+	Rebooted         []int
+	Failed           []rebootFailure
+	Skipped          []int // General.RunningInstanceCount가 0이라 건너뛴 인스턴스
+	Detected         []int // 자동 재부팅이 꺼져 있어 알림만 보내는 인스턴스
+	RunningInstances int
+}
+
+// summary는 재부팅 결과를 하나의 알림 메시지로 만듭니다
+// 실패가 있으면 error 스레드, 아니면 status 스레드로 보내며, 알릴 내용이 없으면 ok가 false입니다
+func (o rebootOutcome) summary() (message, alertType string, ok bool) {
+	if len(o.Rebooted) == 0 && len(o.Failed) == 0 && len(o.Skipped) == 0 && len(o.Detected) == 0 {
+		return "", "", false
+	}
+
+	var lines []string
+	if len(o.Rebooted) > 0 {
+		lines = append(lines, fmt.Sprintf("Rebooted: %s", joinInstanceIDs(o.Rebooted)))
+		lines = append(lines, fmt.Sprintf("Running instances: %d", o.RunningInstances))
+	}
+	if len(o.Failed) > 0 {
+		ids := make([]int, 0, len(o.Failed))
+		for _, f := range o.Failed {
+			ids = append(ids, f.InstanceID)
+		}
+		lines = append(lines, fmt.Sprintf("Failed: %s", joinInstanceIDs(ids)))
+		for _, f := range o.Failed {
+			lines = append(lines, fmt.Sprintf("  %d: %v", f.InstanceID, f.Err))
+		}
+	}
+	if len(o.Skipped) > 0 {
+		lines = append(lines, fmt.Sprintf("Skipped: %s (General.RunningInstanceCount = 0)", joinInstanceIDs(o.Skipped)))
+	}
+	if len(o.Detected) > 0 {
+		lines = append(lines, fmt.Sprintf("Heartbeat timeout: %s (auto reboot disabled, use /restart)", joinInstanceIDs(o.Detected)))
+	}
+
+	title := "✅ Instance Reboot Summary"
+	alertType = "status"
+	if len(o.Failed) > 0 || len(o.Skipped) > 0 || len(o.Detected) > 0 {
+		title = "⚠️ Instance Reboot Summary"
+		alertType = "error"
+	}
+	return fmt.Sprintf("%s\n%s", title, CodeBlock(strings.Join(lines, "\n"))), alertType, true
+}
+
+// joinInstanceIDs는 인스턴스 ID 목록을 쉼표로 연결합니다
+func joinInstanceIDs(ids []int) string {
+	parts := make([]string, 0, len(ids))
+	for _, id := range ids {
+		parts = append(parts, strconv.Itoa(id))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// shouldAlertRebootFailure는 같은 인스턴스의 실패 알림이 쿨다운 내에 이미 전송되었는지 확인하고, 알림을 보낼 경우 시각을 기록합니다
+func (c *VastaiClient) shouldAlertRebootFailure(instanceID int, now time.Time) bool {
+	if last, ok := c.lastRebootFailureAlerts[instanceID]; ok && now.Sub(last) < c.rebootAlertCooldown {
+		return false
+	}
+	c.lastRebootFailureAlerts[instanceID] = now
+	return true
 }
 
 // GetInstances returns all instances
-func (c *VastaiClient) GetInstances() ([]struct {
-	ActualStatus string `json:"actual_status"`
-	ID           int    `json:"id"`
-}, error) {
+func (c *VastaiClient) GetInstances() ([]VastaiInstance, error) {
 	fullURL := c.baseURL + "instances/"
 
 	req, err := http.NewRequest("GET", fullURL, nil)
@@ -495,7 +895,7 @@ func (c *VastaiClient) GetInstances() ([]struct {
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.token))
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
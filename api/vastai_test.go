@@ -0,0 +1,263 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRebootOutcomeSummary(t *testing.T) {
+	if _, _, ok := (rebootOutcome{}).summary(); ok {
+		t.Error("expected no summary without reboot results")
+	}
+
+	message, alertType, ok := rebootOutcome{Rebooted: []int{101, 102}, RunningInstances: 7}.summary()
+	if !ok || alertType != "status" {
+		t.Fatalf("expected status summary, got %q (%v)", alertType, ok)
+	}
+	if !strings.Contains(message, "Rebooted: 101, 102") {
+		t.Errorf("unexpected summary:\n%s", message)
+	}
+
+	message, alertType, _ = rebootOutcome{
+		Rebooted: []int{101},
+		Failed:   []rebootFailure{{InstanceID: 103, Err: errors.New("timeout")}},
+	}.summary()
+	if alertType != "error" {
+		t.Errorf("expected error alert type with failures, got %q", alertType)
+	}
+	if !strings.Contains(message, "Failed: 103") || !strings.Contains(message, "103: timeout") {
+		t.Errorf("unexpected summary:\n%s", message)
+	}
+
+	// 자동 재부팅이 꺼져 있으면 감지된 인스턴스만 알림
+	message, alertType, ok = rebootOutcome{Detected: []int{104}}.summary()
+	if !ok || alertType != "error" || !strings.Contains(message, "Heartbeat timeout: 104") {
+		t.Errorf("unexpected alert-only summary %q (%v):\n%s", alertType, ok, message)
+	}
+}
+
+func TestShouldAlertRebootFailureCooldown(t *testing.T) {
+	client := NewVastaiClient("token")
+	now := time.Now()
+
+	if !client.shouldAlertRebootFailure(103, now) {
+		t.Error("expected first failure to be alerted")
+	}
+	if client.shouldAlertRebootFailure(103, now.Add(10*time.Minute)) {
+		t.Error("expected repeated failure within cooldown to be suppressed")
+	}
+	if !client.shouldAlertRebootFailure(104, now.Add(10*time.Minute)) {
+		t.Error("expected failures of other instances to be alerted")
+	}
+	if !client.shouldAlertRebootFailure(103, now.Add(DefaultRebootAlertCooldownMinutes*time.Minute)) {
+		t.Error("expected failure to be alerted again after the cooldown")
+	}
+}
+
+func TestDownloadInstanceLogsTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := NewVastaiClient("test-token")
+	client.logHTTPClient.Timeout = 50 * time.Millisecond
+
+	start := time.Now()
+	if _, err := client.DownloadInstanceLogs(server.URL); err == nil {
+		t.Fatal("expected a timeout error from the slow log server")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("expected the download to give up quickly, took %s", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	client.logHTTPClient.Timeout = time.Minute
+	if _, err := client.CheckInstanceLogsContext(ctx, server.URL); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestWaitForInstanceLogs(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests < 3 {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("log line\n"))
+	}))
+	defer server.Close()
+
+	client := NewVastaiClient("test-token")
+	client.logWaitInitialDelay = 10 * time.Millisecond
+	client.logWaitMax = 5 * time.Second
+
+	body, err := client.WaitForInstanceLogs(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("expected the logs once they are uploaded, got %v", err)
+	}
+	if body != "log line\n" || requests != 3 {
+		t.Errorf("expected logs on the third poll, got %q after %d requests", body, requests)
+	}
+
+	// 최대 대기 시간 안에 로그가 준비되지 않으면 마지막 오류를 반환
+	requests = -100
+	client.logWaitMax = 100 * time.Millisecond
+	start := time.Now()
+	if _, err := client.WaitForInstanceLogs(context.Background(), server.URL); err == nil || !strings.Contains(err.Error(), "status 404") {
+		t.Errorf("expected the last 404 after the max wait, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("expected polling to stop after the max wait, took %s", elapsed)
+	}
+}
+
+func TestFindTimedOutInstancesStopsOnCancel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"success": true, "temp_download_url": "http://127.0.0.1:1/logs"}`))
+	}))
+	defer server.Close()
+
+	client := NewVastaiClient("test-token")
+	client.baseURL = server.URL + "/"
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	timedOut := client.findTimedOutInstances(ctx, []VastaiInstance{{ID: 1}, {ID: 2}})
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("expected the log wait to be cancelled, took %s", elapsed)
+	}
+	if len(timedOut) != 0 {
+		t.Errorf("expected no timed-out instances after cancellation, got %+v", timedOut)
+	}
+}
+
+func TestGetCharges(t *testing.T) {
+	var filters string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		filters = r.URL.Query().Get("select_filters")
+		w.Write([]byte(`[
+			{"type": "charge", "description": "GPU instance 101", "quantity": "24", "rate": "0.25", "amount": "6.00", "instance_id": 101},
+			{"type": "charge", "description": "storage", "quantity": "10", "rate": "0.01", "amount": "0.1", "instance_id": 101}
+		]`))
+	}))
+	defer server.Close()
+
+	client := NewVastaiClient("test-token")
+	client.baseURL = server.URL + "/"
+
+	day := time.Date(2025, 1, 31, 15, 0, 0, 0, time.UTC)
+	charges, err := client.GetCharges(day)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(charges) != 2 || charges[0].Description != "GPU instance 101" || charges[0].ComputedCost() != 6 {
+		t.Errorf("unexpected charges %+v", charges)
+	}
+	start := time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC).Unix()
+	window := fmt.Sprintf(`"when":{"gte":%d,"lte":%d}`, start, start+24*60*60)
+	if !strings.Contains(filters, window) {
+		t.Errorf("expected the UTC day window %s in filters, got %s", window, filters)
+	}
+
+	if got := YesterdayUTC(time.Date(2025, 2, 1, 0, 30, 0, 0, time.FixedZone("KST", 9*60*60))); !got.Equal(time.Date(2025, 1, 30, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected yesterday in UTC to be 2025-01-30, got %v", got)
+	}
+}
+
+func TestGetDailyCost(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		charges  string
+		expected float64
+		mismatch bool
+	}{
+		{
+			name:     "computed from rate by default",
+			charges:  `{"quantity": "24", "rate": "0.25", "amount": "6.00"}`,
+			expected: 6,
+		},
+		{
+			name:     "reported amount",
+			source:   CostSourceAmount,
+			charges:  `{"quantity": "24", "rate": "0.25", "amount": "6.00"}, {"quantity": "10", "rate": "0.01", "amount": "0.10"}`,
+			expected: 6.1,
+		},
+		{
+			name:     "amount differing within a cent",
+			source:   CostSourceAmount,
+			charges:  `{"quantity": "24", "rate": "0.25", "amount": "6.005"}`,
+			expected: 6.005,
+		},
+		{
+			name:     "computed with a diverging amount",
+			source:   CostSourceComputed,
+			charges:  `{"quantity": "24", "rate": "0.25", "amount": "5.50"}`,
+			expected: 6,
+			mismatch: true,
+		},
+		{
+			name:     "amount with a diverging rate",
+			source:   CostSourceAmount,
+			charges:  `{"quantity": "24", "rate": "0.25", "amount": "5.50"}`,
+			expected: 5.5,
+			mismatch: true,
+		},
+		{
+			// 파싱할 수 없는 금액은 합계에서 빠지므로 계산값과 달라짐
+			name:     "unparsable amount",
+			source:   CostSourceAmount,
+			charges:  `{"quantity": "24", "rate": "0.25", "amount": "6.00"}, {"quantity": "2", "rate": "0.25", "amount": "n/a"}`,
+			expected: 6,
+			mismatch: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("[" + tt.charges + "]"))
+			}))
+			defer server.Close()
+
+			var logs bytes.Buffer
+			log.SetOutput(&logs)
+			defer log.SetOutput(os.Stderr)
+
+			client := NewVastaiClient("test-token")
+			client.baseURL = server.URL + "/"
+			client.SetCostSource(tt.source)
+
+			cost, err := client.GetDailyCost()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cost - tt.expected; diff > 1e-9 || diff < -1e-9 {
+				t.Errorf("expected cost %.4f, got %.4f", tt.expected, cost)
+			}
+			if mismatch := strings.Contains(logs.String(), "daily cost mismatch"); mismatch != tt.mismatch {
+				t.Errorf("expected mismatch warning %v, got logs:\n%s", tt.mismatch, logs.String())
+			}
+		})
+	}
+}
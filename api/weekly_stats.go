@@ -0,0 +1,150 @@
+package api
+
+import (
+	"sync"
+	"time"
+)
+
+// weeklyStatsDays는 WeeklyStatsManager가 보관하는 일 수입니다 (이번 주 + 지난 주 + 진행 중인 오늘)
+const weeklyStatsDays = 15
+
+// DaySummary는 하루 동안의 토큰/비중/비용 집계입니다
+type DaySummary struct {
+	Date     time.Time `json:"date"`     // 보고 시간대 기준 자정
+	Tokens   int64     `json:"tokens"`   // 그날 마지막으로 관측된 24시간 토큰 수
+	AvgShare float64   `json:"avgShare"` // 그날 분 단위 비중(Share)의 평균
+	Spend    float64   `json:"spend"`    // 그날 마지막으로 관측된 일일 비용 ($)
+	Samples  int       `json:"samples"`
+}
+
+// WeekTotals는 최대 7일치 DaySummary의 합계입니다
+type WeekTotals struct {
+	Days     int     `json:"days"`
+	Tokens   int64   `json:"tokens"`
+	AvgShare float64 `json:"avgShare"`
+	Spend    float64 `json:"spend"`
+}
+
+// WeeklySummary는 이번 주와 지난 주 비교 결과입니다
+type WeeklySummary struct {
+	ThisWeek WeekTotals  `json:"thisWeek"`
+	LastWeek WeekTotals  `json:"lastWeek"`
+	Best     *DaySummary `json:"best,omitempty"`  // 이번 주 토큰이 가장 많은 날
+	Worst    *DaySummary `json:"worst,omitempty"` // 이번 주 토큰이 가장 적은 날
+}
+
+// dayBucket은 하루 동안의 누적값입니다
+type dayBucket struct {
+	date     time.Time
+	tokens   int64
+	shareSum float64
+	spend    float64
+	count    int
+}
+
+// WeeklyStatsManager는 계정별로 최근 15일의 일별 통계를 메모리에 보관합니다
+// 재시작하면 초기화되므로 주간 보고서는 보관된 일 수만큼만 반영합니다
+type WeeklyStatsManager struct {
+	days  map[string][]*dayBucket // 계정 이름별, 날짜순 정렬, 최대 15개
+	mutex sync.Mutex
+}
+
+var GlobalWeeklyStats = &WeeklyStatsManager{}
+
+// UpdateStats는 계정의 분 단위 메트릭스를 오늘 버킷에 누적합니다
+func (m *WeeklyStatsManager) UpdateStats(account string, metrics MinuteMetrics) {
+	m.add(account, ReportTime(time.Now()), metrics.User.TokensLast24Hours, metrics.User.Share, metrics.User.TotalDailyCost)
+}
+
+// add는 계정의 now가 속한 날짜 버킷에 값을 누적하고 15일이 지난 버킷은 제거합니다
+// 토큰과 비용은 그날 마지막 값을 사용하므로 계정마다 따로 보관해야 서로 덮어쓰지 않습니다
+func (m *WeeklyStatsManager) add(account string, now time.Time, tokens int64, share, spend float64) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.days == nil {
+		m.days = make(map[string][]*dayBucket)
+	}
+	date := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	days := m.days[account]
+	var bucket *dayBucket
+	if n := len(days); n > 0 && days[n-1].date.Equal(date) {
+		bucket = days[n-1]
+	} else {
+		bucket = &dayBucket{date: date}
+		days = append(days, bucket)
+	}
+	bucket.tokens = tokens
+	bucket.spend = spend
+	bucket.shareSum += share
+	bucket.count++
+
+	cutoff := date.AddDate(0, 0, -(weeklyStatsDays - 1))
+	valid := days[:0]
+	for _, b := range days {
+		if !b.date.Before(cutoff) {
+			valid = append(valid, b)
+		}
+	}
+	m.days[account] = valid
+}
+
+// GetDays는 계정의 보관 중인 일별 통계를 날짜순으로 반환합니다
+func (m *WeeklyStatsManager) GetDays(account string) []DaySummary {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	days := m.days[account]
+	result := make([]DaySummary, 0, len(days))
+	for _, b := range days {
+		day := DaySummary{Date: b.date, Tokens: b.tokens, Spend: b.spend, Samples: b.count}
+		if b.count > 0 {
+			day.AvgShare = b.shareSum / float64(b.count)
+		}
+		result = append(result, day)
+	}
+	return result
+}
+
+// Summary는 계정의 now 이전 7일(이번 주)과 그 앞 7일(지난 주)을 비교합니다
+// now가 속한 날은 아직 끝나지 않았으므로 제외합니다
+func (m *WeeklyStatsManager) Summary(account string, now time.Time) WeeklySummary {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	thisWeekStart := today.AddDate(0, 0, -7)
+	lastWeekStart := today.AddDate(0, 0, -14)
+
+	var summary WeeklySummary
+	var thisShare, lastShare float64
+	for _, day := range m.GetDays(account) {
+		switch {
+		case !day.Date.Before(today):
+			continue
+		case !day.Date.Before(thisWeekStart):
+			summary.ThisWeek.Days++
+			summary.ThisWeek.Tokens += day.Tokens
+			summary.ThisWeek.Spend += day.Spend
+			thisShare += day.AvgShare
+			if summary.Best == nil || day.Tokens > summary.Best.Tokens {
+				best := day
+				summary.Best = &best
+			}
+			if summary.Worst == nil || day.Tokens < summary.Worst.Tokens {
+				worst := day
+				summary.Worst = &worst
+			}
+		case !day.Date.Before(lastWeekStart):
+			summary.LastWeek.Days++
+			summary.LastWeek.Tokens += day.Tokens
+			summary.LastWeek.Spend += day.Spend
+			lastShare += day.AvgShare
+		}
+	}
+	if summary.ThisWeek.Days > 0 {
+		summary.ThisWeek.AvgShare = thisShare / float64(summary.ThisWeek.Days)
+	}
+	if summary.LastWeek.Days > 0 {
+		summary.LastWeek.AvgShare = lastShare / float64(summary.LastWeek.Days)
+	}
+	return summary
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWeeklyStatsManagerSummary(t *testing.T) {
+	m := &WeeklyStatsManager{}
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// 지난 주 7일: 매일 100 토큰, $10
+	for i := 0; i < 7; i++ {
+		m.add("a", base.AddDate(0, 0, i), 100, 1.0, 10)
+	}
+	// 이번 주 7일: 6일째가 최고, 3일째가 최저
+	tokens := []int64{150, 120, 90, 160, 140, 300, 130}
+	for i, v := range tokens {
+		day := base.AddDate(0, 0, 7+i)
+		m.add("a", day, v/2, 1.0, 12)
+		m.add("a", day.Add(time.Hour), v, 3.0, 12) // 같은 날의 마지막 값이 사용됨
+	}
+	// 진행 중인 오늘은 제외
+	now := base.AddDate(0, 0, 14)
+	m.add("a", now, 999, 9.0, 99)
+
+	summary := m.Summary("a", now)
+	if summary.LastWeek.Days != 7 || summary.LastWeek.Tokens != 700 || summary.LastWeek.Spend != 70 {
+		t.Errorf("unexpected last week totals: %+v", summary.LastWeek)
+	}
+	if summary.ThisWeek.Days != 7 || summary.ThisWeek.Tokens != 1090 || summary.ThisWeek.Spend != 84 {
+		t.Errorf("unexpected this week totals: %+v", summary.ThisWeek)
+	}
+	if summary.ThisWeek.AvgShare != 2.0 {
+		t.Errorf("expected average share 2.0, got %v", summary.ThisWeek.AvgShare)
+	}
+	if summary.Best == nil || summary.Best.Tokens != 300 || summary.Worst == nil || summary.Worst.Tokens != 90 {
+		t.Errorf("unexpected best/worst days: %+v / %+v", summary.Best, summary.Worst)
+	}
+}
+
+func TestWeeklyStatsManagerKeeps15Days(t *testing.T) {
+	m := &WeeklyStatsManager{}
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 20; i++ {
+		m.add("a", base.AddDate(0, 0, i), int64(i), 0, 0)
+	}
+
+	days := m.GetDays("a")
+	if len(days) != weeklyStatsDays {
+		t.Fatalf("expected %d days, got %d", weeklyStatsDays, len(days))
+	}
+	if days[0].Tokens != 5 || days[len(days)-1].Tokens != 19 {
+		t.Errorf("unexpected day range: first %d, last %d", days[0].Tokens, days[len(days)-1].Tokens)
+	}
+}
+
+func TestWeeklyStatsManagerPerAccount(t *testing.T) {
+	m := &WeeklyStatsManager{}
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// 같은 날 두 계정의 값이 서로 덮어쓰지 않음
+	m.add("alpha", base, 100, 1.0, 10)
+	m.add("beta", base.Add(time.Minute), 5, 0.1, 1)
+
+	alpha, beta := m.GetDays("alpha"), m.GetDays("beta")
+	if len(alpha) != 1 || alpha[0].Tokens != 100 || alpha[0].Spend != 10 {
+		t.Errorf("unexpected alpha days: %+v", alpha)
+	}
+	if len(beta) != 1 || beta[0].Tokens != 5 || beta[0].Spend != 1 {
+		t.Errorf("unexpected beta days: %+v", beta)
+	}
+	if days := m.GetDays("gamma"); len(days) != 0 {
+		t.Errorf("expected no days for an unknown account, got %+v", days)
+	}
+}
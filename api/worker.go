@@ -1,11 +1,11 @@
 package api
 
 import (
-	"encoding/json"
 	"fmt"
 )
 
 type Instance struct {
+	ID              string `json:"id,omitempty"` // Kuzco 인스턴스 ID
 	Status          string `json:"status"`
 	Model           string `json:"model"`
 	Lane            string `json:"lane"`
@@ -13,11 +13,20 @@ type Instance struct {
 	GPUModel        string `json:"gpuModel"`
 	Version         string `json:"version"`
 	VersionMismatch bool   `json:"versionMismatch"`
+	VersionStatus   string `json:"versionStatus,omitempty"` // 불일치 방향: VersionNewer 또는 VersionOlder
+
+	GPU *GPUReading `json:"gpu,omitempty"` // nvidia-smi 온도/사용률/전력 (없으면 nil)
+
+	// Kuzco가 IP로 판단한 인스턴스 위치
+	Country string `json:"country,omitempty"`
+	Region  string `json:"region,omitempty"`
+	City    string `json:"city,omitempty"`
 }
 
 type Worker struct {
 	ID                 string              `json:"id"`
 	Name               string              `json:"name"`
+	TeamID             string              `json:"teamId,omitempty"`
 	InstanceCount      int                 `json:"instanceCount"`
 	DailyCost          float64             `json:"dailyCost"`
 	TokensPerInstance  int64               `json:"tokensPerInstance"`
@@ -25,6 +34,7 @@ type Worker struct {
 	TotalTokens        int64               `json:"totalTokens"`
 	GenerationsLast24H int                 `json:"generationsLast24h"`
 	GenerationsHistory []GenerationHistory `json:"generationsHistory"`
+	Archived           bool                `json:"archived,omitempty"`
 	Instances          []Instance          `json:"instances"`
 	TokenHistory       []TokenHistory      `json:"tokenHistory"`
 }
@@ -40,6 +50,7 @@ type WorkerResponse struct {
 					IsArchived bool   `json:"isArchived"`
 					TeamID     string `json:"teamId"`
 					Instances  []struct {
+						ID              string `json:"_id"`
 						Status          string `json:"status"`
 						PoolAssignments []struct {
 							Lane string `json:"lane"`
@@ -48,10 +59,11 @@ type WorkerResponse struct {
 							Runtime   string `json:"runtime"`
 							Version   string `json:"version"`
 							IPAddress string `json:"ipAddress"`
+							Country   string `json:"country"`
+							Region    string `json:"region"`
+							City      string `json:"city"`
 							NvidiaSmi struct {
-								GPU []struct {
-									ProductName []string `json:"product_name"`
-								} `json:"gpu"`
+								GPU []nvidiaSmiGPU `json:"gpu"`
 							} `json:"nvidiaSmi"`
 						} `json:"info"`
 					} `json:"instances"`
@@ -61,7 +73,9 @@ type WorkerResponse struct {
 	} `json:"result"`
 }
 
-func (c *Client) GetWorkers() ([]Worker, error) {
+// GetWorkers returns the account's workers with their metrics and estimated daily cost.
+// Archived workers are skipped unless includeArchived is true
+func (c *Client) GetWorkers(includeArchived bool) ([]Worker, error) {
 	// Load GPU prices
 	gpuPrices, err := LoadGPUPrices("instance.json")
 	if err != nil {
@@ -81,17 +95,17 @@ func (c *Client) GetWorkers() ([]Worker, error) {
 	}
 
 	var resp []WorkerResponse
-	if err := json.Unmarshal(respBody, &resp); err != nil {
-		return nil, fmt.Errorf("error parsing response: %w", err)
+	if err := parseJSONOrError("worker.list", respBody, &resp); err != nil {
+		return nil, err
 	}
 
 	if len(resp) == 0 {
-		return nil, fmt.Errorf("empty response received")
+		return nil, fmt.Errorf("empty response received: %w", ErrParse)
 	}
 
 	var workers []Worker
 	for _, w := range resp[0].Result.Data.JSON.Workers {
-		if w.IsArchived {
+		if w.IsArchived && !includeArchived {
 			continue
 		}
 
@@ -132,7 +146,7 @@ func (c *Client) GetWorkers() ([]Worker, error) {
 		}
 
 		// Get worker generations history
-		genHistory, err := kuzcoClient.GetWorkerGenerationsHistory(w.ID, w.TeamID, 2)
+		genHistory, err := kuzcoClient.GetWorkerGenerationsHistory(w.ID, w.TeamID, kuzcoClient.historyHours())
 		if err != nil {
 			return nil, fmt.Errorf("failed to get worker generations history: %w", err)
 		}
@@ -140,12 +154,14 @@ func (c *Client) GetWorkers() ([]Worker, error) {
 		worker := Worker{
 			ID:                 w.ID,
 			Name:               w.Name,
+			TeamID:             w.TeamID,
 			InstanceCount:      len(w.Instances),
 			TokensLast24H:      int64(tokens24h),
 			TotalTokens:        int64(totalTokens),
 			GenerationsLast24H: int(generations24h),
 			Instances:          make([]Instance, 0, len(w.Instances)),
 			GenerationsHistory: genHistory,
+			Archived:           w.IsArchived,
 		}
 
 		// Calculate TokensPerInstance only if there are instances
@@ -157,12 +173,18 @@ func (c *Client) GetWorkers() ([]Worker, error) {
 		dailyCost := 0.0
 		for _, inst := range w.Instances {
 			var gpuModel string
-			if len(inst.Info.NvidiaSmi.GPU) > 0 && len(inst.Info.NvidiaSmi.GPU[0].ProductName) > 0 {
-				gpuModel = normalizeGPUName(inst.Info.NvidiaSmi.GPU[0].ProductName[0])
+			var gpuReading *GPUReading
+			if len(inst.Info.NvidiaSmi.GPU) > 0 {
+				if len(inst.Info.NvidiaSmi.GPU[0].ProductName) > 0 {
+					gpuModel = normalizeGPUName(inst.Info.NvidiaSmi.GPU[0].ProductName[0])
+				}
+				gpuReading = inst.Info.NvidiaSmi.GPU[0].reading()
 			}
 
 			if price, ok := gpuPrices[gpuModel]; ok {
 				dailyCost += price
+			} else if gpuModel != "" {
+				warnUnpricedGPU(gpuModel)
 			}
 
 			var model, lane string
@@ -171,7 +193,7 @@ func (c *Client) GetWorkers() ([]Worker, error) {
 				lane = inst.PoolAssignments[0].Lane
 			}
 
-			var version string
+			var version, versionStatus string
 			var versionMismatch bool
 			var versionDiff string
 			if inst.Info.Version != "" {
@@ -179,10 +201,12 @@ func (c *Client) GetWorkers() ([]Worker, error) {
 				versionMismatch, versionDiff = compareVersions(version, cliVersion)
 				if versionMismatch {
 					version = fmt.Sprintf("%s (%s)", version, versionDiff)
+					versionStatus = versionDirection(versionDiff)
 				}
 			}
 
 			instance := Instance{
+				ID:              inst.ID,
 				Status:          inst.Status,
 				Model:           model,
 				Lane:            lane,
@@ -190,6 +214,11 @@ func (c *Client) GetWorkers() ([]Worker, error) {
 				GPUModel:        gpuModel,
 				Version:         version,
 				VersionMismatch: versionMismatch,
+				VersionStatus:   versionStatus,
+				GPU:             gpuReading,
+				Country:         inst.Info.Country,
+				Region:          inst.Info.Region,
+				City:            inst.Info.City,
 			}
 			worker.Instances = append(worker.Instances, instance)
 		}
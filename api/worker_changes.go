@@ -0,0 +1,139 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// 워커 변경 알림 기본값
+const (
+	DefaultWorkerChangeDedupMinutes = 10 // 같은 변경을 다시 알리지 않는 시간(분)
+	DefaultFlapTransitions          = 4  // flapWindow 안에 이 횟수 이상 바뀌면 flapping으로 판단
+	DefaultFlapWindowMinutes        = 30
+)
+
+// changeHistory는 인스턴스(또는 워커)별 최근 변경 기록입니다
+type changeHistory struct {
+	transitions  []time.Time          // flap 판단 기간 안의 변경 시각
+	lastNotified map[string]time.Time // 변경 내용(종류+상세)별 마지막 알림 시각
+	flapping     bool
+}
+
+// workerChangeLimits는 설정된 중복 억제 시간, flapping 기준 횟수와 기간을 반환하며, 설정되지 않은 값은 기본값을 사용합니다
+func (c AlertConfig) workerChangeLimits() (dedup time.Duration, flapTransitions int, flapWindow time.Duration) {
+	dedupMinutes := c.WorkerChangeDedupMinutes
+	if dedupMinutes <= 0 {
+		dedupMinutes = DefaultWorkerChangeDedupMinutes
+	}
+	flapTransitions = c.FlapTransitions
+	if flapTransitions <= 1 {
+		flapTransitions = DefaultFlapTransitions
+	}
+	flapMinutes := c.FlapWindowMinutes
+	if flapMinutes <= 0 {
+		flapMinutes = DefaultFlapWindowMinutes
+	}
+	return time.Duration(dedupMinutes) * time.Minute, flapTransitions, time.Duration(flapMinutes) * time.Minute
+}
+
+// eventSubject는 변경이 일어난 대상입니다 (인스턴스 이벤트는 워커/IP, 워커 이벤트와 IP 변경은 워커)
+func eventSubject(event WorkerEvent) string {
+	switch event.Type {
+	case EventStatusChanged, EventInstanceAdded, EventInstanceRemoved:
+		if ip, _, ok := strings.Cut(event.Detail, " "); ok {
+			return event.Worker + "/" + strings.TrimSuffix(ip, ":")
+		}
+	}
+	return event.Worker
+}
+
+// filterWorkerChanges는 알릴 변경과 새로 flapping 상태가 된 대상을 반환합니다
+// 같은 대상의 같은 변경은 dedup 동안 다시 알리지 않고, flapWindow 안에 flapTransitions번 이상 바뀐 대상은
+// flapping 알림을 한 번 보낸 뒤 변경이 잠잠해질 때까지(flapWindow 동안 변경 없음) 개별 알림을 보내지 않습니다
+func (m *Client) filterWorkerChanges(events []WorkerEvent, now time.Time, config AlertConfig) (notify []WorkerEvent, flapping []string) {
+	dedup, flapTransitions, flapWindow := config.workerChangeLimits()
+	if m.workerChanges == nil {
+		m.workerChanges = make(map[string]*changeHistory)
+	}
+
+	// 오래된 기록 정리
+	for subject, history := range m.workerChanges {
+		kept := history.transitions[:0]
+		for _, t := range history.transitions {
+			if now.Sub(t) < flapWindow {
+				kept = append(kept, t)
+			}
+		}
+		history.transitions = kept
+		if len(kept) == 0 {
+			history.flapping = false
+		}
+		for key, t := range history.lastNotified {
+			if now.Sub(t) >= dedup {
+				delete(history.lastNotified, key)
+			}
+		}
+		if len(history.transitions) == 0 && len(history.lastNotified) == 0 {
+			delete(m.workerChanges, subject)
+		}
+	}
+
+	for _, event := range events {
+		subject := eventSubject(event)
+		history, ok := m.workerChanges[subject]
+		if !ok {
+			history = &changeHistory{lastNotified: make(map[string]time.Time)}
+			m.workerChanges[subject] = history
+		}
+		history.transitions = append(history.transitions, now)
+
+		if history.flapping {
+			continue
+		}
+		if len(history.transitions) >= flapTransitions {
+			history.flapping = true
+			flapping = append(flapping, fmt.Sprintf("%s: %d changes in %s", subject, len(history.transitions), flapWindow))
+			continue
+		}
+
+		key := event.Type + " " + event.Detail
+		if _, seen := history.lastNotified[key]; seen {
+			continue
+		}
+		history.lastNotified[key] = now
+		notify = append(notify, event)
+	}
+	return notify, flapping
+}
+
+// notifyWorkerChanges는 중복과 flapping을 걸러낸 워커 변경을 Status 스레드로 알립니다
+// 워커 조회에 실패한 수집(missing.Workers)에서는 전환 기록도 알림도 하지 않습니다
+func (m *Client) notifyWorkerChanges(events []WorkerEvent, missing MissingFields, config AlertConfig, sendAlert func(string, string) error) error {
+	if !config.Enabled || !config.NotifyWorkerChanges || missing.Workers {
+		return nil
+	}
+
+	notify, flapping := m.filterWorkerChanges(events, time.Now(), config)
+	if len(notify) > 0 {
+		lines := make([]string, 0, len(notify))
+		for _, event := range notify {
+			lines = append(lines, fmt.Sprintf("%s [%s] %s", event.Worker, event.Type, event.Detail))
+		}
+		title := "🔄 Worker Changes"
+		message := fmt.Sprintf("%s\n%s", title, CodeBlock(strings.Join(lines, "\n")))
+		if err := sendAlert(message, "status"); err != nil {
+			return fmt.Errorf("failed to send worker change alert: %w", err)
+		}
+	}
+	if len(flapping) > 0 {
+		_, _, flapWindow := config.workerChangeLimits()
+		title := "⚠️ Flapping Alert"
+		msg := strings.Join(flapping, "\n") + fmt.Sprintf("\nChange notifications paused until stable for %s", flapWindow)
+		message := fmt.Sprintf("%s\n%s", title, CodeBlock(msg))
+		if err := sendAlert(message, "status"); err != nil {
+			return fmt.Errorf("failed to send flapping alert: %w", err)
+		}
+	}
+	return nil
+}
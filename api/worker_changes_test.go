@@ -0,0 +1,81 @@
+package api
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFilterWorkerChangesDedup(t *testing.T) {
+	client := NewClient()
+	config := AlertConfig{WorkerChangeDedupMinutes: 10, FlapTransitions: 10}
+	now := time.Now()
+	event := WorkerEvent{Type: EventStatusChanged, Worker: "gpu-1", Detail: "10.0.0.1: Running → Initializing"}
+
+	if notify, _ := client.filterWorkerChanges([]WorkerEvent{event}, now, config); len(notify) != 1 {
+		t.Fatalf("expected the first change to be notified, got %+v", notify)
+	}
+	if notify, _ := client.filterWorkerChanges([]WorkerEvent{event}, now.Add(5*time.Minute), config); len(notify) != 0 {
+		t.Errorf("expected the same change within 10 minutes to be suppressed, got %+v", notify)
+	}
+	other := WorkerEvent{Type: EventStatusChanged, Worker: "gpu-1", Detail: "10.0.0.1: Initializing → Running"}
+	if notify, _ := client.filterWorkerChanges([]WorkerEvent{other}, now.Add(6*time.Minute), config); len(notify) != 1 {
+		t.Errorf("expected a different transition to be notified, got %+v", notify)
+	}
+	if notify, _ := client.filterWorkerChanges([]WorkerEvent{event}, now.Add(11*time.Minute), config); len(notify) != 1 {
+		t.Errorf("expected the change to be notified again after the window, got %+v", notify)
+	}
+}
+
+func TestNotifyWorkerChangesSkipsFailedWorkers(t *testing.T) {
+	client := NewClient()
+	config := AlertConfig{Enabled: true, NotifyWorkerChanges: true}
+	var alerts []string
+	sendAlert := func(message, alertType string) error {
+		alerts = append(alerts, message)
+		return nil
+	}
+	event := WorkerEvent{Type: EventWorkerRemoved, Worker: "gpu-1"}
+
+	if err := client.notifyWorkerChanges([]WorkerEvent{event}, MissingFields{Workers: true}, config, sendAlert); err != nil {
+		t.Fatal(err)
+	}
+	if len(alerts) != 0 {
+		t.Fatalf("expected no notification when workers failed to load, got %v", alerts)
+	}
+
+	// 실패한 수집의 변경이 기록되지 않았으므로 다음 정상 수집에서 바로 알림
+	if err := client.notifyWorkerChanges([]WorkerEvent{event}, MissingFields{}, config, sendAlert); err != nil {
+		t.Fatal(err)
+	}
+	if len(alerts) != 1 {
+		t.Fatalf("expected a notification once workers load, got %v", alerts)
+	}
+}
+
+func TestFilterWorkerChangesFlapping(t *testing.T) {
+	client := NewClient()
+	config := AlertConfig{FlapTransitions: 3, FlapWindowMinutes: 30}
+	now := time.Now()
+	up := WorkerEvent{Type: EventStatusChanged, Worker: "gpu-1", Detail: "10.0.0.1: Initializing → Running"}
+	down := WorkerEvent{Type: EventStatusChanged, Worker: "gpu-1", Detail: "10.0.0.1: Running → Initializing"}
+	unrelated := WorkerEvent{Type: EventStatusChanged, Worker: "gpu-2", Detail: "10.0.0.2: Running → Initializing"}
+
+	var notified, flapped int
+	for i, event := range []WorkerEvent{down, up, down, up, down} {
+		notify, flapping := client.filterWorkerChanges([]WorkerEvent{event}, now.Add(time.Duration(i)*time.Minute), config)
+		notified += len(notify)
+		flapped += len(flapping)
+	}
+	if notified != 2 || flapped != 1 {
+		t.Errorf("expected 2 notifications then a single flapping alert, got %d notifications, %d flapping alerts", notified, flapped)
+	}
+
+	if notify, _ := client.filterWorkerChanges([]WorkerEvent{unrelated}, now.Add(5*time.Minute), config); len(notify) != 1 {
+		t.Errorf("expected other instances to be unaffected, got %+v", notify)
+	}
+
+	// 30분 동안 변경이 없으면 다시 알림
+	if notify, flapping := client.filterWorkerChanges([]WorkerEvent{up}, now.Add(40*time.Minute), config); len(notify) != 1 || len(flapping) != 0 {
+		t.Errorf("expected notifications to resume once stable, got %+v, %v", notify, flapping)
+	}
+}
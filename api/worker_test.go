@@ -0,0 +1,88 @@
+package api_test
+
+import (
+	"test/api"
+	"test/api/apitest"
+	"testing"
+)
+
+func TestGetWorkers(t *testing.T) {
+	apitest.ChdirWithGPUPrices(t, `[{"Gpu": "RTX 3090", "Price": 0.21}, {"Gpu": "RTX 4090", "Price": 0.34}]`)
+	fixture := apitest.DefaultKuzcoFixture()
+	fixture.Metrics = map[string]int{api.EndpointMetricsTokensLast24Hours: 900}
+	fixture.Workers = []apitest.Worker{
+		{ID: "w1", Name: "worker-1", TeamID: "team", Instances: []apitest.Instance{
+			{ID: "i1", Status: "Running", Lane: "lane-a", Runtime: "vllm", Version: "0.2.3", IP: "10.0.0.1", GPU: "NVIDIA GeForce RTX 3090"},
+			{Status: "Initializing", Lane: "lane-b", Runtime: "ollama", Version: "0.2.1", IP: "10.0.0.2", GPU: "NVIDIA GeForce RTX 4090"},
+			{Status: "Running", IP: "10.0.0.3", GPU: "NVIDIA H100"},
+		}},
+		{ID: "w2", Name: "archived", TeamID: "team", Archived: true},
+	}
+	server := apitest.NewKuzcoServer(t, fixture)
+
+	workers, err := apitest.NewClient(server).GetWorkers(false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(workers) != 1 {
+		t.Fatalf("Expected archived worker to be skipped, got %d workers", len(workers))
+	}
+
+	w := workers[0]
+	if w.Name != "worker-1" || w.InstanceCount != 3 {
+		t.Errorf("Unexpected worker: %+v", w)
+	}
+	if w.TokensLast24H != 900 || w.TokensPerInstance != 300 {
+		t.Errorf("Unexpected tokens: 24h %d, per instance %d", w.TokensLast24H, w.TokensPerInstance)
+	}
+	if w.GenerationsLast24H != 123 || len(w.GenerationsHistory) != 1 {
+		t.Errorf("Unexpected generations: 24h %d, history %+v", w.GenerationsLast24H, w.GenerationsHistory)
+	}
+
+	// 가격 정보가 없는 GPU(H100)는 비용에 포함되지 않습니다
+	wantCost := (0.21*24 + api.DiskCostPerDay) + (0.34*24 + api.DiskCostPerDay)
+	if diff := w.DailyCost - wantCost; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("Expected daily cost %.4f, got %.4f", wantCost, w.DailyCost)
+	}
+
+	first := w.Instances[0]
+	if first.ID != "i1" || first.GPUModel != "RTX 3090" || first.Lane != "lane-a" || first.Model != "vllm" || first.IP != "10.0.0.1" {
+		t.Errorf("Unexpected first instance: %+v", first)
+	}
+	if first.VersionMismatch {
+		t.Errorf("Expected matching version for first instance, got %+v", first)
+	}
+
+	second := w.Instances[1]
+	if !second.VersionMismatch || second.Version != "0.2.1 (older (0.2.1 < 0.2.3))" || second.VersionStatus != api.VersionOlder {
+		t.Errorf("Expected older version mismatch for second instance, got %+v", second)
+	}
+
+	if third := w.Instances[2]; third.Lane != "" || third.Model != "" {
+		t.Errorf("Expected no lane/model without pool assignment, got %+v", third)
+	}
+}
+
+func TestGetWorkersIncludeArchived(t *testing.T) {
+	apitest.ChdirWithGPUPrices(t, `[{"Gpu": "RTX 3090", "Price": 0.21}]`)
+	fixture := apitest.DefaultKuzcoFixture()
+	fixture.Workers = []apitest.Worker{
+		{ID: "w1", Name: "active", TeamID: "team"},
+		{ID: "w2", Name: "archived", TeamID: "team", Archived: true},
+	}
+	server := apitest.NewKuzcoServer(t, fixture)
+
+	workers, err := apitest.NewClient(server).GetWorkers(true)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(workers) != 2 {
+		t.Fatalf("Expected 2 workers including archived, got %d", len(workers))
+	}
+	if workers[0].Archived || !workers[1].Archived {
+		t.Errorf("Unexpected archived flags: %v, %v", workers[0].Archived, workers[1].Archived)
+	}
+	if workers[1].TotalTokens != 123 {
+		t.Errorf("Expected archived worker total tokens 123, got %d", workers[1].TotalTokens)
+	}
+}
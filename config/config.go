@@ -2,15 +2,21 @@ package config
 
 import (
 	"fmt"
+	"net"
 	"os"
 	"test/api"
+	"test/telegram"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
 type KuzcoConfig struct {
-	Email    string `yaml:"email"`
-	Password string `yaml:"password"`
+	Email     string `yaml:"email"`
+	Password  string `yaml:"password"`
+	UserAgent string `yaml:"userAgent"` // Kuzco API 요청 User-Agent, 비어 있으면 기본값 사용
+	// Teams는 이 로그인으로 수집할 워커 팀 목록입니다 (비어 있으면 로그인한 사용자의 팀만 수집)
+	Teams []api.Team `yaml:"teams"`
 }
 
 type VastaiConfig struct {
@@ -18,6 +24,7 @@ type VastaiConfig struct {
 	Email             string `yaml:"email"`
 	Token             string `yaml:"token"`
 	IncludeVastaiCost bool   `yaml:"includeVastaiCost"`
+	CostSource        string `yaml:"costSource"` // amount | computed (기본값 computed)
 }
 
 type AlertConfig struct {
@@ -32,24 +39,332 @@ type TelegramThreads struct {
 	Error   int `yaml:"error"`
 	Status  int `yaml:"status"`
 	Workers int `yaml:"workers"`
+	Weekly  int `yaml:"weekly"` // 주간 보고서 스레드 (0이면 Daily 스레드 사용)
+}
+
+// Configured는 설정된(0이 아닌) 스레드 ID를 이름별로 반환합니다
+func (t TelegramThreads) Configured() map[string]int {
+	threads := map[string]int{
+		"daily":   t.Daily,
+		"hourly":  t.Hourly,
+		"error":   t.Error,
+		"status":  t.Status,
+		"workers": t.Workers,
+		"weekly":  t.Weekly,
+	}
+	for name, id := range threads {
+		if id == 0 {
+			delete(threads, name)
+		}
+	}
+	return threads
+}
+
+// Override는 override에 설정된(0이 아닌) 스레드로 t를 덮어쓴 결과를 반환합니다
+func (t TelegramThreads) Override(override TelegramThreads) TelegramThreads {
+	if override.Daily != 0 {
+		t.Daily = override.Daily
+	}
+	if override.Hourly != 0 {
+		t.Hourly = override.Hourly
+	}
+	if override.Error != 0 {
+		t.Error = override.Error
+	}
+	if override.Status != 0 {
+		t.Status = override.Status
+	}
+	if override.Workers != 0 {
+		t.Workers = override.Workers
+	}
+	if override.Weekly != 0 {
+		t.Weekly = override.Weekly
+	}
+	return t
 }
 
 type TelegramConfig struct {
 	Token   string          `yaml:"token"`
 	ChatID  string          `yaml:"chat_id"`
 	Threads TelegramThreads `yaml:"threads"`
+
+	// AllowedUserIDs는 권한이 필요한 명령어를 실행할 수 있는 사용자 ID 목록입니다 (비어 있으면 제한 없음)
+	AllowedUserIDs []int64 `yaml:"allowedUserIDs"`
+	// RestrictReadOnly가 true이면 조회 명령어도 AllowedUserIDs로 제한합니다
+	RestrictReadOnly bool `yaml:"restrictReadOnly"`
+	// SkipStartupTest가 true이면 시작 시 스레드 점검 메시지를 보내지 않습니다
+	SkipStartupTest bool `yaml:"skipStartupTest"`
+	// OffsetFile은 재시작 후 이전 명령어를 다시 처리하지 않도록 업데이트 오프셋을 저장하는 파일입니다
+	OffsetFile string `yaml:"offsetFile"`
+	// SkipStartupSummary가 true이면 시작 시 불러온 설정 요약을 Status 스레드로 보내지 않습니다
+	SkipStartupSummary bool `yaml:"skipStartupSummary"`
+	// PollTimeoutSeconds는 getUpdates 롱 폴링 대기 시간(초)입니다 (0: 기본값 30, 음수: 롱 폴링 사용 안 함)
+	PollTimeoutSeconds int `yaml:"pollTimeoutSeconds"`
+}
+
+// PollTimeout은 getUpdates 롱 폴링 대기 시간을 반환하며, 설정되지 않았으면 기본값을, 음수이면 0을 반환합니다
+func (t TelegramConfig) PollTimeout() time.Duration {
+	switch {
+	case t.PollTimeoutSeconds < 0:
+		return 0
+	case t.PollTimeoutSeconds == 0:
+		return telegram.DefaultPollTimeout
+	}
+	return time.Duration(t.PollTimeoutSeconds) * time.Second
 }
 
+// OffsetPath는 설정된 오프셋 파일 경로를 반환하며, 비어 있으면 기본값을 사용합니다
+func (t TelegramConfig) OffsetPath() string {
+	if t.OffsetFile == "" {
+		return telegram.DefaultOffsetFile
+	}
+	return t.OffsetFile
+}
+
+// IsUserAllowed는 사용자가 명령어를 실행할 수 있는지 확인합니다
+// privileged가 false인 조회 명령어는 RestrictReadOnly가 설정된 경우에만 제한됩니다
+func (t TelegramConfig) IsUserAllowed(userID int64, privileged bool) bool {
+	if len(t.AllowedUserIDs) == 0 {
+		return true
+	}
+	if !privileged && !t.RestrictReadOnly {
+		return true
+	}
+	for _, id := range t.AllowedUserIDs {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
+type HTTPConfig struct {
+	ProxyURL string `yaml:"proxyURL"` // Kuzco/Vast.ai 요청에 사용할 프록시 (예: http://proxy:3128)
+}
+
+type SlackConfig struct {
+	Enabled    bool   `yaml:"enabled"`
+	WebhookURL string `yaml:"webhookURL"`
+}
+
+// DiscordConfig는 알림과 보고서를 함께 보낼 Discord 웹훅 설정입니다
+type DiscordConfig struct {
+	Enabled    bool              `yaml:"enabled"`
+	WebhookURL string            `yaml:"webhookURL"` // 기본 웹훅, webhooks에 없는 알림 종류에 사용
+	Webhooks   map[string]string `yaml:"webhooks"`   // 알림 종류(daily, hourly, weekly, worker, error, status)별 웹훅, "off"면 보내지 않음
+	Embeds     bool              `yaml:"embeds"`     // error/status 알림을 색상 embed로 전송
+}
+
+// discordAlertTypes는 discord.webhooks에 지정할 수 있는 알림 종류입니다
+var discordAlertTypes = map[string]bool{"daily": true, "hourly": true, "weekly": true, "worker": true, "error": true, "status": true}
+
+type ReportingConfig struct {
+	DailyWorkerTime string  `yaml:"dailyWorkerTime"` // "HH:MM" 형식, 기본값 09:00
+	Timezone        string  `yaml:"timezone"`        // 보고서 시각과 날짜 경계에 사용할 IANA 타임존 이름, 기본값 로컬
+	EWMAAlpha       float64 `yaml:"ewmaAlpha"`       // 시간별 통계 EWMA 가중치 (0 < alpha <= 1), 기본값 0.3
+	Locale          string  `yaml:"locale"`          // 메시지 언어 (ko | en), 기본값 ko
+	NumberStyle     string  `yaml:"numberStyle"`     // 숫자 표시 방식 (suffix | grouped), 기본값 suffix
+	ShowAccountName bool    `yaml:"showAccountName"` // 시간별/일일/워커 보고서 앞에 계정 이름 표시
+	PointDivisor    int64   `yaml:"pointDivisor"`    // 1포인트에 해당하는 토큰 수, 기본값 10000
+	// WorkerSort는 워커 표의 기본 정렬 기준입니다 (tokens | gen | count | name, 기본값 tokens)
+	WorkerSort string `yaml:"workerSort"`
+	// USDPerMillionTokens는 /earnings에서 토큰 100만 개를 달러로 환산하는 비율입니다 (0이면 달러 표시 안 함)
+	USDPerMillionTokens float64 `yaml:"usdPerMillionTokens"`
+	// EfficiencyDecimals는 1% 효율의 소수점 자릿수입니다 (0-4, 설정하지 않으면 2)
+	EfficiencyDecimals *int `yaml:"efficiencyDecimals"`
+	// WorkersPerPage는 워커 표 한 페이지(메시지)에 넣는 워커 수입니다 (기본값 25)
+	WorkersPerPage int `yaml:"workersPerPage"`
+}
+
+// DefaultWorkersPerPage는 워커 표 한 페이지의 기본 워커 수입니다
+const DefaultWorkersPerPage = 25
+
+// 워커 표 정렬 기준 (reporting.workerSort, /workers sort=...)
+const (
+	WorkerSortTokens = "tokens" // 인스턴스당 토큰 내림차순
+	WorkerSortGen    = "gen"    // 최근 1시간 생성량 내림차순
+	WorkerSortCount  = "count"  // 인스턴스 수 내림차순
+	WorkerSortName   = "name"   // 워커 이름 오름차순
+)
+
+// ValidWorkerSort는 지원하는 워커 표 정렬 기준인지 반환합니다
+func ValidWorkerSort(order string) bool {
+	switch order {
+	case WorkerSortTokens, WorkerSortGen, WorkerSortCount, WorkerSortName:
+		return true
+	}
+	return false
+}
+
+// WorkerSortOrder는 설정된 워커 표 정렬 기준을 반환하며, 설정되지 않았으면 tokens를 사용합니다
+func (r ReportingConfig) WorkerSortOrder() string {
+	if r.WorkerSort == "" {
+		return WorkerSortTokens
+	}
+	return r.WorkerSort
+}
+
+// WorkersPerPageLimit은 워커 표 한 페이지의 워커 수를 반환하며, 설정되지 않았으면 기본값을 사용합니다
+func (r ReportingConfig) WorkersPerPageLimit() int {
+	if r.WorkersPerPage <= 0 {
+		return DefaultWorkersPerPage
+	}
+	return r.WorkersPerPage
+}
+
+// EfficiencyPrecision은 1% 효율의 소수점 자릿수를 반환하며, 설정되지 않았으면 기본값을 사용합니다
+func (r ReportingConfig) EfficiencyPrecision() int {
+	if r.EfficiencyDecimals == nil {
+		return api.DefaultEfficiencyDecimals
+	}
+	return *r.EfficiencyDecimals
+}
+
+// Location은 보고서에 사용할 타임존을 반환하며, 설정되지 않으면 로컬 타임존을 사용합니다
+func (r ReportingConfig) Location() (*time.Location, error) {
+	if r.Timezone == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(r.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid reporting timezone %q: %w", r.Timezone, err)
+	}
+	return loc, nil
+}
+
+// DailyWorkerSchedule은 일일 워커 보고서 전송 시각과 타임존을 반환합니다
+func (r ReportingConfig) DailyWorkerSchedule() (hour, minute int, loc *time.Location, err error) {
+	hour, minute = 9, 0
+
+	loc, err = r.Location()
+	if err != nil {
+		return 0, 0, nil, err
+	}
+
+	if r.DailyWorkerTime != "" {
+		t, err := time.Parse("15:04", r.DailyWorkerTime)
+		if err != nil {
+			return 0, 0, nil, fmt.Errorf("invalid dailyWorkerTime %q (expected HH:MM): %w", r.DailyWorkerTime, err)
+		}
+		hour, minute = t.Hour(), t.Minute()
+	}
+
+	return hour, minute, loc, nil
+}
+
+// DefaultAPIListen은 API 서버의 기본 바인드 주소입니다 (로컬 전용)
+const DefaultAPIListen = "127.0.0.1:8080"
+
+type APIConfig struct {
+	Enabled bool   `yaml:"enabled"` // 프로덕션에서도 API 서버 실행 여부
+	Listen  string `yaml:"listen"`  // 바인드 주소, 기본값 127.0.0.1:8080
+
+	// AuthToken은 /api/ 엔드포인트와 /metrics에 필요한 토큰입니다 (Authorization: Bearer 또는 ?token=, 비어 있으면 인증 없음)
+	AuthToken string `yaml:"authToken"`
+}
+
+// ListenAddr는 설정된 바인드 주소를 반환하며, 비어 있으면 기본값을 사용합니다
+func (a APIConfig) ListenAddr() string {
+	if a.Listen == "" {
+		return DefaultAPIListen
+	}
+	return a.Listen
+}
+
+// FeaturesConfig는 백그라운드 작업별 실행 여부입니다 (설정하지 않은 항목은 실행)
+type FeaturesConfig struct {
+	HourlyReport       *bool `yaml:"hourlyReport"`       // 시간별 보고서
+	DailyWorkerReport  *bool `yaml:"dailyWorkerReport"`  // 일일 워커 보고서
+	WeeklyReport       *bool `yaml:"weeklyReport"`       // 주간 보고서
+	InstanceMonitoring *bool `yaml:"instanceMonitoring"` // Vast.ai 인스턴스 heartbeat 타임아웃 감시
+	AutoReboot         *bool `yaml:"autoReboot"`         // 감시 중 타임아웃이 감지된 인스턴스 자동 재부팅 (false면 알림만, alerts.autoRebootStuck도 재부팅하지 않음)
+}
+
+// featureEnabled는 설정되지 않은 기능을 켜진 것으로 봅니다
+func featureEnabled(enabled *bool) bool {
+	return enabled == nil || *enabled
+}
+
+func (f FeaturesConfig) HourlyReportEnabled() bool       { return featureEnabled(f.HourlyReport) }
+func (f FeaturesConfig) DailyWorkerReportEnabled() bool  { return featureEnabled(f.DailyWorkerReport) }
+func (f FeaturesConfig) WeeklyReportEnabled() bool       { return featureEnabled(f.WeeklyReport) }
+func (f FeaturesConfig) InstanceMonitoringEnabled() bool { return featureEnabled(f.InstanceMonitoring) }
+func (f FeaturesConfig) AutoRebootEnabled() bool         { return featureEnabled(f.AutoReboot) }
+
 type AccountConfig struct {
-	Name   string          `yaml:"name"`
-	Kuzco  KuzcoConfig     `yaml:"kuzco"`
-	Vastai VastaiConfig    `yaml:"vastai"`
-	Alerts api.AlertConfig `yaml:"alerts"`
+	Name     string                `yaml:"name"`
+	Kuzco    KuzcoConfig           `yaml:"kuzco"`
+	Vastai   VastaiConfig          `yaml:"vastai"`
+	Alerts   api.AlertConfig       `yaml:"alerts"`
+	Telegram AccountTelegramConfig `yaml:"telegram"`
+}
+
+// AccountTelegramConfig는 계정별 Telegram 설정입니다
+type AccountTelegramConfig struct {
+	// Threads에 설정된(0이 아닌) 스레드는 이 계정의 알림과 보고서에서 전역 telegram.threads보다 우선합니다
+	Threads TelegramThreads `yaml:"threads"`
 }
 
 type Config struct {
-	Accounts []AccountConfig `yaml:"accounts"`
-	Telegram TelegramConfig  `yaml:"telegram"`
+	Accounts   []AccountConfig      `yaml:"accounts"`
+	Telegram   TelegramConfig       `yaml:"telegram"`
+	Reporting  ReportingConfig      `yaml:"reporting"`
+	Monitoring api.MonitoringConfig `yaml:"monitoring"`
+	API        APIConfig            `yaml:"api"`
+	Slack      SlackConfig          `yaml:"slack"`
+	Discord    DiscordConfig        `yaml:"discord"`
+	HTTP       HTTPConfig           `yaml:"http"`
+	Features   FeaturesConfig       `yaml:"features"`
+
+	// PrimaryAccount는 계정을 지정하지 않은 명령어와 API 서버에 사용할 계정 이름입니다 (정기 보고서는 계정마다 전송)
+	// 비어 있으면 첫 번째 계정을 사용합니다
+	PrimaryAccount string `yaml:"primaryAccount"`
+
+	// TelegramTest는 개발 모드(ENV=dev)에서 Telegram 대신 사용하는 테스트 봇/채널 설정입니다 (선택)
+	TelegramTest *TelegramConfig `yaml:"telegram_test"`
+}
+
+// UseTestTelegram은 telegram_test 설정이 있으면 Telegram 설정을 그것으로 교체하고 true를 반환합니다
+// 테스트 봇은 update ID가 다르므로 offsetFile이 없으면 운영 봇과 다른 파일을 사용합니다
+func (c *Config) UseTestTelegram() bool {
+	if c.TelegramTest == nil {
+		return false
+	}
+	c.Telegram = *c.TelegramTest
+	if c.Telegram.OffsetFile == "" {
+		c.Telegram.OffsetFile = telegram.DefaultOffsetFile + "_test"
+	}
+	return true
+}
+
+// FindAccount는 이름으로 계정 설정을 찾습니다
+func (c *Config) FindAccount(name string) (*AccountConfig, bool) {
+	for i := range c.Accounts {
+		if c.Accounts[i].Name == name {
+			return &c.Accounts[i], true
+		}
+	}
+	return nil, false
+}
+
+// AccountThreads는 계정별 스레드 설정을 전역 스레드 설정 위에 적용하여 반환합니다
+// 계정이 없거나 계정별 설정이 없으면 전역 스레드 설정을 그대로 반환합니다
+func (c *Config) AccountThreads(name string) TelegramThreads {
+	if account, ok := c.FindAccount(name); ok {
+		return c.Telegram.Threads.Override(account.Telegram.Threads)
+	}
+	return c.Telegram.Threads
+}
+
+// PrimaryAccountName은 기본 계정 이름을 반환합니다
+func (c *Config) PrimaryAccountName() string {
+	if c.PrimaryAccount != "" {
+		return c.PrimaryAccount
+	}
+	if len(c.Accounts) > 0 {
+		return c.Accounts[0].Name
+	}
+	return ""
 }
 
 func LoadConfig(path string) (*Config, error) {
@@ -63,6 +378,100 @@ func LoadConfig(path string) (*Config, error) {
 		return nil, fmt.Errorf("error parsing config file: %w", err)
 	}
 
+	if _, _, _, err := cfg.Reporting.DailyWorkerSchedule(); err != nil {
+		return nil, fmt.Errorf("error validating config file: %w", err)
+	}
+
+	if cfg.Reporting.EWMAAlpha < 0 || cfg.Reporting.EWMAAlpha > 1 {
+		return nil, fmt.Errorf("error validating config file: reporting.ewmaAlpha must be between 0 and 1, got %v", cfg.Reporting.EWMAAlpha)
+	}
+
+	switch cfg.Reporting.Locale {
+	case "", "ko", "en":
+	default:
+		return nil, fmt.Errorf("error validating config file: invalid reporting.locale %q (expected ko or en)", cfg.Reporting.Locale)
+	}
+
+	switch cfg.Reporting.NumberStyle {
+	case "", api.NumberStyleSuffix, api.NumberStyleGrouped:
+	default:
+		return nil, fmt.Errorf("error validating config file: invalid reporting.numberStyle %q (expected suffix or grouped)", cfg.Reporting.NumberStyle)
+	}
+
+	if cfg.Reporting.PointDivisor < 0 {
+		return nil, fmt.Errorf("error validating config file: reporting.pointDivisor must not be negative, got %d", cfg.Reporting.PointDivisor)
+	}
+
+	if cfg.Reporting.WorkerSort != "" && !ValidWorkerSort(cfg.Reporting.WorkerSort) {
+		return nil, fmt.Errorf("error validating config file: invalid reporting.workerSort %q (expected tokens, gen, count or name)", cfg.Reporting.WorkerSort)
+	}
+
+	if cfg.Reporting.WorkersPerPage < 0 {
+		return nil, fmt.Errorf("error validating config file: reporting.workersPerPage must not be negative, got %d", cfg.Reporting.WorkersPerPage)
+	}
+
+	if d := cfg.Reporting.EfficiencyDecimals; d != nil && (*d < 0 || *d > 4) {
+		return nil, fmt.Errorf("error validating config file: reporting.efficiencyDecimals must be between 0 and 4, got %d", *d)
+	}
+
+	if cfg.Reporting.USDPerMillionTokens < 0 {
+		return nil, fmt.Errorf("error validating config file: reporting.usdPerMillionTokens must not be negative, got %g", cfg.Reporting.USDPerMillionTokens)
+	}
+
+	if _, err := cfg.Monitoring.CompileRebootLogPattern(); err != nil {
+		return nil, fmt.Errorf("error validating config file: %w", err)
+	}
+
+	if hours := cfg.Monitoring.GenerationsHistoryHours; hours != 0 {
+		if err := api.ValidateHistoryHours(hours); err != nil {
+			return nil, fmt.Errorf("error validating config file: monitoring.generationsHistoryHours: %w", err)
+		}
+	}
+
+	for _, account := range cfg.Accounts {
+		switch account.Vastai.CostSource {
+		case "", api.CostSourceAmount, api.CostSourceComputed:
+		default:
+			return nil, fmt.Errorf("error validating config file: account %q has invalid vastai.costSource %q (expected amount or computed)",
+				account.Name, account.Vastai.CostSource)
+		}
+		for i, team := range account.Kuzco.Teams {
+			if team.ID == "" {
+				return nil, fmt.Errorf("error validating config file: account %q kuzco.teams[%d] has no id", account.Name, i)
+			}
+		}
+	}
+
+	if cfg.PrimaryAccount != "" {
+		if _, ok := cfg.FindAccount(cfg.PrimaryAccount); !ok {
+			return nil, fmt.Errorf("error validating config file: primaryAccount %q not found in accounts", cfg.PrimaryAccount)
+		}
+	}
+
+	if cfg.Slack.Enabled && cfg.Slack.WebhookURL == "" {
+		return nil, fmt.Errorf("error validating config file: slack.webhookURL is required when slack is enabled")
+	}
+	if cfg.Discord.Enabled {
+		if cfg.Discord.WebhookURL == "" && len(cfg.Discord.Webhooks) == 0 {
+			return nil, fmt.Errorf("error validating config file: discord.webhookURL or discord.webhooks is required when discord is enabled")
+		}
+		for alertType := range cfg.Discord.Webhooks {
+			if !discordAlertTypes[alertType] {
+				return nil, fmt.Errorf("error validating config file: discord.webhooks: unknown alert type %q (daily, hourly, weekly, worker, error, status)", alertType)
+			}
+		}
+	}
+
+	if cfg.HTTP.ProxyURL != "" {
+		if _, err := api.ParseProxyURL(cfg.HTTP.ProxyURL); err != nil {
+			return nil, fmt.Errorf("error validating config file: http.proxyURL: %w", err)
+		}
+	}
+
+	if _, _, err := net.SplitHostPort(cfg.API.ListenAddr()); err != nil {
+		return nil, fmt.Errorf("error validating config file: invalid api.listen %q: %w", cfg.API.Listen, err)
+	}
+
 	return &cfg, nil
 }
 
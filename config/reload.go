@@ -0,0 +1,79 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// ReloadChanges는 다시 불러온 설정과 현재 설정의 차이를 반환합니다
+// applied는 ApplyReloadable로 실행 중에 반영되는 항목이고, restartRequired는 재시작해야 반영되는 항목입니다
+func ReloadChanges(current, next *Config) (applied, restartRequired []string) {
+	if current.Telegram.Threads != next.Telegram.Threads {
+		applied = append(applied, fmt.Sprintf("telegram.threads: %+v → %+v", current.Telegram.Threads, next.Telegram.Threads))
+	}
+	if !reflect.DeepEqual(current.Telegram.AllowedUserIDs, next.Telegram.AllowedUserIDs) {
+		applied = append(applied, fmt.Sprintf("telegram.allowedUserIDs: %v → %v", current.Telegram.AllowedUserIDs, next.Telegram.AllowedUserIDs))
+	}
+	if current.Telegram.RestrictReadOnly != next.Telegram.RestrictReadOnly {
+		applied = append(applied, fmt.Sprintf("telegram.restrictReadOnly: %v → %v", current.Telegram.RestrictReadOnly, next.Telegram.RestrictReadOnly))
+	}
+
+	for _, account := range current.Accounts {
+		nextAccount, ok := next.FindAccount(account.Name)
+		if !ok {
+			restartRequired = append(restartRequired, fmt.Sprintf("accounts: %s removed", account.Name))
+			continue
+		}
+		if !reflect.DeepEqual(account.Alerts, nextAccount.Alerts) {
+			applied = append(applied, fmt.Sprintf("accounts.%s.alerts", account.Name))
+		}
+		if account.Telegram != nextAccount.Telegram {
+			applied = append(applied, fmt.Sprintf("accounts.%s.telegram.threads: %+v → %+v", account.Name, account.Telegram.Threads, nextAccount.Telegram.Threads))
+		}
+		if !reflect.DeepEqual(account.Kuzco, nextAccount.Kuzco) || account.Vastai != nextAccount.Vastai {
+			restartRequired = append(restartRequired, fmt.Sprintf("accounts.%s credentials/teams/vastai", account.Name))
+		}
+	}
+	for _, account := range next.Accounts {
+		if _, ok := current.FindAccount(account.Name); !ok {
+			restartRequired = append(restartRequired, fmt.Sprintf("accounts: %s added", account.Name))
+		}
+	}
+
+	if current.Telegram.Token != next.Telegram.Token || current.Telegram.ChatID != next.Telegram.ChatID {
+		restartRequired = append(restartRequired, "telegram.token/chat_id")
+	}
+	sections := []struct {
+		name          string
+		current, next interface{}
+	}{
+		{"reporting", current.Reporting, next.Reporting},
+		{"monitoring", current.Monitoring, next.Monitoring},
+		{"api", current.API, next.API},
+		{"slack", current.Slack, next.Slack},
+		{"discord", current.Discord, next.Discord},
+		{"http", current.HTTP, next.HTTP},
+		{"features", current.Features, next.Features},
+		{"primaryAccount", current.PrimaryAccount, next.PrimaryAccount},
+	}
+	for _, section := range sections {
+		if !reflect.DeepEqual(section.current, section.next) {
+			restartRequired = append(restartRequired, section.name)
+		}
+	}
+	return applied, restartRequired
+}
+
+// ApplyReloadable은 실행 중에 바꿀 수 있는 항목(스레드, 명령어 허용 목록, 계정별 알림/스레드 설정)을 next에서 복사합니다
+// 호출하는 쪽에서 동시 접근을 막아야 합니다
+func (c *Config) ApplyReloadable(next *Config) {
+	c.Telegram.Threads = next.Telegram.Threads
+	c.Telegram.AllowedUserIDs = next.Telegram.AllowedUserIDs
+	c.Telegram.RestrictReadOnly = next.Telegram.RestrictReadOnly
+	for i := range c.Accounts {
+		if account, ok := next.FindAccount(c.Accounts[i].Name); ok {
+			c.Accounts[i].Alerts = account.Alerts
+			c.Accounts[i].Telegram = account.Telegram
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package discord
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"test/api"
+)
+
+// MessageLimit is the maximum length of a Discord message's content
+const MessageLimit = 2000
+
+// embedDescriptionLimit is the maximum length of an embed description; longer alerts are sent as plain content
+const embedDescriptionLimit = 4096
+
+// WebhookOff disables an alert type in Webhooks
+const WebhookOff = "off"
+
+// maxSendAttempts is how many times a send is tried when Discord answers 429 Too Many Requests
+const maxSendAttempts = 4
+
+// maxRetryAfter caps the retry_after wait so a single send cannot block the caller for too long
+const maxRetryAfter = time.Minute
+
+// retrySleep waits before retrying a rate-limited send (replaced in tests)
+var retrySleep = time.Sleep
+
+// Discord embed colors by alert type
+const (
+	ColorError    = 0xd00000
+	ColorStatus   = 0xf2c744
+	ColorRecovery = 0x2eb886
+	ColorDefault  = 0x439fe0
+)
+
+// EmbedFooter is the footer of a Discord embed
+type EmbedFooter struct {
+	Text string `json:"text"`
+}
+
+// Embed is a Discord message embed
+type Embed struct {
+	Title       string       `json:"title,omitempty"`
+	Description string       `json:"description,omitempty"`
+	Color       int          `json:"color"`
+	Footer      *EmbedFooter `json:"footer,omitempty"`
+	Timestamp   string       `json:"timestamp,omitempty"`
+}
+
+// Payload is the body posted to a Discord webhook
+type Payload struct {
+	Content string  `json:"content,omitempty"`
+	Embeds  []Embed `json:"embeds,omitempty"`
+}
+
+// Client represents a Discord webhook client
+type Client struct {
+	WebhookURL string            // default webhook
+	Webhooks   map[string]string // per alert type webhook, WebhookOff to skip the type
+	Embeds     bool              // send error/status alerts as color-coded embeds
+	httpClient *http.Client
+}
+
+// NewClient creates a new Discord client that posts to webhookURL unless webhooks routes the alert type elsewhere
+func NewClient(webhookURL string, webhooks map[string]string) *Client {
+	return &Client{
+		WebhookURL: webhookURL,
+		Webhooks:   webhooks,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// ColorForAlert maps the severity of an alert (api.AlertSeverity) to a Discord embed color
+func ColorForAlert(message, alertType string) int {
+	switch api.AlertSeverity(message, alertType) {
+	case api.SeverityRecovery:
+		return ColorRecovery
+	case api.SeverityError:
+		return ColorError
+	case api.SeverityStatus:
+		return ColorStatus
+	default:
+		return ColorDefault
+	}
+}
+
+// webhookFor returns the webhook for the alert type, or "" if the type is not sent to Discord
+func (c *Client) webhookFor(alertType string) string {
+	if url, ok := c.Webhooks[alertType]; ok {
+		if url == WebhookOff {
+			return ""
+		}
+		return url
+	}
+	return c.WebhookURL
+}
+
+// SendAlert posts an alert or report to the webhook routed for alertType
+// With Embeds, error and status alerts become an embed titled by the first line of the message;
+// everything else is sent as content split into messages within MessageLimit
+func (c *Client) SendAlert(message, alertType string) error {
+	url := c.webhookFor(alertType)
+	if url == "" {
+		return nil
+	}
+
+	if c.Embeds && (alertType == "error" || alertType == "status") {
+		title, text := message, ""
+		if idx := strings.Index(message, "\n"); idx >= 0 {
+			title, text = message[:idx], message[idx+1:]
+		}
+		if len(text) <= embedDescriptionLimit {
+			return c.post(url, Payload{Embeds: []Embed{{
+				Title:       title,
+				Description: text,
+				Color:       ColorForAlert(message, alertType),
+				Footer:      &EmbedFooter{Text: alertType},
+				Timestamp:   time.Now().UTC().Format(time.RFC3339),
+			}}})
+		}
+	}
+
+	for i, part := range api.SplitMessage(message, MessageLimit) {
+		if err := c.post(url, Payload{Content: part}); err != nil {
+			return fmt.Errorf("part %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// post sends a payload to a Discord webhook
+func (c *Client) post(url string, payload Payload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal discord payload: %w", err)
+	}
+
+	for attempt := 1; ; attempt++ {
+		resp, err := c.httpClient.Post(url, "application/json", bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("failed to send discord message: %w", err)
+		}
+
+		// 웹훅은 wait 파라미터가 없으면 204, 있으면 200을 반환
+		if resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusNoContent {
+			resp.Body.Close()
+			return nil
+		}
+		wait := retryAfter(resp)
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusTooManyRequests || attempt >= maxSendAttempts {
+			return fmt.Errorf("discord webhook returned status code %d", resp.StatusCode)
+		}
+		retrySleep(wait)
+	}
+}
+
+// retryAfter reads how long a 429 response asks to wait, from the JSON retry_after (seconds, may be fractional)
+// or the Retry-After header, falling back to 1s and capped at maxRetryAfter
+func retryAfter(resp *http.Response) time.Duration {
+	var wait time.Duration
+	var body struct {
+		RetryAfter float64 `json:"retry_after"`
+	}
+	if data, err := io.ReadAll(resp.Body); err == nil && json.Unmarshal(data, &body) == nil && body.RetryAfter > 0 {
+		wait = time.Duration(body.RetryAfter * float64(time.Second))
+	} else if seconds, err := strconv.ParseFloat(resp.Header.Get("Retry-After"), 64); err == nil && seconds > 0 {
+		wait = time.Duration(seconds * float64(time.Second))
+	}
+	if wait <= 0 {
+		wait = time.Second
+	}
+	if wait > maxRetryAfter {
+		wait = maxRetryAfter
+	}
+	return wait
+}
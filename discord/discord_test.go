@@ -0,0 +1,144 @@
+package discord
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestColorForAlert(t *testing.T) {
+	tests := []struct {
+		message   string
+		alertType string
+		expected  int
+	}{
+		{"⚠️ Version Mismatch Alert", "error", ColorError},
+		{"⚠️ Credit Alert", "status", ColorStatus},
+		{"✅ Credit Balance Recovered", "status", ColorRecovery},
+		{"2025-01-01 report", "daily", ColorDefault},
+	}
+
+	for _, tt := range tests {
+		if got := ColorForAlert(tt.message, tt.alertType); got != tt.expected {
+			t.Errorf("ColorForAlert(%q, %q) = %#x, expected %#x", tt.message, tt.alertType, got, tt.expected)
+		}
+	}
+}
+
+// recordingServer는 받은 웹훅 요청을 경로별로 기록합니다
+func recordingServer(t *testing.T) (*httptest.Server, map[string][]Payload) {
+	t.Helper()
+	received := make(map[string][]Payload)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload Payload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("failed to decode payload: %v", err)
+		}
+		received[r.URL.Path] = append(received[r.URL.Path], payload)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(server.Close)
+	return server, received
+}
+
+func TestSendAlertEmbed(t *testing.T) {
+	server, received := recordingServer(t)
+
+	client := NewClient(server.URL+"/default", nil)
+	client.Embeds = true
+	if err := client.SendAlert("⚠️ Credit Alert\nbalance low", "status"); err != nil {
+		t.Fatalf("SendAlert failed: %v", err)
+	}
+
+	payloads := received["/default"]
+	if len(payloads) != 1 || len(payloads[0].Embeds) != 1 {
+		t.Fatalf("expected 1 embed, got %+v", payloads)
+	}
+	embed := payloads[0].Embeds[0]
+	if embed.Color != ColorStatus || embed.Title != "⚠️ Credit Alert" || embed.Description != "balance low" {
+		t.Errorf("unexpected embed: %+v", embed)
+	}
+}
+
+func TestSendAlertSplitsAndRoutes(t *testing.T) {
+	server, received := recordingServer(t)
+
+	client := NewClient(server.URL+"/default", map[string]string{
+		"daily":  server.URL + "/reports",
+		"status": WebhookOff,
+	})
+	client.Embeds = true
+
+	report := "📊 Daily Report\n" + strings.Repeat("worker | 1.23K | 0.5%\n", 200)
+	if err := client.SendAlert(report, "daily"); err != nil {
+		t.Fatalf("SendAlert failed: %v", err)
+	}
+	parts := received["/reports"]
+	if len(parts) < 2 {
+		t.Fatalf("expected the report to be split into several messages, got %d", len(parts))
+	}
+	for i, part := range parts {
+		if len(part.Content) > MessageLimit || len(part.Embeds) != 0 {
+			t.Errorf("part %d: %d bytes, %d embeds", i, len(part.Content), len(part.Embeds))
+		}
+	}
+
+	if err := client.SendAlert("⚠️ Credit Alert\nbalance low", "status"); err != nil {
+		t.Fatalf("SendAlert failed: %v", err)
+	}
+	if err := client.SendAlert("⚠️ Version Mismatch Alert\nold", "error"); err != nil {
+		t.Fatalf("SendAlert failed: %v", err)
+	}
+	if len(received["/default"]) != 1 || received["/default"][0].Embeds[0].Color != ColorError {
+		t.Errorf("expected only the error alert on the default webhook, got %+v", received["/default"])
+	}
+}
+
+func TestSendAlertRetriesOn429(t *testing.T) {
+	var waits []time.Duration
+	retrySleep = func(d time.Duration) { waits = append(waits, d) }
+	defer func() { retrySleep = time.Sleep }()
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.Header().Set("Retry-After", "3")
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"message":"You are being rate limited.","retry_after":2.5,"global":false}`))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	if err := NewClient(server.URL, nil).SendAlert("report", "daily"); err != nil {
+		t.Fatalf("expected the send to succeed after a retry, got %v", err)
+	}
+	if requests != 2 || len(waits) != 1 || waits[0] != 2500*time.Millisecond {
+		t.Errorf("expected one retry after 2.5s, got %d requests, waits %v", requests, waits)
+	}
+}
+
+func TestSendAlertGivesUpOn429(t *testing.T) {
+	retrySleep = func(time.Duration) {}
+	defer func() { retrySleep = time.Sleep }()
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Retry-After", "1")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	if err := NewClient(server.URL, nil).SendAlert("report", "daily"); err == nil {
+		t.Fatal("expected an error after repeated 429 responses")
+	}
+	if requests != maxSendAttempts {
+		t.Errorf("expected %d attempts, got %d", maxSendAttempts, requests)
+	}
+}
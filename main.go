@@ -1,17 +1,27 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"maps"
+	"math"
 	"os"
 	"os/signal"
+	"runtime/debug"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"syscall"
 	"test/api"
 	"test/config"
+	"test/discord"
+	"test/slack"
 	"test/telegram"
 	"time"
 
@@ -19,69 +29,185 @@ import (
 )
 
 var (
-	currentMetrics *api.MinuteMetrics
-	metricsLock    sync.Mutex
+	accountMetrics  = make(map[string]*api.MinuteMetrics) // 계정 이름별 최신 메트릭스
+	reportSnapshots = make(map[string]*api.MinuteMetrics) // 직전 시간별 보고서 시점의 계정별 메트릭스 (/diff)
+	metricsLock     sync.Mutex
+
+	// primaryAccountName은 API 서버로 전달할 기본 계정 이름입니다
+	primaryAccountName string
+
+	// accountClients는 계정 이름별로 CollectMetrics를 실행 중인 클라이언트입니다 (요청된 일일 보고서용)
+	accountClients     = make(map[string]*api.Client)
+	accountClientsLock sync.Mutex
+
+	// apiServerEnabled는 API 서버가 실행 중인지 여부입니다 (개발 모드 또는 api.enabled)
+	apiServerEnabled bool
+
+	// configLock은 SIGHUP으로 다시 불러오는 설정 항목(cfg.Telegram, 계정별 알림 설정)을 보호합니다
+	configLock sync.RWMutex
 )
 
-// updateCurrentMetrics safely updates the current metrics
-func updateCurrentMetrics(mm api.MinuteMetrics) {
-	log.Printf("Updating current metrics")
+// telegramSettings safely returns the Telegram settings, which can change on SIGHUP
+func telegramSettings(cfg *config.Config) config.TelegramConfig {
+	configLock.RLock()
+	defer configLock.RUnlock()
+	return cfg.Telegram
+}
+
+// accountThreads는 계정별 스레드 설정이 반영된 스레드 ID를 반환합니다
+func accountThreads(cfg *config.Config, accountName string) config.TelegramThreads {
+	configLock.RLock()
+	defer configLock.RUnlock()
+	return cfg.AccountThreads(accountName)
+}
+
+// updateCurrentMetrics safely updates the current metrics for an account
+func updateCurrentMetrics(accountName string, mm api.MinuteMetrics) {
+	log.Printf("Updating current metrics for %s", accountName)
 	metricsLock.Lock()
 	defer metricsLock.Unlock()
-	currentMetrics = &mm
+	accountMetrics[accountName] = &mm
 	log.Printf("Current metrics updated")
 
-	// 개발 모드일 때만 API 서버로 메트릭스 데이터 전달
-	if os.Getenv("ENV") == "dev" {
+	// API 서버가 실행 중일 때만 기본 계정의 메트릭스 데이터 전달
+	if apiServerEnabled && accountName == primaryAccountName {
 		api.UpdateMetrics(mm)
 	}
 }
 
-// getCurrentMetrics safely retrieves the current metrics
-func getCurrentMetrics() *api.MinuteMetrics {
-	log.Printf("Getting current metrics")
+// getCurrentMetrics safely retrieves the current metrics for an account
+func getCurrentMetrics(accountName string) *api.MinuteMetrics {
+	log.Printf("Getting current metrics for %s", accountName)
 	metricsLock.Lock()
 	defer metricsLock.Unlock()
-	if currentMetrics == nil {
+	metrics, ok := accountMetrics[accountName]
+	if !ok {
 		log.Printf("No metrics available")
 		return nil
 	}
 	log.Printf("Retrieved current metrics")
-	return currentMetrics
+	return metrics
+}
+
+// getAllAccountMetrics safely retrieves a snapshot of the latest metrics of every account
+func getAllAccountMetrics() map[string]*api.MinuteMetrics {
+	metricsLock.Lock()
+	defer metricsLock.Unlock()
+	snapshot := make(map[string]*api.MinuteMetrics, len(accountMetrics))
+	for name, mm := range accountMetrics {
+		snapshot[name] = mm
+	}
+	return snapshot
+}
+
+// snapshotReportMetrics stores the latest metrics of an account as its baseline for /diff
+func snapshotReportMetrics(accountName string) {
+	metricsLock.Lock()
+	defer metricsLock.Unlock()
+	if mm, ok := accountMetrics[accountName]; ok {
+		reportSnapshots[accountName] = mm
+	}
+}
+
+// getReportSnapshot safely retrieves the metrics captured at the last hourly report for an account
+func getReportSnapshot(accountName string) *api.MinuteMetrics {
+	metricsLock.Lock()
+	defer metricsLock.Unlock()
+	return reportSnapshots[accountName]
+}
+
+// formatChange는 이전 값 대비 변화량을 부호와 변화율(이전 값이 0이 아닐 때)로 표시합니다
+func formatChange(previous, current float64, format func(float64) string) string {
+	delta := current - previous
+	sign := "+"
+	if delta < 0 {
+		sign = "-"
+	}
+	text := sign + format(math.Abs(delta))
+	if previous != 0 {
+		text += fmt.Sprintf(", %+.1f%%", delta/math.Abs(previous)*100)
+	}
+	return text
+}
+
+// formatPointsFloat는 formatChange처럼 float64 토큰 값을 받는 곳에서 api.FormatPoints를 사용합니다
+func formatPointsFloat(tokens float64) string {
+	return api.FormatPoints(int64(math.Round(tokens)))
+}
+
+// formatMetricsDiff formats the changes between the last hourly report snapshot and the current metrics
+func formatMetricsDiff(previous, current *api.MinuteMetrics) string {
+	since := previous.Timestamp
+	if t, err := time.Parse(time.RFC3339, previous.Timestamp); err == nil {
+		since = api.ReportTime(t).Format("15:04")
+	}
+
+	lines := []string{
+		fmt.Sprintf(msg("diff.title"), since),
+		"",
+		fmt.Sprintf(msg("diff.tokens"),
+			api.FormatPoints(previous.User.TokensLast24Hours),
+			api.FormatPoints(current.User.TokensLast24Hours),
+			formatChange(float64(previous.User.TokensLast24Hours), float64(current.User.TokensLast24Hours), formatPointsFloat)),
+		fmt.Sprintf(msg("diff.share"),
+			previous.User.Share*100, current.User.Share*100,
+			formatChange(previous.User.Share*100, current.User.Share*100, func(v float64) string { return fmt.Sprintf("%.3f%%p", v) })),
+		fmt.Sprintf(msg("diff.instances"),
+			previous.User.TotalInstances, current.User.TotalInstances,
+			formatChange(float64(previous.User.TotalInstances), float64(current.User.TotalInstances), func(v float64) string { return fmt.Sprintf("%.0f", v) })),
+	}
+
+	if previous.User.VastaiCredit != nil && current.User.VastaiCredit != nil {
+		lines = append(lines, fmt.Sprintf(msg("diff.credit"),
+			previous.User.VastaiCredit.Credit, current.User.VastaiCredit.Credit,
+			formatChange(previous.User.VastaiCredit.Credit, current.User.VastaiCredit.Credit, func(v float64) string { return fmt.Sprintf("$%.2f", v) })))
+	}
+
+	return strings.Join(lines, "\n")
 }
 
 // formatHourlyStats formats hourly statistics into a message string
 func formatHourlyStats(stats api.HourlyStats) string {
-	return fmt.Sprintf("시간별 통계 (%s ~ %s)\n\n"+
-		"RPM:\n"+
-		"  최소: %d\n"+
-		"  최대: %d\n"+
-		"  평균: %.0f\n"+
-		"  현재: %d\n\n"+
-		"인스턴스 수:\n"+
-		"  최소: %d\n"+
-		"  최대: %d\n"+
-		"  평균: %.0f\n"+
-		"  현재: %d\n\n"+
-		"생성량:\n"+
-		"  전체: %d\n"+
-		"  사용자: %d\n"+
-		"  비율: %.2f%%",
-		stats.StartTime.Format("15:04:05"),
-		stats.EndTime.Format("15:04:05"),
+	return fmt.Sprintf(msg("hourly.template"),
+		api.ReportTime(stats.StartTime).Format("15:04:05"),
+		api.ReportTime(stats.EndTime).Format("15:04:05"),
 		stats.RPM.Min,
 		stats.RPM.Max,
 		stats.RPM.Avg,
+		stats.RPM.EWMA,
 		stats.RPM.Current,
 		stats.TotalInstances.Min,
 		stats.TotalInstances.Max,
 		stats.TotalInstances.Avg,
+		stats.TotalInstances.EWMA,
 		stats.TotalInstances.Current,
 		stats.GenerationLastHour.General,
 		stats.GenerationLastHour.User,
 		stats.GenerationLastHour.Ratio)
 }
 
+// formatDailyStats는 최근 24시간의 시간별 RPM과 인스턴스 수 추이를 포맷합니다
+func formatDailyStats(stats []api.DailyHourStats) string {
+	if len(stats) == 0 {
+		return msg("daily.empty")
+	}
+
+	var b strings.Builder
+	b.WriteString(msg("daily.header"))
+	for _, stat := range stats {
+		b.WriteString(fmt.Sprintf("%s | %5d %7.0f %5d | %4d %6.1f %4d\n",
+			api.ReportTime(stat.Hour).Format("01/02 15h"),
+			stat.RPM.Min,
+			stat.RPM.Avg,
+			stat.RPM.Max,
+			stat.TotalInstances.Min,
+			stat.TotalInstances.Avg,
+			stat.TotalInstances.Max))
+	}
+
+	return fmt.Sprintf(msg("daily.title"), len(stats), api.CodeBlock(strings.TrimRight(b.String(), "\n")))
+}
+
 // formatReport formats the report message
 func formatReport(metrics *api.MinuteMetrics) string {
 	vastaiEfficiency := 0.0
@@ -91,459 +217,2278 @@ func formatReport(metrics *api.MinuteMetrics) string {
 		kuzcoEfficiency = metrics.User.KuzcoDailyCost / (metrics.User.Share * 100)
 	}
 
-	// 포인트 값 먼저 1000으로 나누기 (소수점 조정)
-	myPoints := float64(metrics.User.TokensLast24Hours) / 10000
-	totalPoints := float64(metrics.General.TokensLast24Hours) / 10000
-
-	// 적절한 단위 결정 (K, M, B)
-	myPointsFormatted := formatNumber(myPoints)
-	totalPointsFormatted := formatNumber(totalPoints)
+	// 포인트 환산 및 단위 결정 (K, M, B)
+	myPointsFormatted := api.FormatPoints(metrics.User.TokensLast24Hours)
+	totalPointsFormatted := api.FormatPoints(metrics.General.TokensLast24Hours)
 
-	message := fmt.Sprintf("포인트 : %s | %s\n비중 : %.3f%%\n비용(vast,kuzco) : $%.2f | $%.2f\n1%% 효율(vast,kuzco) : $%d | $%d",
+	message := fmt.Sprintf(msg("report.template"),
 		myPointsFormatted,
 		totalPointsFormatted,
 		metrics.User.Share*100,
 		metrics.User.VastaiDailyCost,
 		metrics.User.KuzcoDailyCost,
-		int(vastaiEfficiency),
-		int(kuzcoEfficiency))
+		api.FormatEfficiency(vastaiEfficiency),
+		api.FormatEfficiency(kuzcoEfficiency))
 
 	if metrics.User.VastaiCredit != nil {
-		message += fmt.Sprintf("\n잔액 : $%.2f", metrics.User.VastaiCredit.Credit)
+		message += fmt.Sprintf(msg("report.balance"), metrics.User.VastaiCredit.Credit)
 	}
+	message += "\n(" + api.PointsNote() + ")"
 
 	return message
 }
 
-// formatNumber 함수 추가: 숫자를 K, M, B 단위로 자동 변환
-func formatNumber(num float64) string {
-	if num >= 1000000000 {
-		return fmt.Sprintf("%.2fB", num/1000000000)
-	} else if num >= 1000000 {
-		return fmt.Sprintf("%.2fM", num/1000000)
-	} else if num >= 1000 {
-		return fmt.Sprintf("%.2fK", num/1000)
+// formatTotalReport는 모든 계정의 메트릭스를 합산한 리포트를 포맷합니다
+func formatTotalReport(all map[string]*api.MinuteMetrics) string {
+	names := make([]string, 0, len(all))
+	for name := range all {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var (
+		totalTokens    int64
+		generalTokens  int64
+		totalInstances int
+		totalCost      float64
+		totalShare     float64
+		totalCredit    float64
+		hasCredit      bool
+		lines          []string
+	)
+	for _, name := range names {
+		mm := all[name]
+		totalTokens += mm.User.TokensLast24Hours
+		totalInstances += mm.User.TotalInstances
+		totalCost += mm.User.TotalDailyCost
+		totalShare += mm.User.Share
+		if mm.General.TokensLast24Hours > generalTokens {
+			generalTokens = mm.General.TokensLast24Hours
+		}
+		if mm.User.VastaiCredit != nil {
+			totalCredit += mm.User.VastaiCredit.Credit
+			hasCredit = true
+		}
+		lines = append(lines, fmt.Sprintf(msg("total.line"),
+			telegram.EscapeMarkdown(name),
+			api.FormatPoints(mm.User.TokensLast24Hours),
+			mm.User.TotalInstances,
+			mm.User.Share*100,
+			mm.User.TotalDailyCost))
+	}
+
+	efficiency := 0.0
+	if totalShare > 0 {
+		efficiency = totalCost / (totalShare * 100)
+	}
+
+	message := fmt.Sprintf(msg("total.template"),
+		len(names),
+		api.FormatPoints(totalTokens),
+		api.FormatPoints(generalTokens),
+		totalInstances,
+		totalShare*100,
+		totalCost,
+		api.FormatEfficiency(efficiency))
+	if hasCredit {
+		message += fmt.Sprintf(msg("report.balance"), totalCredit)
 	}
-	return fmt.Sprintf("%.2f", num)
+	message += "\n\n" + strings.Join(lines, "\n")
+	message += "\n\n(" + api.PointsNote() + ")"
+
+	return message
 }
 
-// handleTelegramCommand processes telegram bot commands
-func handleTelegramCommand(update telegram.Update, telegramClient *telegram.Client, cfg *config.Config) error {
-	command := strings.TrimSpace(update.Message.Text)
-	log.Printf("Processing command: %s", command)
+// commandArgCounts는 계정 이름 앞에 오는 명령어별 고유 인자 수입니다
+var commandArgCounts = map[string]int{
+	"/logs":     1,
+	"/restart":  1,
+	"/genhours": 1,
+}
 
-	// /report 명령어는 최신 데이터를 가져옵니다
-	if command == "/report" {
-		log.Printf("Generating fresh report")
+// privilegedCommands는 telegram.allowedUserIDs에 포함된 사용자만 실행할 수 있는 명령어입니다
+var privilegedCommands = map[string]bool{
+	"/restart":   true,
+	"/rebootall": true,
+	"/report":    true,
+	"/logs":      true,
+	"/export":    true,
+	"/snooze":    true,
+	"/ignore":    true,
+	"/unignore":  true,
+	"/config":    true,
+	"/selftest":  true,
+}
 
-		// 계정 정보 가져오기 (첫 번째 계정 사용)
-		if len(cfg.Accounts) == 0 {
-			return telegramClient.SendMessage(update.Message.MessageThreadID, "계정 정보가 없습니다.")
-		}
+// logTailLines는 /logs 명령어가 전송하는 마지막 로그 줄 수입니다
+const logTailLines = 50
 
-		account := cfg.Accounts[0]
-		client := api.NewClient()
+// handleInstanceLogs는 Vast.ai 인스턴스 로그를 요청하고 바로 응답하며, 로그가 준비되면 마지막 줄들을 따로 전송합니다
+func handleInstanceLogs(telegramClient *telegram.Client, threadID int, account *config.AccountConfig, monitoring api.MonitoringConfig, args []string) error {
+	if len(args) == 0 {
+		return telegramClient.SendMessage(threadID, msg("logs.usage"))
+	}
+	instanceID, err := strconv.Atoi(args[0])
+	if err != nil || instanceID <= 0 {
+		return telegramClient.SendMessage(threadID, fmt.Sprintf(msg("logs.invalidID"), telegram.EscapeMarkdown(args[0])))
+	}
+	if !account.Vastai.Enabled {
+		return telegramClient.SendMessage(threadID,
+			fmt.Sprintf(msg("error.vastaiDisabled"), telegram.EscapeMarkdown(account.Name)))
+	}
 
-		// 로그인
-		token, userID, err := client.Login(account.Kuzco.Email, account.Kuzco.Password)
-		if err != nil {
-			log.Printf("Login failed: %v", err)
-			return telegramClient.SendMessage(update.Message.MessageThreadID, "로그인 실패: "+err.Error())
+	vastaiClient := api.NewVastaiClient(account.Vastai.Token)
+	if err := vastaiClient.SetMonitoringConfig(monitoring); err != nil {
+		log.Printf("Invalid monitoring config for %s, using defaults: %v", account.Name, err)
+	}
+
+	// 인스턴스가 해당 계정 소유인지 확인
+	instances, err := vastaiClient.GetInstances()
+	if err != nil {
+		return telegramClient.SendMessage(threadID, fmt.Sprintf(msg("error.vastaiInstances"), telegram.EscapeMarkdown(err.Error())))
+	}
+	found := false
+	for _, inst := range instances {
+		if inst.ID == instanceID {
+			found = true
+			break
 		}
+	}
+	if !found {
+		return telegramClient.SendMessage(threadID,
+			fmt.Sprintf(msg("logs.notOwned"), telegram.EscapeMarkdown(account.Name), instanceID))
+	}
 
-		client.SetToken(token)
+	logResp, err := vastaiClient.RequestInstanceLogs(instanceID)
+	if err != nil {
+		return telegramClient.SendMessage(threadID, fmt.Sprintf(msg("logs.requestFailed"), telegram.EscapeMarkdown(err.Error())))
+	}
 
-		// 최신 메트릭스 수집
-		kuzcoClient := api.NewKuzcoClient(client)
-		metrics, err := kuzcoClient.GetAllMetrics(userID)
-		if err != nil {
-			log.Printf("Failed to get metrics: %v", err)
-			return telegramClient.SendMessage(update.Message.MessageThreadID, "메트릭스 수집 실패: "+err.Error())
+	// 로그가 준비될 때까지 기다리는 동안 다른 명령어 처리가 멈추지 않도록 백그라운드에서 받아 전송
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("[ERROR] Fetching logs for instance %d panicked: %v\n%s", instanceID, r, debug.Stack())
+			}
+		}()
+		if err := sendInstanceLogs(telegramClient, threadID, vastaiClient, instanceID, logResp.TempDownloadURL); err != nil {
+			log.Printf("[ERROR] Failed to send logs for instance %d: %v", instanceID, err)
 		}
+	}()
+	return telegramClient.SendMessage(threadID, fmt.Sprintf(msg("logs.waiting"), instanceID))
+}
 
-		// Vastai 정보 가져오기 (활성화된 경우)
-		var vastaiCredit *api.VastaiCredit
-		var vastaiCost float64
+// sendInstanceLogs는 임시 URL에 로그가 준비될 때까지 기다린 뒤 마지막 줄들을 전송합니다
+func sendInstanceLogs(telegramClient *telegram.Client, threadID int, vastaiClient *api.VastaiClient, instanceID int, downloadURL string) error {
+	body, err := vastaiClient.WaitForInstanceLogs(context.Background(), downloadURL)
+	if err != nil {
+		return telegramClient.SendMessage(threadID, fmt.Sprintf(msg("logs.downloadFailed"), telegram.EscapeMarkdown(err.Error())))
+	}
 
-		if account.Vastai.Enabled {
-			vastaiClient := api.NewVastaiClient(account.Vastai.Token)
+	lines := api.TailLines(body, logTailLines)
+	title := fmt.Sprintf(msg("logs.title"), instanceID, len(lines))
+	return sendPages(telegramClient, threadID, chunkCodeBlocks(title, lines))
+}
 
-			// 크레딧 정보 가져오기
-			credit, err := vastaiClient.GetCredit()
-			if err != nil {
-				log.Printf("Failed to get vastai credit: %v", err)
-			} else {
-				vastaiCredit = credit
-			}
+// restartConfirmWindow는 /restart 명령어를 다시 보내 재부팅을 확정해야 하는 시간입니다
+const restartConfirmWindow = time.Minute
 
-			// 비용 정보 가져오기 (포함하도록 설정된 경우)
-			if account.Vastai.IncludeVastaiCost {
-				cost, err := vastaiClient.GetDailyCost()
-				if err != nil {
-					log.Printf("Failed to get vastai cost: %v", err)
-				} else {
-					vastaiCost = cost
-				}
-			}
-		}
+var (
+	pendingRestarts     = make(map[string]time.Time) // "계정/인스턴스ID"별 첫 요청 시각
+	pendingRestartsLock sync.Mutex
+)
 
-		// 효율성 계산
-		vastaiEfficiency := 0.0
-		kuzcoEfficiency := 0.0
-		if metrics.User.Share > 0 {
-			vastaiEfficiency = vastaiCost / (metrics.User.Share * 100)
-			kuzcoEfficiency = metrics.User.TotalDailyCost / (metrics.User.Share * 100)
-		}
+// confirmRestart는 같은 인스턴스에 대한 재부팅 요청이 확인 시간 내에 다시 들어왔는지 확인합니다
+// 첫 요청이면 대기 목록에 등록하고 false를 반환합니다
+func confirmRestart(accountName string, instanceID int, now time.Time) bool {
+	key := fmt.Sprintf("%s/%d", accountName, instanceID)
 
-		// 포인트 값 먼저 1000으로 나누기 (소수점 조정)
-		myPoints := float64(metrics.User.TokensLast24Hours) / 10000
-		totalPoints := float64(metrics.General.TokensLast24Hours) / 10000
+	pendingRestartsLock.Lock()
+	defer pendingRestartsLock.Unlock()
 
-		// 적절한 단위 결정 (K, M, B)
-		myPointsFormatted := formatNumber(myPoints)
-		totalPointsFormatted := formatNumber(totalPoints)
+	if requestedAt, ok := pendingRestarts[key]; ok && now.Sub(requestedAt) <= restartConfirmWindow {
+		delete(pendingRestarts, key)
+		return true
+	}
+	pendingRestarts[key] = now
+	return false
+}
 
-		// 응답 메시지 생성
-		response := fmt.Sprintf("포인트 : %s | %s\n비중 : %.3f%%\n비용(vast,kuzco) : $%.2f | $%.2f\n1%% 효율(vast,kuzco) : $%d | $%d",
-			myPointsFormatted,
-			totalPointsFormatted,
-			metrics.User.Share*100,
-			vastaiCost,
-			metrics.User.TotalDailyCost,
-			int(vastaiEfficiency),
-			int(kuzcoEfficiency))
+// handleRestartInstance는 확인 절차를 거쳐 Vast.ai 인스턴스를 재부팅합니다
+func handleRestartInstance(telegramClient *telegram.Client, update telegram.Update, account *config.AccountConfig, args []string) error {
+	threadID := update.Message.MessageThreadID
+	if len(args) == 0 {
+		return telegramClient.SendMessage(threadID, msg("restart.usage"))
+	}
+	instanceID, err := strconv.Atoi(args[0])
+	if err != nil || instanceID <= 0 {
+		return telegramClient.SendMessage(threadID, fmt.Sprintf(msg("logs.invalidID"), telegram.EscapeMarkdown(args[0])))
+	}
+	if !account.Vastai.Enabled {
+		return telegramClient.SendMessage(threadID,
+			fmt.Sprintf(msg("error.vastaiDisabled"), telegram.EscapeMarkdown(account.Name)))
+	}
 
-		// Vastai 크레딧 정보 추가
-		if vastaiCredit != nil {
-			response += fmt.Sprintf("\n잔액 : $%.2f", vastaiCredit.Credit)
+	requester := requesterName(update)
+	log.Printf("Restart requested for instance %d (%s) by %s at %s",
+		instanceID, account.Name, requester, time.Now().Format(time.RFC3339))
+
+	if !confirmRestart(account.Name, instanceID, time.Now()) {
+		return telegramClient.SendMessage(threadID,
+			fmt.Sprintf(msg("restart.confirm"), instanceID, int(restartConfirmWindow.Seconds()), strings.Join(append([]string{"/restart"}, args...), " ")))
+	}
+
+	if err := api.NewVastaiClient(account.Vastai.Token).RebootInstance(instanceID); err != nil {
+		log.Printf("[ERROR] Failed to reboot instance %d (%s) requested by %s: %v", instanceID, account.Name, requester, err)
+		return telegramClient.SendMessage(threadID,
+			fmt.Sprintf(msg("restart.failed"), instanceID, telegram.EscapeMarkdown(err.Error())))
+	}
+
+	log.Printf("Instance %d (%s) rebooted by %s", instanceID, account.Name, requester)
+	return telegramClient.SendMessage(threadID, fmt.Sprintf(msg("restart.success"), instanceID))
+}
+
+// handleAlerts는 활성화된 알림 목록을 보내거나, `/alerts ack <type>`으로 알림을 해소될 때까지 확인 처리합니다
+func handleAlerts(telegramClient *telegram.Client, threadID int, accountName string, args []string) error {
+	if len(args) > 0 && args[0] == "ack" {
+		if len(args) < 2 {
+			return telegramClient.SendMessage(threadID, fmt.Sprintf(msg("alerts.ackUsage"), telegram.EscapeMarkdown(strings.Join(api.AlertTypes, ", "))))
+		}
+		alertType := args[1]
+		if err := api.AcknowledgeAlert(accountName, alertType); err != nil {
+			log.Printf("Failed to acknowledge alert %s for %s: %v", alertType, accountName, err)
+			return telegramClient.SendMessage(threadID, fmt.Sprintf(msg("alerts.ackFailed"), telegram.EscapeMarkdown(err.Error())))
 		}
+		log.Printf("Alert %s acknowledged for %s", alertType, accountName)
+		return telegramClient.SendMessage(threadID, fmt.Sprintf(msg("alerts.ackDone"), alertType))
+	}
 
-		return telegramClient.SendMessage(update.Message.MessageThreadID, response)
+	state := api.CurrentAlertState(accountName)
+	return telegramClient.SendMessage(threadID, formatActiveAlerts(accountName, state.ActiveAlerts(), time.Now()))
+}
+
+// handleSnooze는 /snooze <duration>으로 모든 알림을 일시 중지하고, /snooze off로 해제합니다
+// 인자가 없으면 현재 일시 중지 상태를 표시합니다
+func handleSnooze(telegramClient *telegram.Client, update telegram.Update, args []string) error {
+	threadID := update.Message.MessageThreadID
+	if len(args) == 0 {
+		if until, snoozed := api.AlertsSnoozed(time.Now()); snoozed {
+			return telegramClient.SendMessage(threadID, fmt.Sprintf(msg("snooze.active"), formatSnoozeTime(until)))
+		}
+		return telegramClient.SendMessage(threadID, msg("snooze.usage"))
 	}
 
-	// 다른 명령어는 캐시된 메트릭스 사용
-	metrics := getCurrentMetrics()
-	if metrics == nil {
-		log.Printf("[ERROR] No metrics available for command: %s", command)
-		response := "No metrics available. \nPlease wait a moment."
-		return telegramClient.SendMessage(update.Message.MessageThreadID, response)
+	if args[0] == "off" {
+		if !api.ResumeAlerts() {
+			return telegramClient.SendMessage(threadID, msg("snooze.notActive"))
+		}
+		log.Printf("Alerts resumed early by %s", requesterName(update))
+		return telegramClient.SendMessage(threadID, msg("snooze.resumed"))
 	}
 
-	var response string
+	duration, err := time.ParseDuration(args[0])
+	if err != nil || duration <= 0 {
+		return telegramClient.SendMessage(threadID, fmt.Sprintf(msg("snooze.invalid"), telegram.EscapeMarkdown(args[0])))
+	}
+	until := time.Now().Add(duration)
+	api.SnoozeAlerts(until)
+	log.Printf("Alerts snoozed until %s by %s", until.Format(time.RFC3339), requesterName(update))
+	return telegramClient.SendMessage(threadID, fmt.Sprintf(msg("snooze.done"), formatSnoozeTime(until)))
+}
 
-	switch command {
-	case "/help":
-		log.Printf("Generating help message")
-		response = "사용 가능한 명령어:\n\n" +
-			"`/help` - 이 도움말을 표시합니다\n" +
-			"`/balance` - Vast.ai 잔액을 표시합니다\n" +
-			"`/status` - 인스턴스 상태를 표시합니다\n" +
-			"`/report` - 상세 리포트를 표시합니다\n" +
-			"`/cost` - Vast.ai와 Kuzco의 일일 비용과 잔액을 표시합니다\n" +
-			"`/hourly` - 지난 1시간 동안의 통계를 표시합니다\n" +
-			"`/workers` - 워커별 시간당 생성량을 표시합니다"
+// handleIgnore는 /ignore <instanceID>로 인스턴스를 자동 재부팅에서 제외하고, /unignore <instanceID>로 다시 포함합니다
+// 인자가 없으면 제외된 인스턴스 목록을 표시합니다
+func handleIgnore(telegramClient *telegram.Client, update telegram.Update, command string, args []string) error {
+	threadID := update.Message.MessageThreadID
+	if len(args) == 0 {
+		if command == "/unignore" && len(api.IgnoredInstances()) == 0 {
+			return telegramClient.SendMessage(threadID, msg("unignore.usage"))
+		}
+		return telegramClient.SendMessage(threadID, formatIgnoredInstances())
+	}
 
-	case "/balance":
-		log.Printf("Checking balance")
-		if metrics.User.VastaiCredit != nil {
-			response = fmt.Sprintf("Balance : `$%.2f`", metrics.User.VastaiCredit.Credit)
-			log.Printf("Balance: $%.2f", metrics.User.VastaiCredit.Credit)
-		} else {
-			response = "Balance information not available"
-			log.Printf("Balance information not available")
+	instanceID, err := strconv.Atoi(args[0])
+	if err != nil || instanceID <= 0 {
+		return telegramClient.SendMessage(threadID, fmt.Sprintf(msg("logs.invalidID"), telegram.EscapeMarkdown(args[0])))
+	}
+
+	if command == "/unignore" {
+		if !api.UnignoreInstance(instanceID) {
+			return telegramClient.SendMessage(threadID, fmt.Sprintf(msg("unignore.notIgnored"), instanceID))
 		}
+		log.Printf("Instance %d included in auto reboot again by %s", instanceID, requesterName(update))
+		return telegramClient.SendMessage(threadID, fmt.Sprintf(msg("unignore.done"), instanceID))
+	}
 
-	case "/status":
-		log.Printf("Checking status")
-		response = fmt.Sprintf("Vast.Ai  : %d\nActual Instances : %d",
-			metrics.User.TotalInstances,
-			metrics.User.ActualTotalInstances)
-		log.Printf("Status - Vast.Ai: %d, Actual Instances: %d",
-			metrics.User.TotalInstances,
-			metrics.User.ActualTotalInstances)
+	if !api.IgnoreInstance(instanceID) {
+		return telegramClient.SendMessage(threadID, fmt.Sprintf(msg("ignore.already"), instanceID))
+	}
+	log.Printf("Instance %d excluded from auto reboot by %s", instanceID, requesterName(update))
+	return telegramClient.SendMessage(threadID, fmt.Sprintf(msg("ignore.done"), instanceID, instanceID))
+}
 
-	case "/cost":
-		log.Printf("Calculating costs")
-		response = fmt.Sprintf("Kuzco 일일 비용: `$%.2f`", metrics.User.KuzcoDailyCost)
-		log.Printf("Kuzco daily cost: $%.2f", metrics.User.KuzcoDailyCost)
+// formatIgnoredInstances는 자동 재부팅에서 제외된 인스턴스 목록 메시지를 만듭니다
+func formatIgnoredInstances() string {
+	ids := api.IgnoredInstances()
+	if len(ids) == 0 {
+		return msg("ignore.none")
+	}
+	parts := make([]string, 0, len(ids))
+	for _, id := range ids {
+		parts = append(parts, strconv.Itoa(id))
+	}
+	return fmt.Sprintf(msg("ignore.list"), strings.Join(parts, ", "))
+}
 
-		if metrics.User.VastaiCredit != nil {
-			response += fmt.Sprintf("\nVast.ai 일일 비용: `$%.2f`", metrics.User.VastaiDailyCost)
-			response += fmt.Sprintf("\n잔액: `$%.2f`", metrics.User.VastaiCredit.Credit)
-			log.Printf("Vast.ai daily cost: $%.2f, Credit: $%.2f",
-				metrics.User.VastaiDailyCost,
-				metrics.User.VastaiCredit.Credit)
+// formatSnoozeTime은 일시 중지 해제 시각을 보고 시간대로 포맷합니다
+func formatSnoozeTime(t time.Time) string {
+	return api.ReportTime(t).Format("2006-01-02 15:04 MST")
+}
 
-			if metrics.User.VastaiCredit.Credit <= metrics.User.VastaiDailyCost {
-				response += fmt.Sprintf("\n⚠️ 잔액이 일일 비용보다 적습니다!")
-				log.Printf("WARNING: Credit is less than daily cost")
-			}
+// snoozeCheckInterval은 알림 일시 중지가 끝났는지 확인하는 간격입니다
+const snoozeCheckInterval = time.Minute
 
-			if metrics.User.VastaiDailyCost > 0 {
-				daysLeft := metrics.User.VastaiCredit.Credit / metrics.User.VastaiDailyCost
-				response += fmt.Sprintf("\n예상 가능 사용일: %.1f일", daysLeft)
-				log.Printf("Estimated days left: %.1f", daysLeft)
-			}
-		} else {
-			log.Printf("Vast.ai credit information not available")
+// startSnoozeWatcher는 알림 일시 중지가 끝나면 Status 스레드에 한 번 알립니다
+func startSnoozeWatcher(telegramClient *telegram.Client, cfg *config.Config) {
+	ticker := time.NewTicker(snoozeCheckInterval)
+	defer ticker.Stop()
+
+	for now := range ticker.C {
+		until, expired := api.ExpireSnooze(now)
+		if !expired {
+			continue
+		}
+		log.Printf("Alert snooze ended at %s", until.Format(time.RFC3339))
+		if err := telegramClient.SendMessage(telegramSettings(cfg).Threads.Status, msg("snooze.expired")); err != nil {
+			log.Printf("[ERROR] Failed to send snooze end notice: %v", err)
 		}
+	}
+}
 
-	case "/hourly":
-		log.Printf("Getting hourly stats")
-		stats := api.GlobalHourlyStats.GetStats()
-		response = formatHourlyStats(stats)
-		log.Printf("Hourly stats generated")
+// formatActiveAlerts는 활성화된 알림과 활성화된 기간을 포맷합니다
+func formatActiveAlerts(accountName string, alerts []api.ActiveAlert, now time.Time) string {
+	if len(alerts) == 0 {
+		return fmt.Sprintf(msg("alerts.empty"), telegram.EscapeMarkdown(accountName))
+	}
 
-	case "/workers":
-		log.Printf("Getting worker stats")
-		response = formatWorkerStats(metrics)
+	lines := make([]string, 0, len(alerts))
+	for _, alert := range alerts {
+		age := msg("alerts.unknownSince")
+		if !alert.Since.IsZero() {
+			d := now.Sub(alert.Since)
+			age = fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+		}
+		line := fmt.Sprintf("%-14s | %s", alert.Type, age)
+		if alert.Acked {
+			line += " | ack"
+		}
+		lines = append(lines, line)
+	}
+	return fmt.Sprintf(msg("alerts.title"), telegram.EscapeMarkdown(accountName), len(alerts)) + "\n" +
+		api.CodeBlock(strings.Join(lines, "\n")) + "\n" + msg("alerts.ackHint")
+}
 
-		// 추가 페이지가 있는지 확인
-		if strings.Contains(response, "$$$") {
-			parts := strings.Split(response, "$$$")
-			response = parts[0] // 첫 번째 페이지 내용
+// genHistoryHours는 /genhistory 명령어가 조회하는 생성량 기록 기간(시간)입니다
+const genHistoryHours = 24
 
-			// 첫 번째 페이지 전송
-			if err := telegramClient.SendMessage(update.Message.MessageThreadID, response); err != nil {
-				log.Printf("Error sending first worker page: %v", err)
-				return err
+// handleGenHistory는 최근 24시간 사용자 생성량 기록을 막대 차트 PNG로 전송합니다
+// 기록이 모두 0이거나 사진 전송에 실패하면 텍스트 표로 대신 전송합니다
+func handleGenHistory(telegramClient *telegram.Client, threadID int, account *config.AccountConfig) error {
+	client, userID, err := loginAccount(account)
+	if err != nil {
+		log.Printf("Login failed: %v", err)
+		return telegramClient.SendMessage(threadID, fmt.Sprintf(msg("error.login"), telegram.EscapeMarkdown(err.Error())))
+	}
+	history, err := api.NewKuzcoClient(client).GetTeamsGenerationsHistory(userID, genHistoryHours)
+	if err != nil && len(history) == 0 {
+		log.Printf("Failed to get generations history: %v", err)
+		return telegramClient.SendMessage(threadID, fmt.Sprintf(msg("error.metrics"), telegram.EscapeMarkdown(err.Error())))
+	}
+	if len(history) == 0 {
+		return telegramClient.SendMessage(threadID, fmt.Sprintf(msg("genhistory.empty"), telegram.EscapeMarkdown(account.Name)))
+	}
+	partial := partialTeamsNote(err)
+
+	// API는 최신 시간부터 반환하므로 차트는 오래된 시간부터 그립니다
+	values := make([]int, len(history))
+	maxValue, total := 0, 0
+	for i, h := range history {
+		values[len(history)-1-i] = h.Value
+		total += h.Value
+		if h.Value > maxValue {
+			maxValue = h.Value
+		}
+	}
+	caption := fmt.Sprintf(msg("genhistory.caption"), telegram.EscapeMarkdown(account.Name),
+		formatHistoryDate(history[len(history)-1].Date), formatHistoryDate(history[0].Date), len(history), maxValue, total) + partial
+
+	if maxValue > 0 {
+		chart, err := api.RenderBarChart(values, 800, 400)
+		if err == nil {
+			filename := fmt.Sprintf("genhistory-%s-%s.png", account.Name, api.ReportTime(time.Now()).Format("20060102-1504"))
+			if err = telegramClient.SendPhoto(threadID, filename, chart, caption); err == nil {
+				return nil
 			}
+		}
+		log.Printf("Failed to send generations history chart, falling back to text: %v", err)
+	}
+	return telegramClient.SendMessage(threadID, caption+"\n"+formatGenHistoryTable(history))
+}
 
-			// 추가 페이지가 있으면 JSON에서 파싱
-			if len(parts) > 1 {
-				var workerPages struct {
-					Pages []string `json:"pages"`
-				}
+// formatGenHistoryTable은 생성량 기록을 오래된 시간부터 텍스트 표로 포맷합니다
+func formatGenHistoryTable(history []api.GenerationHistory) string {
+	return api.CodeBlock(strings.Join(genHistoryLines(history), "\n"))
+}
 
-				if err := json.Unmarshal([]byte(parts[1]), &workerPages); err != nil {
-					log.Printf("Error parsing worker pages: %v", err)
-				} else {
-					// 각 추가 페이지를 순차적으로 전송 (0.5초 딜레이)
-					for i, page := range workerPages.Pages {
-						time.Sleep(500 * time.Millisecond) // 0.5초 딜레이로 순서 보장
-						if err := telegramClient.SendMessage(update.Message.MessageThreadID, page); err != nil {
-							log.Printf("Error sending worker page %d: %v", i+2, err)
-						}
-					}
-				}
-			}
+// genHistoryLines는 생성량 기록 표의 헤더와 오래된 시간부터의 행을 반환합니다
+func genHistoryLines(history []api.GenerationHistory) []string {
+	lines := make([]string, 0, len(history)+1)
+	lines = append(lines, msg("genhistory.header"))
+	for i := len(history) - 1; i >= 0; i-- {
+		lines = append(lines, fmt.Sprintf("%-11s | %6d", formatHistoryDate(history[i].Date), history[i].Value))
+	}
+	return lines
+}
+
+// handleGenHours는 /genhours <n>으로 최근 n시간의 사용자 생성량 기록을 새로 조회하여 표로 전송합니다
+// 기록이 길면 여러 메시지로 나누어 보냅니다
+func handleGenHours(telegramClient *telegram.Client, threadID int, account *config.AccountConfig, args []string) error {
+	if len(args) == 0 {
+		return telegramClient.SendMessage(threadID, fmt.Sprintf(msg("genhours.usage"), api.MaxGenerationsHistoryHours))
+	}
+	hours, err := strconv.Atoi(args[0])
+	if err != nil || api.ValidateHistoryHours(hours) != nil {
+		return telegramClient.SendMessage(threadID,
+			fmt.Sprintf(msg("genhours.invalid"), telegram.EscapeMarkdown(args[0]), api.MaxGenerationsHistoryHours))
+	}
+
+	client, userID, err := loginAccount(account)
+	if err != nil {
+		log.Printf("Login failed: %v", err)
+		return telegramClient.SendMessage(threadID, fmt.Sprintf(msg("error.login"), telegram.EscapeMarkdown(err.Error())))
+	}
+	history, err := api.NewKuzcoClient(client).GetTeamsGenerationsHistory(userID, hours)
+	if err != nil && len(history) == 0 {
+		log.Printf("Failed to get generations history: %v", err)
+		return telegramClient.SendMessage(threadID, fmt.Sprintf(msg("error.metrics"), telegram.EscapeMarkdown(err.Error())))
+	}
+	if len(history) == 0 {
+		return telegramClient.SendMessage(threadID, fmt.Sprintf(msg("genhours.empty"), telegram.EscapeMarkdown(account.Name), hours))
+	}
 
-			// 이미 메시지를 보냈으므로 빈 문자열로 설정
-			response = ""
+	maxValue, total := 0, 0
+	for _, h := range history {
+		total += h.Value
+		if h.Value > maxValue {
+			maxValue = h.Value
 		}
+	}
+	title := fmt.Sprintf(msg("genhours.title"), telegram.EscapeMarkdown(account.Name), len(history),
+		total, float64(total)/float64(len(history)), maxValue) + partialTeamsNote(err)
+	return sendPages(telegramClient, threadID, chunkCodeBlocks(title, genHistoryLines(history)))
+}
 
-		log.Printf("Worker stats generated")
+// partialTeamsNote는 일부 팀의 기록을 가져오지 못한 경우 팀별 오류 안내를, 없으면 빈 문자열을 반환합니다
+func partialTeamsNote(err error) string {
+	if err == nil {
+		return ""
+	}
+	log.Printf("Partial team history collected: %v", err)
+	return fmt.Sprintf(msg("report.partial"), telegram.EscapeMarkdown(err.Error()))
+}
 
-	default:
-		log.Printf("Unknown command: %s", command)
-		return nil
+// chargesDateLayout은 /charges의 날짜 형식입니다
+const chargesDateLayout = "2006-01-02"
+
+// maxChargeDescription은 /charges 표에 표시하는 청구 설명의 최대 길이입니다
+const maxChargeDescription = 40
+
+// handleCharges는 /charges [YYYY-MM-DD]로 하루(UTC) 동안의 Vast.ai 청구 내역을 항목별로 전송합니다
+// 날짜가 없으면 GetDailyCost와 같은 어제를 조회합니다
+func handleCharges(telegramClient *telegram.Client, threadID int, account *config.AccountConfig, args []string) error {
+	if !account.Vastai.Enabled {
+		return telegramClient.SendMessage(threadID, fmt.Sprintf(msg("error.vastaiDisabled"), telegram.EscapeMarkdown(account.Name)))
 	}
 
-	log.Printf("Sending response to thread %d", update.Message.MessageThreadID)
-	return telegramClient.SendMessage(update.Message.MessageThreadID, response)
+	day := api.YesterdayUTC(time.Now())
+	if len(args) > 0 && args[0] != account.Name {
+		parsed, err := time.Parse(chargesDateLayout, args[0])
+		if err != nil {
+			return telegramClient.SendMessage(threadID, fmt.Sprintf(msg("charges.invalid"), telegram.EscapeMarkdown(args[0])))
+		}
+		day = parsed
+	}
+
+	charges, err := api.NewVastaiClient(account.Vastai.Token).GetCharges(day)
+	if err != nil {
+		log.Printf("Failed to get vastai charges: %v", err)
+		return telegramClient.SendMessage(threadID, fmt.Sprintf(msg("error.metrics"), telegram.EscapeMarkdown(err.Error())))
+	}
+	date := day.Format(chargesDateLayout)
+	if len(charges) == 0 {
+		return telegramClient.SendMessage(threadID, fmt.Sprintf(msg("charges.empty"), telegram.EscapeMarkdown(account.Name), date))
+	}
+
+	var amountTotal, computedTotal float64
+	table := api.NewTable("Description", "Qty", "Rate", "Amount").
+		SetAlign(1, api.AlignRight).SetAlign(2, api.AlignRight).SetAlign(3, api.AlignRight)
+	for _, charge := range charges {
+		amount, _ := strconv.ParseFloat(charge.Amount, 64)
+		amountTotal += amount
+		computedTotal += charge.ComputedCost()
+
+		description := charge.Description
+		if runes := []rune(description); len(runes) > maxChargeDescription {
+			description = string(runes[:maxChargeDescription-1]) + "…"
+		}
+		table.AddRow(description, charge.Quantity, charge.Rate, "$"+charge.Amount)
+	}
+
+	title := fmt.Sprintf(msg("charges.title"), telegram.EscapeMarkdown(account.Name), date, len(charges), amountTotal, computedTotal)
+	return sendPages(telegramClient, threadID, chunkCodeBlocks(title, table.Lines()))
+}
+
+// earningsHours는 /earnings가 표시하는 기간(시간)입니다
+const earningsHours = 24
+
+// earningsCacheTTL은 tokenEarningsHistory 조회 결과를 재사용하는 시간입니다 (시간 단위 데이터라 자주 바뀌지 않음)
+const earningsCacheTTL = 10 * time.Minute
+
+// earningsCacheEntry는 계정별로 캐시한 시간별 토큰 적립 기록입니다
+type earningsCacheEntry struct {
+	fetchedAt time.Time
+	history   []api.TokenEarningsHistory
+}
+
+var (
+	earningsCache     = make(map[string]earningsCacheEntry)
+	earningsCacheLock sync.Mutex
+)
+
+// cachedTokenEarningsHistory는 계정(설정된 팀 합계)의 최근 24시간 토큰 적립 기록을 반환하며, earningsCacheTTL 이내에 조회한 결과가 있으면 재사용합니다
+// 일부 팀이 실패한 결과는 나머지 팀의 기록과 팀별 오류를 함께 반환하고 캐시하지 않습니다
+func cachedTokenEarningsHistory(account *config.AccountConfig) ([]api.TokenEarningsHistory, time.Time, error) {
+	earningsCacheLock.Lock()
+	entry, ok := earningsCache[account.Name]
+	earningsCacheLock.Unlock()
+	if ok && time.Since(entry.fetchedAt) < earningsCacheTTL {
+		return entry.history, entry.fetchedAt, nil
+	}
+
+	client, userID, err := loginAccount(account)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("login failed: %w", err)
+	}
+	history, err := api.NewKuzcoClient(client).GetTeamsTokenEarningsHistory(userID, earningsHours)
+	if err != nil {
+		return history, time.Now(), err
+	}
+
+	entry = earningsCacheEntry{fetchedAt: time.Now(), history: history}
+	earningsCacheLock.Lock()
+	earningsCache[account.Name] = entry
+	earningsCacheLock.Unlock()
+	return entry.history, entry.fetchedAt, nil
+}
+
+// handleEarnings는 최근 24시간의 시간별 토큰 적립량을 표로 전송합니다
+// usdPerMillionTokens가 설정되어 있으면 달러 환산 금액도 함께 표시합니다
+func handleEarnings(telegramClient *telegram.Client, threadID int, account *config.AccountConfig, usdPerMillionTokens float64) error {
+	history, fetchedAt, err := cachedTokenEarningsHistory(account)
+	if err != nil && len(history) == 0 {
+		log.Printf("Failed to get token earnings history: %v", err)
+		return telegramClient.SendMessage(threadID, fmt.Sprintf(msg("error.metrics"), telegram.EscapeMarkdown(err.Error())))
+	}
+	if len(history) == 0 {
+		return telegramClient.SendMessage(threadID, fmt.Sprintf(msg("earnings.empty"), telegram.EscapeMarkdown(account.Name), earningsHours))
+	}
+
+	headers := []string{"Time", "Points"}
+	if usdPerMillionTokens > 0 {
+		headers = append(headers, "USD")
+	}
+	table := api.NewTable(headers...).SetAlign(1, api.AlignRight).SetAlign(2, api.AlignRight)
+	var totalTokens int64
+	for _, h := range history {
+		tokens := int64(h.Value)
+		totalTokens += tokens
+		row := []string{formatHistoryDate(h.Date), api.FormatPoints(tokens)}
+		if usdPerMillionTokens > 0 {
+			row = append(row, fmt.Sprintf("$%.2f", tokensToUSD(tokens, usdPerMillionTokens)))
+		}
+		table.AddRow(row...)
+	}
+
+	total := api.FormatPoints(totalTokens) + " pt"
+	if usdPerMillionTokens > 0 {
+		total += fmt.Sprintf(" ($%.2f)", tokensToUSD(totalTokens, usdPerMillionTokens))
+	}
+	title := fmt.Sprintf(msg("earnings.title"), telegram.EscapeMarkdown(account.Name), earningsHours, total,
+		api.ReportTime(fetchedAt).Format("15:04")) + partialTeamsNote(err)
+	lines := append(table.Lines(), "", api.PointsNote())
+	return sendPages(telegramClient, threadID, chunkCodeBlocks(title, lines))
+}
+
+// tokensToUSD는 토큰 수를 100만 토큰당 달러 비율로 환산합니다
+func tokensToUSD(tokens int64, usdPerMillionTokens float64) float64 {
+	return float64(tokens) / 1e6 * usdPerMillionTokens
+}
+
+// formatHistoryDate는 RFC3339 기록 시간을 보고 시간대의 "01-02 15:04"로 바꾸며, 해석할 수 없으면 그대로 반환합니다
+func formatHistoryDate(date string) string {
+	t, err := time.Parse(time.RFC3339, date)
+	if err != nil {
+		return date
+	}
+	return api.ReportTime(t).Format("01-02 15:04")
+}
+
+// requesterName은 명령어를 보낸 사용자의 이름(없으면 ID)을 반환합니다
+func requesterName(update telegram.Update) string {
+	if update.Message.From.Username != "" {
+		return update.Message.From.Username
+	}
+	return strconv.FormatInt(update.Message.From.ID, 10)
+}
+
+// handleRebootAll은 heartbeat 타임아웃 감지 로직을 즉시 한 번 실행하여 해당 인스턴스를 모두 재부팅합니다
+// 인스턴스마다 로그를 확인하느라 오래 걸리므로 백그라운드에서 실행하고 결과는 명령어 스레드로 보냅니다
+func handleRebootAll(telegramClient *telegram.Client, update telegram.Update, account *config.AccountConfig, monitoring api.MonitoringConfig) error {
+	threadID := update.Message.MessageThreadID
+	if !account.Vastai.Enabled {
+		return telegramClient.SendMessage(threadID,
+			fmt.Sprintf(msg("error.vastaiDisabled"), telegram.EscapeMarkdown(account.Name)))
+	}
+
+	requester := requesterName(update)
+	log.Printf("Bulk reboot requested for %s by %s at %s", account.Name, requester, time.Now().Format(time.RFC3339))
+
+	vastaiClient := api.NewVastaiClient(account.Vastai.Token)
+	if err := vastaiClient.SetMonitoringConfig(monitoring); err != nil {
+		log.Printf("Invalid monitoring config for %s, using defaults: %v", account.Name, err)
+	}
+
+	go func() {
+		sendAlert := func(message, alertType string) error {
+			return telegramClient.SendMessage(threadID, message)
+		}
+		if err := vastaiClient.StartContinuousMonitoring(context.Background(), sendAlert, true); err != nil {
+			log.Printf("[ERROR] Bulk reboot for %s requested by %s failed: %v", account.Name, requester, err)
+			if err := telegramClient.SendMessage(threadID, fmt.Sprintf(msg("rebootall.failed"), telegram.EscapeMarkdown(err.Error()))); err != nil {
+				log.Printf("[ERROR] Failed to send bulk reboot result: %v", err)
+			}
+			return
+		}
+
+		count := vastaiClient.LastRebootCount()
+		log.Printf("Bulk reboot for %s requested by %s rebooted %d instances", account.Name, requester, count)
+		if err := telegramClient.SendMessage(threadID, fmt.Sprintf(msg("rebootall.done"), count)); err != nil {
+			log.Printf("[ERROR] Failed to send bulk reboot result: %v", err)
+		}
+	}()
+
+	return telegramClient.SendMessage(threadID, msg("rebootall.started"))
+}
+
+// selfTestPhase는 /selftest에서 측정한 한 단계의 소요 시간과 결과입니다
+type selfTestPhase struct {
+	name    string
+	elapsed time.Duration
+	err     error
+}
+
+// handleSelfTest는 로그인, Kuzco 메트릭스 각 단계, Vast.ai 조회를 한 번씩 실행하며 단계별 소요 시간을 측정합니다
+// 전체 수집은 오래 걸릴 수 있으므로 백그라운드에서 실행하고 결과는 명령어 스레드로 보냅니다
+func handleSelfTest(telegramClient *telegram.Client, update telegram.Update, account *config.AccountConfig, monitoring api.MonitoringConfig) error {
+	threadID := update.Message.MessageThreadID
+	log.Printf("Self test for %s requested by %s", account.Name, requesterName(update))
+
+	go func() {
+		var phases []selfTestPhase
+		timePhase := func(name string, fn func() error) error {
+			start := time.Now()
+			err := fn()
+			phases = append(phases, selfTestPhase{name: name, elapsed: time.Since(start), err: err})
+			return err
+		}
+		total := time.Now()
+
+		var client *api.Client
+		var userID string
+		err := timePhase("login", func() (err error) {
+			client, userID, err = loginAccount(account)
+			return err
+		})
+		if err == nil {
+			client.SetMonitoringConfig(monitoring)
+			kuzcoClient := api.NewKuzcoClient(client)
+			kuzcoClient.StepHook = func(name string, elapsed time.Duration, err error) {
+				phases = append(phases, selfTestPhase{name: name, elapsed: elapsed, err: err})
+			}
+			kuzcoClient.GetAllMetrics(userID)
+		}
+
+		if account.Vastai.Enabled {
+			vastaiClient := api.NewVastaiClient(account.Vastai.Token)
+			vastaiClient.SetCostSource(account.Vastai.CostSource)
+			timePhase("vastai credit", func() error {
+				_, err := vastaiClient.GetCredit()
+				return err
+			})
+			timePhase("vastai daily cost", func() error {
+				_, err := vastaiClient.GetDailyCost()
+				return err
+			})
+			timePhase("vastai instances", func() error {
+				_, err := vastaiClient.GetInstances()
+				return err
+			})
+		}
+
+		elapsed := time.Since(total)
+		log.Printf("Self test for %s finished in %s", account.Name, elapsed.Round(time.Millisecond))
+		title := fmt.Sprintf(msg("selftest.title"), telegram.EscapeMarkdown(account.Name), elapsed.Round(time.Millisecond))
+		if err := sendPages(telegramClient, threadID, chunkCodeBlocks(title, selfTestLines(phases))); err != nil {
+			log.Printf("[ERROR] Failed to send self test result: %v", err)
+		}
+	}()
+
+	return telegramClient.SendMessage(threadID, msg("selftest.started"))
+}
+
+// selfTestLines는 단계별 소요 시간을 표로 만들고, 가장 오래 걸린 단계를 표시합니다
+func selfTestLines(phases []selfTestPhase) []string {
+	slowest := -1
+	for i, phase := range phases {
+		if slowest < 0 || phase.elapsed > phases[slowest].elapsed {
+			slowest = i
+		}
+	}
+
+	table := api.NewTable("Phase", "Time", "Result").SetAlign(1, api.AlignRight)
+	for i, phase := range phases {
+		name := phase.name
+		if i == slowest {
+			name += " *"
+		}
+		result := "ok"
+		if phase.err != nil {
+			result = phase.err.Error()
+		}
+		table.AddRow(name, phase.elapsed.Round(time.Millisecond).String(), result)
+	}
+	return append(table.Lines(), "", "* slowest phase")
+}
+
+// chunkCodeBlocks는 줄 목록을 텔레그램 길이 제한에 맞는 코드 블록 페이지로 나눕니다
+// 제목은 첫 페이지에만 붙으며, 너무 긴 줄은 잘라냅니다
+func chunkCodeBlocks(title string, lines []string) []string {
+	const maxLineLength = 500
+
+	trimmed := make([]string, len(lines))
+	for i, line := range lines {
+		if len(line) > maxLineLength {
+			line = line[:maxLineLength] + "…"
+		}
+		trimmed[i] = line
+	}
+	// 페이지 나누기와 코드 블록 다시 열기는 SplitMessage가 처리
+	return api.SplitMessage(title+"\n"+api.CodeBlock(strings.Join(trimmed, "\n")), telegramMessageLimit)
+}
+
+// splitCommandFlags는 명령어 인자에서 "--"로 시작하는 플래그와 "key=value" 형식의 옵션을 분리합니다
+func splitCommandFlags(fields []string) (args []string, flags map[string]bool, options map[string]string) {
+	flags = make(map[string]bool)
+	options = make(map[string]string)
+	for _, field := range fields {
+		if strings.HasPrefix(field, "--") {
+			flags[field] = true
+			continue
+		}
+		if key, value, ok := strings.Cut(field, "="); ok && key != "" {
+			options[key] = value
+			continue
+		}
+		args = append(args, field)
+	}
+	return args, flags, options
+}
+
+// loginAccount는 계정으로 Kuzco에 로그인한 클라이언트와 사용자 ID를 반환합니다
+func loginAccount(account *config.AccountConfig) (*api.Client, string, error) {
+	client := api.NewClient()
+	client.SetUserAgent(account.Kuzco.UserAgent)
+	client.SetTeams(account.Kuzco.Teams)
+
+	token, userID, err := client.Login(account.Kuzco.Email, account.Kuzco.Password)
+	if err != nil {
+		return nil, "", err
+	}
+	client.SetToken(token)
+	return client, userID, nil
+}
+
+// handleTelegramCommand processes telegram bot commands
+func handleTelegramCommand(update telegram.Update, telegramClient *telegram.Client, cfg *config.Config) error {
+	fields := strings.Fields(update.Message.Text)
+	if len(fields) == 0 {
+		return nil
+	}
+	command := fields[0]
+	log.Printf("Processing command: %s", command)
+
+	// /alerts 조회는 누구나, /alerts ack은 권한이 있는 사용자만 실행할 수 있습니다
+	privileged := privilegedCommands[command] || (command == "/alerts" && len(fields) > 1 && fields[1] == "ack")
+	if !telegramSettings(cfg).IsUserAllowed(update.Message.From.ID, privileged) {
+		log.Printf("[WARN] Unauthorized command %s from user %d (%s)", command, update.Message.From.ID, update.Message.From.Username)
+		return telegramClient.SendMessage(update.Message.MessageThreadID, msg("error.unauthorized"))
+	}
+
+	// /snooze 명령어는 계정과 무관하게 모든 알림을 일시 중지합니다
+	if command == "/snooze" {
+		return handleSnooze(telegramClient, update, fields[1:])
+	}
+
+	// /ignore, /unignore 명령어는 Vast.ai 인스턴스 ID로 지정하므로 계정과 무관합니다
+	if command == "/ignore" || command == "/unignore" {
+		return handleIgnore(telegramClient, update, command, fields[1:])
+	}
+
+	// /threads 명령어는 명령어를 보낸 토픽의 스레드 ID를 알려줍니다 (설정 전이라 계정이 없어도 동작)
+	if command == "/threads" {
+		return handleThreads(telegramClient, update, cfg)
+	}
+
+	// /price 명령어는 instance.json에서 불러온 가격을 보여주므로 계정과 무관합니다
+	if command == "/price" {
+		return handlePrice(telegramClient, update.Message.MessageThreadID, strings.Join(fields[1:], " "))
+	}
+
+	// /config 명령어는 비밀 값을 가린 현재 설정을 전송합니다
+	if command == "/config" {
+		log.Printf("Sending redacted config to %s", requesterName(update))
+		return handleConfig(telegramClient, update.Message.MessageThreadID, cfg)
+	}
+
+	// 명령어 고유 인자 뒤에 계정 이름을 지정할 수 있으며, 없으면 기본 계정을 사용합니다
+	if len(cfg.Accounts) == 0 {
+		return telegramClient.SendMessage(update.Message.MessageThreadID, msg("error.noAccounts"))
+	}
+	args, flags, options := splitCommandFlags(fields[1:])
+	accountArgIndex := commandArgCounts[command]
+	if command == "/alerts" && len(args) > 0 && args[0] == "ack" {
+		accountArgIndex = 2 // /alerts ack <type> [account]
+	}
+	if command == "/charges" && len(args) > 0 {
+		if _, isAccount := cfg.FindAccount(args[0]); !isAccount {
+			accountArgIndex = 1 // /charges <YYYY-MM-DD> [account]
+		}
+	}
+	accountName := cfg.PrimaryAccountName()
+	if len(args) > accountArgIndex {
+		accountName = args[accountArgIndex]
+	}
+	account, ok := cfg.FindAccount(accountName)
+	if !ok {
+		return telegramClient.SendMessage(update.Message.MessageThreadID,
+			fmt.Sprintf(msg("error.unknownAccount"), telegram.EscapeMarkdown(accountName)))
+	}
+
+	// /report 명령어는 최신 데이터를 가져옵니다
+	if command == "/report" {
+		log.Printf("Generating fresh report for %s", account.Name)
+
+		// 로그인
+		client, userID, err := loginAccount(account)
+		if err != nil {
+			log.Printf("Login failed: %v", err)
+			return telegramClient.SendMessage(update.Message.MessageThreadID, fmt.Sprintf(msg("error.login"), telegram.EscapeMarkdown(err.Error())))
+		}
+
+		// 최신 메트릭스 수집
+		kuzcoClient := api.NewKuzcoClient(client)
+		metrics, err := kuzcoClient.GetAllMetrics(userID)
+		if err != nil && metrics == nil {
+			log.Printf("Failed to get metrics: %v", err)
+			return telegramClient.SendMessage(update.Message.MessageThreadID, fmt.Sprintf(msg("error.metrics"), telegram.EscapeMarkdown(err.Error())))
+		}
+		partialErr := err
+		if partialErr != nil {
+			log.Printf("Partial metrics collected: %v", partialErr)
+		}
+
+		// Vastai 정보 가져오기 (활성화된 경우)
+		var vastaiCredit *api.VastaiCredit
+		var vastaiCost float64
+
+		if account.Vastai.Enabled {
+			vastaiClient := api.NewVastaiClient(account.Vastai.Token)
+			vastaiClient.SetCostSource(account.Vastai.CostSource)
+
+			// 크레딧 정보 가져오기
+			credit, err := vastaiClient.GetCredit()
+			if err != nil {
+				log.Printf("Failed to get vastai credit: %v", err)
+			} else {
+				vastaiCredit = credit
+			}
+
+			// 비용 정보 가져오기 (포함하도록 설정된 경우)
+			if account.Vastai.IncludeVastaiCost {
+				cost, err := vastaiClient.GetDailyCost()
+				if err != nil {
+					log.Printf("Failed to get vastai cost: %v", err)
+				} else {
+					vastaiCost = cost
+				}
+			}
+		}
+
+		// 효율성 계산
+		vastaiEfficiency := 0.0
+		kuzcoEfficiency := 0.0
+		if metrics.User.Share > 0 {
+			vastaiEfficiency = vastaiCost / (metrics.User.Share * 100)
+			kuzcoEfficiency = metrics.User.TotalDailyCost / (metrics.User.Share * 100)
+		}
+
+		// 포인트 환산 및 단위 결정 (K, M, B)
+		myPointsFormatted := api.FormatPoints(metrics.User.TokensLast24Hours)
+		totalPointsFormatted := api.FormatPoints(metrics.General.TokensLast24Hours)
+
+		// 응답 메시지 생성
+		response := fmt.Sprintf(msg("report.template"),
+			myPointsFormatted,
+			totalPointsFormatted,
+			metrics.User.Share*100,
+			vastaiCost,
+			metrics.User.TotalDailyCost,
+			api.FormatEfficiency(vastaiEfficiency),
+			api.FormatEfficiency(kuzcoEfficiency))
+
+		// Vastai 크레딧 정보 추가
+		if vastaiCredit != nil {
+			response += fmt.Sprintf(msg("report.balance"), vastaiCredit.Credit)
+		}
+		response += "\n(" + api.PointsNote() + ")"
+
+		// 일부 메트릭스 수집 실패 안내
+		if partialErr != nil {
+			response += fmt.Sprintf(msg("report.partial"), telegram.EscapeMarkdown(partialErr.Error()))
+		}
+
+		return telegramClient.SendMessage(update.Message.MessageThreadID, response)
+	}
+
+	// /logs 명령어는 인스턴스 로그를 요청하여 전송합니다
+	if command == "/logs" {
+		log.Printf("Fetching logs for %v (%s)", args, account.Name)
+		return handleInstanceLogs(telegramClient, update.Message.MessageThreadID, account, cfg.Monitoring, args)
+	}
+
+	// /workers sort=gen|count|name 으로 워커 표 정렬 기준을 바꿀 수 있습니다
+	workerSort := cfg.Reporting.WorkerSortOrder()
+	if command == "/workers" {
+		if order, ok := options["sort"]; ok {
+			if !config.ValidWorkerSort(order) {
+				return telegramClient.SendMessage(update.Message.MessageThreadID, fmt.Sprintf(msg("workers.invalidSort"), telegram.EscapeMarkdown(order)))
+			}
+			workerSort = order
+		}
+	}
+
+	// /workers --all 명령어는 보관된 워커를 포함하여 새로 조회합니다
+	if command == "/workers" && flags["--all"] {
+		log.Printf("Getting worker stats including archived workers for %s", account.Name)
+
+		client, _, err := loginAccount(account)
+		if err != nil {
+			log.Printf("Login failed: %v", err)
+			return telegramClient.SendMessage(update.Message.MessageThreadID, fmt.Sprintf(msg("error.login"), telegram.EscapeMarkdown(err.Error())))
+		}
+		workers, err := client.GetWorkers(true)
+		if err != nil {
+			log.Printf("Failed to get workers: %v", err)
+			return telegramClient.SendMessage(update.Message.MessageThreadID, fmt.Sprintf(msg("error.metrics"), telegram.EscapeMarkdown(err.Error())))
+		}
+
+		var metrics api.MinuteMetrics
+		for _, w := range workers {
+			metrics.User.Workers = append(metrics.User.Workers, api.NewWorkerMinuteMetrics(w))
+		}
+		return sendPages(telegramClient, update.Message.MessageThreadID, formatWorkerStats(&metrics, workerSort, cfg.Reporting.WorkersPerPageLimit()))
+	}
+
+	// /restart 명령어는 확인 후 인스턴스를 재부팅합니다
+	if command == "/restart" {
+		return handleRestartInstance(telegramClient, update, account, args)
+	}
+
+	if command == "/rebootall" {
+		return handleRebootAll(telegramClient, update, account, cfg.Monitoring)
+	}
+
+	// /selftest 명령어는 수집 한 주기를 실행하며 단계별 소요 시간을 측정합니다
+	if command == "/selftest" {
+		return handleSelfTest(telegramClient, update, account, cfg.Monitoring)
+	}
+
+	// /instances 명령어는 Vast.ai 인스턴스 목록을 새로 조회합니다
+	if command == "/instances" {
+		log.Printf("Listing Vast.ai instances")
+
+		if !account.Vastai.Enabled {
+			return telegramClient.SendMessage(update.Message.MessageThreadID,
+				fmt.Sprintf(msg("error.vastaiDisabled"), telegram.EscapeMarkdown(account.Name)))
+		}
+
+		instances, err := api.NewVastaiClient(account.Vastai.Token).GetInstances()
+		if err != nil {
+			log.Printf("Failed to get vastai instances: %v", err)
+			return telegramClient.SendMessage(update.Message.MessageThreadID, fmt.Sprintf(msg("error.vastaiInstances"), telegram.EscapeMarkdown(err.Error())))
+		}
+
+		return telegramClient.SendMessage(update.Message.MessageThreadID, formatInstances(instances, getCurrentMetrics(account.Name)))
+	}
+
+	// /vast 명령어는 Kuzco와 무관하게 Vast.ai API만 조회합니다
+	if command == "/vast" {
+		log.Printf("Checking raw Vast.ai status for %s", account.Name)
+
+		if !account.Vastai.Enabled {
+			return telegramClient.SendMessage(update.Message.MessageThreadID,
+				fmt.Sprintf(msg("error.vastaiDisabled"), telegram.EscapeMarkdown(account.Name)))
+		}
+
+		vastaiClient := api.NewVastaiClient(account.Vastai.Token)
+		credit, err := vastaiClient.GetCredit()
+		if err != nil {
+			log.Printf("Failed to get vastai credit: %v", err)
+		}
+		instanceCount, err := vastaiClient.GetInstanceCount()
+		if err != nil {
+			log.Printf("Failed to get vastai instance count: %v", err)
+			instanceCount = -1
+		}
+		instances, err := vastaiClient.GetInstances()
+		if err != nil {
+			log.Printf("Failed to get vastai instances: %v", err)
+		}
+
+		return telegramClient.SendMessage(update.Message.MessageThreadID, formatVastStatus(account.Name, credit, instanceCount, instances))
+	}
+
+	if command == "/charges" {
+		log.Printf("Getting Vast.ai charges for %s", account.Name)
+		return handleCharges(telegramClient, update.Message.MessageThreadID, account, args)
+	}
+
+	// /genhours 명령어는 최근 n시간 생성량 기록을 새로 조회하여 표로 전송합니다
+	if command == "/genhours" {
+		log.Printf("Getting generations history by hour for %s", account.Name)
+		return handleGenHours(telegramClient, update.Message.MessageThreadID, account, args)
+	}
+
+	// /earnings 명령어는 최근 24시간 시간별 토큰 적립량을 표시합니다 (10분간 캐시)
+	if command == "/earnings" {
+		log.Printf("Getting token earnings history for %s", account.Name)
+		return handleEarnings(telegramClient, update.Message.MessageThreadID, account, cfg.Reporting.USDPerMillionTokens)
+	}
+
+	// /genhistory 명령어는 최근 24시간 생성량 기록을 새로 조회하여 차트로 전송합니다
+	if command == "/genhistory" {
+		log.Printf("Charting generations history for %s", account.Name)
+		return handleGenHistory(telegramClient, update.Message.MessageThreadID, account)
+	}
+
+	// /alerts 명령어는 현재 활성화된 알림을 표시하거나 확인 처리합니다
+	if command == "/alerts" {
+		return handleAlerts(telegramClient, update.Message.MessageThreadID, account.Name, args)
+	}
+
+	// /total 명령어는 모든 계정의 캐시된 메트릭스를 합산합니다
+	if command == "/total" {
+		log.Printf("Generating combined report for all accounts")
+		all := getAllAccountMetrics()
+		if len(all) == 0 {
+			return telegramClient.SendMessage(update.Message.MessageThreadID, msg("error.noMetrics"))
+		}
+		return telegramClient.SendMessage(update.Message.MessageThreadID, formatTotalReport(all))
+	}
+
+	// 다른 명령어는 캐시된 메트릭스 사용
+	metrics := getCurrentMetrics(account.Name)
+	if metrics == nil {
+		log.Printf("[ERROR] No metrics available for command: %s", command)
+		response := msg("error.noMetrics")
+		return telegramClient.SendMessage(update.Message.MessageThreadID, response)
+	}
+
+	var response string
+
+	switch command {
+	case "/help":
+		log.Printf("Generating help message")
+		response = msg("help")
+
+	case "/export":
+		log.Printf("Exporting metrics for %s", account.Name)
+		data, err := json.MarshalIndent(metrics, "", "  ")
+		if err != nil {
+			return telegramClient.SendMessage(update.Message.MessageThreadID, fmt.Sprintf(msg("error.export"), telegram.EscapeMarkdown(err.Error())))
+		}
+		filename := fmt.Sprintf("metrics-%s-%s.json", account.Name, api.ReportTime(time.Now()).Format("20060102-150405"))
+		return telegramClient.SendDocument(update.Message.MessageThreadID, filename, data)
+
+	case "/top":
+		log.Printf("Getting top/bottom workers")
+		response = formatTopWorkers(metrics)
+
+	case "/unpriced":
+		log.Printf("Checking GPU models without price")
+		prices, err := api.LoadGPUPrices("instance.json")
+		if err != nil {
+			return telegramClient.SendMessage(update.Message.MessageThreadID, fmt.Sprintf(msg("error.gpuPrices"), telegram.EscapeMarkdown(err.Error())))
+		}
+		response = formatUnpricedGPUs(api.UnpricedGPUModels(metrics.User.Workers, prices))
+
+	case "/geo":
+		log.Printf("Getting instance locations")
+		response = formatGeo(metrics)
+
+	case "/dupes":
+		log.Printf("Checking duplicate instance IPs")
+		response = formatDuplicateIPs(metrics)
+
+	case "/gpuhealth":
+		log.Printf("Getting GPU readings")
+		// SIGHUP으로 알림 설정이 바뀔 수 있으므로 잠금을 잡고 복사
+		configLock.RLock()
+		alerts := account.Alerts
+		configLock.RUnlock()
+		response = formatGPUHealth(metrics, alerts)
+
+	case "/teams":
+		log.Printf("Getting per-team metrics")
+		response = formatTeams(metrics)
+
+	case "/diff":
+		log.Printf("Getting changes since the last hourly report")
+		if previous := getReportSnapshot(account.Name); previous != nil {
+			response = formatMetricsDiff(previous, metrics)
+		} else {
+			response = msg("diff.empty")
+		}
+
+	case "/lanes":
+		log.Printf("Getting lane stats")
+		response = formatLaneStats(metrics)
+
+	case "/balance":
+		log.Printf("Checking balance")
+		if metrics.User.VastaiCredit != nil {
+			response = fmt.Sprintf(msg("balance.value"), metrics.User.VastaiCredit.Credit)
+			log.Printf("Balance: $%.2f", metrics.User.VastaiCredit.Credit)
+		} else {
+			response = msg("balance.unavailable")
+			log.Printf("Balance information not available")
+		}
+
+	case "/status":
+		log.Printf("Checking status")
+		response = fmt.Sprintf(msg("status.counts"),
+			metrics.User.TotalInstances,
+			metrics.User.ActualTotalInstances,
+			metrics.User.GenerationsPerInstance,
+			formatStatusHistogram(metrics))
+		if geo := formatGeoSummary(metrics); geo != "" {
+			response += "\n" + geo
+		}
+		log.Printf("Status - Vast.Ai: %d, Actual Instances: %d",
+			metrics.User.TotalInstances,
+			metrics.User.ActualTotalInstances)
+
+	case "/cost":
+		log.Printf("Calculating costs")
+		response = fmt.Sprintf(msg("cost.kuzco"), metrics.User.KuzcoDailyCost)
+		log.Printf("Kuzco daily cost: $%.2f", metrics.User.KuzcoDailyCost)
+
+		if metrics.User.VastaiCredit != nil {
+			response += fmt.Sprintf(msg("cost.vastai"), metrics.User.VastaiDailyCost)
+			if metrics.User.VastaiHourlyBurn > 0 {
+				response += fmt.Sprintf(msg("cost.burn"), metrics.User.VastaiHourlyBurn)
+			}
+			response += fmt.Sprintf(msg("cost.balance"), metrics.User.VastaiCredit.Credit)
+			log.Printf("Vast.ai daily cost: $%.2f, Credit: $%.2f",
+				metrics.User.VastaiDailyCost,
+				metrics.User.VastaiCredit.Credit)
+
+			if metrics.User.VastaiCredit.Credit <= metrics.User.VastaiDailyCost {
+				response += msg("cost.lowBalance")
+				log.Printf("WARNING: Credit is less than daily cost")
+			}
+
+			if metrics.User.VastaiDailyCost > 0 {
+				daysLeft := metrics.User.VastaiCredit.Credit / metrics.User.VastaiDailyCost
+				response += fmt.Sprintf(msg("cost.daysLeft"), daysLeft)
+				log.Printf("Estimated days left: %.1f", daysLeft)
+			}
+		} else {
+			log.Printf("Vast.ai credit information not available")
+		}
+
+	case "/forecast":
+		log.Printf("Calculating per-worker credit forecast")
+		response = formatForecast(metrics)
+
+	case "/hourly":
+		log.Printf("Getting hourly stats")
+		stats := api.HourlyStatsFor(account.Name).GetStats()
+		response = formatHourlyStats(stats)
+		log.Printf("Hourly stats generated")
+
+	case "/daily":
+		log.Printf("Getting daily stats")
+		response = formatDailyStats(api.DailyStatsFor(account.Name).GetStats())
+
+	case "/workers":
+		log.Printf("Getting worker stats")
+		pages := formatWorkerStats(metrics, workerSort, cfg.Reporting.WorkersPerPageLimit())
+		if err := sendPages(telegramClient, update.Message.MessageThreadID, pages); err != nil {
+			log.Printf("Error sending worker pages: %v", err)
+			return err
+		}
+		log.Printf("Worker stats generated")
+		return nil
+
+	default:
+		log.Printf("Unknown command: %s", command)
+		return nil
+	}
+
+	log.Printf("Sending response to thread %d", update.Message.MessageThreadID)
+	return telegramClient.SendMessage(update.Message.MessageThreadID, response)
+}
+
+// runThreadSelfTest는 설정된 각 스레드에 점검 메시지를 보내 잘못된 스레드 ID를 시작 시점에 드러냅니다
+func runThreadSelfTest(telegramClient *telegram.Client, threads config.TelegramThreads) {
+	configured := threads.Configured()
+	names := make([]string, 0, len(configured))
+	for name := range configured {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var failed []string
+	for _, name := range names {
+		threadID := configured[name]
+		if err := telegramClient.SendMessage(threadID, fmt.Sprintf(msg("selftest.message"), name)); err != nil {
+			log.Printf("[ERROR] Startup test failed for %s thread (%d): %v", name, threadID, err)
+			failed = append(failed, name)
+		}
+	}
+
+	if len(failed) > 0 {
+		log.Printf("[ERROR] Startup test: %d/%d threads failed: %s", len(failed), len(names), strings.Join(failed, ", "))
+		return
+	}
+	log.Printf("Startup test: all %d configured threads reachable", len(names))
+}
+
+// formatStartupSummary는 계정 수, Vast.ai 사용 여부, 적용 중인 알림 기준, 보고서 시각, 버전을 요약합니다
+func formatStartupSummary(cfg *config.Config) string {
+	accounts := make([]string, 0, len(cfg.Accounts))
+	for _, account := range cfg.Accounts {
+		vastai := "off"
+		if account.Vastai.Enabled {
+			vastai = "on"
+		}
+		alerts := "off"
+		if account.Alerts.Enabled {
+			dropPercent, dropWindow := account.Alerts.TokenDropThreshold()
+			alerts = fmt.Sprintf("instances<%d, credit<$%.2f, drop %.0f%%/%s",
+				account.Alerts.MinInstanceCount, account.Alerts.MinCredit, dropPercent, dropWindow)
+		}
+		accounts = append(accounts, fmt.Sprintf("%-12s | vast %-3s | alerts %s", account.Name, vastai, alerts))
+	}
+
+	dailyTime := "09:00"
+	if cfg.Reporting.DailyWorkerTime != "" {
+		dailyTime = cfg.Reporting.DailyWorkerTime
+	}
+	timezone := cfg.Reporting.Timezone
+	if timezone == "" {
+		timezone = "Local"
+	}
+
+	summary := fmt.Sprintf(msg("startup.title"), buildVersion(), len(cfg.Accounts), telegram.EscapeMarkdown(cfg.PrimaryAccountName()))
+	if len(accounts) > 0 {
+		summary += "\n" + api.CodeBlock(strings.Join(accounts, "\n"))
+	}
+	// features로 끈 보고서는 표시하지 않음
+	var reports []string
+	if cfg.Features.HourlyReportEnabled() {
+		reports = append(reports, msg("startup.reportHourly"))
+	}
+	if cfg.Features.DailyWorkerReportEnabled() {
+		reports = append(reports, fmt.Sprintf(msg("startup.reportWorkers"), dailyTime, telegram.EscapeMarkdown(timezone)))
+	}
+	if cfg.Features.WeeklyReportEnabled() {
+		reports = append(reports, fmt.Sprintf(msg("startup.reportWeekly"), dailyTime))
+	}
+	reports = append(reports, msg("startup.reportDaily"))
+	return summary + "\n" + fmt.Sprintf(msg("startup.reports"), strings.Join(reports, " | "))
+}
+
+// handleThreads는 명령어가 실행된 토픽의 message_thread_id와 chat_id를 알려주고,
+// 같은 ID가 이미 설정된 스레드(전역 또는 계정별)가 있으면 함께 표시합니다
+func handleThreads(telegramClient *telegram.Client, update telegram.Update, cfg *config.Config) error {
+	threadID := update.Message.MessageThreadID
+	if threadID == 0 {
+		return telegramClient.SendMessage(threadID, fmt.Sprintf(msg("threads.noTopic"), update.Message.Chat.ID))
+	}
+
+	configLock.RLock()
+	var matches []string
+	for name, id := range cfg.Telegram.Threads.Configured() {
+		if id == threadID {
+			matches = append(matches, name)
+		}
+	}
+	for _, account := range cfg.Accounts {
+		for name, id := range account.Telegram.Threads.Configured() {
+			if id == threadID {
+				matches = append(matches, account.Name+"."+name)
+			}
+		}
+	}
+	configLock.RUnlock()
+	sort.Strings(matches)
+
+	message := fmt.Sprintf(msg("threads.id"), threadID, update.Message.Chat.ID)
+	if len(matches) > 0 {
+		message += "\n" + fmt.Sprintf(msg("threads.configured"), telegram.EscapeMarkdown(strings.Join(matches, ", ")))
+	} else {
+		message += "\n" + msg("threads.unconfigured")
+	}
+	return telegramClient.SendMessage(threadID, message)
+}
+
+// handleConfig는 비밀번호와 토큰을 "***"로 가린 현재 설정과 계정별 실제 적용 스레드를 전송합니다
+func handleConfig(telegramClient *telegram.Client, threadID int, cfg *config.Config) error {
+	configLock.RLock()
+	out, err := cfg.RedactedYAML()
+	threads := make([]string, 0, len(cfg.Accounts))
+	for _, account := range cfg.Accounts {
+		threads = append(threads, fmt.Sprintf("#   %s: %+v", account.Name, cfg.AccountThreads(account.Name)))
+	}
+	configLock.RUnlock()
+	if err != nil {
+		return telegramClient.SendMessage(threadID, fmt.Sprintf(msg("config.error"), telegram.EscapeMarkdown(err.Error())))
+	}
+
+	lines := strings.Split(strings.TrimRight(out, "\n"), "\n")
+	if len(threads) > 0 {
+		lines = append(lines, "", "# effective threads")
+		lines = append(lines, threads...)
+	}
+	return sendPages(telegramClient, threadID, chunkCodeBlocks(fmt.Sprintf(msg("config.title"), buildVersion()), lines))
+}
+
+// version은 빌드 시 -ldflags "-X main.version=..."으로 설정하는 모니터 버전입니다
+var version = ""
+
+// buildVersion은 설정된 버전, 없으면 빌드 정보의 VCS 리비전, 둘 다 없으면 "dev"를 반환합니다
+func buildVersion() string {
+	if version != "" {
+		return version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" && len(setting.Value) >= 7 {
+				return setting.Value[:7]
+			}
+		}
+	}
+	return "dev"
+}
+
+// telegramConflictBackoff는 getUpdates가 409 Conflict를 반환했을 때 재시도 전 대기 시간입니다
+const telegramConflictBackoff = 60 * time.Second
+
+// startTelegramBot starts the telegram bot and listens for updates
+func startTelegramBot(telegramClient *telegram.Client, cfg *config.Config) {
+	log.Printf("Starting Telegram bot...")
+	offsetPath := cfg.Telegram.OffsetPath()
+	offset, err := telegram.LoadOffset(offsetPath)
+	if err != nil {
+		log.Printf("Failed to load Telegram offset, starting from 0: %v", err)
+	}
+	telegramClient.PollTimeout = cfg.Telegram.PollTimeout()
+	for {
+		updates, err := telegramClient.GetUpdates(offset)
+		var conflictErr *telegram.ConflictError
+		if errors.As(err, &conflictErr) {
+			// 같은 봇 토큰으로 다른 인스턴스가 폴링 중이면 오래 기다린 후 재시도
+			log.Printf("[WARN] Another instance is polling this bot token, retrying in %s: %v", telegramConflictBackoff, err)
+			time.Sleep(telegramConflictBackoff)
+			continue
+		}
+		if err != nil {
+			log.Printf("[ERROR] Failed to get updates: %v", err)
+			time.Sleep(5 * time.Second)
+			continue
+		}
+
+		for _, update := range updates {
+			log.Printf("Received command: %s in thread %d", update.Message.Text, update.Message.MessageThreadID)
+			if err := handleUpdate(update, telegramClient, cfg); err != nil {
+				log.Printf("[ERROR] Failed to handle command '%s': %v", update.Message.Text, err)
+			} else {
+				log.Printf("Successfully handled command: %s", update.Message.Text)
+			}
+			offset = update.UpdateID + 1
+			if err := telegram.SaveOffset(offsetPath, offset); err != nil {
+				log.Printf("[WARN] Failed to persist Telegram offset: %v", err)
+			}
+		}
+
+		// 롱 폴링은 새 업데이트가 올 때까지 대기하므로 짧은 폴링일 때만 쉬어 감
+		if telegramClient.PollTimeout == 0 {
+			time.Sleep(1 * time.Second)
+		}
+	}
+}
+
+// handleUpdate는 handleTelegramCommand를 실행하고, 명령어 처리 중 panic이 나면 오류로 바꿔 반환합니다
+// 잘못된 명령어 하나로 봇 루프 전체가 다시 시작되지 않도록 업데이트마다 복구합니다
+func handleUpdate(update telegram.Update, telegramClient *telegram.Client, cfg *config.Config) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("[ERROR] Command '%s' panicked: %v\n%s", update.Message.Text, r, debug.Stack())
+			err = fmt.Errorf("command panicked: %v", r)
+		}
+	}()
+	return handleTelegramCommand(update, telegramClient, cfg)
+}
+
+// startHourlyReporter starts the automatic hourly report sender
+func startHourlyReporter(telegramClient *telegram.Client, cfg *config.Config, accountName string) {
+	log.Printf("Starting hourly reporter for %s...", accountName)
+
+	// 개발 모드 체크
+	isDev := os.Getenv("ENV") == "dev"
+
+	// 타이머 간격 설정
+	var reportInterval time.Duration
+	var initialDelay time.Duration
+
+	if isDev {
+		// 개발 모드에서는 2분 간격으로 보고서 전송
+		reportInterval = 2 * time.Minute
+		// 다음 짝수 분(0, 2, 4...)에 맞춰 시작
+		now := api.ReportTime(time.Now())
+		nextEvenMinute := time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), ((now.Minute()/2)+1)*2, 0, 0, now.Location())
+		initialDelay = nextEvenMinute.Sub(now)
+		log.Printf("개발 모드: 첫 시간별 보고서 %s 후 전송, 이후 %s 간격으로 전송", initialDelay, reportInterval)
+	} else {
+		// 프로덕션 모드에서는 1시간 간격으로 전송
+		reportInterval = time.Hour
+		// 다음 정시(00분)에 맞춰 시작
+		now := api.ReportTime(time.Now())
+		nextHour := time.Date(now.Year(), now.Month(), now.Day(), now.Hour()+1, 0, 0, 0, now.Location())
+		initialDelay = nextHour.Sub(now)
+		log.Printf("프로덕션 모드: 첫 시간별 보고서 %s 후 전송(정시), 이후 1시간 간격으로 전송", initialDelay)
+	}
+
+	// 초기 지연 후 첫 보고서 전송
+	time.Sleep(initialDelay)
+
+	// 첫 보고서 전송
+	sendHourlyReport(telegramClient, cfg, accountName, isDev)
+
+	// 이후 정기적으로 보고서 전송
+	ticker := time.NewTicker(reportInterval)
+	defer ticker.Stop()
+
+	for {
+		<-ticker.C
+		sendHourlyReport(telegramClient, cfg, accountName, isDev)
+	}
+}
+
+// sendHourlyReport는 시간별 보고서와 워커 보고서를 전송합니다
+// 프로덕션 모드에서는 같은 시간의 보고서가 이미 전송되었으면(재시작 직후 등) 다시 보내지 않습니다
+func sendHourlyReport(telegramClient *telegram.Client, cfg *config.Config, accountName string, isDev bool) {
+	// 타이머가 정시 직전에 실행되어도 같은 시간으로 처리되도록 분 단위로 반올림합니다
+	period := api.ReportTime(time.Now().Round(time.Minute)).Format("2006-01-02T15")
+	if !isDev && api.ReportSent(accountName, api.ReportHourly, period) {
+		log.Printf("시간별 보고서가 이미 전송되어 건너뜁니다 (%s)", period)
+		snapshotReportMetrics(accountName)
+		return
+	}
+
+	log.Printf("시간별 통계 조회 중...")
+	stats := api.HourlyStatsFor(accountName).GetStats()
+	message := withAccountHeader(cfg, accountName, formatHourlyStats(stats))
+
+	threadID := accountThreads(cfg, accountName).Hourly
+	log.Printf("시간별 보고서 스레드 %d로 전송 중...", threadID)
+	notifySinks(message, "hourly")
+	if err := telegramClient.SendMessage(threadID, message); err != nil {
+		log.Printf("[ERROR] 시간별 보고서 전송 실패: %v", err)
+	} else {
+		log.Printf("시간별 보고서 전송 완료")
+		if !isDev {
+			api.MarkReportSent(accountName, api.ReportHourly, period)
+		}
+	}
+
+	// 워커 보고서도 함께 전송
+	sendWorkerReport(telegramClient, cfg, accountName)
+	snapshotReportMetrics(accountName)
+}
+
+// sendRequestedReport는 POST /api/report로 요청된 보고서를 즉시 전송합니다
+// 정기 보고서와 달리 전송 기록을 남기지 않으므로 예정된 보고서는 그대로 전송됩니다
+func sendRequestedReport(telegramClient *telegram.Client, cfg *config.Config, accountName, kind string) error {
+	threads := accountThreads(cfg, accountName)
+	switch kind {
+	case api.ReportHourly:
+		message := withAccountHeader(cfg, accountName, formatHourlyStats(api.HourlyStatsFor(accountName).GetStats()))
+		notifySinks(message, "hourly")
+		return telegramClient.SendMessage(threads.Hourly, message)
+	case api.ReportDaily:
+		// 일일 보고서는 계정의 수집 루프가 정기 보고서와 같은 방식으로 생성하여 Daily 스레드로 전송
+		accountClientsLock.Lock()
+		client := accountClients[accountName]
+		accountClientsLock.Unlock()
+		if client == nil {
+			return fmt.Errorf("metrics collection for %s is not running", accountName)
+		}
+		if !client.RequestDailyReport() {
+			return fmt.Errorf("a daily report for %s is already in progress", accountName)
+		}
+		return nil
+	case api.ReportWorker:
+		metrics := getCurrentMetrics(accountName)
+		if metrics == nil {
+			return fmt.Errorf("no metrics collected yet for %s", accountName)
+		}
+		return sendWorkerPages(telegramClient, cfg, accountName, metrics)
+	}
+	return fmt.Errorf("unknown report type %q", kind)
+}
+
+// sendWorkerReport 함수는 워커 보고서를 생성하고 전송합니다
+func sendWorkerReport(telegramClient *telegram.Client, cfg *config.Config, accountName string) {
+	log.Printf("시간별 워커 보고서 생성 중...")
+	metrics := getCurrentMetrics(accountName)
+	if metrics == nil {
+		log.Printf("[ERROR] 시간별 워커 보고서용 메트릭스가 없습니다")
+		return
+	}
+
+	if err := sendWorkerPages(telegramClient, cfg, accountName, metrics); err != nil {
+		log.Printf("[ERROR] 시간별 워커 보고서 전송 실패: %v", err)
+	} else {
+		log.Printf("시간별 워커 보고서 전송 완료")
+	}
+}
+
+// sendWorkerPages는 워커 보고서를 Workers 스레드와 Slack/Discord(설정된 경우)로 전송합니다
+func sendWorkerPages(telegramClient *telegram.Client, cfg *config.Config, accountName string, metrics *api.MinuteMetrics) error {
+	pages := withAccountHeaderPages(cfg, accountName, formatWorkerStats(metrics, cfg.Reporting.WorkerSortOrder(), cfg.Reporting.WorkersPerPageLimit()))
+	for _, page := range pages {
+		notifySinks(page, "worker")
+	}
+	return sendPages(telegramClient, accountThreads(cfg, accountName).Workers, pages)
+}
+
+// Alerter는 텔레그램과 함께 알림과 정기 보고서를 받는 채널입니다 (Slack, Discord)
+type Alerter interface {
+	SendAlert(message, alertType string) error
+}
+
+// alertSink는 로그에 표시할 이름과 함께 등록된 Alerter입니다
+type alertSink struct {
+	name    string
+	alerter Alerter
+}
+
+// alertSinks는 설정에서 활성화된 채널 목록입니다 (시작 시 newAlertSinks로 설정)
+var alertSinks []alertSink
+
+// newAlertSinks는 slack.enabled, discord.enabled 설정에 따라 채널 목록을 만듭니다
+func newAlertSinks(cfg *config.Config) []alertSink {
+	var sinks []alertSink
+	if cfg.Slack.Enabled {
+		sinks = append(sinks, alertSink{"slack", slack.NewClient(cfg.Slack.WebhookURL)})
+	}
+	if cfg.Discord.Enabled {
+		discordClient := discord.NewClient(cfg.Discord.WebhookURL, cfg.Discord.Webhooks)
+		discordClient.Embeds = cfg.Discord.Embeds
+		sinks = append(sinks, alertSink{"discord", discordClient})
+	}
+	return sinks
+}
+
+// notifySinks는 설정된 모든 채널로 같은 메시지를 보내며, 오류는 로그만 남깁니다
+// 알림과 정기 보고서 모두 이 함수를 거치므로 채널마다 받는 메시지가 같습니다
+func notifySinks(message, alertType string) {
+	for _, sink := range alertSinks {
+		if err := sink.alerter.SendAlert(message, alertType); err != nil {
+			log.Printf("[ERROR] Failed to send %s %s message: %v", sink.name, alertType, err)
+		}
+	}
+}
+
+// withAccountHeader는 reporting.showAccountName이 설정된 경우 보고서 앞에 계정 이름을 붙입니다
+func withAccountHeader(cfg *config.Config, accountName, message string) string {
+	if !cfg.Reporting.ShowAccountName || accountName == "" {
+		return message
+	}
+	return fmt.Sprintf("👤 *%s*\n%s", telegram.EscapeMarkdown(accountName), message)
+}
+
+// withAccountHeaderPages는 여러 페이지 보고서의 첫 페이지에만 계정 이름을 붙입니다
+func withAccountHeaderPages(cfg *config.Config, accountName string, pages []string) []string {
+	if len(pages) > 0 {
+		pages[0] = withAccountHeader(cfg, accountName, pages[0])
+	}
+	return pages
+}
+
+// sendPages는 여러 페이지로 나뉜 메시지를 순서대로 전송합니다
+// 첫 페이지 전송에 실패하면 중단하고, 이후 페이지의 오류는 로그만 남깁니다
+func sendPages(telegramClient *telegram.Client, threadID int, pages []string) error {
+	for i, page := range pages {
+		if i > 0 {
+			time.Sleep(500 * time.Millisecond) // 0.5초 딜레이로 순서 보장
+		}
+		if err := telegramClient.SendMessage(threadID, page); err != nil {
+			if i == 0 {
+				return err
+			}
+			log.Printf("워커 페이지 %d 전송 오류: %v", i+1, err)
+		}
+	}
+	return nil
+}
+
+// startInstanceMonitoring runs the instance monitoring loop until ctx is cancelled (SIGINT/SIGTERM)
+func startInstanceMonitoring(ctx context.Context, vastaiClient *api.VastaiClient, sendAlert func(string, string) error) {
+	log.Printf("Starting instance monitoring service...")
+
+	if err := vastaiClient.StartContinuousMonitoring(ctx, sendAlert, false); err != nil {
+		log.Printf("[ERROR] Failed to start instance monitoring: %v", err)
+		if sendAlert != nil {
+			message := fmt.Sprintf(msg("monitoring.error"),
+				api.ReportTime(time.Now()).Format("15:04:05"),
+				telegram.EscapeMarkdown(err.Error()))
+			log.Printf("Sending error alert: %s", message)
+			if err := sendAlert(message, "error"); err != nil {
+				log.Printf("[ERROR] Failed to send monitoring error alert: %v", err)
+			} else {
+				log.Printf("Successfully sent error alert")
+			}
+		}
+	}
+}
+
+// 계정별 고루틴이 panic으로 멈춘 뒤 다시 시작하기까지 기다리는 시간입니다
+// 연속으로 panic이 나면 accountLoopMaxRestartDelay까지 두 배씩 늘어나며,
+// accountLoopStableRun 이상 실행된 뒤의 panic은 새 장애로 보고 처음 대기 시간부터 다시 시작합니다
+var (
+	accountLoopRestartDelay    = time.Minute
+	accountLoopMaxRestartDelay = 30 * time.Minute
+	accountLoopStableRun       = 30 * time.Minute
+)
+
+// runAccountLoop는 계정별 고루틴(fn)을 실행하고, panic이 나면 스택을 로그로 남기고 error 알림을 보낸 뒤
+// 대기 시간 후 다시 시작합니다. 한 계정의 오류로 그 계정의 보고가 조용히 멈추지 않도록 합니다
+// 같은 장애로 panic이 반복되면 알림은 처음 한 번만 보내고 대기 시간을 점점 늘립니다
+// fn이 정상적으로 끝나거나 ctx가 취소되면 다시 시작하지 않습니다
+func runAccountLoop(ctx context.Context, accountName, loop string, sendAlert func(string, string) error, fn func()) {
+	delay := accountLoopRestartDelay
+	alerted := false
+	for {
+		started := time.Now()
+		r, panicked := runRecovered(accountName, loop, fn)
+		if !panicked {
+			return
+		}
+
+		// 충분히 오래 실행된 뒤의 panic이면 백오프와 알림 상태 초기화
+		if time.Since(started) >= accountLoopStableRun {
+			delay = accountLoopRestartDelay
+			alerted = false
+		}
+		if !alerted {
+			alerted = true
+			title := "💥 Account Goroutine Panic"
+			message := fmt.Sprintf("Account: %s\nLoop: %s\nPanic: %v\nRestarting in %s", accountName, loop, r, delay)
+			if err := sendAlert(title+"\n"+api.CodeBlock(message), "error"); err != nil {
+				log.Printf("[ERROR] Failed to send panic alert: %v", err)
+			}
+		}
+
+		log.Printf("Restarting %s for %s in %s", loop, accountName, delay)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, accountLoopMaxRestartDelay)
+	}
+}
+
+// sharedLoopAccount는 특정 계정에 속하지 않는 고루틴(텔레그램 봇, /snooze 해제 안내)을 runAccountLoop에 넘길 때 쓰는 이름입니다
+// 설정에 없는 계정 이름이므로 panic 알림은 기본 error 스레드로 전송됩니다
+const sharedLoopAccount = "all accounts"
+
+// loopPanicAlert는 고루틴 panic 알림을 계정의 error 스레드와 설정된 채널로 보내는 sendAlert를 반환합니다
+// 정기 보고서와 텔레그램 봇은 계정별 수집 고루틴보다 먼저 시작되어 그 sendAlert를 쓸 수 없습니다
+func loopPanicAlert(telegramClient *telegram.Client, cfg *config.Config, accountName string) func(string, string) error {
+	return func(message, alertType string) error {
+		notifySinks(message, alertType)
+		return sendPages(telegramClient, accountThreads(cfg, accountName).Error, api.SplitMessage(message, telegramMessageLimit))
+	}
+}
+
+// runRecovered는 fn을 실행하고, panic이 나면 스택을 로그로 남긴 뒤 panic 값을 반환합니다
+func runRecovered(accountName, loop string, fn func()) (r any, panicked bool) {
+	defer func() {
+		if r = recover(); r == nil {
+			return
+		}
+		panicked = true
+		log.Printf("[ERROR] %s for %s panicked: %v\n%s", loop, accountName, r, debug.Stack())
+	}()
+	fn()
+	return nil, false
+}
+
+// statusHistogram은 인스턴스 상태별 개수를 "Running: 12, Initializing: 2" 형식으로 반환합니다
+func statusHistogram(counts map[string]int) string {
+	statuses := make([]string, 0, len(counts))
+	for status := range counts {
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		if counts[statuses[i]] != counts[statuses[j]] {
+			return counts[statuses[i]] > counts[statuses[j]]
+		}
+		return statuses[i] < statuses[j]
+	})
+
+	parts := make([]string, 0, len(statuses))
+	for _, status := range statuses {
+		parts = append(parts, fmt.Sprintf("%s: %d", status, counts[status]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatStatusHistogram은 전체 및 워커별 인스턴스 상태 분포를 포맷합니다
+func formatStatusHistogram(metrics *api.MinuteMetrics) string {
+	total := make(map[string]int)
+	var workerLines []string
+	for _, worker := range metrics.User.Workers {
+		counts := make(map[string]int)
+		for _, inst := range worker.Instances {
+			status := inst.Status
+			if status == "" {
+				status = "Unknown"
+			}
+			counts[status]++
+			total[status]++
+		}
+		if len(counts) > 0 {
+			workerLines = append(workerLines, fmt.Sprintf("%s - %s", worker.Name, statusHistogram(counts)))
+		}
+	}
+
+	if len(total) == 0 {
+		return msg("status.empty")
+	}
+
+	return fmt.Sprintf(msg("status.histogram"), statusHistogram(total), api.CodeBlock(strings.Join(workerLines, "\n")))
+}
+
+// statusGeoLocations는 /status에 표시하는 상위 위치 수입니다
+const statusGeoLocations = 3
+
+// geoLabel은 위치를 "국가/지역" 형식으로 표시합니다
+func geoLabel(geo api.GeoCount) string {
+	if geo.Region == "" {
+		return geo.Country
+	}
+	return geo.Country + "/" + geo.Region
+}
+
+// formatGeoSummary는 /status에 붙이는 인스턴스가 많은 상위 위치 요약이며, 위치 정보가 없으면 빈 문자열을 반환합니다
+func formatGeoSummary(metrics *api.MinuteMetrics) string {
+	locations := api.GeoDistribution(metrics.User.Workers)
+	if len(locations) == 0 || (len(locations) == 1 && locations[0].Country == api.UnknownLocation) {
+		return ""
+	}
+
+	parts := make([]string, 0, statusGeoLocations+1)
+	for i, geo := range locations {
+		if i == statusGeoLocations {
+			parts = append(parts, fmt.Sprintf(msg("status.geoMore"), len(locations)-i))
+			break
+		}
+		parts = append(parts, fmt.Sprintf("%s %d", telegram.EscapeMarkdown(geoLabel(geo)), geo.Instances))
+	}
+	return fmt.Sprintf(msg("status.geo"), strings.Join(parts, ", "))
+}
+
+// formatGeo는 국가/지역별 인스턴스 수와 Running 인스턴스 수를 표로 포맷합니다
+func formatGeo(metrics *api.MinuteMetrics) string {
+	locations := api.GeoDistribution(metrics.User.Workers)
+	if len(locations) == 0 {
+		return msg("geo.empty")
+	}
+
+	countries := make(map[string]bool)
+	table := api.NewTable("Country", "Region", "I", "Running").SetAlign(2, api.AlignRight).SetAlign(3, api.AlignRight)
+	for _, geo := range locations {
+		countries[geo.Country] = true
+		table.AddRow(geo.Country, geo.Region, strconv.Itoa(geo.Instances), strconv.Itoa(geo.Running))
+	}
+	return fmt.Sprintf(msg("geo.title"), len(countries), len(locations)) + "\n" + table.String()
+}
+
+// formatDuplicateIPs는 여러 인스턴스가 보고한 IP와 해당 인스턴스를 포맷합니다
+func formatDuplicateIPs(metrics *api.MinuteMetrics) string {
+	duplicates := api.FindDuplicateIPs(metrics.User.Workers)
+	if len(duplicates) == 0 {
+		return msg("dupes.none")
+	}
+
+	var lines []string
+	for _, dup := range duplicates {
+		lines = append(lines, dup.IP)
+		for _, inst := range dup.Instances {
+			lines = append(lines, "  "+inst)
+		}
+	}
+	return fmt.Sprintf(msg("dupes.title"), len(duplicates)) + "\n" + api.CodeBlock(strings.Join(lines, "\n"))
+}
+
+// formatLaneStats는 Lane별 인스턴스 수와 시간당 생성량을 집계하여 포맷합니다
+// 인스턴스별 생성량은 제공되지 않으므로 워커의 생성량을 인스턴스 수로 균등 분배합니다
+func formatLaneStats(metrics *api.MinuteMetrics) string {
+	type laneStat struct {
+		Lane        string
+		Instances   int
+		Generations float64
+	}
+
+	byLane := make(map[string]*laneStat)
+	for _, worker := range metrics.User.Workers {
+		if len(worker.Instances) == 0 {
+			continue
+		}
+		perInstance := float64(worker.GenerationLastHour) / float64(len(worker.Instances))
+		for _, inst := range worker.Instances {
+			lane := inst.Lane
+			if lane == "" {
+				lane = "N/A"
+			}
+			stat, ok := byLane[lane]
+			if !ok {
+				stat = &laneStat{Lane: lane}
+				byLane[lane] = stat
+			}
+			stat.Instances++
+			stat.Generations += perInstance
+		}
+	}
+
+	if len(byLane) == 0 {
+		return msg("lanes.empty")
+	}
+
+	lanes := make([]*laneStat, 0, len(byLane))
+	for _, stat := range byLane {
+		lanes = append(lanes, stat)
+	}
+
+	// 생성량 기준으로 내림차순 정렬
+	sort.Slice(lanes, func(i, j int) bool {
+		if lanes[i].Generations != lanes[j].Generations {
+			return lanes[i].Generations > lanes[j].Generations
+		}
+		return lanes[i].Lane < lanes[j].Lane
+	})
+
+	var b strings.Builder
+	for _, stat := range lanes {
+		b.WriteString(fmt.Sprintf("%-10s | %3d | %6.0f\n", stat.Lane, stat.Instances, stat.Generations))
+	}
+
+	return fmt.Sprintf(msg("lanes.title"), len(lanes),
+		api.CodeBlock("Lane       |   I |  1hGen\n"+strings.TrimRight(b.String(), "\n")))
+}
+
+// formatVastStatus는 Vast.ai 잔액, 인스턴스 수, actual_status별 인스턴스 분포를 포맷합니다
+// 조회에 실패한 값은 credit이 nil, instanceCount가 음수, instances가 nil로 전달됩니다
+func formatVastStatus(accountName string, credit *api.VastaiCredit, instanceCount int, instances []api.VastaiInstance) string {
+	lines := []string{fmt.Sprintf(msg("vast.title"), telegram.EscapeMarkdown(accountName)), ""}
+
+	if credit != nil {
+		lines = append(lines, fmt.Sprintf(msg("vast.credit"), credit.Credit))
+	} else {
+		lines = append(lines, msg("vast.credit.unavailable"))
+	}
+	if instanceCount >= 0 {
+		lines = append(lines, fmt.Sprintf(msg("vast.instances"), instanceCount))
+	} else {
+		lines = append(lines, msg("vast.instances.unavailable"))
+	}
+
+	if instances == nil {
+		lines = append(lines, msg("vast.statuses.unavailable"))
+		return strings.Join(lines, "\n")
+	}
+
+	counts := make(map[string]int)
+	for _, inst := range instances {
+		status := inst.ActualStatus
+		if status == "" {
+			status = "unknown"
+		}
+		counts[status]++
+	}
+	statuses := make([]string, 0, len(counts))
+	for status := range counts {
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		if counts[statuses[i]] != counts[statuses[j]] {
+			return counts[statuses[i]] > counts[statuses[j]]
+		}
+		return statuses[i] < statuses[j]
+	})
+
+	var b strings.Builder
+	for _, status := range statuses {
+		b.WriteString(fmt.Sprintf("%-12s | %3d\n", status, counts[status]))
+	}
+	if b.Len() > 0 {
+		lines = append(lines, api.CodeBlock(strings.TrimRight(b.String(), "\n")))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// normalizeGPUName은 "rtx4090"처럼 대소문자나 공백이 달라도 같은 GPU로 찾을 수 있도록 이름을 정규화합니다
+func normalizeGPUName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), ""))
+}
+
+// handlePrice는 LoadGPUPrices가 계산한 GPU별 일일 비용(GPU 가격×24 + 디스크 비용)을 표시합니다
+// gpu가 주어지면 이름이 같은 GPU 하나를, 없으면 이름에 gpu가 포함된 GPU들을 표시합니다
+func handlePrice(telegramClient *telegram.Client, threadID int, gpu string) error {
+	prices, err := api.LoadGPUPrices("instance.json")
+	if err != nil {
+		return telegramClient.SendMessage(threadID, fmt.Sprintf(msg("error.gpuPrices"), telegram.EscapeMarkdown(err.Error())))
+	}
+
+	models := make([]string, 0, len(prices))
+	query := normalizeGPUName(gpu)
+	for model := range prices {
+		if normalizeGPUName(model) == query {
+			models = []string{model}
+			break
+		}
+		if strings.Contains(normalizeGPUName(model), query) {
+			models = append(models, model)
+		}
+	}
+	if len(models) == 0 {
+		return telegramClient.SendMessage(threadID, fmt.Sprintf(msg("price.notFound"), telegram.EscapeMarkdown(gpu)))
+	}
+	sort.Strings(models)
+
+	table := api.NewTable("GPU", "$/day", "$/hour").SetAlign(1, api.AlignRight).SetAlign(2, api.AlignRight)
+	for _, model := range models {
+		table.AddRow(model, fmt.Sprintf("%.3f", prices[model]), fmt.Sprintf("%.4f", prices[model]/24))
+	}
+	return sendPages(telegramClient, threadID, chunkCodeBlocks(fmt.Sprintf(msg("price.title"), len(models)), table.Lines()))
+}
+
+// formatUnpricedGPUs는 instance.json에 가격이 없는 GPU 모델과 인스턴스 수를 포맷합니다
+func formatUnpricedGPUs(unpriced map[string]int) string {
+	if len(unpriced) == 0 {
+		return msg("unpriced.empty")
+	}
+
+	models := make([]string, 0, len(unpriced))
+	for model := range unpriced {
+		models = append(models, model)
+	}
+	sort.Strings(models)
+
+	var b strings.Builder
+	for _, model := range models {
+		b.WriteString(fmt.Sprintf("%-16s | %3d\n", model, unpriced[model]))
+	}
+
+	return fmt.Sprintf(msg("unpriced.title"), len(models),
+		api.CodeBlock("GPU              |   I\n"+strings.TrimRight(b.String(), "\n")))
 }
 
-// startTelegramBot starts the telegram bot and listens for updates
-func startTelegramBot(telegramClient *telegram.Client, cfg *config.Config) {
-	log.Printf("Starting Telegram bot...")
-	offset := 0
-	for {
-		updates, err := telegramClient.GetUpdates(offset)
-		if err != nil {
-			log.Printf("[ERROR] Failed to get updates: %v", err)
-			time.Sleep(5 * time.Second)
-			continue
+// formatGPUHealth는 인스턴스별 GPU 온도, 사용률, 전력을 포맷하며 과열/유휴 인스턴스는 표시합니다
+func formatGPUHealth(metrics *api.MinuteMetrics, alerts api.AlertConfig) string {
+	tempC, idleUtil := alerts.GPUThresholds()
+
+	// IP는 비어 있거나 겹칠 수 있으므로 워커 안에서의 인스턴스 순서로 구분
+	instanceKey := func(worker string, index int) string { return fmt.Sprintf("%s#%d", worker, index) }
+	flagged := make(map[string]string)
+	for _, issue := range api.FindGPUIssues(metrics.User.Workers, tempC, idleUtil) {
+		key := instanceKey(issue.Worker, issue.Instance)
+		switch {
+		case issue.Hot && issue.Idle:
+			flagged[key] = " HOT,IDLE"
+		case issue.Hot:
+			flagged[key] = " HOT"
+		default:
+			flagged[key] = " IDLE"
 		}
+	}
 
-		for _, update := range updates {
-			log.Printf("Received command: %s in thread %d", update.Message.Text, update.Message.MessageThreadID)
-			if err := handleTelegramCommand(update, telegramClient, cfg); err != nil {
-				log.Printf("[ERROR] Failed to handle command '%s': %v", update.Message.Text, err)
-			} else {
-				log.Printf("Successfully handled command: %s", update.Message.Text)
+	var b strings.Builder
+	count := 0
+	for _, worker := range metrics.User.Workers {
+		for i, inst := range worker.Instances {
+			if inst.GPU == nil {
+				continue
 			}
-			offset = update.UpdateID + 1
+			count++
+			b.WriteString(fmt.Sprintf("%-12s | %-15s | %s%s\n", worker.Name, inst.IP,
+				api.FormatGPUReading(*inst.GPU), flagged[instanceKey(worker.Name, i)]))
 		}
-
-		time.Sleep(1 * time.Second)
 	}
+	if count == 0 {
+		return msg("gpuhealth.empty")
+	}
+
+	return fmt.Sprintf(msg("gpuhealth.title"), count, len(flagged), tempC, idleUtil,
+		api.CodeBlock("Worker       | IP              |  Temp | Util | Power\n"+strings.TrimRight(b.String(), "\n")))
 }
 
-// startHourlyReporter starts the automatic hourly report sender
-func startHourlyReporter(telegramClient *telegram.Client, cfg *config.Config) {
-	log.Printf("Starting hourly reporter...")
+// formatTeams는 kuzco.teams에 설정된 팀별 토큰, 비중, 인스턴스, 최근 1시간 생성량을 표로 포맷합니다
+func formatTeams(metrics *api.MinuteMetrics) string {
+	if len(metrics.User.Teams) == 0 {
+		return msg("teams.empty")
+	}
 
-	// 개발 모드 체크
-	isDev := os.Getenv("ENV") == "dev"
+	table := api.NewTable("Team", "Points", "Share", "I", "1hG").
+		SetAlign(1, api.AlignRight).SetAlign(2, api.AlignRight).SetAlign(3, api.AlignRight).SetAlign(4, api.AlignRight)
+	for _, team := range metrics.User.Teams {
+		table.AddRow(team.Name, api.FormatPoints(team.TokensLast24Hours), fmt.Sprintf("%.3f%%", team.Share*100),
+			strconv.Itoa(team.Instances), strconv.Itoa(team.GenerationLastHour))
+	}
+	table.AddRow("Total", api.FormatPoints(metrics.User.TokensLast24Hours), fmt.Sprintf("%.3f%%", metrics.User.Share*100),
+		strconv.Itoa(metrics.User.ActualTotalInstances), strconv.Itoa(metrics.User.GenerationLastHour))
+	return fmt.Sprintf(msg("teams.title"), len(metrics.User.Teams)) + "\n" + table.String()
+}
 
-	// 타이머 간격 설정
-	var reportInterval time.Duration
-	var initialDelay time.Duration
+// topWorkerCount는 /top 명령어가 상위/하위 각각 표시할 워커 수입니다
+const topWorkerCount = 5
 
-	if isDev {
-		// 개발 모드에서는 2분 간격으로 보고서 전송
-		reportInterval = 2 * time.Minute
-		// 다음 짝수 분(0, 2, 4...)에 맞춰 시작
-		now := time.Now()
-		nextEvenMinute := time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), ((now.Minute()/2)+1)*2, 0, 0, now.Location())
-		initialDelay = nextEvenMinute.Sub(now)
-		log.Printf("개발 모드: 첫 시간별 보고서 %s 후 전송, 이후 %s 간격으로 전송", initialDelay, reportInterval)
-	} else {
-		// 프로덕션 모드에서는 1시간 간격으로 전송
-		reportInterval = time.Hour
-		// 다음 정시(00분)에 맞춰 시작
-		now := time.Now()
-		nextHour := time.Date(now.Year(), now.Month(), now.Day(), now.Hour()+1, 0, 0, 0, now.Location())
-		initialDelay = nextHour.Sub(now)
-		log.Printf("프로덕션 모드: 첫 시간별 보고서 %s 후 전송(정시), 이후 1시간 간격으로 전송", initialDelay)
+// formatTopWorkers는 인스턴스당 토큰 기준 상위/하위 워커와 전체 중앙값을 포맷합니다
+// 인스턴스별 토큰은 제공되지 않으므로 워커의 TokensPerInstance로 순위를 매깁니다
+func formatTopWorkers(metrics *api.MinuteMetrics) string {
+	workers := make([]api.WorkerMinuteMetrics, 0, len(metrics.User.Workers))
+	for _, worker := range metrics.User.Workers {
+		if worker.InstanceCount > 0 {
+			workers = append(workers, worker)
+		}
+	}
+	if len(workers) == 0 {
+		return msg("top.empty")
 	}
 
-	// 초기 지연 후 첫 보고서 전송
-	time.Sleep(initialDelay)
+	sort.SliceStable(workers, func(i, j int) bool {
+		return workers[i].TokensPerInstance > workers[j].TokensPerInstance
+	})
 
-	// 첫 보고서 전송
-	log.Printf("시간별 통계 조회 중...")
-	stats := api.GlobalHourlyStats.GetStats()
-	message := formatHourlyStats(stats)
+	// 중앙값 계산 (정렬된 순서 기준)
+	n := len(workers)
+	median := float64(workers[n/2].TokensPerInstance)
+	if n%2 == 0 {
+		median = float64(workers[n/2-1].TokensPerInstance+workers[n/2].TokensPerInstance) / 2
+	}
 
-	log.Printf("시간별 보고서 스레드 %d로 전송 중...", cfg.Telegram.Threads.Hourly)
-	if err := telegramClient.SendMessage(cfg.Telegram.Threads.Hourly, message); err != nil {
-		log.Printf("[ERROR] 시간별 보고서 전송 실패: %v", err)
-	} else {
-		log.Printf("시간별 보고서 전송 완료")
+	row := func(rank int, worker api.WorkerMinuteMetrics) string {
+		gpus := make(map[string]bool)
+		lanes := make(map[string]bool)
+		for _, inst := range worker.Instances {
+			if inst.GPUModel != "" {
+				gpus[inst.GPUModel] = true
+			}
+			if inst.Lane != "" {
+				lanes[inst.Lane] = true
+			}
+		}
+		return fmt.Sprintf("%2d | %-12s | %2d | %8s | %s | %s",
+			rank, worker.Name, worker.InstanceCount, api.FormatPoints(worker.TokensPerInstance),
+			joinSortedKeys(gpus), joinSortedKeys(lanes))
 	}
 
-	// 워커 보고서도 함께 전송
-	sendWorkerReport(telegramClient, cfg)
+	topCount := topWorkerCount
+	if topCount > n {
+		topCount = n
+	}
+	var top []string
+	for i := 0; i < topCount; i++ {
+		top = append(top, row(i+1, workers[i]))
+	}
 
-	// 이후 정기적으로 보고서 전송
-	ticker := time.NewTicker(reportInterval)
-	defer ticker.Stop()
+	// 하위 목록은 상위 목록과 겹치지 않도록 합니다
+	bottomStart := n - topWorkerCount
+	if bottomStart < topCount {
+		bottomStart = topCount
+	}
+	var bottom []string
+	for i := bottomStart; i < n; i++ {
+		bottom = append(bottom, row(i+1, workers[i]))
+	}
 
-	for {
-		<-ticker.C
-		log.Printf("시간별 통계 조회 중...")
-		stats := api.GlobalHourlyStats.GetStats()
-		message := formatHourlyStats(stats)
+	message := fmt.Sprintf(msg("top.title"), n, formatPointsFloat(median)) + "\n" +
+		msg("top.best") + "\n" + api.CodeBlock(strings.Join(top, "\n"))
+	if len(bottom) > 0 {
+		message += "\n" + msg("top.worst") + "\n" + api.CodeBlock(strings.Join(bottom, "\n"))
+	}
+	return message
+}
 
-		log.Printf("시간별 보고서 스레드 %d로 전송 중...", cfg.Telegram.Threads.Hourly)
-		if err := telegramClient.SendMessage(cfg.Telegram.Threads.Hourly, message); err != nil {
-			log.Printf("[ERROR] 시간별 보고서 전송 실패: %v", err)
-		} else {
-			log.Printf("시간별 보고서 전송 완료")
+// formatForecast는 현재 소모가 계속된다고 가정하고 Vast.ai 잔액이 소진되기까지의 일 수와
+// 워커별 일일 비용 비중을 비용이 큰 순서로 포맷합니다
+func formatForecast(metrics *api.MinuteMetrics) string {
+	credit := metrics.User.VastaiCredit
+	if credit == nil {
+		return msg("forecast.noCredit")
+	}
+
+	var workers []api.WorkerMinuteMetrics
+	var workerCost float64
+	for _, worker := range metrics.User.Workers {
+		if worker.DailyCost > 0 {
+			workers = append(workers, worker)
+			workerCost += worker.DailyCost
 		}
+	}
 
-		// 워커 보고서도 함께 전송
-		sendWorkerReport(telegramClient, cfg)
+	// 전체 소모는 실제 Vast.ai 비용을 우선 사용하고, 없으면 워커 비용 합계를 사용합니다
+	dailyBurn := metrics.User.TotalDailyCost
+	if dailyBurn <= 0 {
+		dailyBurn = workerCost
+	}
+	if dailyBurn <= 0 || workerCost <= 0 {
+		return fmt.Sprintf(msg("forecast.noCost"), credit.Credit)
 	}
-}
 
-// sendWorkerReport 함수는 워커 보고서를 생성하고 전송합니다
-func sendWorkerReport(telegramClient *telegram.Client, cfg *config.Config) {
-	log.Printf("시간별 워커 보고서 생성 중...")
-	metrics := getCurrentMetrics()
-	if metrics != nil {
-		workerReport := formatWorkerStats(metrics)
+	sort.SliceStable(workers, func(i, j int) bool {
+		return workers[i].DailyCost > workers[j].DailyCost
+	})
 
-		// 추가 페이지 처리
-		if strings.Contains(workerReport, "$$$") {
-			parts := strings.Split(workerReport, "$$$")
-			firstPage := parts[0]
+	lines := []string{" # | Worker       |  $/day | Share |   Solo"}
+	for i, worker := range workers {
+		lines = append(lines, fmt.Sprintf("%2d | %-12s | %6.2f | %4.1f%% | %5.1fd",
+			i+1, worker.Name, worker.DailyCost, worker.DailyCost/workerCost*100, credit.Credit/worker.DailyCost))
+	}
 
-			// 첫 번째 페이지 전송
-			if err := telegramClient.SendMessage(cfg.Telegram.Threads.Workers, firstPage); err != nil {
-				log.Printf("[ERROR] 시간별 워커 보고서(첫 페이지) 전송 실패: %v", err)
-			} else {
-				log.Printf("시간별 워커 보고서(첫 페이지) 전송 완료")
-			}
+	return fmt.Sprintf(msg("forecast.title"), credit.Credit, dailyBurn, credit.Credit/dailyBurn) + "\n" +
+		api.CodeBlock(strings.Join(lines, "\n")) + "\n" + msg("forecast.note")
+}
 
-			// 추가 페이지 처리
-			if len(parts) > 1 {
-				var workerPages struct {
-					Pages []string `json:"pages"`
-				}
+// joinSortedKeys는 집합의 키를 정렬하여 쉼표로 연결하며, 비어 있으면 N/A를 반환합니다
+func joinSortedKeys(set map[string]bool) string {
+	if len(set) == 0 {
+		return "N/A"
+	}
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ",")
+}
 
-				if err := json.Unmarshal([]byte(parts[1]), &workerPages); err != nil {
-					log.Printf("워커 페이지 파싱 오류: %v", err)
-				} else {
-					// 각 추가 페이지를 순차적으로 전송 (0.5초 딜레이)
-					for i, page := range workerPages.Pages {
-						time.Sleep(500 * time.Millisecond) // 0.5초 딜레이로 순서 보장
-						if err := telegramClient.SendMessage(cfg.Telegram.Threads.Workers, page); err != nil {
-							log.Printf("워커 페이지 %d 전송 오류: %v", i+2, err)
-						} else {
-							log.Printf("시간별 워커 보고서(페이지 %d) 전송 완료", i+2)
-						}
-					}
+// formatInstances는 Vast.ai 인스턴스 목록을 IP 기준으로 Kuzco 워커와 매칭하여 포맷합니다
+// 매칭되는 워커가 없는 인스턴스는 orphaned로 표시됩니다
+func formatInstances(instances []api.VastaiInstance, metrics *api.MinuteMetrics) string {
+	type kuzcoInstance struct {
+		Worker string
+		GPU    string
+		Lane   string
+	}
+
+	byIP := make(map[string]kuzcoInstance)
+	if metrics != nil {
+		for _, worker := range metrics.User.Workers {
+			for _, inst := range worker.Instances {
+				if inst.IP == "" {
+					continue
 				}
-			}
-		} else {
-			// 단일 페이지 전송
-			if err := telegramClient.SendMessage(cfg.Telegram.Threads.Workers, workerReport); err != nil {
-				log.Printf("[ERROR] 시간별 워커 보고서 전송 실패: %v", err)
-			} else {
-				log.Printf("시간별 워커 보고서 전송 완료")
+				byIP[inst.IP] = kuzcoInstance{Worker: worker.Name, GPU: inst.GPUModel, Lane: inst.Lane}
 			}
 		}
-	} else {
-		log.Printf("[ERROR] 시간별 워커 보고서용 메트릭스가 없습니다")
 	}
-}
-
-// startInstanceMonitoring starts the instance monitoring loop
-func startInstanceMonitoring(vastaiClient *api.VastaiClient, sendAlert func(string, string) error) {
-	log.Printf("Starting instance monitoring service...")
-
-	// Stop channel for monitoring
-	stopChan := make(chan struct{})
 
-	// Handle graceful shutdown
-	go func() {
-		// Wait for interrupt signal
-		sigChan := make(chan os.Signal, 1)
-		signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
-		<-sigChan
-
-		// Signal monitoring to stop
-		close(stopChan)
-		log.Printf("Stopping instance monitoring...")
-	}()
+	// 호출하는 쪽의 인스턴스 목록 순서가 바뀌지 않도록 복사본을 정렬
+	instances = slices.Clone(instances)
+	sort.Slice(instances, func(i, j int) bool {
+		return instances[i].ID < instances[j].ID
+	})
 
-	// Start continuous monitoring
-	if err := vastaiClient.StartContinuousMonitoring(sendAlert, false, stopChan); err != nil {
-		log.Printf("[ERROR] Failed to start instance monitoring: %v", err)
-		if sendAlert != nil {
-			message := fmt.Sprintf("⚠️ Instance Monitoring Error\n시간: %s\n오류: %s",
-				time.Now().Format("15:04:05"),
-				err.Error())
-			log.Printf("Sending error alert: %s", message)
-			if err := sendAlert(message, "error"); err != nil {
-				log.Printf("[ERROR] Failed to send monitoring error alert: %v", err)
-			} else {
-				log.Printf("Successfully sent error alert")
-			}
+	var b strings.Builder
+	orphaned := 0
+	for _, inst := range instances {
+		match, ok := byIP[inst.PublicIP]
+		if !ok {
+			orphaned++
+			b.WriteString(fmt.Sprintf("%d | %s | %s | $%.3f/h | orphaned\n",
+				inst.ID, inst.ActualStatus, inst.GPUName, inst.DPHTotal))
+			continue
 		}
+		b.WriteString(fmt.Sprintf("%d | %s | %s | %s | %s\n",
+			inst.ID, inst.ActualStatus, match.Worker, match.GPU, match.Lane))
 	}
 
-	// This function shouldn't exit until program ends
-	select {}
+	title := fmt.Sprintf(msg("instances.title"), len(instances), orphaned)
+	if len(instances) == 0 {
+		return title
+	}
+	return fmt.Sprintf("%s\n%s", title, api.CodeBlock(strings.TrimRight(b.String(), "\n")))
 }
 
+// telegramMessageLimit는 텔레그램 메시지 한 건의 최대 길이입니다 (여유분 포함)
+const telegramMessageLimit = 4000
+
 // formatWorkerStats 함수는 워커별 토큰당 수익을 포맷합니다
-func formatWorkerStats(metrics *api.MinuteMetrics) string {
+// 결과는 페이지마다 최대 perPage명의 워커가 들어가고 텔레그램 메시지 길이 제한을 넘지 않도록 나뉘어 반환됩니다
+func formatWorkerStats(metrics *api.MinuteMetrics, sortBy string, perPage int) []string {
 	// 워커 정보를 저장할 슬라이스
 	type WorkerInfo struct {
 		Name               string
 		ModelType          []string // 모델 타입을
 		GPU                []string // GPU 유형들
 		Lane               []string // Lane 정보
+		Archived           bool     // 보관된 워커 여부
+		TotalTokens        int64    // 전체 기간 토큰 (보관된 워커 표시용)
 		TokensPerInstance  int64
 		GenerationsLast24H int
 		GenerationLastHour int
-		InstanceCount      int   // 인스턴스 개수 추가
-		AvgTokens          int64 // 인스턴스당 평균 토큰
-		AvgGenLastHour     int   // 인스턴스당 평균 시간당 생성량
-		AvgGenLast24H      int   // 인스턴스당 평균 24시간 생성량
+		InstanceCount      int    // 인스턴스 개수 추가
+		AvgTokens          int64  // 인스턴스당 평균 토큰
+		AvgGenLastHour     int    // 인스턴스당 평균 시간당 생성량
+		AvgGenLast24H      int    // 인스턴스당 평균 24시간 생성량
+		TokensLastHour     *int64 // 직전 1시간 동안 늘어난 누적 토큰 (기록이 1시간 쌓이기 전에는 nil)
 	}
 
 	// 유효한 워커(토큰당 수익이 0이 아닌) 정보를 저장할 슬라이스
@@ -551,21 +2496,22 @@ func formatWorkerStats(metrics *api.MinuteMetrics) string {
 
 	// 워커 정보 수집 - TokensPerInstance가 0인 워커는 제외
 	for _, worker := range metrics.User.Workers {
-		// 토큰당 수익이 0인 워커는 건너뜀
-		if worker.TokensPerInstance <= 0 {
+		// 토큰당 수익이 0인 워커는 건너뜀 (보관된 워커는 누적 토큰 표시를 위해 포함)
+		if worker.TokensPerInstance <= 0 && !worker.Archived {
 			continue
 		}
 
 		// 인스턴스 개수 확인
 		instanceCount := worker.InstanceCount
-		if instanceCount < 1 {
-			instanceCount = 1 // 0으로 나누기 방지
+		divisor := instanceCount
+		if divisor < 1 {
+			divisor = 1 // 0으로 나누기 방지
 		}
 
 		// 인스턴스당 평균값 계산
 		avgTokens := worker.TokensPerInstance
-		avgGenLastHour := worker.GenerationLastHour / instanceCount
-		avgGenLast24H := worker.GenerationsLast24H / instanceCount
+		avgGenLastHour := worker.GenerationLastHour / divisor
+		avgGenLast24H := worker.GenerationsLast24H / divisor
 
 		// 각 인스턴스의 고유 모델, GPU, Lane 유형 수집
 		uniqueModels := make(map[string]bool)
@@ -616,6 +2562,8 @@ func formatWorkerStats(metrics *api.MinuteMetrics) string {
 			ModelType:          modelList,
 			GPU:                gpuList,
 			Lane:               laneList,
+			Archived:           worker.Archived,
+			TotalTokens:        worker.TotalTokens,
 			TokensPerInstance:  worker.TokensPerInstance,
 			GenerationsLast24H: worker.GenerationsLast24H,
 			GenerationLastHour: worker.GenerationLastHour,
@@ -623,26 +2571,44 @@ func formatWorkerStats(metrics *api.MinuteMetrics) string {
 			AvgTokens:          avgTokens,
 			AvgGenLastHour:     avgGenLastHour,
 			AvgGenLast24H:      avgGenLast24H,
+			TokensLastHour:     worker.TokensLastHour,
 		}
 
 		workers = append(workers, info)
 	}
 
-	// 토큰당 수익 기준으로 내림차순 정렬
-	sort.Slice(workers, func(i, j int) bool {
-		return workers[i].TokensPerInstance > workers[j].TokensPerInstance
+	// 정렬 기준에 따라 정렬 (기본값: 인스턴스당 토큰 내림차순, 같으면 인스턴스당 토큰 순)
+	sort.SliceStable(workers, func(i, j int) bool {
+		a, b := workers[i], workers[j]
+		switch sortBy {
+		case config.WorkerSortGen:
+			if a.GenerationLastHour != b.GenerationLastHour {
+				return a.GenerationLastHour > b.GenerationLastHour
+			}
+		case config.WorkerSortCount:
+			if a.InstanceCount != b.InstanceCount {
+				return a.InstanceCount > b.InstanceCount
+			}
+		case config.WorkerSortName:
+			return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+		}
+		return a.TokensPerInstance > b.TokensPerInstance
 	})
 
 	// 총 워커 수와 전체 생성량 계산
 	totalWorkers := len(workers)
 	if totalWorkers == 0 {
-		return "🖥️ 토큰당 수익이 있는 워커가 없습니다."
+		return []string{msg("workers.empty")}
 	}
 
 	totalGenerations := 0
 	totalGenerationsLast24H := 0
 	totalInstances := 0
+	archivedWorkers := 0
 	for _, w := range workers {
+		if w.Archived {
+			archivedWorkers++
+		}
 		totalGenerations += w.GenerationLastHour
 		totalGenerationsLast24H += w.GenerationsLast24H
 		totalInstances += w.InstanceCount
@@ -655,28 +2621,33 @@ func formatWorkerStats(metrics *api.MinuteMetrics) string {
 		avgGeneration24HPerInstance = totalGenerationsLast24H / totalInstances
 	}
 
-	// 결과 메시지 생성
-	var messageBuilder strings.Builder
-
 	// 헤더 메시지 생성
-	messageBuilder.WriteString(fmt.Sprintf("📊 워커 현황 요약 (%d개 워커/%d개 인스턴스)\n", totalWorkers, totalInstances))
-	messageBuilder.WriteString(fmt.Sprintf("• 총 생성량: %d/시간 | %d/24시간\n", totalGenerations, totalGenerationsLast24H))
-	messageBuilder.WriteString(fmt.Sprintf("• 인스턴스당 평균: %d/시간 | %d/24시간\n\n", avgGenerationPerInstance, avgGeneration24HPerInstance))
+	var summary strings.Builder
+	summary.WriteString(fmt.Sprintf(msg("workers.summary"), totalWorkers, totalInstances))
+	summary.WriteString(fmt.Sprintf(msg("workers.total"), totalGenerations, totalGenerationsLast24H))
+	summary.WriteString(fmt.Sprintf(msg("workers.average"), avgGenerationPerInstance, avgGeneration24HPerInstance))
+	if archivedWorkers > 0 {
+		summary.WriteString(fmt.Sprintf(msg("workers.archivedNote"), archivedWorkers))
+	}
+	if sortBy != "" && sortBy != config.WorkerSortTokens {
+		summary.WriteString(fmt.Sprintf(msg("workers.sortedBy"), sortBy))
+	}
 
-	// 헤더 구분선
-	messageBuilder.WriteString("-----------------------------------------------------------------------\n")
-	messageBuilder.WriteString("  R  | 워커 | I |  토큰/I    | 1hG/I | 모델 | GPU | Lane\n")
-	messageBuilder.WriteString("-----------------------------------------------------------------------\n")
+	// 열 너비는 내용에서 계산하며, 표 헤더는 페이지마다 반복
+	table := api.NewTable(strings.Split(msg("workers.tableHeader"), "|")...)
+	for _, col := range []int{0, 2, 3, 4, 5} {
+		table.SetAlign(col, api.AlignRight)
+	}
 
 	// 모든 워커 정보를 한꺼번에 표시
 	for i, w := range workers {
 		// 모델 타입에 따라 아이콘 선택
-		modelType := "일반"
+		modelType := msg("workers.modelGeneral")
 		if len(w.ModelType) > 0 {
 			// 모델 타입 단순화
 			simplifiedModels := make([]string, 0, len(w.ModelType))
 			for _, model := range w.ModelType {
-				simpleModel := "기타"
+				simpleModel := msg("workers.modelOther")
 				if strings.Contains(strings.ToLower(model), "vllm") {
 					simpleModel = "VL"
 				} else if strings.Contains(strings.ToLower(model), "ollama") {
@@ -713,39 +2684,64 @@ func formatWorkerStats(metrics *api.MinuteMetrics) string {
 			laneInfo = strings.Join(w.Lane, ",")
 		}
 
-		// 토큰당 수익 포맷팅
-		tokensFormatted := formatNumber(float64(w.TokensPerInstance))
+		// 토큰당 수익 포맷팅 (보관된 워커는 전체 기간 토큰)
+		tokensFormatted := api.FormatPoints(w.TokensPerInstance)
+		if w.Archived {
+			tokensFormatted = api.FormatPoints(w.TotalTokens)
+		}
 
 		// 1시간 생성량/인스턴스 사용
 		genPerInstance := w.AvgGenLastHour
 
-		// 워커 이름 추출 (v숫자만 남기기)
+		// 직전 1시간 토큰 증가량 (수집 기록이 1시간 쌓이기 전에는 "-")
+		tokensLastHour := "-"
+		if w.TokensLastHour != nil {
+			tokensLastHour = api.FormatPoints(*w.TokensLastHour)
+		}
+
+		// 워커 이름 추출 (코드 블록 안이므로 Markdown 이스케이프 불필요)
 		workerName := w.Name
+		if w.Archived {
+			workerName += " [A]"
+		}
 
 		// GPU 모델 추출 - 3060 등의 숫자만
 		// gpuModel := w.GPU
 
-		// 순위에 따라 들여쓰기 수준 조정
-		rankStr := fmt.Sprintf("%3d", i+1)
+		table.AddRow(strconv.Itoa(i+1), workerName, strconv.Itoa(w.InstanceCount), tokensFormatted,
+			tokensLastHour, strconv.Itoa(genPerInstance), modelType, gpuInfo, laneInfo)
+	}
 
-		// 표시할 행 생성 (요청된 형식으로)
-		messageBuilder.WriteString(fmt.Sprintf(" %-4s | %-5s | %1d | %-11s | %5d | %-5s | %-8s | %s\n",
-			rankStr,
-			workerName,
-			w.InstanceCount,
-			tokensFormatted,
-			genPerInstance,
-			modelType,
-			gpuInfo,
-			laneInfo))
+	// 페이지마다 표 머리글을 반복하고 perPage로 워커 수를 제한해야 하므로 SplitMessage 대신 직접 나눔
+	lines := table.Lines()
+	header := strings.Join(lines[:2], "\n") + "\n"
+
+	var pages []string
+	prefix := summary.String()
+	var page strings.Builder
+	page.WriteString(header)
+	rows := 0
+	for _, line := range lines[2:] {
+		// 워커 수가 perPage에 이르거나 메시지 길이 제한을 넘으면 새 페이지 시작 (코드 블록 구분자 길이 포함)
+		if (perPage > 0 && rows >= perPage) || len(prefix)+page.Len()+len(line)+8 > telegramMessageLimit {
+			pages = append(pages, prefix+api.CodeBlock(strings.TrimRight(page.String(), "\n")))
+			prefix = ""
+			page.Reset()
+			page.WriteString(header)
+			rows = 0
+		}
+		page.WriteString(line)
+		page.WriteString("\n")
+		rows++
 	}
 
-	return messageBuilder.String()
+	pages = append(pages, prefix+api.CodeBlock(strings.TrimRight(page.String(), "\n")))
+	return pages
 }
 
 // startDailyWorkerReporter는 매일 워커 현황을 전송합니다
-func startDailyWorkerReporter(telegramClient *telegram.Client, cfg *config.Config) {
-	log.Printf("Starting daily worker reporter...")
+func startDailyWorkerReporter(telegramClient *telegram.Client, cfg *config.Config, accountName string) {
+	log.Printf("Starting daily worker reporter for %s...", accountName)
 
 	// 개발 모드 체크
 	isDev := os.Getenv("ENV") == "dev"
@@ -758,9 +2754,14 @@ func startDailyWorkerReporter(telegramClient *telegram.Client, cfg *config.Confi
 		initialDelay = 20 * time.Second
 		log.Printf("개발 모드: %s 후 첫 워커 보고서 전송, 이후 1분 간격으로 전송", initialDelay)
 	} else {
-		// 프로덕션 모드에서는 매일 오전 9시에 전송
-		now := time.Now()
-		nextReport := time.Date(now.Year(), now.Month(), now.Day(), 9, 0, 0, 0, time.Local)
+		// 프로덕션 모드에서는 설정된 시각(기본 오전 9시)에 전송
+		hour, minute, loc, err := cfg.Reporting.DailyWorkerSchedule()
+		if err != nil {
+			log.Printf("[ERROR] 워커 보고서 시간 설정 오류, 기본값 사용: %v", err)
+			hour, minute, loc = 9, 0, time.Local
+		}
+		now := time.Now().In(loc)
+		nextReport := time.Date(now.Year(), now.Month(), now.Day(), hour, minute, 0, 0, loc)
 		if now.After(nextReport) {
 			nextReport = nextReport.Add(24 * time.Hour)
 		}
@@ -776,7 +2777,7 @@ func startDailyWorkerReporter(telegramClient *telegram.Client, cfg *config.Confi
 		log.Printf("워커 보고서 생성 중...")
 
 		// 현재 메트릭스 가져오기
-		metrics := getCurrentMetrics()
+		metrics := getCurrentMetrics(accountName)
 		if metrics == nil {
 			log.Printf("[ERROR] 워커 보고서용 메트릭스가 없습니다")
 			// 메트릭스가 없는 경우 1시간 후 다시 시도 (개발 모드에서는 30초 후)
@@ -789,47 +2790,16 @@ func startDailyWorkerReporter(telegramClient *telegram.Client, cfg *config.Confi
 			continue
 		}
 
-		// 워커 보고서 생성
-		workerReport := formatWorkerStats(metrics)
-
-		// 추가 페이지 처리
-		if strings.Contains(workerReport, "$$$") {
-			parts := strings.Split(workerReport, "$$$")
-			firstPage := parts[0]
-
-			// 첫 번째 페이지 전송
-			if err := telegramClient.SendMessage(cfg.Telegram.Threads.Workers, firstPage); err != nil {
-				log.Printf("[ERROR] 워커 보고서(첫 페이지) 전송 실패: %v", err)
-			} else {
-				log.Printf("워커 보고서(첫 페이지) 전송 완료")
-			}
-
-			// 추가 페이지 처리
-			if len(parts) > 1 {
-				var workerPages struct {
-					Pages []string `json:"pages"`
-				}
-
-				if err := json.Unmarshal([]byte(parts[1]), &workerPages); err != nil {
-					log.Printf("워커 페이지 파싱 오류: %v", err)
-				} else {
-					// 각 추가 페이지를 순차적으로 전송 (0.5초 딜레이)
-					for i, page := range workerPages.Pages {
-						time.Sleep(500 * time.Millisecond) // 0.5초 딜레이로 순서 보장
-						if err := telegramClient.SendMessage(cfg.Telegram.Threads.Workers, page); err != nil {
-							log.Printf("워커 페이지 %d 전송 오류: %v", i+2, err)
-						} else {
-							log.Printf("워커 보고서(페이지 %d) 전송 완료", i+2)
-						}
-					}
-				}
-			}
+		// 워커 보고서 생성 및 전송 (재시작 직후 같은 날 보고서를 다시 보내지 않음)
+		period := api.ReportTime(time.Now().Round(time.Minute)).Format("2006-01-02")
+		if !isDev && api.ReportSent(accountName, api.ReportDaily, period) {
+			log.Printf("오늘 워커 보고서가 이미 전송되어 건너뜁니다 (%s)", period)
+		} else if err := sendWorkerPages(telegramClient, cfg, accountName, metrics); err != nil {
+			log.Printf("[ERROR] 워커 보고서 전송 실패: %v", err)
 		} else {
-			// 단일 페이지 전송
-			if err := telegramClient.SendMessage(cfg.Telegram.Threads.Workers, workerReport); err != nil {
-				log.Printf("[ERROR] 워커 보고서 전송 실패: %v", err)
-			} else {
-				log.Printf("워커 보고서 전송 완료")
+			log.Printf("워커 보고서 전송 완료")
+			if !isDev {
+				api.MarkReportSent(accountName, api.ReportDaily, period)
 			}
 		}
 
@@ -841,7 +2811,107 @@ func startDailyWorkerReporter(telegramClient *telegram.Client, cfg *config.Confi
 		} else {
 			// 프로덕션 모드에서는 다음 날 같은 시간
 			timer.Reset(24 * time.Hour)
-			log.Printf("다음 워커 보고서 예정 시간: %s", time.Now().Add(24*time.Hour).Format("2006-01-02 15:04:05"))
+			log.Printf("다음 워커 보고서 예정 시간: %s", api.ReportTime(time.Now().Add(24*time.Hour)).Format("2006-01-02 15:04:05"))
+		}
+	}
+}
+
+// formatWeeklyReport는 이번 주와 지난 주의 토큰, 평균 비중, 총 비용을 비교합니다
+// 일별 데이터는 메모리에만 보관되므로 보관된 일 수를 함께 표시합니다
+func formatWeeklyReport(summary api.WeeklySummary, now time.Time) string {
+	if summary.ThisWeek.Days == 0 {
+		return msg("weekly.empty")
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	dollars := func(v float64) string { return fmt.Sprintf("$%.2f", v) }
+	percent := func(v float64) string { return fmt.Sprintf("%.3f%%p", v) }
+
+	this, last := summary.ThisWeek, summary.LastWeek
+	lines := []string{
+		fmt.Sprintf(msg("weekly.tokens"), api.FormatPoints(last.Tokens), api.FormatPoints(this.Tokens),
+			formatChange(float64(last.Tokens), float64(this.Tokens), formatPointsFloat)),
+		fmt.Sprintf(msg("weekly.share"), last.AvgShare*100, this.AvgShare*100, formatChange(last.AvgShare*100, this.AvgShare*100, percent)),
+		fmt.Sprintf(msg("weekly.spend"), last.Spend, this.Spend, formatChange(last.Spend, this.Spend, dollars)),
+		fmt.Sprintf(msg("weekly.best"), summary.Best.Date.Format("01/02 Mon"), api.FormatPoints(summary.Best.Tokens)),
+		fmt.Sprintf(msg("weekly.worst"), summary.Worst.Date.Format("01/02 Mon"), api.FormatPoints(summary.Worst.Tokens)),
+	}
+
+	message := fmt.Sprintf(msg("weekly.title"),
+		today.AddDate(0, 0, -7).Format("2006-01-02"),
+		today.AddDate(0, 0, -1).Format("2006-01-02")) + "\n" + api.CodeBlock(strings.Join(lines, "\n"))
+	if this.Days < 7 || last.Days < 7 {
+		message += "\n" + fmt.Sprintf(msg("weekly.limited"), this.Days, last.Days)
+	}
+	return message
+}
+
+// startWeeklyReporter는 매주 월요일 일일 워커 보고서와 같은 시각에 주간 요약을 전송합니다
+func startWeeklyReporter(telegramClient *telegram.Client, cfg *config.Config, accountName string) {
+	log.Printf("Starting weekly reporter for %s...", accountName)
+
+	// 개발 모드 체크
+	isDev := os.Getenv("ENV") == "dev"
+
+	var initialDelay time.Duration
+	if isDev {
+		// 개발 모드에서는 30초 후 첫 보고서 전송, 이후 5분 간격으로 전송
+		initialDelay = 30 * time.Second
+		log.Printf("개발 모드: %s 후 첫 주간 보고서 전송, 이후 5분 간격으로 전송", initialDelay)
+	} else {
+		hour, minute, loc, err := cfg.Reporting.DailyWorkerSchedule()
+		if err != nil {
+			log.Printf("[ERROR] 주간 보고서 시간 설정 오류, 기본값 사용: %v", err)
+			hour, minute, loc = 9, 0, time.Local
+		}
+		now := time.Now().In(loc)
+		daysUntilMonday := (int(time.Monday) - int(now.Weekday()) + 7) % 7
+		nextReport := time.Date(now.Year(), now.Month(), now.Day()+daysUntilMonday, hour, minute, 0, 0, loc)
+		if !nextReport.After(now) {
+			nextReport = nextReport.AddDate(0, 0, 7)
+		}
+		initialDelay = nextReport.Sub(now)
+		log.Printf("다음 주간 보고서 예정 시간: %s", nextReport.Format("2006-01-02 15:04:05"))
+	}
+
+	timer := time.NewTimer(initialDelay)
+	defer timer.Stop()
+
+	for {
+		<-timer.C
+		log.Printf("주간 보고서 생성 중...")
+
+		now := api.ReportTime(time.Now())
+		// 재시작 직후 같은 주의 보고서를 다시 보내지 않음 (타이머가 정시 직전에 실행되어도 같은 주로 처리)
+		year, week := now.Round(time.Minute).ISOWeek()
+		period := fmt.Sprintf("%d-W%02d", year, week)
+		if !isDev && api.ReportSent(accountName, api.ReportWeekly, period) {
+			log.Printf("이번 주 주간 보고서가 이미 전송되어 건너뜁니다 (%s)", period)
+		} else {
+			message := withAccountHeader(cfg, accountName, formatWeeklyReport(api.GlobalWeeklyStats.Summary(accountName, now), now))
+
+			threads := accountThreads(cfg, accountName)
+			threadID := threads.Weekly
+			if threadID == 0 {
+				threadID = threads.Daily
+			}
+			notifySinks(message, "weekly")
+			if err := telegramClient.SendMessage(threadID, message); err != nil {
+				log.Printf("[ERROR] 주간 보고서 전송 실패: %v", err)
+			} else {
+				log.Printf("주간 보고서 전송 완료")
+				if !isDev {
+					api.MarkReportSent(accountName, api.ReportWeekly, period)
+				}
+			}
+		}
+
+		if isDev {
+			timer.Reset(5 * time.Minute)
+		} else {
+			// 다음 주 같은 요일, 같은 시각
+			timer.Reset(7 * 24 * time.Hour)
+			log.Printf("다음 주간 보고서 예정 시간: %s", api.ReportTime(time.Now().Add(7*24*time.Hour)).Format("2006-01-02 15:04:05"))
 		}
 	}
 }
@@ -849,44 +2919,145 @@ func startDailyWorkerReporter(telegramClient *telegram.Client, cfg *config.Confi
 func main() {
 	// Configure logging with timestamp, source file, and line number
 	log.SetFlags(log.Ldate | log.Ltime | log.Lmicroseconds | log.Lshortfile)
+	// 최근 로그를 /api/logs로 볼 수 있도록 메모리에도 보관
+	log.SetOutput(io.MultiWriter(os.Stderr, api.GlobalLogBuffer))
 	log.Printf("Starting Kuzco Monitor...")
 
 	if err := godotenv.Load(); err != nil {
 		log.Printf("Warning: .env file not found: %v", err)
 	}
 
-	cfg, err := config.LoadConfig("config.yaml")
+	cfg, err := config.LoadConfig(configPath)
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
-	client := api.NewClient()
+	// 개발 모드에서는 telegram_test 설정이 있으면 테스트 봇/채널로 전송
+	if os.Getenv("ENV") == "dev" && cfg.UseTestTelegram() {
+		log.Printf("개발 모드: telegram_test 봇/채널 사용")
+	}
+
 	telegramClient := telegram.NewClient(cfg.Telegram.Token, cfg.Telegram.ChatID)
 
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	// Slack/Discord 알림과 보고서 (선택)
+	alertSinks = newAlertSinks(cfg)
+	for _, sink := range alertSinks {
+		log.Printf("%s alerts enabled", sink.name)
+	}
+
+	// SIGINT/SIGTERM을 받으면 ctx가 취소되어 인스턴스 모니터링이 멈춥니다
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	var monitors sync.WaitGroup
+
+	// 프록시는 클라이언트 생성 전에 설정해야 합니다
+	if err := api.SetHTTPProxy(cfg.HTTP.ProxyURL); err != nil {
+		log.Fatalf("Invalid http.proxyURL: %v", err)
+	}
+	if cfg.HTTP.ProxyURL != "" {
+		log.Printf("Using HTTP proxy for Kuzco/Vast.ai requests")
+	}
+
+	// 재시작 후에도 알림 상태(해결 알림, 불일치 유예 시간)를 유지
+	if err := api.LoadAlertState(cfg.Monitoring.AlertStatePath()); err != nil {
+		log.Printf("Failed to load alert state, starting fresh: %v", err)
+	}
+
+	primaryAccountName = cfg.PrimaryAccountName()
+	api.SetHourlyEWMAAlpha(cfg.Reporting.EWMAAlpha)
+	api.GlobalWorkerEvents.SetCapacity(cfg.Monitoring.EventBufferSize)
+	api.GlobalLogBuffer.SetCapacity(cfg.Monitoring.LogBufferLines)
+	api.GlobalLogBuffer.SetSecrets(cfg.Secrets())
+	if loc, err := cfg.Reporting.Location(); err == nil {
+		api.SetReportLocation(loc)
+	}
+	setReportLocale(cfg.Reporting.Locale)
+	api.SetNumberStyle(cfg.Reporting.NumberStyle)
+	api.SetPointDivisor(cfg.Reporting.PointDivisor)
+	api.SetEfficiencyDecimals(cfg.Reporting.EfficiencyPrecision())
 
-	// 개발 모드일 때만 API 서버 시작
+	// 개발 모드이거나 api.enabled가 설정된 경우 API 서버 시작
 	isDev := os.Getenv("ENV") == "dev"
-	if isDev {
-		// API 서버 시작 (포트 8080)
-		metricsServer := api.NewMetricsServer(8080)
-		go metricsServer.Start()
-		log.Printf("Metrics API server started on port 8080 (개발 모드)")
+	if isDev || cfg.API.Enabled {
+		apiServerEnabled = true
+		listenAddr := cfg.API.ListenAddr()
+		metricsServer := api.NewMetricsServer(listenAddr)
+		metricsServer.SetAuthToken(cfg.API.AuthToken)
+		metricsServer.SetAccountName(primaryAccountName)
+		metricsServer.SetReportTrigger(func(kind string) error {
+			return sendRequestedReport(telegramClient, cfg, primaryAccountName, kind)
+		})
+		go func() {
+			if err := metricsServer.Start(); err != nil {
+				log.Printf("[ERROR] Metrics API server stopped: %v", err)
+			}
+		}()
+		log.Printf("Metrics API server started on %s", listenAddr)
+	}
+
+	// 스레드 설정 오류를 첫 보고서 전송 전에 확인
+	if !cfg.Telegram.SkipStartupTest {
+		runThreadSelfTest(telegramClient, cfg.Telegram.Threads)
+	}
+
+	// 배포 직후 잘못된 설정을 바로 알 수 있도록 불러온 설정 요약을 전송
+	if !cfg.Telegram.SkipStartupSummary {
+		if err := telegramClient.SendMessage(cfg.Telegram.Threads.Status, formatStartupSummary(cfg)); err != nil {
+			log.Printf("[ERROR] Failed to send startup summary: %v", err)
+		}
 	}
 
 	// Start telegram bot
-	go startTelegramBot(telegramClient, cfg)
+	go runAccountLoop(ctx, sharedLoopAccount, "telegram bot", loopPanicAlert(telegramClient, cfg, sharedLoopAccount), func() {
+		startTelegramBot(telegramClient, cfg)
+	})
+
+	// 정기 보고서는 계정마다 따로 전송하며, 보고서 포맷 중 panic이 나도 프로세스가 멈추지 않도록 runAccountLoop로 감쌉니다
+	for _, account := range cfg.Accounts {
+		reportAlert := loopPanicAlert(telegramClient, cfg, account.Name)
+
+		// Start hourly reporter
+		if cfg.Features.HourlyReportEnabled() {
+			go runAccountLoop(ctx, account.Name, "hourly report", reportAlert, func() {
+				startHourlyReporter(telegramClient, cfg, account.Name)
+			})
+		}
+
+		// Start daily worker reporter
+		if cfg.Features.DailyWorkerReportEnabled() {
+			go runAccountLoop(ctx, account.Name, "daily worker report", reportAlert, func() {
+				startDailyWorkerReporter(telegramClient, cfg, account.Name)
+			})
+		}
 
-	// Start hourly reporter
-	go startHourlyReporter(telegramClient, cfg)
+		// Start weekly reporter
+		if cfg.Features.WeeklyReportEnabled() {
+			go runAccountLoop(ctx, account.Name, "weekly report", reportAlert, func() {
+				startWeeklyReporter(telegramClient, cfg, account.Name)
+			})
+		}
+	}
+	if !cfg.Features.HourlyReportEnabled() {
+		log.Printf("Hourly report disabled (features.hourlyReport)")
+	}
+	if !cfg.Features.DailyWorkerReportEnabled() {
+		log.Printf("Daily worker report disabled (features.dailyWorkerReport)")
+	}
+	if !cfg.Features.WeeklyReportEnabled() {
+		log.Printf("Weekly report disabled (features.weeklyReport)")
+	}
 
-	// Start daily worker reporter
-	go startDailyWorkerReporter(telegramClient, cfg)
+	// /snooze 해제 안내
+	go runAccountLoop(ctx, sharedLoopAccount, "snooze watcher", loopPanicAlert(telegramClient, cfg, sharedLoopAccount), func() {
+		startSnoozeWatcher(telegramClient, cfg)
+	})
 
 	for _, account := range cfg.Accounts {
 		fmt.Printf("Starting metrics collection for account: %s\n", account.Name)
 
+		// 계정마다 별도의 클라이언트를 사용하여 토큰과 설정이 섞이지 않도록 합니다
+		client := api.NewClient()
+		client.SetUserAgent(account.Kuzco.UserAgent)
 		token, userID, err := client.Login(account.Kuzco.Email, account.Kuzco.Password)
 		if err != nil {
 			log.Printf("Login failed for %s: %v", account.Name, err)
@@ -894,26 +3065,47 @@ func main() {
 		}
 
 		client.SetToken(token)
+		client.SetCredentials(account.Kuzco.Email, account.Kuzco.Password)
+		client.SetAccountName(account.Name)
+		client.SetTeams(account.Kuzco.Teams)
+		client.SetVastaiCostSource(account.Vastai.CostSource)
+		client.SetMonitoringConfig(cfg.Monitoring)
+		client.SetAutoReboot(cfg.Features.AutoRebootEnabled())
+		accountClientsLock.Lock()
+		accountClients[account.Name] = client
+		accountClientsLock.Unlock()
 
 		dailyChan := make(chan api.DailyMetrics, 1)
 		minuteChan := make(chan api.MinuteMetrics, 1)
 		stopChan := make(chan struct{})
 
 		sendAlert := func(message, alertType string) error {
+			threads := accountThreads(cfg, account.Name)
 			var threadID int
 			switch alertType {
 			case "daily":
-				threadID = cfg.Telegram.Threads.Daily
+				threadID = threads.Daily
 			case "hourly":
-				threadID = cfg.Telegram.Threads.Hourly
+				threadID = threads.Hourly
 			case "error":
-				threadID = cfg.Telegram.Threads.Error
+				threadID = threads.Error
 			case "status":
-				threadID = cfg.Telegram.Threads.Status
+				threadID = threads.Status
 			case "worker":
-				threadID = cfg.Telegram.Threads.Workers
+				threadID = threads.Workers
 			}
-			return telegramClient.SendMessage(threadID, message)
+			if alertType == "daily" || alertType == "worker" {
+				message = withAccountHeader(cfg, account.Name, message)
+			}
+			// /snooze 중에는 보고서를 제외한 알림을 로그로만 남김
+			if alertType == "error" || alertType == "status" {
+				if until, snoozed := api.AlertsSnoozed(time.Now()); snoozed {
+					log.Printf("Alert for %s suppressed (snoozed until %s): %s", account.Name, until.Format(time.RFC3339), message)
+					return nil
+				}
+			}
+			notifySinks(message, alertType)
+			return sendPages(telegramClient, threadID, api.SplitMessage(message, telegramMessageLimit))
 		}
 
 		var vastaiToken string
@@ -921,34 +3113,124 @@ func main() {
 		if account.Vastai.Enabled {
 			vastaiToken = account.Vastai.Token
 			vastaiClient = api.NewVastaiClient(vastaiToken)
+			if err := vastaiClient.SetMonitoringConfig(cfg.Monitoring); err != nil {
+				log.Printf("Invalid monitoring config for %s, using defaults: %v", account.Name, err)
+			}
 			// Start instance monitoring if Vast.ai is enabled
-			go startInstanceMonitoring(vastaiClient, sendAlert)
+			vastaiClient.SetAccountName(account.Name)
+			vastaiClient.SetAutoReboot(cfg.Features.AutoRebootEnabled())
+			if cfg.Features.InstanceMonitoringEnabled() {
+				monitors.Add(1)
+				go func() {
+					defer monitors.Done()
+					runAccountLoop(ctx, account.Name, "instance monitoring", sendAlert, func() {
+						startInstanceMonitoring(ctx, vastaiClient, sendAlert)
+					})
+				}()
+			} else {
+				log.Printf("Instance monitoring disabled for %s (features.instanceMonitoring)", account.Name)
+			}
 		}
 
-		go client.CollectMetrics(
-			userID,
-			vastaiToken,
-			account.Vastai.IncludeVastaiCost,
-			account.Alerts,
-			sendAlert,
-			dailyChan,
-			minuteChan,
-			stopChan,
-		)
-
-		go func(email string) {
+		go runAccountLoop(ctx, account.Name, "metrics collection", sendAlert, func() {
+			client.CollectMetrics(
+				userID,
+				vastaiToken,
+				account.Vastai.IncludeVastaiCost,
+				account.Alerts,
+				sendAlert,
+				dailyChan,
+				minuteChan,
+				stopChan,
+			)
+		})
+
+		go runAccountLoop(ctx, account.Name, "metrics update", sendAlert, func() {
 			for {
 				select {
 				case <-dailyChan:
-					fmt.Printf("Daily Metrics for %s:\n", email)
+					fmt.Printf("Daily Metrics for %s:\n", account.Name)
 				case mm := <-minuteChan:
-					fmt.Printf("Minute Metrics for %s:\n", email)
-					updateCurrentMetrics(mm)
+					fmt.Printf("Minute Metrics for %s:\n", account.Name)
+					updateCurrentMetrics(account.Name, mm)
 				}
 			}
-		}(account.Name)
+		})
 	}
 
-	<-sigChan
+	// SIGHUP으로 스레드, 명령어 허용 목록, 알림 기준을 재시작 없이 다시 불러옵니다
+	hupChan := make(chan os.Signal, 1)
+	signal.Notify(hupChan, syscall.SIGHUP)
+	go func() {
+		for range hupChan {
+			accountClientsLock.Lock()
+			clients := maps.Clone(accountClients)
+			accountClientsLock.Unlock()
+			reloadConfig(cfg, clients)
+		}
+	}()
+
+	<-ctx.Done()
 	fmt.Println("\nShutting down...")
+	waitForShutdown(&monitors, shutdownTimeout)
+}
+
+// shutdownTimeout은 종료 시 진행 중인 인스턴스 모니터링이 멈추기를 기다리는 최대 시간입니다
+const shutdownTimeout = 10 * time.Second
+
+// waitForShutdown은 모니터링 고루틴이 모두 멈추거나 timeout이 지날 때까지 기다립니다
+// 로그 대기는 바로 취소되지만 진행 중인 Vast.ai 요청은 끝날 때까지 기다려야 하므로 시간을 제한합니다
+func waitForShutdown(monitors *sync.WaitGroup, timeout time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		monitors.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		log.Printf("Instance monitoring stopped")
+	case <-time.After(timeout):
+		log.Printf("[WARN] Instance monitoring did not stop within %s, exiting anyway", timeout)
+	}
+}
+
+// configPath는 설정 파일 경로입니다
+const configPath = "config.yaml"
+
+// reloadConfig는 설정 파일을 다시 읽어 실행 중에 바꿀 수 있는 항목을 반영하고 변경 내용을 로그로 남깁니다
+// 설정 파일이 유효하지 않으면 현재 설정을 유지합니다
+func reloadConfig(cfg *config.Config, accountClients map[string]*api.Client) {
+	log.Printf("SIGHUP received, reloading %s...", configPath)
+	next, err := config.LoadConfig(configPath)
+	if err != nil {
+		log.Printf("[ERROR] Config reload failed, keeping current config: %v", err)
+		return
+	}
+	if os.Getenv("ENV") == "dev" {
+		next.UseTestTelegram()
+	}
+
+	configLock.Lock()
+	applied, restartRequired := config.ReloadChanges(cfg, next)
+	cfg.ApplyReloadable(next)
+	// 재시작 전까지 기존 값도 사용되므로 이전과 새 설정의 비밀 값을 모두 가림
+	api.GlobalLogBuffer.SetSecrets(append(cfg.Secrets(), next.Secrets()...))
+	configLock.Unlock()
+
+	for name, client := range accountClients {
+		if account, ok := next.FindAccount(name); ok {
+			client.SetAlertConfig(account.Alerts)
+		}
+	}
+
+	if len(applied) == 0 && len(restartRequired) == 0 {
+		log.Printf("Config reloaded: no changes")
+		return
+	}
+	for _, change := range applied {
+		log.Printf("Config reloaded: %s", change)
+	}
+	if len(restartRequired) > 0 {
+		log.Printf("[WARN] Config changes that require a restart were not applied: %s", strings.Join(restartRequired, ", "))
+	}
 }
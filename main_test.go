@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"test/api"
+	"test/config"
+	"test/telegram"
+	"testing"
+	"time"
+)
+
+func TestFormatWorkerStatsTokensLastHour(t *testing.T) {
+	delta := int64(2500000)
+	metrics := &api.MinuteMetrics{}
+	metrics.User.Workers = []api.WorkerMinuteMetrics{
+		{Name: "worker-new", InstanceCount: 1, TokensPerInstance: 2000},
+		{Name: "worker-old", InstanceCount: 1, TokensPerInstance: 1000, TokensLastHour: &delta},
+	}
+
+	pages := formatWorkerStats(metrics, "", 0)
+	if len(pages) != 1 {
+		t.Fatalf("expected a single page, got %d", len(pages))
+	}
+	var newRow, oldRow string
+	for _, line := range strings.Split(pages[0], "\n") {
+		switch {
+		case strings.Contains(line, "worker-new"):
+			newRow = line
+		case strings.Contains(line, "worker-old"):
+			oldRow = line
+		}
+	}
+	if !strings.Contains(pages[0], "1hT") {
+		t.Errorf("expected a last-hour tokens column, got:\n%s", pages[0])
+	}
+	if !strings.Contains(oldRow, api.FormatPoints(delta)) {
+		t.Errorf("expected last-hour tokens %s in %q", api.FormatPoints(delta), oldRow)
+	}
+	// 1시간치 기록이 없는 워커는 "-"로 표시
+	if cells := strings.Split(newRow, "|"); len(cells) < 5 || strings.TrimSpace(cells[4]) != "-" {
+		t.Errorf("expected \"-\" for a worker without last-hour tokens, got %q", newRow)
+	}
+}
+
+func TestFormatStartupSummaryReports(t *testing.T) {
+	off := false
+	cfg := &config.Config{}
+	cfg.Features.HourlyReport = &off
+	cfg.Features.WeeklyReport = &off
+
+	summary := formatStartupSummary(cfg)
+	if strings.Contains(summary, msg("startup.reportHourly")) || strings.Contains(summary, fmt.Sprintf(msg("startup.reportWeekly"), "09:00")) {
+		t.Errorf("expected the disabled hourly and weekly reports to be omitted, got:\n%s", summary)
+	}
+	if !strings.Contains(summary, msg("startup.reportDaily")) || !strings.Contains(summary, fmt.Sprintf(msg("startup.reportWorkers"), "09:00", "Local")) {
+		t.Errorf("expected the enabled reports to be listed, got:\n%s", summary)
+	}
+}
+
+func TestChunkCodeBlocksSplitsLongOutput(t *testing.T) {
+	lines := make([]string, 300)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("line %03d %s", i, strings.Repeat("x", 40))
+	}
+
+	pages := chunkCodeBlocks("title", lines)
+	if len(pages) < 2 {
+		t.Fatalf("expected several pages, got %d", len(pages))
+	}
+	if !strings.HasPrefix(pages[0], "title\n```") {
+		t.Errorf("expected the title on the first page, got %q", pages[0][:20])
+	}
+	for i, page := range pages {
+		if len(page) > telegramMessageLimit {
+			t.Errorf("page %d is %d bytes, over the limit", i, len(page))
+		}
+		if i > 0 && strings.Contains(page, "title") {
+			t.Errorf("expected the title only on the first page, found it on page %d", i)
+		}
+		if !strings.HasPrefix(strings.TrimPrefix(page, "title\n"), "```") || !strings.HasSuffix(page, "```") {
+			t.Errorf("expected page %d to be a closed code block", i)
+		}
+	}
+}
+
+func TestRunAccountLoopAlertsOncePerOutage(t *testing.T) {
+	restart, maxRestart, stable := accountLoopRestartDelay, accountLoopMaxRestartDelay, accountLoopStableRun
+	accountLoopRestartDelay, accountLoopMaxRestartDelay, accountLoopStableRun = time.Millisecond, 4*time.Millisecond, time.Hour
+	defer func() {
+		accountLoopRestartDelay, accountLoopMaxRestartDelay, accountLoopStableRun = restart, maxRestart, stable
+	}()
+
+	alerts := 0
+	sendAlert := func(string, string) error {
+		alerts++
+		return nil
+	}
+	runs := 0
+	runAccountLoop(context.Background(), "main", "test loop", sendAlert, func() {
+		runs++
+		if runs < 4 {
+			panic("boom")
+		}
+	})
+
+	if runs != 4 {
+		t.Errorf("expected the loop to restart until it returned, got %d runs", runs)
+	}
+	if alerts != 1 {
+		t.Errorf("expected a single alert for repeated panics, got %d", alerts)
+	}
+}
+
+func TestRunAccountLoopRecoversReporterPanic(t *testing.T) {
+	restart := accountLoopRestartDelay
+	accountLoopRestartDelay = time.Millisecond
+	defer func() { accountLoopRestartDelay = restart }()
+
+	var alerts []string
+	sendAlert := func(message, alertType string) error {
+		alerts = append(alerts, message)
+		return nil
+	}
+	// 첫 실행에서는 메트릭스가 없어 보고서 포맷 중 panic이 나고, 다시 시작한 뒤에는 정상적으로 끝남
+	var metrics *api.MinuteMetrics
+	var pages []string
+	runAccountLoop(context.Background(), "main", "hourly report", sendAlert, func() {
+		current := metrics
+		metrics = &api.MinuteMetrics{}
+		pages = formatWorkerStats(current, "", 0)
+	})
+
+	if len(pages) == 0 {
+		t.Errorf("expected the reporter to run again after the panic")
+	}
+	if len(alerts) != 1 || !strings.Contains(alerts[0], "hourly report") {
+		t.Errorf("expected one panic alert naming the loop, got %q", alerts)
+	}
+}
+
+func TestHandleUpdateRecoversPanic(t *testing.T) {
+	update := telegram.Update{}
+	update.Message.Text = "/threads"
+
+	// 텔레그램 클라이언트가 없으면 응답 전송에서 panic이 나지만 오류로 반환되어야 함
+	err := handleUpdate(update, nil, &config.Config{})
+	if err == nil || !strings.Contains(err.Error(), "panicked") {
+		t.Errorf("expected the panic to be returned as an error, got %v", err)
+	}
+}
+
+// recordingAlerter는 받은 메시지를 기록하는 Alerter입니다
+type recordingAlerter struct {
+	messages []string
+	err      error
+}
+
+func (a *recordingAlerter) SendAlert(message, alertType string) error {
+	a.messages = append(a.messages, alertType+": "+message)
+	return a.err
+}
+
+func TestNotifySinksFansOut(t *testing.T) {
+	failing := &recordingAlerter{err: fmt.Errorf("webhook down")}
+	working := &recordingAlerter{}
+	saved := alertSinks
+	alertSinks = []alertSink{{"failing", failing}, {"working", working}}
+	defer func() { alertSinks = saved }()
+
+	notifySinks("weekly report", "weekly")
+	notifySinks("⚠️ Credit Alert", "status")
+
+	// 한 채널의 전송이 실패해도 모든 채널이 같은 메시지를 받음
+	if strings.Join(failing.messages, ",") != strings.Join(working.messages, ",") || len(working.messages) != 2 {
+		t.Errorf("expected every sink to get the same messages, got %q and %q", failing.messages, working.messages)
+	}
+}
+
+func TestNewAlertSinks(t *testing.T) {
+	cfg := &config.Config{}
+	if sinks := newAlertSinks(cfg); len(sinks) != 0 {
+		t.Errorf("expected no sinks when Slack and Discord are disabled, got %d", len(sinks))
+	}
+	cfg.Slack = config.SlackConfig{Enabled: true, WebhookURL: "https://hooks.slack.com/services/x"}
+	cfg.Discord.Enabled = true
+	cfg.Discord.WebhookURL = "https://discord.com/api/webhooks/1/x"
+	if sinks := newAlertSinks(cfg); len(sinks) != 2 || sinks[0].name != "slack" || sinks[1].name != "discord" {
+		t.Errorf("expected slack and discord sinks, got %+v", sinks)
+	}
+}
+
+func TestFormatInstancesKeepsCallerOrder(t *testing.T) {
+	instances := []api.VastaiInstance{{ID: 3}, {ID: 1}, {ID: 2}}
+	formatInstances(instances, nil)
+	if instances[0].ID != 3 || instances[1].ID != 1 || instances[2].ID != 2 {
+		t.Errorf("expected the caller's slice to keep its order, got %+v", instances)
+	}
+}
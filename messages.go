@@ -0,0 +1,460 @@
+package main
+
+import "log"
+
+// 지원하는 리포트 로케일
+const (
+	localeKorean  = "ko"
+	localeEnglish = "en"
+)
+
+// reportLocale은 사용자에게 보여지는 메시지의 로케일입니다 (기본값 ko)
+var reportLocale = localeKorean
+
+// messageCatalog는 로케일별 메시지 템플릿입니다
+// 템플릿은 fmt.Sprintf 형식 문자열이며, 모든 로케일이 같은 인자 순서를 사용해야 합니다
+var messageCatalog = map[string]map[string]string{
+	localeKorean: {
+		// 시간별 통계
+		"hourly.template": "시간별 통계 (%s ~ %s)\n\n" +
+			"RPM:\n" +
+			"  최소: %d\n" +
+			"  최대: %d\n" +
+			"  평균: %.0f\n" +
+			"  추세(EWMA): %.0f\n" +
+			"  현재: %d\n\n" +
+			"인스턴스 수:\n" +
+			"  최소: %d\n" +
+			"  최대: %d\n" +
+			"  평균: %.0f\n" +
+			"  추세(EWMA): %.1f\n" +
+			"  현재: %d\n\n" +
+			"생성량:\n" +
+			"  전체: %d\n" +
+			"  사용자: %d\n" +
+			"  비율: %.2f%%",
+
+		// 리포트
+		"report.template": "포인트 : %s | %s\n비중 : %.3f%%\n비용(vast,kuzco) : $%.2f | $%.2f\n1%% 효율(vast,kuzco) : $%s | $%s",
+		"daily.title":     "📈 24시간 추이 (%d시간)\n%s",
+		"daily.header":    "시간      |   RPM 최소/평균/최대 | 인스턴스 최소/평균/최대\n",
+		"daily.empty":     "24시간 통계가 아직 없습니다.",
+
+		"report.balance":             "\n잔액 : $%.2f",
+		"report.partial":             "\n\n⚠️ 일부 메트릭스를 가져오지 못했습니다:\n%s",
+		"total.template":             "📊 전체 계정 합계 (%d개 계정)\n\n포인트 : %s | %s\n인스턴스 : %d\n비중 : %.3f%%\n비용 : $%.2f\n1%% 효율 : $%s",
+		"total.line":                 "• %s : %s | %d대 | %.3f%% | $%.2f",
+		"cost.kuzco":                 "Kuzco 일일 비용: `$%.2f`",
+		"cost.vastai":                "\nVast.ai 일일 비용: `$%.2f`",
+		"cost.burn":                  "\n현재 소모: `$%.3f/시간`",
+		"cost.balance":               "\n잔액: `$%.2f`",
+		"cost.lowBalance":            "\n⚠️ 잔액이 일일 비용보다 적습니다!",
+		"cost.daysLeft":              "\n예상 가능 사용일: %.1f일",
+		"balance.value":              "Balance : `$%.2f`",
+		"balance.unavailable":        "Balance information not available",
+		"status.counts":              "Vast.Ai  : %d\nActual Instances : %d\n인스턴스당 시간당 생성량 : %.1f\n\n%s",
+		"status.histogram":           "상태 : %s\n%s",
+		"status.empty":               "인스턴스 상태 정보가 없습니다.",
+		"status.geo":                 "위치 : %s",
+		"status.geoMore":             "외 %d곳",
+		"geo.title":                  "🌍 인스턴스 위치 (국가 %d개, 지역 %d곳)",
+		"geo.empty":                  "인스턴스가 없습니다.",
+		"dupes.title":                "⚠️ 여러 인스턴스가 보고한 IP %d개 (비용 중복 가능)",
+		"dupes.none":                 "✅ 중복된 인스턴스 IP가 없습니다.",
+		"lanes.title":                "🛣️ Lane별 생성량 (%d개 Lane)\n%s",
+		"lanes.empty":                "🛣️ Lane 정보가 있는 인스턴스가 없습니다.",
+		"top.title":                  "🏆 인스턴스당 토큰 순위 (%d개 워커, 중앙값 %s)",
+		"top.best":                   "상위 워커",
+		"top.worst":                  "하위 워커",
+		"top.empty":                  "🏆 인스턴스가 있는 워커가 없습니다.",
+		"startup.title":              "🚀 Kuzco Monitor 시작 (버전 `%s`)\n계정: %d개 (기본 계정: %s)",
+		"startup.reports":            "보고서: %s",
+		"startup.reportHourly":       "시간별 매시 정각",
+		"startup.reportWorkers":      "워커 매일 %s (%s)",
+		"startup.reportWeekly":       "주간 월요일 %s",
+		"startup.reportDaily":        "일일 UTC 00:00",
+		"genhistory.caption":         "📊 %s 생성량 기록\n%s ~ %s (%d시간) | 최대 %d | 합계 %d",
+		"genhistory.header":          "시간        | 생성량",
+		"genhistory.empty":           "📊 %s 계정의 최근 24시간 생성량 기록이 없습니다.",
+		"genhours.usage":             "사용법: `/genhours <시간>` (1~%d)",
+		"genhours.invalid":           "잘못된 시간입니다: %s (1~%d)",
+		"genhours.empty":             "📊 %s 계정의 최근 %d시간 생성량 기록이 없습니다.",
+		"genhours.title":             "📊 %s 생성량 기록 (%d시간) | 합계 %d | 시간당 평균 %.0f | 최대 %d",
+		"earnings.empty":             "💰 %s 계정의 최근 %d시간 토큰 적립 기록이 없습니다.",
+		"earnings.title":             "💰 %s 시간별 적립 (최근 %d시간) | 합계 %s | %s 조회",
+		"forecast.title":             "🔮 잔액 소진 예측\n잔액: `$%.2f` | 일일 소모: `$%.2f` | 예상 가능 사용일: `%.1f일`",
+		"forecast.note":              "_현재 워커 구성과 소모가 그대로 유지된다고 가정합니다. Share는 워커 일일 비용 비중, Solo는 해당 워커만 남았을 때의 사용일입니다._",
+		"forecast.noCredit":          "🔮 Vast.ai 잔액 정보가 없어 예측할 수 없습니다.",
+		"forecast.noCost":            "🔮 잔액 `$%.2f` - 일일 비용이 0이라 소진 시점을 예측할 수 없습니다.",
+		"diff.title":                 "🔀 직전 시간별 보고서(%s) 대비 변화",
+		"diff.tokens":                "토큰 (24h): %s → %s (%s)",
+		"diff.share":                 "비중: %.3f%% → %.3f%% (%s)",
+		"diff.instances":             "인스턴스: %d → %d (%s)",
+		"diff.credit":                "잔액: $%.2f → $%.2f (%s)",
+		"diff.empty":                 "비교할 시간별 보고서 스냅샷이 아직 없습니다.",
+		"alerts.title":               "🚨 활성 알림 (%s, %d개)",
+		"alerts.empty":               "✅ %s 계정에 활성 알림이 없습니다.",
+		"alerts.unknownSince":        "알 수 없음",
+		"alerts.ackHint":             "`/alerts ack <type>` - 해소될 때까지 복구 알림을 보내지 않습니다",
+		"alerts.ackUsage":            "사용법: `/alerts ack <type>` (%s)",
+		"alerts.ackDone":             "🔕 `%s` 알림을 해소될 때까지 확인 처리했습니다.",
+		"alerts.ackFailed":           "알림 확인 처리 실패: %s",
+		"snooze.usage":               "사용법: `/snooze <기간>` (예: `2h`, `30m`) 또는 `/snooze off`",
+		"snooze.invalid":             "잘못된 기간입니다: %s (예: `2h`, `30m`)",
+		"snooze.done":                "🔕 %s까지 모든 알림을 일시 중지합니다. (보고서는 계속 전송)",
+		"snooze.active":              "🔕 %s까지 알림이 일시 중지되어 있습니다. `/snooze off`로 해제할 수 있습니다.",
+		"snooze.notActive":           "알림이 일시 중지되어 있지 않습니다.",
+		"snooze.resumed":             "🔔 알림 일시 중지를 해제했습니다.",
+		"snooze.expired":             "🔔 알림 일시 중지가 끝나 알림을 다시 보냅니다.",
+		"threads.id":                 "🧵 이 토픽의 스레드 ID: `%d` (chat\\_id: `%d`)",
+		"threads.configured":         "설정된 스레드: %s",
+		"threads.unconfigured":       "아직 설정에 없는 스레드입니다. `telegram.threads`의 원하는 항목(daily, hourly, error, status, workers, weekly)에 위 ID를 넣으세요.",
+		"threads.noTopic":            "🧵 토픽 밖(일반 채팅)에서 실행되었습니다. 스레드 ID를 확인하려면 원하는 토픽 안에서 `/threads`를 보내세요. (chat\\_id: `%d`)",
+		"config.title":               "⚙️ 현재 설정 (버전 `%s`, 비밀 값은 `***`로 표시)",
+		"config.error":               "❌ 설정을 표시할 수 없습니다: %s",
+		"weekly.title":               "🗓️ 주간 요약 (%s ~ %s)",
+		"weekly.tokens":              "토큰 : %s → %s (%s)",
+		"weekly.share":               "평균 비중 : %.3f%% → %.3f%% (%s)",
+		"weekly.spend":               "총 비용 : $%.2f → $%.2f (%s)",
+		"weekly.best":                "최고의 날 : %s (%s)",
+		"weekly.worst":               "최저의 날 : %s (%s)",
+		"weekly.limited":             "⚠️ 기록 저장소가 없어 메모리에 남은 데이터만 집계했습니다 (이번 주 %d일, 지난 주 %d일, 재시작 시 초기화)",
+		"weekly.empty":               "🗓️ 주간 요약에 사용할 일별 데이터가 아직 없습니다.",
+		"selftest.message":           "🔧 시작 점검: `%s` 스레드 연결 확인",
+		"vast.title":                 "☁️ Vast.ai 계정 상태 (%s)",
+		"vast.credit":                "잔액 : $%.2f",
+		"vast.credit.unavailable":    "잔액 : 조회 실패",
+		"vast.instances":             "인스턴스 : %d",
+		"vast.instances.unavailable": "인스턴스 : 조회 실패",
+		"vast.statuses.unavailable":  "인스턴스 상태 목록을 조회하지 못했습니다.",
+		"unpriced.title":             "💸 가격이 없는 GPU 모델 (%d개, 일일 비용 $0으로 계산됨)\n%s",
+		"unpriced.empty":             "✅ 모든 GPU 모델의 가격이 instance.json에 있습니다.",
+		"gpuhealth.title":            "🌡️ GPU 상태 (인스턴스 %d개, 이상 %d개)\n기준: %d°C 이상 과열, Running 중 사용률 %d%% 이하 유휴\n%s",
+		"gpuhealth.empty":            "GPU 측정값이 있는 인스턴스가 없습니다.",
+		"teams.title":                "👥 팀별 현황 (%d개 팀, 최근 24시간)",
+		"teams.empty":                "팀이 설정되지 않았습니다. 계정의 `kuzco.teams`에 팀 ID를 추가하세요.",
+		"error.gpuPrices":            "GPU 가격 파일을 불러오지 못했습니다: %s",
+		"instances.title":            "🖥️ Vast.ai 인스턴스 (%d개, orphaned %d개)",
+		"logs.usage":                 "사용법: `/logs <instanceID> [account]`",
+		"logs.invalidID":             "잘못된 인스턴스 ID입니다: %s",
+		"ignore.list":                "🙈 자동 재부팅 제외 인스턴스: %s\n`/unignore <instanceID>`로 다시 포함할 수 있습니다.",
+		"ignore.none":                "자동 재부팅에서 제외된 인스턴스가 없습니다. 사용법: `/ignore <instanceID>`",
+		"ignore.done":                "🙈 인스턴스 %d를 자동 재부팅에서 제외합니다. (`/unignore %d`: 다시 포함)",
+		"ignore.already":             "인스턴스 %d는 이미 자동 재부팅에서 제외되어 있습니다.",
+		"unignore.usage":             "사용법: `/unignore <instanceID>`",
+		"unignore.done":              "✅ 인스턴스 %d를 다시 자동 재부팅합니다.",
+		"unignore.notIgnored":        "인스턴스 %d는 자동 재부팅에서 제외되어 있지 않습니다.",
+		"logs.notOwned":              "%s 계정에 인스턴스 %d가 없습니다.",
+		"logs.requestFailed":         "로그 요청 실패: %s",
+		"logs.downloadFailed":        "로그 다운로드 실패: %s",
+		"logs.waiting":               "⏳ 인스턴스 %d 로그를 요청했습니다. 준비되면 전송합니다.",
+		"logs.title":                 "📜 인스턴스 %d 로그 (마지막 %d줄)",
+		"restart.usage":              "사용법: `/restart <instanceID> [account]`",
+		"restart.confirm":            "⚠️ 인스턴스 %d를 재부팅하려면 %d초 안에 `%s`를 다시 보내세요.",
+		"restart.success":            "✅ 인스턴스 %d 재부팅을 요청했습니다.",
+		"restart.failed":             "인스턴스 %d 재부팅 실패: %s",
+		"selftest.started":           "⏱️ 수집 한 주기를 실행하며 단계별 소요 시간을 측정하는 중입니다. 완료되면 결과를 알려드립니다.",
+		"selftest.title":             "⏱️ %s 수집 소요 시간 (전체 %s)",
+		"rebootall.started":          "🔄 모든 인스턴스의 heartbeat 타임아웃을 확인하는 중입니다. 완료되면 결과를 알려드립니다.",
+		"rebootall.done":             "✅ 일괄 재부팅 완료: %d개 인스턴스를 재부팅했습니다.",
+		"rebootall.failed":           "일괄 재부팅 실패: %s",
+		"monitoring.error":           "⚠️ Instance Monitoring Error\n시간: %s\n오류: %s",
+		"error.noAccounts":           "계정 정보가 없습니다.",
+		"error.unauthorized":         "⛔ 이 명령어를 실행할 권한이 없습니다.",
+		"error.unknownAccount":       "알 수 없는 계정입니다: %s",
+		"error.vastaiDisabled":       "%s 계정은 Vast.ai가 활성화되어 있지 않습니다.",
+		"price.title":                "💲 instance.json GPU 일일 비용 (%d개, GPU 시간당 가격×24 + 디스크 비용)",
+		"price.notFound":             "instance.json에 %s GPU 가격이 없습니다. `/price`로 전체 목록을 확인하세요.",
+		"charges.invalid":            "잘못된 날짜입니다: %s (예: `2025-01-31`)",
+		"charges.empty":              "%s의 %s (UTC) Vast.ai 청구 내역이 없습니다.",
+		"charges.title":              "🧾 %s Vast.ai 청구 내역 %s (UTC, %d건)\n합계: $%.2f (수량×단가: $%.2f)",
+		"error.vastaiInstances":      "Vast.ai 인스턴스 조회 실패: %s",
+		"error.login":                "로그인 실패: %s",
+		"error.metrics":              "메트릭스 수집 실패: %s",
+		"error.export":               "메트릭스 직렬화 실패: %s",
+		"error.noMetrics":            "No metrics available. \nPlease wait a moment.",
+
+		// 워커 요약
+		"workers.empty":        "🖥️ 토큰당 수익이 있는 워커가 없습니다.",
+		"workers.summary":      "📊 워커 현황 요약 (%d개 워커/%d개 인스턴스)\n",
+		"workers.total":        "• 총 생성량: %d/시간 | %d/24시간\n",
+		"workers.average":      "• 인스턴스당 평균: %d/시간 | %d/24시간\n\n",
+		"workers.archivedNote": "• \\[A] 보관된 워커 %d개 (토큰은 전체 기간 누적)\n\n",
+		"workers.tableHeader":  "R|워커|I|토큰/I|1hT|1hG/I|모델|GPU|Lane",
+		"workers.sortedBy":     "• 정렬: %s\n\n",
+		"workers.invalidSort":  "잘못된 정렬 기준입니다: %s (`sort=gen`, `sort=count`, `sort=name`, `sort=tokens`)",
+		"workers.modelGeneral": "일반",
+		"workers.modelOther":   "기타",
+
+		// 도움말
+		"help": "사용 가능한 명령어:\n\n" +
+			"`/help` - 이 도움말을 표시합니다\n" +
+			"`/balance` - Vast.ai 잔액을 표시합니다\n" +
+			"`/status` - 인스턴스 상태를 표시합니다\n" +
+			"`/report` - 상세 리포트를 표시합니다\n" +
+			"`/cost` - Vast.ai와 Kuzco의 일일 비용과 잔액을 표시합니다\n" +
+			"`/hourly` - 지난 1시간 동안의 통계를 표시합니다\n" +
+			"`/daily` - 최근 24시간의 시간별 RPM과 인스턴스 수 추이를 표시합니다\n" +
+			"`/workers` - 워커별 시간당 생성량을 표시합니다 (`--all`: 보관된 워커 포함, `sort=gen|count|name`: 정렬 기준)\n" +
+			"`/instances` - Vast.ai 인스턴스와 연결된 워커를 표시합니다\n" +
+			"`/total` - 모든 계정의 합산 리포트를 표시합니다\n" +
+			"`/export` - 현재 메트릭스를 JSON 파일로 전송합니다\n" +
+			"`/lanes` - Lane별 인스턴스 수와 생성량을 표시합니다\n" +
+			"`/top` - 인스턴스당 토큰 기준 상위/하위 5개 워커를 표시합니다\n" +
+			"`/diff` - 직전 시간별 보고서 이후 토큰, 비중, 인스턴스, 잔액 변화를 표시합니다\n" +
+			"`/unpriced` - instance.json에 가격이 없는 GPU 모델을 표시합니다\n" +
+			"`/price [gpu]` - 비용 계산에 사용하는 GPU별 일일 가격(디스크 비용 포함)을 표시합니다\n" +
+			"`/gpuhealth` - 인스턴스별 GPU 온도, 사용률, 전력과 과열/유휴 여부를 표시합니다\n" +
+			"`/geo` - 국가/지역별 인스턴스 수와 Running 인스턴스 수를 표시합니다\n" +
+			"`/dupes` - 같은 IP를 보고한 인스턴스를 표시합니다\n" +
+			"`/teams` - `kuzco.teams`에 설정된 팀별 토큰, 비중, 인스턴스, 생성량을 표시합니다\n" +
+			"`/genhistory` - 최근 24시간 생성량 기록을 막대 차트로 표시합니다\n" +
+			"`/genhours <n>` - 최근 n시간(최대 168)의 생성량 기록을 표로 표시합니다\n" +
+			"`/earnings` - 최근 24시간의 시간별 토큰 적립량(설정 시 달러 환산)을 표시합니다\n" +
+			"`/forecast` - 현재 소모 기준 잔액 소진 예상일과 워커별 비용 비중을 표시합니다\n" +
+			"`/vast` - Kuzco와 별개로 Vast.ai 잔액, 인스턴스 수, 상태별 분포를 표시합니다\n" +
+			"`/charges [YYYY-MM-DD]` - 하루(UTC, 기본값 어제) 동안의 Vast.ai 청구 내역을 항목별로 표시합니다\n" +
+			"`/logs <instanceID>` - Vast.ai 인스턴스의 최근 로그를 표시합니다\n" +
+			"`/restart <instanceID>` - Vast.ai 인스턴스를 재부팅합니다 (1분 내 재전송으로 확인)\n" +
+			"`/rebootall` - heartbeat 타임아웃이 감지된 모든 인스턴스를 즉시 재부팅합니다\n" +
+			"`/ignore <instanceID>` - 인스턴스를 자동 재부팅에서 제외합니다 (인자 없이: 제외 목록, `/unignore <instanceID>`: 다시 포함)\n" +
+			"`/alerts` - 활성화된 알림과 지속 시간을 표시합니다 (`/alerts ack <type>`: 해소될 때까지 확인 처리)\n" +
+			"`/snooze <기간>` - 모든 알림을 지정한 기간 동안 일시 중지합니다 (`/snooze off`: 해제)\n" +
+			"`/config` - 비밀번호와 토큰을 가린 현재 설정을 표시합니다\n" +
+			"`/threads` - 명령어를 보낸 토픽의 스레드 ID를 표시합니다 (설정용)\n" +
+			"`/selftest` - 수집 한 주기를 실행하여 단계별(로그인, 메트릭스, 워커, Vast.ai) 소요 시간을 표시합니다\n\n" +
+			"명령어 뒤에 계정 이름을 붙이면 해당 계정을 조회합니다 (예: `/status account2`)",
+	},
+	localeEnglish: {
+		// Hourly stats
+		"hourly.template": "Hourly Stats (%s ~ %s)\n\n" +
+			"RPM:\n" +
+			"  Min: %d\n" +
+			"  Max: %d\n" +
+			"  Avg: %.0f\n" +
+			"  Trend (EWMA): %.0f\n" +
+			"  Current: %d\n\n" +
+			"Instances:\n" +
+			"  Min: %d\n" +
+			"  Max: %d\n" +
+			"  Avg: %.0f\n" +
+			"  Trend (EWMA): %.1f\n" +
+			"  Current: %d\n\n" +
+			"Generations:\n" +
+			"  Total: %d\n" +
+			"  User: %d\n" +
+			"  Ratio: %.2f%%",
+
+		// Report
+		"report.template": "Points : %s | %s\nShare : %.3f%%\nCost (vast,kuzco) : $%.2f | $%.2f\n1%% efficiency (vast,kuzco) : $%s | $%s",
+		"daily.title":     "📈 24-hour Trend (%d hours)\n%s",
+		"daily.header":    "Hour      |   RPM min/avg/max | Instances min/avg/max\n",
+		"daily.empty":     "No 24-hour stats yet.",
+
+		"report.balance":             "\nBalance : $%.2f",
+		"report.partial":             "\n\n⚠️ Some metrics could not be collected:\n%s",
+		"total.template":             "📊 All Accounts Total (%d accounts)\n\nPoints : %s | %s\nInstances : %d\nShare : %.3f%%\nCost : $%.2f\n1%% efficiency : $%s",
+		"total.line":                 "• %s : %s | %d inst | %.3f%% | $%.2f",
+		"cost.kuzco":                 "Kuzco daily cost: `$%.2f`",
+		"cost.vastai":                "\nVast.ai daily cost: `$%.2f`",
+		"cost.burn":                  "\nCurrent burn: `$%.3f/hr`",
+		"cost.balance":               "\nBalance: `$%.2f`",
+		"cost.lowBalance":            "\n⚠️ Balance is lower than the daily cost!",
+		"cost.daysLeft":              "\nEstimated days left: %.1f",
+		"balance.value":              "Balance : `$%.2f`",
+		"balance.unavailable":        "Balance information not available",
+		"status.counts":              "Vast.Ai  : %d\nActual Instances : %d\nGenerations per instance (1h) : %.1f\n\n%s",
+		"status.histogram":           "Status : %s\n%s",
+		"status.empty":               "No instance status information.",
+		"status.geo":                 "Locations : %s",
+		"status.geoMore":             "%d more",
+		"geo.title":                  "🌍 Instance locations (%d countries, %d regions)",
+		"geo.empty":                  "No instances.",
+		"dupes.title":                "⚠️ %d IPs reported by more than one instance (possibly paid twice)",
+		"dupes.none":                 "✅ No duplicate instance IPs.",
+		"lanes.title":                "🛣️ Generations by Lane (%d lanes)\n%s",
+		"lanes.empty":                "🛣️ No instances with lane information.",
+		"top.title":                  "🏆 Tokens per Instance Ranking (%d workers, median %s)",
+		"top.best":                   "Top workers",
+		"top.worst":                  "Bottom workers",
+		"top.empty":                  "🏆 No workers with instances.",
+		"startup.title":              "🚀 Kuzco Monitor started (version `%s`)\nAccounts: %d (primary: %s)",
+		"startup.reports":            "Reports: %s",
+		"startup.reportHourly":       "hourly on the hour",
+		"startup.reportWorkers":      "workers daily at %s (%s)",
+		"startup.reportWeekly":       "weekly Monday %s",
+		"startup.reportDaily":        "daily UTC 00:00",
+		"genhistory.caption":         "📊 %s Generations History\n%s ~ %s (%d hours) | max %d | total %d",
+		"genhistory.header":          "Time        |  Gens",
+		"genhistory.empty":           "📊 No generations history in the last 24 hours for %s.",
+		"genhours.usage":             "Usage: `/genhours <hours>` (1-%d)",
+		"genhours.invalid":           "Invalid number of hours: %s (1-%d)",
+		"genhours.empty":             "📊 No generations history in the last %[2]d hours for %[1]s.",
+		"genhours.title":             "📊 %s Generations History (%d hours) | total %d | avg %.0f/h | max %d",
+		"earnings.empty":             "💰 No token earnings history in the last %[2]d hours for %[1]s.",
+		"earnings.title":             "💰 %s Hourly Earnings (last %d hours) | total %s | fetched %s",
+		"forecast.title":             "🔮 Credit Forecast\nBalance: `$%.2f` | Daily burn: `$%.2f` | Estimated days left: `%.1f`",
+		"forecast.note":              "_Assumes the current fleet and burn stay constant. Share is the worker's part of daily spend, Solo is days left if only that worker kept running._",
+		"forecast.noCredit":          "🔮 Vast.ai credit information is not available, cannot forecast.",
+		"forecast.noCost":            "🔮 Balance `$%.2f` - daily cost is zero, so there is nothing to forecast.",
+		"diff.title":                 "🔀 Changes since the last hourly report (%s)",
+		"diff.tokens":                "Tokens (24h): %s → %s (%s)",
+		"diff.share":                 "Share: %.3f%% → %.3f%% (%s)",
+		"diff.instances":             "Instances: %d → %d (%s)",
+		"diff.credit":                "Balance: $%.2f → $%.2f (%s)",
+		"diff.empty":                 "No hourly report snapshot to compare against yet.",
+		"alerts.title":               "🚨 Active Alerts (%s, %d)",
+		"alerts.empty":               "✅ No active alerts for %s.",
+		"alerts.unknownSince":        "unknown",
+		"alerts.ackHint":             "`/alerts ack <type>` - skip the recovery notification until the alert clears",
+		"alerts.ackUsage":            "Usage: `/alerts ack <type>` (%s)",
+		"alerts.ackDone":             "🔕 `%s` alert acknowledged until it clears.",
+		"alerts.ackFailed":           "Failed to acknowledge alert: %s",
+		"snooze.usage":               "Usage: `/snooze <duration>` (e.g. `2h`, `30m`) or `/snooze off`",
+		"snooze.invalid":             "Invalid duration: %s (e.g. `2h`, `30m`)",
+		"snooze.done":                "🔕 All alerts snoozed until %s. (Reports are still sent)",
+		"snooze.active":              "🔕 Alerts are snoozed until %s. Use `/snooze off` to resume.",
+		"snooze.notActive":           "Alerts are not snoozed.",
+		"snooze.resumed":             "🔔 Alerts resumed.",
+		"snooze.expired":             "🔔 Alert snooze has ended; alerts resumed.",
+		"threads.id":                 "🧵 Thread ID of this topic: `%d` (chat\\_id: `%d`)",
+		"threads.configured":         "Configured as: %s",
+		"threads.unconfigured":       "Not configured yet. Put this ID under the matching `telegram.threads` entry (daily, hourly, error, status, workers, weekly).",
+		"threads.noTopic":            "🧵 Sent outside a topic (general chat). Send `/threads` inside the topic you want to configure to get its thread ID. (chat\\_id: `%d`)",
+		"config.title":               "⚙️ Current configuration (version `%s`, secrets shown as `***`)",
+		"config.error":               "❌ Failed to show the configuration: %s",
+		"weekly.title":               "🗓️ Weekly Summary (%s ~ %s)",
+		"weekly.tokens":              "Tokens : %s → %s (%s)",
+		"weekly.share":               "Avg share : %.3f%% → %.3f%% (%s)",
+		"weekly.spend":               "Total spend : $%.2f → $%.2f (%s)",
+		"weekly.best":                "Best day : %s (%s)",
+		"weekly.worst":               "Worst day : %s (%s)",
+		"weekly.limited":             "⚠️ No history store, so only in-memory data was summed (this week %d days, last week %d days, reset on restart)",
+		"weekly.empty":               "🗓️ No daily data for a weekly summary yet.",
+		"selftest.message":           "🔧 Startup check: `%s` thread is reachable",
+		"vast.title":                 "☁️ Vast.ai Account Status (%s)",
+		"vast.credit":                "Balance : $%.2f",
+		"vast.credit.unavailable":    "Balance : unavailable",
+		"vast.instances":             "Instances : %d",
+		"vast.instances.unavailable": "Instances : unavailable",
+		"vast.statuses.unavailable":  "Failed to fetch the instance status list.",
+		"unpriced.title":             "💸 GPU models without a price (%d, counted as $0/day)\n%s",
+		"unpriced.empty":             "✅ Every GPU model in the fleet has a price in instance.json.",
+		"gpuhealth.title":            "🌡️ GPU health (%d instances, %d flagged)\nThresholds: hot at %d°C or above, idle at %d%% utilization or below while Running\n%s",
+		"gpuhealth.empty":            "No instance reports GPU readings.",
+		"teams.title":                "👥 Per-team status (%d teams, last 24 hours)",
+		"teams.empty":                "No teams configured. Add team IDs under the account's `kuzco.teams`.",
+		"error.gpuPrices":            "Failed to load GPU prices: %s",
+		"instances.title":            "🖥️ Vast.ai Instances (%d total, %d orphaned)",
+		"logs.usage":                 "Usage: `/logs <instanceID> [account]`",
+		"logs.invalidID":             "Invalid instance ID: %s",
+		"ignore.list":                "🙈 Instances excluded from auto reboot: %s\nUse `/unignore <instanceID>` to include one again.",
+		"ignore.none":                "No instances are excluded from auto reboot. Usage: `/ignore <instanceID>`",
+		"ignore.done":                "🙈 Instance %d excluded from auto reboot. (`/unignore %d`: include again)",
+		"ignore.already":             "Instance %d is already excluded from auto reboot.",
+		"unignore.usage":             "Usage: `/unignore <instanceID>`",
+		"unignore.done":              "✅ Instance %d will be auto rebooted again.",
+		"unignore.notIgnored":        "Instance %d is not excluded from auto reboot.",
+		"logs.notOwned":              "Account %s has no instance %d.",
+		"logs.requestFailed":         "Failed to request logs: %s",
+		"logs.downloadFailed":        "Failed to download logs: %s",
+		"logs.waiting":               "⏳ Requested logs for instance %d. They will be sent when ready.",
+		"logs.title":                 "📜 Instance %d logs (last %d lines)",
+		"restart.usage":              "Usage: `/restart <instanceID> [account]`",
+		"restart.confirm":            "⚠️ To reboot instance %d, send `%[3]s` again within %[2]d seconds.",
+		"restart.success":            "✅ Reboot requested for instance %d.",
+		"restart.failed":             "Failed to reboot instance %d: %s",
+		"selftest.started":           "⏱️ Running one collection cycle and timing each phase. Results will follow when done.",
+		"selftest.title":             "⏱️ %s collection timing (total %s)",
+		"rebootall.started":          "🔄 Checking all instances for heartbeat timeouts. Results will follow when done.",
+		"rebootall.done":             "✅ Bulk reboot finished: %d instances rebooted.",
+		"rebootall.failed":           "Bulk reboot failed: %s",
+		"monitoring.error":           "⚠️ Instance Monitoring Error\nTime: %s\nError: %s",
+		"error.noAccounts":           "No accounts configured.",
+		"error.unauthorized":         "⛔ You are not authorized to run this command.",
+		"error.unknownAccount":       "Unknown account: %s",
+		"error.vastaiDisabled":       "Vast.ai is not enabled for account %s.",
+		"price.title":                "💲 GPU daily cost from instance.json (%d, hourly GPU price×24 + disk cost)",
+		"price.notFound":             "No price for %s in instance.json. Send `/price` for the full list.",
+		"charges.invalid":            "Invalid date: %s (e.g. `2025-01-31`)",
+		"charges.empty":              "No Vast.ai charges for %s on %s (UTC).",
+		"charges.title":              "🧾 %s Vast.ai charges on %s (UTC, %d items)\nTotal: $%.2f (quantity×rate: $%.2f)",
+		"error.vastaiInstances":      "Failed to get Vast.ai instances: %s",
+		"error.login":                "Login failed: %s",
+		"error.metrics":              "Failed to collect metrics: %s",
+		"error.export":               "Failed to serialize metrics: %s",
+		"error.noMetrics":            "No metrics available. \nPlease wait a moment.",
+
+		// Worker summary
+		"workers.empty":        "🖥️ No workers with token earnings.",
+		"workers.summary":      "📊 Worker Summary (%d workers/%d instances)\n",
+		"workers.total":        "• Total generations: %d/hour | %d/24h\n",
+		"workers.average":      "• Average per instance: %d/hour | %d/24h\n\n",
+		"workers.archivedNote": "• \\[A] %d archived workers (tokens are all-time totals)\n\n",
+		"workers.tableHeader":  "R|Worker|I|Tokens/I|1hT|1hG/I|Model|GPU|Lane",
+		"workers.sortedBy":     "• Sorted by: %s\n\n",
+		"workers.invalidSort":  "Invalid sort order: %s (`sort=gen`, `sort=count`, `sort=name`, `sort=tokens`)",
+		"workers.modelGeneral": "General",
+		"workers.modelOther":   "Other",
+
+		// Help
+		"help": "Available commands:\n\n" +
+			"`/help` - Show this help\n" +
+			"`/balance` - Show the Vast.ai balance\n" +
+			"`/status` - Show instance status\n" +
+			"`/report` - Show a detailed report\n" +
+			"`/cost` - Show Vast.ai and Kuzco daily costs and balance\n" +
+			"`/hourly` - Show stats for the last hour\n" +
+			"`/daily` - Show hourly RPM and instance trends for the last 24 hours\n" +
+			"`/workers` - Show hourly generations per worker (`--all`: include archived workers, `sort=gen|count|name`: sort order)\n" +
+			"`/instances` - Show Vast.ai instances with their workers\n" +
+			"`/total` - Show a combined report for all accounts\n" +
+			"`/export` - Send current metrics as a JSON file\n" +
+			"`/lanes` - Show instances and generations per lane\n" +
+			"`/top` - Show the top and bottom 5 workers by tokens per instance\n" +
+			"`/diff` - Show token, share, instance and balance changes since the last hourly report\n" +
+			"`/unpriced` - Show GPU models without a price in instance.json\n" +
+			"`/price [gpu]` - Show the per-day GPU prices (disk cost included) used for cost calculations\n" +
+			"`/gpuhealth` - Show GPU temperature, utilization and power per instance, flagging hot or idle GPUs\n" +
+			"`/geo` - Show instances and Running instances per country/region\n" +
+			"`/dupes` - Show instances reporting the same IP\n" +
+			"`/teams` - Show tokens, share, instances and generations per team configured in `kuzco.teams`\n" +
+			"`/genhistory` - Show the last 24 hours of generations as a bar chart\n" +
+			"`/genhours <n>` - Show the last n hours (max 168) of generations as a table\n" +
+			"`/earnings` - Show hourly token earnings over the last 24 hours (in USD when configured)\n" +
+			"`/forecast` - Show days until the credit runs out and each worker's share of daily spend\n" +
+			"`/vast` - Show the raw Vast.ai balance, instance count and status breakdown\n" +
+			"`/charges [YYYY-MM-DD]` - List each Vast.ai charge of a UTC day (default: yesterday)\n" +
+			"`/logs <instanceID>` - Show recent logs of a Vast.ai instance\n" +
+			"`/restart <instanceID>` - Reboot a Vast.ai instance (send twice within a minute to confirm)\n" +
+			"`/rebootall` - Reboot every instance with a detected heartbeat timeout now\n" +
+			"`/ignore <instanceID>` - Exclude an instance from auto reboot (no argument: list, `/unignore <instanceID>`: include again)\n" +
+			"`/alerts` - Show active alerts and how long they have been active (`/alerts ack <type>`: acknowledge until it clears)\n" +
+			"`/snooze <duration>` - Mute all alerts for a duration (`/snooze off`: resume)\n" +
+			"`/config` - Show the current configuration with passwords and tokens redacted\n" +
+			"`/threads` - Show the thread ID of the topic the command was sent in (for setup)\n" +
+			"`/selftest` - Run one collection cycle and show how long each phase (login, metrics, workers, Vast.ai) took\n\n" +
+			"Append an account name to a command to query that account (e.g. `/status account2`)",
+	},
+}
+
+// setReportLocale는 메시지 로케일을 설정합니다. 지원하지 않는 로케일은 무시됩니다
+func setReportLocale(locale string) {
+	if locale == "" {
+		return
+	}
+	if _, ok := messageCatalog[locale]; !ok {
+		log.Printf("Unsupported report locale %q, using %q", locale, reportLocale)
+		return
+	}
+	reportLocale = locale
+}
+
+// msg는 현재 로케일의 메시지 템플릿을 반환합니다
+// 현재 로케일에 없는 키는 한국어 템플릿으로 대체합니다
+func msg(key string) string {
+	if text, ok := messageCatalog[reportLocale][key]; ok {
+		return text
+	}
+	return messageCatalog[localeKorean][key]
+}
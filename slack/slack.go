@@ -0,0 +1,100 @@
+package slack
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"test/api"
+)
+
+// Slack attachment colors by alert type
+const (
+	ColorError    = "#d00000"
+	ColorStatus   = "#f2c744"
+	ColorRecovery = "#2eb886"
+	ColorDefault  = "#439fe0"
+)
+
+// Attachment is a Slack message attachment
+type Attachment struct {
+	Color    string   `json:"color"`
+	Title    string   `json:"title,omitempty"`
+	Text     string   `json:"text"`
+	Footer   string   `json:"footer,omitempty"`
+	Ts       int64    `json:"ts,omitempty"`
+	MrkdwnIn []string `json:"mrkdwn_in,omitempty"`
+}
+
+// Payload is the body posted to a Slack incoming webhook
+type Payload struct {
+	Attachments []Attachment `json:"attachments"`
+}
+
+// Client represents a Slack incoming-webhook client
+type Client struct {
+	WebhookURL string
+	httpClient *http.Client
+}
+
+// NewClient creates a new Slack client
+func NewClient(webhookURL string) *Client {
+	return &Client{
+		WebhookURL: webhookURL,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// ColorForAlert maps the severity of an alert (api.AlertSeverity) to a Slack attachment color
+func ColorForAlert(message, alertType string) string {
+	switch api.AlertSeverity(message, alertType) {
+	case api.SeverityRecovery:
+		return ColorRecovery
+	case api.SeverityError:
+		return ColorError
+	case api.SeverityStatus:
+		return ColorStatus
+	default:
+		return ColorDefault
+	}
+}
+
+// SendAlert posts an alert to Slack as a color-coded attachment
+// The first line of the message becomes the attachment title
+func (c *Client) SendAlert(message, alertType string) error {
+	title, text := message, ""
+	if idx := strings.Index(message, "\n"); idx >= 0 {
+		title, text = message[:idx], message[idx+1:]
+	}
+
+	payload := Payload{
+		Attachments: []Attachment{{
+			Color:    ColorForAlert(message, alertType),
+			Title:    title,
+			Text:     text,
+			Footer:   alertType,
+			Ts:       time.Now().Unix(),
+			MrkdwnIn: []string{"text"},
+		}},
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal slack payload: %w", err)
+	}
+
+	resp, err := c.httpClient.Post(c.WebhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to send slack message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("slack webhook returned non-200 status code: %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package slack
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestColorForAlert(t *testing.T) {
+	tests := []struct {
+		message   string
+		alertType string
+		expected  string
+	}{
+		{"⚠️ Version Mismatch Alert", "error", ColorError},
+		{"⚠️ Credit Alert", "status", ColorStatus},
+		{"✅ Credit Balance Recovered", "status", ColorRecovery},
+		{"✅ Version Mismatch Resolved", "error", ColorRecovery},
+		{"2025-01-01 report", "daily", ColorDefault},
+	}
+
+	for _, tt := range tests {
+		if got := ColorForAlert(tt.message, tt.alertType); got != tt.expected {
+			t.Errorf("ColorForAlert(%q, %q) = %s, expected %s", tt.message, tt.alertType, got, tt.expected)
+		}
+	}
+}
+
+func TestSendAlert(t *testing.T) {
+	var received Payload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("failed to decode payload: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	if err := client.SendAlert("⚠️ Credit Alert\nbalance low", "status"); err != nil {
+		t.Fatalf("SendAlert failed: %v", err)
+	}
+
+	if len(received.Attachments) != 1 {
+		t.Fatalf("Expected 1 attachment, got %d", len(received.Attachments))
+	}
+	att := received.Attachments[0]
+	if att.Color != ColorStatus {
+		t.Errorf("Expected color %s, got %s", ColorStatus, att.Color)
+	}
+	if att.Title != "⚠️ Credit Alert" || att.Text != "balance low" {
+		t.Errorf("Unexpected title/text: %q / %q", att.Title, att.Text)
+	}
+}
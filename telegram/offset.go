@@ -0,0 +1,48 @@
+package telegram
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// DefaultOffsetFile은 마지막으로 처리한 업데이트 오프셋을 저장하는 기본 파일 경로입니다
+const DefaultOffsetFile = "data/telegram_offset"
+
+// LoadOffset은 파일에 저장된 getUpdates 오프셋을 불러옵니다
+// 파일이 없으면 0을 반환합니다
+func LoadOffset(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("error reading offset file: %w", err)
+	}
+
+	offset, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0, fmt.Errorf("error parsing offset file: %w", err)
+	}
+	return offset, nil
+}
+
+// SaveOffset은 다음에 요청할 getUpdates 오프셋(마지막 UpdateID + 1)을 임시 파일에 쓴 뒤 교체하여 저장합니다
+func SaveOffset(path string, offset int) error {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return fmt.Errorf("error creating offset directory: %w", err)
+		}
+	}
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, []byte(strconv.Itoa(offset)), 0600); err != nil {
+		return fmt.Errorf("error writing offset file: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("error replacing offset file: %w", err)
+	}
+	return nil
+}
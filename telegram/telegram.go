@@ -1,13 +1,40 @@
 package telegram
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
+	"mime/multipart"
 	"net/http"
 	"net/url"
+	"strings"
+	"time"
 )
 
+// ParseMode is the Telegram formatting mode used when sending a message
+type ParseMode string
+
+const (
+	ParseModeMarkdown ParseMode = "Markdown"
+	ParseModeHTML     ParseMode = "HTML"
+)
+
+var (
+	markdownEscaper = strings.NewReplacer("_", "\\_", "*", "\\*", "`", "\\`", "[", "\\[")
+	htmlEscaper     = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+)
+
+// EscapeMarkdown escapes characters that have special meaning in Telegram Markdown
+func EscapeMarkdown(text string) string {
+	return markdownEscaper.Replace(text)
+}
+
+// EscapeHTML escapes characters that have special meaning in Telegram HTML
+func EscapeHTML(text string) string {
+	return htmlEscaper.Replace(text)
+}
+
 type Update struct {
 	UpdateID int `json:"update_id"`
 	Message  struct {
@@ -15,57 +42,235 @@ type Update struct {
 		Chat struct {
 			ID int64 `json:"id"`
 		} `json:"chat"`
+		From struct {
+			ID       int64  `json:"id"`
+			Username string `json:"username"`
+		} `json:"from"`
 		MessageThreadID int `json:"message_thread_id"`
 	} `json:"message"`
 }
 
+// DefaultPollTimeout is the getUpdates long-poll timeout used by NewClient
+const DefaultPollTimeout = 30 * time.Second
+
+// pollTimeoutMargin is added to the long-poll timeout for the HTTP request,
+// so the connection is not cut before Telegram answers an empty poll
+const pollTimeoutMargin = 10 * time.Second
+
+// DefaultAPIURL is the Telegram Bot API base URL used by NewClient
+const DefaultAPIURL = "https://api.telegram.org"
+
+// maxSendAttempts is how many times a send is tried when Telegram answers 429 Too Many Requests
+const maxSendAttempts = 4
+
+// maxRetryAfter caps the retry_after wait so a single send cannot block the caller for too long
+const maxRetryAfter = time.Minute
+
+// retrySleep waits before retrying a rate-limited send (replaced in tests)
+var retrySleep = time.Sleep
+
 // Client represents a Telegram bot client
 type Client struct {
 	Token  string
 	ChatID string
+
+	// PollTimeout is how long GetUpdates waits for new updates (0: return immediately)
+	PollTimeout time.Duration
+
+	// APIURL is the Bot API base URL (empty: DefaultAPIURL)
+	APIURL string
+}
+
+// APIError is returned when the Bot API answers with a non-200 status code
+type APIError struct {
+	StatusCode  int
+	Description string
+	// RetryAfter is how long Telegram asks to wait before the next request (429 only)
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	if e.Description == "" {
+		return fmt.Sprintf("telegram API returned non-200 status code: %d", e.StatusCode)
+	}
+	return fmt.Sprintf("telegram API returned non-200 status code: %d (%s)", e.StatusCode, e.Description)
+}
+
+// methodURL returns the Bot API URL of method
+func (c *Client) methodURL(method string) string {
+	base := c.APIURL
+	if base == "" {
+		base = DefaultAPIURL
+	}
+	return fmt.Sprintf("%s/bot%s/%s", strings.TrimRight(base, "/"), c.Token, method)
+}
+
+// parseAPIError reads a non-200 Bot API response body ({"description", "parameters": {"retry_after"}})
+func parseAPIError(resp *http.Response) *APIError {
+	apiErr := &APIError{StatusCode: resp.StatusCode}
+	var body struct {
+		Description string `json:"description"`
+		Parameters  struct {
+			RetryAfter int `json:"retry_after"`
+		} `json:"parameters"`
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err == nil && json.Unmarshal(data, &body) == nil {
+		apiErr.Description = body.Description
+		apiErr.RetryAfter = time.Duration(body.Parameters.RetryAfter) * time.Second
+	}
+	return apiErr
+}
+
+// postWithRetry sends the request built by send and, when Telegram answers 429,
+// waits for retry_after (1s if missing, at most maxRetryAfter) and tries again up to maxSendAttempts times
+func postWithRetry(send func() (*http.Response, error)) error {
+	for attempt := 1; ; attempt++ {
+		resp, err := send()
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode == http.StatusOK {
+			resp.Body.Close()
+			return nil
+		}
+		apiErr := parseAPIError(resp)
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusTooManyRequests || attempt >= maxSendAttempts {
+			return apiErr
+		}
+		wait := apiErr.RetryAfter
+		if wait <= 0 {
+			wait = time.Second
+		}
+		if wait > maxRetryAfter {
+			wait = maxRetryAfter
+		}
+		retrySleep(wait)
+	}
 }
 
 // NewClient creates a new Telegram client
 func NewClient(token, chatID string) *Client {
 	return &Client{
-		Token:  token,
-		ChatID: chatID,
+		Token:       token,
+		ChatID:      chatID,
+		PollTimeout: DefaultPollTimeout,
 	}
 }
 
-// SendMessage sends a message to Telegram using the specified thread
+// SendMessage sends a Markdown message to Telegram using the specified thread
 func (c *Client) SendMessage(threadID int, message string) error {
-	apiURL := fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage", c.Token)
+	return c.SendMessageWithMode(threadID, message, ParseModeMarkdown)
+}
+
+// SendMessageMarkdown sends a message formatted with Telegram Markdown
+func (c *Client) SendMessageMarkdown(threadID int, message string) error {
+	return c.SendMessageWithMode(threadID, message, ParseModeMarkdown)
+}
+
+// SendMessageHTML sends a message formatted with Telegram HTML
+func (c *Client) SendMessageHTML(threadID int, message string) error {
+	return c.SendMessageWithMode(threadID, message, ParseModeHTML)
+}
+
+// SendMessageWithMode sends a message to Telegram using the specified thread and parse mode
+func (c *Client) SendMessageWithMode(threadID int, message string, mode ParseMode) error {
+	apiURL := c.methodURL("sendMessage")
 
 	params := url.Values{}
 	params.Add("chat_id", c.ChatID)
 	params.Add("text", message)
-	params.Add("parse_mode", "Markdown")
+	if mode != "" {
+		params.Add("parse_mode", string(mode))
+	}
 	if threadID > 0 {
 		params.Add("message_thread_id", fmt.Sprintf("%d", threadID))
 	}
 
-	resp, err := http.PostForm(apiURL, params)
+	return postWithRetry(func() (*http.Response, error) {
+		resp, err := http.PostForm(apiURL, params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to send telegram message: %w", err)
+		}
+		return resp, nil
+	})
+}
+
+// SendDocument uploads a file to Telegram using the specified thread
+func (c *Client) SendDocument(threadID int, filename string, data []byte) error {
+	return c.sendFile("sendDocument", "document", threadID, filename, data, "")
+}
+
+// SendPhoto uploads an image to Telegram using the specified thread, with an optional Markdown caption
+func (c *Client) SendPhoto(threadID int, filename string, data []byte, caption string) error {
+	return c.sendFile("sendPhoto", "photo", threadID, filename, data, caption)
+}
+
+// sendFile은 method(sendDocument, sendPhoto)로 파일을 multipart 형식으로 업로드합니다
+func (c *Client) sendFile(method, field string, threadID int, filename string, data []byte, caption string) error {
+	apiURL := c.methodURL(method)
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	if err := writer.WriteField("chat_id", c.ChatID); err != nil {
+		return fmt.Errorf("failed to write chat_id field: %w", err)
+	}
+	if threadID > 0 {
+		if err := writer.WriteField("message_thread_id", fmt.Sprintf("%d", threadID)); err != nil {
+			return fmt.Errorf("failed to write message_thread_id field: %w", err)
+		}
+	}
+	if caption != "" {
+		if err := writer.WriteField("caption", caption); err != nil {
+			return fmt.Errorf("failed to write caption field: %w", err)
+		}
+		if err := writer.WriteField("parse_mode", string(ParseModeMarkdown)); err != nil {
+			return fmt.Errorf("failed to write parse_mode field: %w", err)
+		}
+	}
+	part, err := writer.CreateFormFile(field, filename)
 	if err != nil {
-		return fmt.Errorf("failed to send telegram message: %w", err)
+		return fmt.Errorf("failed to create %s part: %w", field, err)
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("telegram API returned non-200 status code: %d", resp.StatusCode)
+	if _, err := part.Write(data); err != nil {
+		return fmt.Errorf("failed to write %s: %w", field, err)
 	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to finalize multipart body: %w", err)
+	}
+
+	// 재시도할 때마다 같은 본문을 다시 읽을 수 있도록 바이트로 보관
+	payload := body.Bytes()
+	return postWithRetry(func() (*http.Response, error) {
+		resp, err := http.Post(apiURL, writer.FormDataContentType(), bytes.NewReader(payload))
+		if err != nil {
+			return nil, fmt.Errorf("failed to send telegram %s: %w", field, err)
+		}
+		return resp, nil
+	})
+}
 
-	return nil
+// ConflictError is returned by GetUpdates when Telegram responds with 409 Conflict,
+// which means another client is already polling updates with the same bot token
+type ConflictError struct {
+	Description string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("telegram getUpdates conflict: %s", e.Description)
 }
 
 // GetUpdates retrieves updates from Telegram bot API
 func (c *Client) GetUpdates(offset int) ([]Update, error) {
-	apiURL := fmt.Sprintf("https://api.telegram.org/bot%s/getUpdates", c.Token)
+	apiURL := c.methodURL("getUpdates")
 	params := url.Values{}
 	params.Add("offset", fmt.Sprintf("%d", offset))
-	params.Add("timeout", "30")
+	params.Add("timeout", fmt.Sprintf("%d", int(c.PollTimeout.Seconds())))
 
-	resp, err := http.Get(apiURL + "?" + params.Encode())
+	httpClient := &http.Client{Timeout: c.PollTimeout + pollTimeoutMargin}
+	resp, err := httpClient.Get(apiURL + "?" + params.Encode())
 	if err != nil {
 		return nil, err
 	}
@@ -77,13 +282,18 @@ func (c *Client) GetUpdates(offset int) ([]Update, error) {
 	}
 
 	var result struct {
-		Ok     bool     `json:"ok"`
-		Result []Update `json:"result"`
+		Ok          bool     `json:"ok"`
+		Description string   `json:"description"`
+		Result      []Update `json:"result"`
 	}
 
 	if err := json.Unmarshal(body, &result); err != nil {
 		return nil, err
 	}
 
+	if resp.StatusCode == http.StatusConflict {
+		return nil, &ConflictError{Description: result.Description}
+	}
+
 	return result.Result, nil
 }
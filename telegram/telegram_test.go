@@ -0,0 +1,116 @@
+package telegram
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestEscapeMarkdown(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"worker1", "worker1"},
+		{"my_worker", `my\_worker`},
+		{"*bold*", `\*bold\*`},
+		{"`code`", "\\`code\\`"},
+		{"[link](x)", `\[link](x)`},
+		{"gpu_v2_*test*", `gpu\_v2\_\*test\*`},
+	}
+
+	for _, tt := range tests {
+		if got := EscapeMarkdown(tt.input); got != tt.expected {
+			t.Errorf("EscapeMarkdown(%q) = %q, expected %q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestEscapeHTML(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"worker1", "worker1"},
+		{"<b>name</b>", "&lt;b&gt;name&lt;/b&gt;"},
+		{"a & b", "a &amp; b"},
+		{"my_worker*", "my_worker*"},
+	}
+
+	for _, tt := range tests {
+		if got := EscapeHTML(tt.input); got != tt.expected {
+			t.Errorf("EscapeHTML(%q) = %q, expected %q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestOffsetRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "telegram_offset")
+
+	offset, err := LoadOffset(path)
+	if err != nil || offset != 0 {
+		t.Fatalf("expected 0 without a file, got %d (%v)", offset, err)
+	}
+
+	if err := SaveOffset(path, 12345); err != nil {
+		t.Fatalf("SaveOffset failed: %v", err)
+	}
+	offset, err = LoadOffset(path)
+	if err != nil || offset != 12345 {
+		t.Errorf("expected 12345, got %d (%v)", offset, err)
+	}
+}
+
+func TestSendMessageRetriesOn429(t *testing.T) {
+	var waits []time.Duration
+	retrySleep = func(d time.Duration) { waits = append(waits, d) }
+	defer func() { retrySleep = time.Sleep }()
+
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"ok":false,"error_code":429,"description":"Too Many Requests: retry after 7","parameters":{"retry_after":7}}`))
+			return
+		}
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("token", "chat")
+	client.APIURL = server.URL
+	if err := client.SendMessage(1, "hello"); err != nil {
+		t.Fatalf("expected the send to succeed after retries, got %v", err)
+	}
+	if attempts != 3 || len(waits) != 2 || waits[0] != 7*time.Second {
+		t.Errorf("expected 3 attempts with 7s waits, got %d attempts, waits %v", attempts, waits)
+	}
+}
+
+func TestSendMessageGivesUpOn429(t *testing.T) {
+	retrySleep = func(time.Duration) {}
+	defer func() { retrySleep = time.Sleep }()
+
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"ok":false,"description":"Too Many Requests","parameters":{"retry_after":1}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("token", "chat")
+	client.APIURL = server.URL
+	err := client.SendMessage(1, "hello")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests || apiErr.RetryAfter != time.Second {
+		t.Fatalf("expected a 429 APIError, got %v", err)
+	}
+	if attempts != maxSendAttempts {
+		t.Errorf("expected %d attempts, got %d", maxSendAttempts, attempts)
+	}
+}